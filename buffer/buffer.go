@@ -20,6 +20,10 @@ type Buffer struct {
 	// この Buffer が保持する Page が変更された場合に、最新の LSN を保持する.
 	// もし LSN が負の値ならば、その変更に該当するログレコードは作成されないことを意味する.
 	lsn log.LSN
+
+	// 削除したファイルのブロックを保持していて、pin されていたので破棄できなかったかどうか.
+	// 他のブロックとして再利用されないように、unpin された時に破棄する.
+	discarded bool
 }
 
 func NewBuffer(fm *file.FileManager, lm *log.LogManager) *Buffer {
@@ -66,7 +70,7 @@ func (b *Buffer) assignToBlock(blockID file.BlockID) {
 }
 
 func (b *Buffer) flush() {
-	if b.transactionNumber >= 0 {
+	if b.transactionNumber >= 0 && !b.discarded {
 		// 先にログをディスクに書き込むのが大事
 		b.logManager.Flush(b.lsn)
 		b.fileManager.Write(b.blockID, b.contents)
@@ -74,6 +78,14 @@ func (b *Buffer) flush() {
 	}
 }
 
+// 削除したファイルのブロックを、後でファイルに書き戻さないように割り当てを外す.
+func (b *Buffer) discard() {
+	b.blockID = file.BlockID{}
+	b.transactionNumber = -1
+	b.lsn = -1
+	b.discarded = false
+}
+
 func (b *Buffer) pin() {
 	b.pinCount++
 }
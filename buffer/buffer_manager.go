@@ -87,6 +87,20 @@ func (bm *BufferManager) FlushAll(transactionNumber types.TransactionNumber) {
 	<-replyChan
 }
 
+// 削除するファイルのブロックを保持しているバッファーを破棄する. ファイルを削除した後に書き戻されないようにするため.
+func (bm *BufferManager) DiscardFile(fileName string) {
+	replyChan := make(chan bool)
+	defer close(replyChan)
+
+	req := &DiscardFileRequest{
+		fileName:  fileName,
+		replyChan: replyChan,
+	}
+
+	bm.requestChan <- req
+	<-replyChan
+}
+
 func (bm *BufferManager) Unpin(buffer *Buffer) {
 	replyChan := make(chan bool)
 	defer close(replyChan)
@@ -172,6 +186,29 @@ func (far *FlushAllRequest) resolve(bm *BufferManager) {
 	far.replyChan <- true
 }
 
+type DiscardFileRequest struct {
+	fileName string
+	// 完了したことの通知だけするためのチャンネル. 値は使わない.
+	replyChan chan bool
+}
+
+// ファイルのブロックを保持しているバッファーを、ファイルに書き込まずに未割り当ての状態に戻す.
+// pin されているバッファーは、使用中の呼び出し元があるので印だけつけておき、unpin された時に破棄する.
+// 印のついたバッファーはファイルに書き込まず、同じ名前で作り直したファイルのブロックとしても使わない.
+func (dfr *DiscardFileRequest) resolve(bm *BufferManager) {
+	for _, buffer := range bm.bufferPool {
+		if buffer.GetBlockID().Filename != dfr.fileName {
+			continue
+		}
+		if buffer.IsPinned() {
+			buffer.discarded = true
+		} else {
+			buffer.discard()
+		}
+	}
+	dfr.replyChan <- true
+}
+
 type UnpinRequest struct {
 	buffer *Buffer
 	// 完了したことの通知だけするためのチャンネル. 値は使わない.
@@ -181,6 +218,9 @@ type UnpinRequest struct {
 func (ur *UnpinRequest) resolve(bm *BufferManager) {
 	ur.buffer.unpin()
 	if !ur.buffer.IsPinned() {
+		if ur.buffer.discarded {
+			ur.buffer.discard()
+		}
 		bm.numAvailable++
 		bm.notifyAll()
 	}
@@ -257,7 +297,7 @@ func (bm *BufferManager) tryToPin(blockID file.BlockID) *Buffer {
 func (bm *BufferManager) findExistngBuffer(blockID file.BlockID) *Buffer {
 	// TODO: これO(1)に改善できそう
 	for _, buffer := range bm.bufferPool {
-		if buffer.GetBlockID() == blockID {
+		if buffer.GetBlockID() == blockID && !buffer.discarded {
 			return buffer
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"simple-db-go/file"
+	"simple-db-go/log"
 	"simple-db-go/types"
 	"testing"
 	"time"
@@ -120,3 +121,47 @@ func TestPinUnpinBuffer(t *testing.T) {
 		assert.Equal(t, types.Int(0), bufferManager.numAvailable, "numAvailable should be 0")
 	})
 }
+
+func TestDiscardFile(t *testing.T) {
+	getBufferManagerForTest(t)
+	fileManager := file.GetManagerForTest(bufferManagerTestName)
+	logManager := log.GetManagerForTest(bufferManagerTestName)
+	bufferManager := NewBufferManager(fileManager, logManager, bufferPoolSize)
+
+	fileName := "test_discard_file"
+	blockID := file.NewBlockID(fileName, 0)
+	page := file.NewPage(blockSize)
+	page.SetInt(0, 1)
+	fileManager.Write(blockID, page)
+
+	t.Run("pin されていないバッファーは、すぐに破棄される.", func(t *testing.T) {
+		buffer := bufferManager.Pin(blockID)
+		bufferManager.Unpin(buffer)
+
+		bufferManager.DiscardFile(fileName)
+		assert.Equal(t, file.BlockID{}, buffer.GetBlockID(), "ブロックの割り当てが外れる.")
+	})
+
+	t.Run("pin されているバッファーは、unpin された時に破棄され、それまでは同じブロックとして使われない.", func(t *testing.T) {
+		buffer := bufferManager.Pin(blockID)
+		buffer.GetContents().SetInt(0, 2)
+		buffer.SetModified(1, -1)
+
+		bufferManager.DiscardFile(fileName)
+		assert.Equal(t, blockID, buffer.GetBlockID(), "pin されている間は、ブロックの割り当てはそのまま.")
+
+		// 同じ名前でファイルを作り直したものとして、同じブロックを pin する.
+		fileManager.Write(blockID, file.NewPage(blockSize))
+		recreatedBuffer := bufferManager.Pin(blockID)
+		assert.NotSame(t, buffer, recreatedBuffer, "破棄する予定のバッファーは使わない.")
+		assert.Equal(t, types.Int(0), recreatedBuffer.GetContents().GetInt(0), "作り直したファイルの内容が読まれる.")
+		bufferManager.Unpin(recreatedBuffer)
+
+		bufferManager.Unpin(buffer)
+		assert.Equal(t, file.BlockID{}, buffer.GetBlockID(), "unpin されると、ブロックの割り当てが外れる.")
+
+		bufferManager.FlushAll(1)
+		fileManager.Read(blockID, page)
+		assert.Equal(t, types.Int(0), page.GetInt(0), "破棄したバッファーの変更はファイルに書き込まれない.")
+	})
+}
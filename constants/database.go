@@ -2,12 +2,30 @@ package constants
 
 import "simple-db-go/types"
 
-// テーブルやカラム名の最大長.
-const MAX_NAME_LENGTH = 16
+// テーブルやカラム名などの識別子の最大長. MySQL に合わせている.
+// NOTE: カタログの1行は1つのブロックに収まる必要がある. 識別子を4つ持つ fk_catalog の1行が 284 バイトになるので、ブロックサイズはそれ以上にすること.
+const MAX_NAME_LENGTH = 64
 
-// View の定義本体の最大文字数
-// NOTE: もちろん、ありえないくらい小さすぎる。書籍には、clob(9999)とかの方がマシと書いてある.
-const MAX_VIEW_DEF_LENGTH = 100
+// View の定義本体は、このバイト数ごとに分割して view_catalog の複数の行に保存する. 定義全体の長さに上限は無い.
+const VIEW_DEF_CHUNK_LENGTH = 100
+
+// 制約の定義本体の最大文字数
+const MAX_CONSTRAINT_DEF_LENGTH = 100
+
+// 統計情報のカタログに保存する、列の最小値・最大値とヒストグラムの上限値の最大文字数.
+// これより長い文字列は切り詰めて保存するので、読み込んだ統計情報では推定が少し不正確になる.
+const MAX_STAT_VALUE_LENGTH = 32
+
+// レコードの物理的な形式. 形式を変えた後も、それより前に作られたテーブルのファイルを正しく読めるようにするため、テーブルごとに記録する.
+const (
+	// null bitmap が無い、最初の形式.
+	RECORD_FORMAT_WITHOUT_NULL_BITMAP types.RecordFormat = 0
+	// スロットの末尾に null bitmap を置く形式.
+	RECORD_FORMAT_WITH_NULL_BITMAP types.RecordFormat = 1
+
+	// 新しく作るテーブルの形式.
+	CURRENT_RECORD_FORMAT = RECORD_FORMAT_WITH_NULL_BITMAP
+)
 
 // jdbc の値に合わせている.
 // https://docs.oracle.com/javase/jp/8/docs/api/java/sql/Types.html
@@ -15,3 +33,22 @@ const (
 	INTEGER types.FieldType = 4
 	VARCHAR types.FieldType = 12
 )
+
+// フィールドに指定できる制約の種類.
+const (
+	NOT_NULL types.ConstraintType = 1
+	DEFAULT  types.ConstraintType = 2
+	CHECK    types.ConstraintType = 3
+	// PRIMARY KEY は NOT NULL かつ UNIQUE で、テーブルに1つだけ指定できる.
+	PRIMARY_KEY types.ConstraintType = 4
+	UNIQUE      types.ConstraintType = 5
+	// AUTO_INCREMENT は INT のフィールドにだけ、テーブルに1つだけ指定できる.
+	AUTO_INCREMENT types.ConstraintType = 6
+)
+
+// 外部キーの ON DELETE で指定できる動作. 省略した場合は RESTRICT になる.
+const (
+	RESTRICT types.ReferentialAction = 1
+	CASCADE  types.ReferentialAction = 2
+	SET_NULL types.ReferentialAction = 3
+)
@@ -182,6 +182,93 @@ func (gbl *GetBlockLength) handleError(err error) {
 	gbl.replyChan <- -1
 }
 
+type RemoveFileRequest struct {
+	fileName  string
+	errorChan chan error
+}
+
+func (rfr *RemoveFileRequest) getFileName(fm *FileManager) string {
+	return filepath.Join(fm.dbDirectoryPath, rfr.fileName)
+}
+
+// NOTE: 他のリクエストと同じく、ファイルが存在しない場合は一度作ってから削除する.
+func (rfr *RemoveFileRequest) openFile(fm *FileManager) (*os.File, error) {
+	return os.OpenFile(rfr.getFileName(fm), fileFlag, 0644)
+}
+
+func (rfr *RemoveFileRequest) resolve(f *os.File, fm *FileManager) {
+	delete(fm.files, rfr.getFileName(fm))
+	if err := f.Close(); err != nil {
+		rfr.handleError(err)
+		return
+	}
+	rfr.handleError(os.Remove(rfr.getFileName(fm)))
+}
+
+func (rfr *RemoveFileRequest) handleError(err error) {
+	rfr.errorChan <- err
+}
+
+type TruncateFileRequest struct {
+	fileName   string
+	blockCount types.Int
+	errorChan  chan error
+}
+
+func (tfr *TruncateFileRequest) getFileName(fm *FileManager) string {
+	return filepath.Join(fm.dbDirectoryPath, tfr.fileName)
+}
+
+func (tfr *TruncateFileRequest) openFile(fm *FileManager) (*os.File, error) {
+	return os.OpenFile(tfr.getFileName(fm), fileFlag, 0644)
+}
+
+func (tfr *TruncateFileRequest) resolve(f *os.File, fm *FileManager) {
+	tfr.handleError(f.Truncate(int64(tfr.blockCount) * int64(fm.BlockSize())))
+}
+
+func (tfr *TruncateFileRequest) handleError(err error) {
+	tfr.errorChan <- err
+}
+
+type RenameFileRequest struct {
+	fileName    string
+	newFileName string
+	errorChan   chan error
+}
+
+func (rfr *RenameFileRequest) getFileName(fm *FileManager) string {
+	return filepath.Join(fm.dbDirectoryPath, rfr.fileName)
+}
+
+// NOTE: 他のリクエストと同じく、ファイルが存在しない場合は空のファイルを作ってから名前を変える.
+func (rfr *RenameFileRequest) openFile(fm *FileManager) (*os.File, error) {
+	return os.OpenFile(rfr.getFileName(fm), fileFlag, 0644)
+}
+
+// 変更後の名前のファイルが既にある場合は置き換える. 開いていたファイルは閉じておく.
+func (rfr *RenameFileRequest) resolve(f *os.File, fm *FileManager) {
+	delete(fm.files, rfr.getFileName(fm))
+	if err := f.Close(); err != nil {
+		rfr.handleError(err)
+		return
+	}
+
+	newFileName := filepath.Join(fm.dbDirectoryPath, rfr.newFileName)
+	if newFile, exists := fm.files[newFileName]; exists {
+		delete(fm.files, newFileName)
+		if err := newFile.Close(); err != nil {
+			rfr.handleError(err)
+			return
+		}
+	}
+	rfr.handleError(os.Rename(rfr.getFileName(fm), newFileName))
+}
+
+func (rfr *RenameFileRequest) handleError(err error) {
+	rfr.errorChan <- err
+}
+
 func (fm *FileManager) initDbDirectory() {
 	if _, err := os.Stat(fm.dbDirectoryPath); os.IsNotExist(err) {
 		fm.isNew = true
@@ -302,6 +389,53 @@ func (fm *FileManager) GetBlockLength(fileName string) types.Int {
 	return <-req.replyChan
 }
 
+// ファイルを閉じて削除する.
+func (fm *FileManager) Remove(fileName string) {
+	req := &RemoveFileRequest{
+		fileName:  fileName,
+		errorChan: make(chan error),
+	}
+	fm.requestChan <- req
+
+	if err := <-req.errorChan; err != nil {
+		panic(fmt.Sprintf("ファイルの削除に失敗しました. %v", err))
+	}
+}
+
+// ファイルを指定したブロック数に切り詰める. 切り詰めた後ろのブロックの内容は失われる.
+func (fm *FileManager) Truncate(fileName string, blockCount types.Int) {
+	req := &TruncateFileRequest{
+		fileName:   fileName,
+		blockCount: blockCount,
+		errorChan:  make(chan error),
+	}
+	fm.requestChan <- req
+
+	if err := <-req.errorChan; err != nil {
+		panic(fmt.Sprintf("ファイルの切り詰めに失敗しました. %v", err))
+	}
+}
+
+// ファイルの名前を変える. 変更後の名前のファイルが既にある場合は置き換える.
+func (fm *FileManager) Rename(fileName string, newFileName string) {
+	req := &RenameFileRequest{
+		fileName:    fileName,
+		newFileName: newFileName,
+		errorChan:   make(chan error),
+	}
+	fm.requestChan <- req
+
+	if err := <-req.errorChan; err != nil {
+		panic(fmt.Sprintf("ファイル名の変更に失敗しました. %v", err))
+	}
+}
+
+// ファイルが存在するかどうか. 他のメソッドと違い、存在しないファイルを作らない.
+func (fm *FileManager) Exists(fileName string) bool {
+	_, err := os.Stat(filepath.Join(fm.dbDirectoryPath, fileName))
+	return err == nil
+}
+
 func (fm *FileManager) Close() {
 	fm.closeChan <- true
 }
@@ -92,3 +92,79 @@ func TestAppend(t *testing.T) {
 		t.Errorf("Expected empty block, got %v", value)
 	}
 }
+
+func TestRemove(t *testing.T) {
+	fileManager := getFileManagerForTest(t)
+
+	fileName := "test_remove_file"
+	fileManager.Write(NewBlockID(fileName, 0), NewPage(blockSize))
+	if _, err := os.Stat(filepath.Join(fileManagerTestName, fileName)); os.IsNotExist(err) {
+		t.Fatalf("Expected %s to exist, but it does not", fileName)
+	}
+
+	fileManager.Remove(fileName)
+	if _, err := os.Stat(filepath.Join(fileManagerTestName, fileName)); !os.IsNotExist(err) {
+		t.Fatalf("Expected %s to be removed, but it exists", fileName)
+	}
+
+	// 削除したファイルに再び書き込むと、新しいファイルとして作られる.
+	fileManager.Append(fileName)
+	if length := fileManager.GetBlockLength(fileName); length != 1 {
+		t.Errorf("Expected %d, got %d", 1, length)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	fileManager := getFileManagerForTest(t)
+
+	fileName := "test_truncate_file"
+	page := NewPage(blockSize)
+	page.SetString(0, "hello")
+	fileManager.Write(NewBlockID(fileName, 0), page)
+	fileManager.Write(NewBlockID(fileName, 1), page)
+	fileManager.Write(NewBlockID(fileName, 2), page)
+
+	fileManager.Truncate(fileName, 1)
+	if length := fileManager.GetBlockLength(fileName); length != 1 {
+		t.Errorf("Expected %d, got %d", 1, length)
+	}
+
+	// 残したブロックの内容は変わらない.
+	readPage := NewPage(blockSize)
+	fileManager.Read(NewBlockID(fileName, 0), readPage)
+	if value := readPage.GetString(0); value != "hello" {
+		t.Errorf("Expected 'hello', got '%s'", value)
+	}
+
+	fileManager.Truncate(fileName, 0)
+	if length := fileManager.GetBlockLength(fileName); length != 0 {
+		t.Errorf("Expected %d, got %d", 0, length)
+	}
+}
+
+func TestRename(t *testing.T) {
+	fileManager := getFileManagerForTest(t)
+
+	fileName := "test_rename_file"
+	newFileName := "test_rename_file_new"
+	page := NewPage(blockSize)
+	page.SetString(0, "hello")
+	fileManager.Write(NewBlockID(fileName, 0), page)
+	fileManager.Write(NewBlockID(newFileName, 0), NewPage(blockSize))
+	fileManager.Write(NewBlockID(newFileName, 1), NewPage(blockSize))
+
+	fileManager.Rename(fileName, newFileName)
+	if fileManager.Exists(fileName) {
+		t.Fatalf("Expected %s to be renamed, but it exists", fileName)
+	}
+
+	// 変更後の名前のファイルは置き換えられる.
+	if length := fileManager.GetBlockLength(newFileName); length != 1 {
+		t.Errorf("Expected %d, got %d", 1, length)
+	}
+	readPage := NewPage(blockSize)
+	fileManager.Read(NewBlockID(newFileName, 0), readPage)
+	if value := readPage.GetString(0); value != "hello" {
+		t.Errorf("Expected 'hello', got '%s'", value)
+	}
+}
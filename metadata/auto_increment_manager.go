@@ -0,0 +1,174 @@
+package metadata
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/file"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+// AUTO_INCREMENT のカウンタを管理する構造体.
+// どのフィールドが AUTO_INCREMENT かはカタログに保存し、カウンタの値はテーブルごとのファイルの先頭ブロックに保存する.
+// カウンタを進めるトランザクションはそのブロックだけを xlock するので、別のテーブルへの INSERT は互いに待たない.
+// カウンタは他のカタログと同じくトランザクションの中で更新するので、コミットしたカウンタはクラッシュ後のリカバリでも残り、
+// ロールバックしたトランザクションで採番した値は再び採番される.
+type AutoIncrementManager struct {
+	layout       *record.Layout
+	tableManager *TableManager
+}
+
+// AUTO_INCREMENT カタログは後から追加したので、既存のデータベースに無い場合も作成する.
+// カウンタのファイルも後から追加したので、既存のデータベースでファイルが無いカウンタは、カタログの next_value から作成する.
+func NewAutoIncrementManager(isNew bool, tableManager *TableManager, transaction *transaction.Transaction) *AutoIncrementManager {
+	if _, err := tableManager.GetLayout(AUTO_INCREMENT_CATALOG_TABLE_NAME, transaction); isNew || err != nil {
+		schema := record.NewSchema()
+		schema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		schema.AddStringField("field_name", constants.MAX_NAME_LENGTH)
+		schema.AddIntField("next_value")
+		tableManager.CreateTable(AUTO_INCREMENT_CATALOG_TABLE_NAME, schema, transaction)
+	}
+
+	layout, err := tableManager.GetLayout(AUTO_INCREMENT_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		// AUTO_INCREMENT カタログは必ず初期化するべきなので、無い場合は panic で落とす.
+		panic("AutoIncrementManager の初期化時に AUTO_INCREMENT カタログのレイアウトが取得できませんでした.")
+	}
+
+	autoIncrementManager := &AutoIncrementManager{
+		layout:       layout,
+		tableManager: tableManager,
+	}
+
+	if !isNew {
+		for _, row := range autoIncrementManager.readCatalogRows(transaction) {
+			if transaction.Size(autoIncrementFileName(row.TableName)) == 0 {
+				autoIncrementManager.SetNextValue(row.TableName, row.NextValue, transaction)
+			}
+		}
+	}
+	return autoIncrementManager
+}
+
+// テーブルのカウンタを保存するファイル名.
+func autoIncrementFileName(tableName types.TableName) string {
+	return string(tableName) + ".autoinc"
+}
+
+// カウンタを保存するブロック. ファイルが無い場合は作成する.
+// NOTE: ロールバックしたトランザクションが作成したファイルは、中身が 0 のまま残ることがあるので、ブロックがあればそれを使う.
+func counterBlockID(tableName types.TableName, transaction *transaction.Transaction) file.BlockID {
+	filename := autoIncrementFileName(tableName)
+	if transaction.Size(filename) == 0 {
+		return transaction.Append(filename)
+	}
+	return file.NewBlockID(filename, 0)
+}
+
+func (am *AutoIncrementManager) readCatalogRows(transaction *transaction.Transaction) []AutoIncrementCatalogRow {
+	tableScan := query.NewTableScan(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout)
+	defer tableScan.Close()
+
+	rows := []AutoIncrementCatalogRow{}
+	for tableScan.Next() {
+		rows = append(rows, ReadAutoIncrementCatalogRow(tableScan))
+	}
+	return rows
+}
+
+// テーブルの AUTO_INCREMENT のフィールドを登録し、カウンタのファイルを作成する. カウンタは 1 から始める.
+// autoinc_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
+// 単に panic させる.
+func (am *AutoIncrementManager) CreateAutoIncrement(tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) {
+	tableScan := query.NewTableScan(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout)
+	defer tableScan.Close()
+
+	tableScan.Insert()
+
+	if err := tableScan.SetString("table_name", string(tableName)); err != nil {
+		panic(fmt.Sprintf("[CreateAutoIncrement] autoinc_catalog テーブルの table_name に文字列をセットできませんでした. table_name=%s, error=%+v", tableName, err))
+	}
+
+	if err := tableScan.SetString("field_name", string(fieldName)); err != nil {
+		panic(fmt.Sprintf("[CreateAutoIncrement] autoinc_catalog テーブルの field_name に文字列をセットできませんでした. field_name=%s, error=%+v", fieldName, err))
+	}
+
+	if err := tableScan.SetInt("next_value", 1); err != nil {
+		panic(fmt.Sprintf("[CreateAutoIncrement] autoinc_catalog テーブルの next_value に整数をセットできませんでした. table_name=%s, error=%+v", tableName, err))
+	}
+
+	am.SetNextValue(tableName, 1, transaction)
+}
+
+// テーブルの AUTO_INCREMENT のフィールドとカウンタを返す. AUTO_INCREMENT のフィールドが無いテーブルでは false を返す.
+func (am *AutoIncrementManager) GetAutoIncrement(tableName types.TableName, transaction *transaction.Transaction) (AutoIncrementCatalogRow, bool) {
+	tableScan := query.NewTableScan(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout)
+	defer tableScan.Close()
+
+	for tableScan.Next() {
+		row := ReadAutoIncrementCatalogRow(tableScan)
+		if row.TableName == tableName {
+			row.NextValue = am.getNextValue(tableName, transaction)
+			return row, true
+		}
+	}
+
+	return AutoIncrementCatalogRow{}, false
+}
+
+func (am *AutoIncrementManager) getNextValue(tableName types.TableName, transaction *transaction.Transaction) types.Int {
+	blockID := counterBlockID(tableName, transaction)
+	transaction.Pin(blockID)
+	defer transaction.Unpin(blockID)
+	return transaction.GetInt(blockID, 0)
+}
+
+// テーブルのカウンタを、次に採番する値に更新する.
+func (am *AutoIncrementManager) SetNextValue(tableName types.TableName, nextValue types.Int, transaction *transaction.Transaction) {
+	blockID := counterBlockID(tableName, transaction)
+	transaction.Pin(blockID)
+	defer transaction.Unpin(blockID)
+	transaction.SetInt(blockID, 0, nextValue, true)
+}
+
+// 削除するテーブルの AUTO_INCREMENT のカウンタを削除する. カウンタのファイルはコミット時に削除される.
+func (am *AutoIncrementManager) DropTable(tableName types.TableName, transaction *transaction.Transaction) {
+	if _, exists := am.GetAutoIncrement(tableName, transaction); exists {
+		transaction.RemoveFileOnCommit(autoIncrementFileName(tableName))
+	}
+	am.DropAutoIncrement(tableName, transaction)
+}
+
+// テーブルの AUTO_INCREMENT のカウンタを削除する.
+// NOTE: 同じトランザクションで AUTO_INCREMENT を再び登録することがあるので、カウンタのファイルは削除せずに残す.
+// 再び登録した時には、カウンタを 1 に戻して使う.
+func (am *AutoIncrementManager) DropAutoIncrement(tableName types.TableName, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout, func(tableScan *query.TableScan) bool {
+		return ReadAutoIncrementCatalogRow(tableScan).TableName == tableName
+	})
+}
+
+// テーブル名の変更に合わせて、カウンタのテーブル名を変更する.
+// カウンタは新しいテーブル名のファイルに書き写し、元のファイルはコミット時に削除される.
+func (am *AutoIncrementManager) RenameTable(tableName types.TableName, newTableName types.TableName, transaction *transaction.Transaction) {
+	row, exists := am.GetAutoIncrement(tableName, transaction)
+	if !exists {
+		return
+	}
+	am.SetNextValue(newTableName, row.NextValue, transaction)
+	transaction.RemoveFileOnCommit(autoIncrementFileName(tableName))
+
+	UpdateCatalogRows(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout, func(tableScan *query.TableScan) bool {
+		return ReadAutoIncrementCatalogRow(tableScan).TableName == tableName
+	}, "table_name", string(newTableName))
+}
+
+// フィールド名の変更に合わせて、カウンタのフィールド名を変更する.
+func (am *AutoIncrementManager) RenameField(tableName types.TableName, fieldName types.FieldName, newFieldName types.FieldName, transaction *transaction.Transaction) {
+	UpdateCatalogRows(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, am.layout, func(tableScan *query.TableScan) bool {
+		row := ReadAutoIncrementCatalogRow(tableScan)
+		return row.TableName == tableName && row.FieldName == fieldName
+	}, "field_name", string(newFieldName))
+}
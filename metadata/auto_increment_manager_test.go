@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoIncrementManagerNewAutoIncrementManager(t *testing.T) {
+	transaction := newTransactionForTest(t, autoIncrementManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	NewAutoIncrementManager(true, tableManager, transaction)
+
+	t.Run("テーブルカタログに AUTO_INCREMENT カタログのレコードが登録されている.", func(t *testing.T) {
+		row, err := ReadTableCatalogRowFor(AUTO_INCREMENT_CATALOG_TABLE_NAME, transaction, tableManager)
+		if assert.NoError(t, err) {
+			assert.Equal(t, TableCatalogRow{AUTO_INCREMENT_CATALOG_TABLE_NAME, 148, constants.CURRENT_RECORD_FORMAT}, row, "AUTO_INCREMENT カタログのスロットサイズが期待した値であるはず.")
+		}
+	})
+
+	t.Run("フィールドカタログに AUTO_INCREMENT カタログのレコードが登録されている.", func(t *testing.T) {
+		expectedRecords := []FieldCatalogRow{
+			{AUTO_INCREMENT_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 64, 4},
+			{AUTO_INCREMENT_CATALOG_TABLE_NAME, "field_name", constants.VARCHAR, 64, 72},
+			{AUTO_INCREMENT_CATALOG_TABLE_NAME, "next_value", constants.INTEGER, 0, 140},
+		}
+		actualRecords := ReadFieldCatalogRowsFor(AUTO_INCREMENT_CATALOG_TABLE_NAME, transaction, tableManager)
+		assert.ElementsMatch(t, expectedRecords, actualRecords, "フィールドカタログに AUTO_INCREMENT カタログのレコードが登録されているはず.")
+	})
+}
+
+func TestAutoIncrementManagerCounter(t *testing.T) {
+	transaction := newTransactionForTest(t, autoIncrementManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	autoIncrementManager := NewAutoIncrementManager(true, tableManager, transaction)
+
+	autoIncrementManager.CreateAutoIncrement("users", "id", transaction)
+	autoIncrementManager.CreateAutoIncrement("orders", "order_id", transaction)
+
+	t.Run("登録直後のカウンタは 1 である.", func(t *testing.T) {
+		row, ok := autoIncrementManager.GetAutoIncrement("users", transaction)
+		if assert.True(t, ok) {
+			assert.Equal(t, AutoIncrementCatalogRow{"users", "id", 1}, row)
+		}
+	})
+
+	t.Run("カウンタを更新すると、そのテーブルのカウンタだけが変わる.", func(t *testing.T) {
+		autoIncrementManager.SetNextValue("orders", 10, transaction)
+
+		row, ok := autoIncrementManager.GetAutoIncrement("orders", transaction)
+		if assert.True(t, ok) {
+			assert.Equal(t, AutoIncrementCatalogRow{"orders", "order_id", 10}, row)
+		}
+
+		row, ok = autoIncrementManager.GetAutoIncrement("users", transaction)
+		if assert.True(t, ok) {
+			assert.Equal(t, AutoIncrementCatalogRow{"users", "id", 1}, row)
+		}
+	})
+
+	t.Run("AUTO_INCREMENT の無いテーブルでは false が返る.", func(t *testing.T) {
+		_, ok := autoIncrementManager.GetAutoIncrement("items", transaction)
+		assert.False(t, ok)
+	})
+}
+
+func TestAutoIncrementManagerCounterLock(t *testing.T) {
+	transaction := newTransactionForTest(t, autoIncrementCounterLockTestName)
+	tableManager := NewTableManager(true, transaction)
+	autoIncrementManager := NewAutoIncrementManager(true, tableManager, transaction)
+	autoIncrementManager.CreateAutoIncrement("members", "id", transaction)
+	autoIncrementManager.CreateAutoIncrement("posts", "id", transaction)
+	transaction.Commit()
+
+	t.Run("別のテーブルのカウンタを進めるトランザクションは、互いに待たない.", func(t *testing.T) {
+		transaction1 := newTransactionForTest(t, autoIncrementCounterLockTestName)
+		defer transaction1.Rollback()
+		transaction2 := newTransactionForTest(t, autoIncrementCounterLockTestName)
+		defer transaction2.Rollback()
+
+		autoIncrementManager.SetNextValue("members", 2, transaction1)
+		// 同じブロックのロックを待つ場合は、待ちの上限を超えて panic する.
+		assert.NotPanics(t, func() {
+			autoIncrementManager.SetNextValue("posts", 2, transaction2)
+		})
+	})
+}
+
+func TestAutoIncrementManagerLegacyCounter(t *testing.T) {
+	transaction := newTransactionForTest(t, autoIncrementManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	autoIncrementManager := NewAutoIncrementManager(true, tableManager, transaction)
+
+	// カウンタのファイルを追加する前のデータベースでは、カウンタはカタログの next_value にだけある.
+	tableScan := query.NewTableScan(transaction, AUTO_INCREMENT_CATALOG_TABLE_NAME, autoIncrementManager.layout)
+	tableScan.Insert()
+	assert.NoError(t, tableScan.SetString("table_name", "legacy_items"))
+	assert.NoError(t, tableScan.SetString("field_name", "id"))
+	assert.NoError(t, tableScan.SetInt("next_value", 5))
+	tableScan.Close()
+
+	t.Run("既存のデータベースでは、カウンタのファイルがカタログの next_value から作成される.", func(t *testing.T) {
+		autoIncrementManager := NewAutoIncrementManager(false, tableManager, transaction)
+
+		row, ok := autoIncrementManager.GetAutoIncrement("legacy_items", transaction)
+		if assert.True(t, ok) {
+			assert.Equal(t, AutoIncrementCatalogRow{"legacy_items", "id", 5}, row)
+		}
+		assert.Equal(t, types.Int(1), transaction.Size(autoIncrementFileName("legacy_items")))
+	})
+}
@@ -1,6 +1,44 @@
 package metadata
 
-import "simple-db-go/types"
+import (
+	"simple-db-go/constants"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"simple-db-go/util"
+)
+
+// テーブル名やフィールド名などの識別子が、カタログに保存できる長さかどうかを確認する.
+func validateName[T ~string](name T) error {
+	if util.Len(name) > constants.MAX_NAME_LENGTH {
+		return NameTooLongError{Name: string(name)}
+	}
+	return nil
+}
+
+// テーブル名と、スキーマの全てのフィールド名を確認する.
+func validateTableNames(tableName types.TableName, schema *record.Schema) error {
+	if err := validateName(tableName); err != nil {
+		return err
+	}
+	for _, fieldName := range schema.Fields() {
+		if err := validateName(fieldName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// メタデータマネージャが作るカタログテーブルかどうか.
+func IsCatalogTable(tableName types.TableName) bool {
+	switch tableName {
+	case TABLE_CATALOG_TABLE_NAME, FIELD_CATALOG_TABLE_NAME, VIEW_CATALOG_TABLE_NAME, INDEX_CATALOG_TABLE_NAME,
+		CONSTRAINT_CATALOG_TABLE_NAME, FOREIGN_KEY_CATALOG_TABLE_NAME, AUTO_INCREMENT_CATALOG_TABLE_NAME,
+		TABLE_STAT_CATALOG_TABLE_NAME, COLUMN_STAT_CATALOG_TABLE_NAME, HISTOGRAM_CATALOG_TABLE_NAME:
+		return true
+	default:
+		return false
+	}
+}
 
 // テーブルカタログを記録するテーブル名.
 const TABLE_CATALOG_TABLE_NAME = "table_catalog"
@@ -9,6 +47,7 @@ const TABLE_CATALOG_TABLE_NAME = "table_catalog"
 type TableCatalogRow struct {
 	TableName types.TableName
 	SlotSize  types.SlotSize
+	Format    types.RecordFormat
 }
 
 // フィールドカタログを記録するテーブル名.
@@ -26,8 +65,11 @@ type FieldCatalogRow struct {
 // ビューカタログを記録するテーブル名.
 const VIEW_CATALOG_TABLE_NAME = "view_catalog"
 
+// ビューカタログテーブルの１行を表す.
+// ビューの定義は VIEW_DEF_CHUNK_LENGTH ごとに分割して複数の行に保存し、Seq は 0 から始まる分割した順番になる.
 type ViewCatalogRow struct {
 	ViewName types.ViewName
+	Seq      types.Int
 	ViewDef  types.ViewDef
 }
 
@@ -39,3 +81,77 @@ type IndexCatalogRow struct {
 	TableName types.TableName
 	FieldName types.FieldName
 }
+
+// 制約カタログを記録するテーブル名.
+// NOTE: 以前はテーブル名が 16 文字までだったので、constraint を省略している.
+const CONSTRAINT_CATALOG_TABLE_NAME = "constr_catalog"
+
+// 制約カタログテーブルの１行を表す. 1つの制約が1行になる.
+type ConstraintCatalogRow struct {
+	TableName  types.TableName
+	FieldName  types.FieldName
+	Type       types.ConstraintType
+	Definition types.ConstraintDef
+}
+
+// 外部キーカタログを記録するテーブル名.
+const FOREIGN_KEY_CATALOG_TABLE_NAME = "fk_catalog"
+
+// 外部キーカタログテーブルの１行を表す. TableName.FieldName が RefTableName.RefFieldName を参照する.
+type ForeignKeyCatalogRow struct {
+	TableName    types.TableName
+	FieldName    types.FieldName
+	RefTableName types.TableName
+	RefFieldName types.FieldName
+	OnDelete     types.ReferentialAction
+}
+
+// AUTO_INCREMENT のカウンタを記録するテーブル名.
+const AUTO_INCREMENT_CATALOG_TABLE_NAME = "autoinc_catalog"
+
+// AUTO_INCREMENT カタログテーブルの１行を表す. NextValue は次に採番する値.
+// NOTE: カタログの next_value 列は、カウンタのファイルが無い古いデータベースでカウンタを作成する時にだけ読む.
+// 現在のカウンタはファイルにあり、GetAutoIncrement はファイルから読んだ値を NextValue にして返す.
+type AutoIncrementCatalogRow struct {
+	TableName types.TableName
+	FieldName types.FieldName
+	NextValue types.Int
+}
+
+// テーブルの統計情報を記録するテーブル名.
+const TABLE_STAT_CATALOG_TABLE_NAME = "tblstat_catalog"
+
+// テーブルの統計情報カタログテーブルの１行を表す.
+type TableStatCatalogRow struct {
+	TableName  types.TableName
+	NumBlocks  types.Int
+	NumRecords types.Int
+}
+
+// 列の統計情報を記録するテーブル名.
+const COLUMN_STAT_CATALOG_TABLE_NAME = "colstat_catalog"
+
+// 列の統計情報カタログテーブルの１行を表す.
+// 最小値と最大値は、列の型に関わらず文字列にして保存する. 全て NULL の列では DistinctValues が 0 になり、最小値と最大値は使わない.
+type ColumnStatCatalogRow struct {
+	TableName      types.TableName
+	FieldName      types.FieldName
+	NumNulls       types.Int
+	DistinctValues types.Int
+	MinValue       string
+	MaxValue       string
+}
+
+// 列のヒストグラムを記録するテーブル名.
+const HISTOGRAM_CATALOG_TABLE_NAME = "histogram_catalog"
+
+// ヒストグラムカタログテーブルの１行を表す. 1つのバケットが1行になり、Seq は 0 から始まるバケットの順番になる.
+// バケットの上限値は、列の型に関わらず文字列にして保存する.
+type HistogramCatalogRow struct {
+	TableName      types.TableName
+	FieldName      types.FieldName
+	Seq            types.Int
+	UpperBound     string
+	NumRecords     types.Int
+	DistinctValues types.Int
+}
@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"sync"
+)
+
+// テーブルのレイアウトとビューの定義を、カタログテーブルを読まずに取得できるようにメモリに保持する.
+// キャッシュにはコミット済みのカタログだけを保持する.
+// カタログを変更したトランザクションは、終了するまでキャッシュを使わずにカタログテーブルを読み、
+// コミットかロールバックした時にキャッシュを全て破棄する.
+// NOTE: キャッシュしたレイアウトは複数のトランザクションで共有するので、呼び出し側で変更してはいけない.
+type catalogCache struct {
+	layouts map[types.TableName]*record.Layout
+	// テーブルの FROM 句ではビューかどうかを毎回確認するので、ビューが存在しないことも保持する.
+	viewDefs map[types.ViewName]cachedViewDef
+
+	// カタログを変更して、まだ終了していないトランザクション.
+	modifyingTransactions map[*transaction.Transaction]bool
+
+	// キャッシュを破棄するたびに増やす.
+	// カタログテーブルを読んでいる間にキャッシュが破棄された場合、読んだ値は古いかもしれないので保持しない.
+	generation types.Int
+
+	mu sync.Mutex
+}
+
+type cachedViewDef struct {
+	viewDef types.ViewDef
+	exists  bool
+}
+
+func newCatalogCache() *catalogCache {
+	return &catalogCache{
+		layouts:               make(map[types.TableName]*record.Layout),
+		viewDefs:              make(map[types.ViewName]cachedViewDef),
+		modifyingTransactions: make(map[*transaction.Transaction]bool),
+	}
+}
+
+// キャッシュにあればそれを返し、無ければ load で読んだ値をキャッシュしてから返す.
+// load はカタログテーブルを読むので、他のトランザクションのロックを待つことがある. その間はキャッシュのロックを保持しない.
+func loadCatalog[K comparable, V any](cc *catalogCache, entries map[K]V, key K, transaction *transaction.Transaction, load func() (V, error)) (V, error) {
+	cc.mu.Lock()
+	if !cc.modifyingTransactions[transaction] {
+		if value, exists := entries[key]; exists {
+			cc.mu.Unlock()
+			return value, nil
+		}
+	}
+	generation := cc.generation
+	cc.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if generation == cc.generation && !cc.modifyingTransactions[transaction] {
+		entries[key] = value
+	}
+	return value, nil
+}
+
+func (cc *catalogCache) getLayout(tableName types.TableName, transaction *transaction.Transaction, load func() (*record.Layout, error)) (*record.Layout, error) {
+	return loadCatalog(cc, cc.layouts, tableName, transaction, load)
+}
+
+func (cc *catalogCache) getViewDef(viewName types.ViewName, transaction *transaction.Transaction, load func() (cachedViewDef, error)) (cachedViewDef, error) {
+	return loadCatalog(cc, cc.viewDefs, viewName, transaction, load)
+}
+
+// トランザクションがテーブルかビューのカタログを変更したことを記録する.
+// 変更はまだコミットされていないので、この時点ではキャッシュは破棄しない.
+func (cc *catalogCache) markModified(transaction *transaction.Transaction) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.modifyingTransactions[transaction] {
+		return
+	}
+	cc.modifyingTransactions[transaction] = true
+
+	invalidate := func() { cc.invalidate(transaction) }
+	transaction.OnCommit(invalidate)
+	transaction.OnRollback(invalidate)
+}
+
+func (cc *catalogCache) invalidate(transaction *transaction.Transaction) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.modifyingTransactions, transaction)
+	clear(cc.layouts)
+	clear(cc.viewDefs)
+	cc.generation++
+}
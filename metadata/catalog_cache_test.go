@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogCache(t *testing.T) {
+	transaction := newTransactionForTest(t, catalogCacheTestName)
+	tableManager := NewTableManager(true, transaction)
+	viewManager := NewViewManager(true, tableManager, transaction)
+
+	testTableName := types.TableName("cached_table")
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	tableManager.CreateTable(testTableName, schema, transaction)
+	transaction.Commit()
+
+	t.Run("コミット済みのレイアウトは、トランザクションをまたいでキャッシュから取得できる.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, catalogCacheTestName)
+		layout, err := tableManager.GetLayout(testTableName, transaction)
+		assert.NoError(t, err)
+		transaction.Commit()
+
+		transaction = newTransactionForTest(t, catalogCacheTestName)
+		defer transaction.Rollback()
+		cachedLayout, err := tableManager.GetLayout(testTableName, transaction)
+		if assert.NoError(t, err) {
+			assert.Same(t, layout, cachedLayout, "キャッシュしたレイアウトが返るべし.")
+		}
+	})
+
+	t.Run("カタログを変更したトランザクションは、自身の変更を読める.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, catalogCacheTestName)
+		defer transaction.Rollback()
+
+		assert.NoError(t, tableManager.DropTable(testTableName, transaction))
+		_, err := tableManager.GetLayout(testTableName, transaction)
+		assert.Error(t, err, "削除したテーブルのレイアウトはキャッシュから取得されないべし.")
+	})
+
+	t.Run("ロールバックすると、変更前のカタログが読める.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, catalogCacheTestName)
+		defer transaction.Rollback()
+
+		layout, err := tableManager.GetLayout(testTableName, transaction)
+		if assert.NoError(t, err, "ロールバックしたので、テーブルは残っているべし.") {
+			assert.Equal(t, []types.FieldName{"id"}, layout.GetSchema().Fields())
+		}
+	})
+
+	t.Run("コミットするとキャッシュが破棄され、変更後のカタログが読める.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, catalogCacheTestName)
+		newSchema := record.NewSchema()
+		newSchema.AddIntField("id")
+		newSchema.AddStringField("name", 10)
+		assert.NoError(t, tableManager.AlterTable(testTableName, testTableName, record.NewLayout(newSchema), transaction))
+		transaction.Commit()
+
+		transaction = newTransactionForTest(t, catalogCacheTestName)
+		defer transaction.Rollback()
+		layout, err := tableManager.GetLayout(testTableName, transaction)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []types.FieldName{"id", "name"}, layout.GetSchema().Fields(), "変更後のレイアウトが読めるべし.")
+		}
+	})
+
+	t.Run("ビューが存在しないこともキャッシュし、ビューを作成してコミットすると取得できる.", func(t *testing.T) {
+		testViewName := types.ViewName("cached_view")
+
+		transaction := newTransactionForTest(t, catalogCacheTestName)
+		_, err := viewManager.GetViewDef(testViewName, transaction)
+		assert.IsType(t, CannotGetViewError{}, err)
+		transaction.Commit()
+
+		transaction = newTransactionForTest(t, catalogCacheTestName)
+		assert.NoError(t, viewManager.CreateView(testViewName, "select id from cached_table", transaction))
+		viewDef, err := viewManager.GetViewDef(testViewName, transaction)
+		if assert.NoError(t, err, "作成したトランザクションでは、作成したビューが取得できるべし.") {
+			assert.Equal(t, types.ViewDef("select id from cached_table"), viewDef)
+		}
+		transaction.Commit()
+
+		transaction = newTransactionForTest(t, catalogCacheTestName)
+		defer transaction.Rollback()
+		viewDef, err = viewManager.GetViewDef(testViewName, transaction)
+		if assert.NoError(t, err, "コミットした後は、他のトランザクションからも取得できるべし.") {
+			assert.Equal(t, types.ViewDef("select id from cached_table"), viewDef)
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package metadata
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"slices"
+)
+
+// 識別子を 16 文字まで、ビューの定義を1行に 100 文字までしか保存できなかった頃のカタログの識別子の長さ.
+// その頃のテーブルは、カタログテーブルも含めて全て null bitmap の無い形式で作られている.
+const LEGACY_MAX_NAME_LENGTH = 16
+
+// 移行の対象になるカタログテーブル.
+var catalogTableNames = []types.TableName{
+	TABLE_CATALOG_TABLE_NAME,
+	FIELD_CATALOG_TABLE_NAME,
+	VIEW_CATALOG_TABLE_NAME,
+	INDEX_CATALOG_TABLE_NAME,
+	CONSTRAINT_CATALOG_TABLE_NAME,
+	FOREIGN_KEY_CATALOG_TABLE_NAME,
+	AUTO_INCREMENT_CATALOG_TABLE_NAME,
+}
+
+// 古い形式のカタログから読み出した、カタログテーブル以外のカタログレコード.
+type legacyCatalog struct {
+	tables         []TableCatalogRow
+	fields         []FieldCatalogRow
+	views          []ViewCatalogRow
+	indexes        []IndexCatalogRow
+	constraints    []ConstraintCatalogRow
+	foreignKeys    []ForeignKeyCatalogRow
+	autoIncrements []AutoIncrementCatalogRow
+}
+
+func newLegacyTableCatalogLayout() *record.Layout {
+	schema := record.NewSchema()
+	schema.AddStringField("table_name", LEGACY_MAX_NAME_LENGTH)
+	schema.AddIntField("slot_size")
+	return record.NewLayoutOf(schema, constants.RECORD_FORMAT_WITHOUT_NULL_BITMAP)
+}
+
+func newLegacyFieldCatalogLayout() *record.Layout {
+	schema := record.NewSchema()
+	schema.AddStringField("table_name", LEGACY_MAX_NAME_LENGTH)
+	schema.AddStringField("field_name", LEGACY_MAX_NAME_LENGTH)
+	schema.AddIntField("type")
+	schema.AddIntField("length")
+	schema.AddIntField("offset")
+	return record.NewLayoutOf(schema, constants.RECORD_FORMAT_WITHOUT_NULL_BITMAP)
+}
+
+// table_catalog の先頭の行は、常に table_catalog 自身のカタログレコードになっている.
+// 古い形式のレイアウトで読んだその行の slot_size が、古い形式のレイアウトのスロットサイズと一致すれば古い形式と判定する.
+// 新しい形式のカタログを古い形式のレイアウトで読むと、slot_size の位置は table_name の余白(0)になるので一致しない.
+func isLegacyCatalog(transaction *transaction.Transaction) bool {
+	if transaction.Size(query.TableFileName(TABLE_CATALOG_TABLE_NAME)) == 0 {
+		return false
+	}
+
+	layout := newLegacyTableCatalogLayout()
+	tableScan := query.NewTableScan(transaction, TABLE_CATALOG_TABLE_NAME, layout)
+	defer tableScan.Close()
+
+	if !tableScan.Next() {
+		return false
+	}
+	row := ReadTableCatalogRow(tableScan)
+	return row.TableName == TABLE_CATALOG_TABLE_NAME && row.SlotSize == layout.GetSlotSize()
+}
+
+// 古い形式のカタログから、カタログテーブル以外のカタログレコードを全て読み出し、カタログテーブルを空にする.
+// テーブルのファイルも、現在の形式に書き直す.
+// 空にする変更も書き直す変更もログに残るので、移行の途中でクラッシュしてもリカバリで古い形式のカタログに戻る.
+// 空になったブロックは、コミットした時に切り詰める.
+func readAndClearLegacyCatalog(transaction *transaction.Transaction) *legacyCatalog {
+	layouts := map[types.TableName]*record.Layout{
+		TABLE_CATALOG_TABLE_NAME: newLegacyTableCatalogLayout(),
+		FIELD_CATALOG_TABLE_NAME: newLegacyFieldCatalogLayout(),
+	}
+	readRows := func(tableName types.TableName, read func(tableScan *query.TableScan)) {
+		// 古いデータベースには、後から追加したカタログテーブルが無いこともある.
+		layout, exists := layouts[tableName]
+		if !exists {
+			return
+		}
+
+		tableScan := query.NewTableScan(transaction, tableName, layout)
+		defer tableScan.Close()
+		for tableScan.Next() {
+			read(tableScan)
+		}
+	}
+
+	tables := []TableCatalogRow{}
+	readRows(TABLE_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		tables = append(tables, ReadTableCatalogRow(tableScan))
+	})
+	fields := []FieldCatalogRow{}
+	readRows(FIELD_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		fields = append(fields, ReadFieldCatalogRow(tableScan))
+	})
+
+	catalog := &legacyCatalog{}
+	for _, table := range tables {
+		tableFields := slices.DeleteFunc(slices.Clone(fields), func(field FieldCatalogRow) bool { return field.TableName != table.TableName })
+		if !slices.Contains(catalogTableNames, table.TableName) {
+			table, tableFields = rewriteLegacyTable(transaction, table, tableFields)
+			catalog.tables = append(catalog.tables, table)
+			catalog.fields = append(catalog.fields, tableFields...)
+			continue
+		}
+
+		// カタログテーブルのレイアウトも、古い形式のフィールドカタログに保存されている.
+		if _, exists := layouts[table.TableName]; !exists {
+			schema := record.NewSchema()
+			offsets := make(map[types.FieldName]types.FieldOffsetInSlot)
+			for _, field := range tableFields {
+				schema.AddField(field.FieldName, field.Type, field.Length)
+				offsets[field.FieldName] = field.Offset
+			}
+			layouts[table.TableName] = record.NewLayoutWith(schema, offsets, table.SlotSize, table.Format)
+		}
+	}
+
+	readRows(VIEW_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		catalog.views = append(catalog.views, ReadViewCatalogRow(tableScan))
+	})
+	readRows(INDEX_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		catalog.indexes = append(catalog.indexes, ReadIndexCatalogRow(tableScan))
+	})
+	readRows(CONSTRAINT_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		catalog.constraints = append(catalog.constraints, ReadConstraintCatalogRow(tableScan))
+	})
+	readRows(FOREIGN_KEY_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		catalog.foreignKeys = append(catalog.foreignKeys, ReadForeignKeyCatalogRow(tableScan))
+	})
+	readRows(AUTO_INCREMENT_CATALOG_TABLE_NAME, func(tableScan *query.TableScan) {
+		catalog.autoIncrements = append(catalog.autoIncrements, ReadAutoIncrementCatalogRow(tableScan))
+	})
+
+	for _, tableName := range catalogTableNames {
+		layout, exists := layouts[tableName]
+		if !exists {
+			continue
+		}
+
+		tableScan := query.NewTableScan(transaction, tableName, layout)
+		tableScan.Clear()
+		tableScan.Close()
+		transaction.TruncateFileOnCommit(query.TableFileName(tableName))
+	}
+
+	return catalog
+}
+
+// null bitmap の無い形式のテーブルのレコードを、現在の形式で書き直し、書き直した後のカタログレコードを返す.
+// 一時テーブルに写してからテーブルのファイルを空にし、現在の形式のレイアウトで書き戻す.
+func rewriteLegacyTable(transaction *transaction.Transaction, table TableCatalogRow, fields []FieldCatalogRow) (TableCatalogRow, []FieldCatalogRow) {
+	schema := record.NewSchema()
+	offsets := make(map[types.FieldName]types.FieldOffsetInSlot)
+	for _, field := range fields {
+		schema.AddField(field.FieldName, field.Type, field.Length)
+		offsets[field.FieldName] = field.Offset
+	}
+	legacyLayout := record.NewLayoutWith(schema, offsets, table.SlotSize, table.Format)
+	if legacyLayout.HasNullBitmap() {
+		return table, fields
+	}
+	layout := record.NewLayout(schema)
+
+	tempTable := query.NewTempTable(transaction, schema)
+	copyRecords(query.NewTableScan(transaction, table.TableName, legacyLayout), tempTable.Open(), schema.Fields())
+
+	tableScan := query.NewTableScan(transaction, table.TableName, legacyLayout)
+	tableScan.Clear()
+	tableScan.Close()
+	copyRecords(tempTable.Open(), query.NewTableScan(transaction, table.TableName, layout), schema.Fields())
+
+	newFields := make([]FieldCatalogRow, 0, len(fields))
+	for _, field := range fields {
+		// layout は fields から作ったので、エラーは発生しない.
+		field.Offset, _ = layout.GetOffset(field.FieldName)
+		newFields = append(newFields, field)
+	}
+	return TableCatalogRow{TableName: table.TableName, SlotSize: layout.GetSlotSize(), Format: layout.GetFormat()}, newFields
+}
+
+// source の全てのレコードを destination に追加する. どちらの scan も Close する.
+// 同じスキーマのテーブル同士で写すので、エラーは起こらない. 単に panic とする.
+func copyRecords(source query.Scan, destination query.UpdateScan, fieldNames []types.FieldName) {
+	defer source.Close()
+	defer destination.Close()
+
+	for source.Next() {
+		destination.Insert()
+		for _, fieldName := range fieldNames {
+			value, err := source.GetValue(fieldName)
+			if err == nil {
+				err = destination.SetValue(fieldName, value)
+			}
+			if err != nil {
+				panic(fmt.Sprintf("[copyRecords] レコードの書き直しに失敗しました. field_name=%s, err=%+v", fieldName, err))
+			}
+		}
+	}
+}
+
+// 古い形式のカタログから読み出したカタログレコードを、新しい形式で作り直したカタログに書き込む.
+// テーブルのファイルは readAndClearLegacyCatalog で書き直してあるので、書き直した後のカタログレコードを書き込む.
+// ビューの定義は、新しい形式では分割して書き込まれる.
+func (mm *MetadataManager) restoreLegacyCatalog(catalog *legacyCatalog, transaction *transaction.Transaction) {
+	for _, table := range catalog.tables {
+		WriteTableCatalogRow(transaction, mm.tableManager, table)
+	}
+	WriteFieldCatalogRows(transaction, mm.tableManager, catalog.fields)
+
+	for _, view := range catalog.views {
+		WriteViewCatalogRow(view, transaction, mm.tableManager)
+	}
+	for _, index := range catalog.indexes {
+		mm.indexManager.writeCatalogRow(index, transaction)
+	}
+	mm.constraintManager.CreateConstraints(catalog.constraints, transaction)
+	mm.constraintManager.CreateForeignKeys(catalog.foreignKeys, transaction)
+	for _, counter := range catalog.autoIncrements {
+		mm.autoIncrementManager.CreateAutoIncrement(counter.TableName, counter.FieldName, transaction)
+		mm.autoIncrementManager.SetNextValue(counter.TableName, counter.NextValue, transaction)
+	}
+}
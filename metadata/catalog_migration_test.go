@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/file"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ベースラインのバージョンのファイルの1行. キーはスロット内のオフセットで、値は types.Int か string.
+type baselineRow map[types.Int]any
+
+// ベースラインのバージョンのファイルを、スロットサイズとオフセットを固定した値で書き込む.
+// 現在のコードでレイアウトを計算すると、レイアウトを変更した時にこのテストも一緒に変わってしまうので使わない.
+// ベースラインのスロットは、先頭4バイトの empty/inuse フラグとフィールドだけで、null bitmap は無い.
+func writeBaselineFile(transaction *transaction.Transaction, tableName types.TableName, slotSize types.Int, rows []baselineRow) {
+	slotsPerBlock := transaction.BlockSize() / slotSize
+
+	var blockID file.BlockID
+	for i, row := range rows {
+		slot := types.Int(i) % slotsPerBlock
+		if slot == 0 {
+			if i > 0 {
+				transaction.Unpin(blockID)
+			}
+			blockID = transaction.Append(query.TableFileName(tableName))
+			transaction.Pin(blockID)
+		}
+
+		slotOffset := slot * slotSize
+		transaction.SetInt(blockID, slotOffset, types.Int(record.SLOT_INUSE), true)
+		for offset, value := range row {
+			switch value := value.(type) {
+			case types.Int:
+				transaction.SetInt(blockID, slotOffset+offset, value, true)
+			case string:
+				transaction.SetString(blockID, slotOffset+offset, value, true)
+			}
+		}
+	}
+	transaction.Unpin(blockID)
+}
+
+// ベースラインのバージョンで、users テーブルと users_view ビュー、インデックスを作ったデータベース.
+// 識別子は 16 文字まで(文字列は長さ4バイト＋16バイト)で、どのテーブルにも null bitmap と format 列は無い.
+func writeBaselineDatabase(transaction *transaction.Transaction) {
+	// table_catalog: table_name(4+16) @4, slot_size @24. スロットサイズは 4+20+4 = 28.
+	writeBaselineFile(transaction, TABLE_CATALOG_TABLE_NAME, 28, []baselineRow{
+		{4: "table_catalog", 24: types.Int(28)},
+		{4: "field_catalog", 24: types.Int(56)},
+		{4: "view_catalog", 24: types.Int(128)},
+		{4: "index_catalog", 24: types.Int(64)},
+		{4: "users", 24: types.Int(22)},
+	})
+
+	// field_catalog: table_name @4, field_name @24, type @44, length @48, offset @52. スロットサイズは 4+20+20+4+4+4 = 56.
+	integer := types.Int(constants.INTEGER)
+	varchar := types.Int(constants.VARCHAR)
+	writeBaselineFile(transaction, FIELD_CATALOG_TABLE_NAME, 56, []baselineRow{
+		{4: "table_catalog", 24: "table_name", 44: varchar, 48: types.Int(16), 52: types.Int(4)},
+		{4: "table_catalog", 24: "slot_size", 44: integer, 48: types.Int(0), 52: types.Int(24)},
+		{4: "field_catalog", 24: "table_name", 44: varchar, 48: types.Int(16), 52: types.Int(4)},
+		{4: "field_catalog", 24: "field_name", 44: varchar, 48: types.Int(16), 52: types.Int(24)},
+		{4: "field_catalog", 24: "type", 44: integer, 48: types.Int(0), 52: types.Int(44)},
+		{4: "field_catalog", 24: "length", 44: integer, 48: types.Int(0), 52: types.Int(48)},
+		{4: "field_catalog", 24: "offset", 44: integer, 48: types.Int(0), 52: types.Int(52)},
+		{4: "view_catalog", 24: "view_name", 44: varchar, 48: types.Int(16), 52: types.Int(4)},
+		{4: "view_catalog", 24: "view_def", 44: varchar, 48: types.Int(100), 52: types.Int(24)},
+		{4: "index_catalog", 24: "index_name", 44: varchar, 48: types.Int(16), 52: types.Int(4)},
+		{4: "index_catalog", 24: "table_name", 44: varchar, 48: types.Int(16), 52: types.Int(24)},
+		{4: "index_catalog", 24: "field_name", 44: varchar, 48: types.Int(16), 52: types.Int(44)},
+		{4: "users", 24: "id", 44: integer, 48: types.Int(0), 52: types.Int(4)},
+		{4: "users", 24: "name", 44: varchar, 48: types.Int(10), 52: types.Int(8)},
+	})
+
+	// view_catalog: view_name @4, view_def(4+100) @24. スロットサイズは 4+20+104 = 128.
+	writeBaselineFile(transaction, VIEW_CATALOG_TABLE_NAME, 128, []baselineRow{
+		{4: "users_view", 24: "select id from users"},
+	})
+
+	// index_catalog: index_name @4, table_name @24, field_name @44. スロットサイズは 4+20+20+20 = 64.
+	writeBaselineFile(transaction, INDEX_CATALOG_TABLE_NAME, 64, []baselineRow{
+		{4: "users_id_index", 24: "users", 44: "id"},
+	})
+
+	// users: id @4, name(4+10) @8. スロットサイズは 4+4+14 = 22.
+	writeBaselineFile(transaction, "users", 22, []baselineRow{
+		{4: types.Int(1), 8: "alice"},
+		{4: types.Int(2), 8: "bob"},
+	})
+}
+
+func TestCatalogMigration(t *testing.T) {
+	baselineTransaction := newTransactionForTest(t, catalogMigrationTestName)
+	writeBaselineDatabase(baselineTransaction)
+	assert.True(t, isLegacyCatalog(baselineTransaction), "ベースラインのカタログは古い形式と判定されるべし.")
+	baselineTransaction.Commit()
+
+	// 既存のデータベースとして起動すると、新しい形式のカタログに移行される.
+	transaction := newTransactionForTest(t, catalogMigrationTestName)
+	metadataManager := NewMetadataManager(false, transaction)
+	transaction.Commit()
+
+	// 移行後のデータベースを、もう一度既存のデータベースとして起動する.
+	transaction = newTransactionForTest(t, catalogMigrationTestName)
+	metadataManager = NewMetadataManager(false, transaction)
+	defer transaction.Rollback()
+
+	t.Run("移行後は古い形式のカタログと判定されない.", func(t *testing.T) {
+		assert.False(t, isLegacyCatalog(transaction), "新しい形式のカタログと判定されるべし.")
+	})
+
+	t.Run("テーブルは現在の形式に書き直され、データがそのまま読める.", func(t *testing.T) {
+		layout, err := metadataManager.GetLayout("users", transaction)
+		if !assert.NoError(t, err, "移行前に作成したテーブルの定義が取得できるべし.") {
+			return
+		}
+		assert.Equal(t, constants.CURRENT_RECORD_FORMAT, layout.GetFormat(), "現在の形式に書き直されるべし.")
+		assert.Equal(t, types.SlotSize(22+4), layout.GetSlotSize(), "null bitmap の分だけスロットサイズが大きくなるべし.")
+
+		tableScan := query.NewTableScan(transaction, "users", layout)
+		defer tableScan.Close()
+
+		ids := []types.Int{}
+		names := []string{}
+		for tableScan.Next() {
+			id, _ := tableScan.GetInt("id")
+			name, _ := tableScan.GetString("name")
+			ids = append(ids, id)
+			names = append(names, name)
+		}
+		assert.Equal(t, []types.Int{1, 2}, ids, "移行前に登録したレコードが読めるべし.")
+		assert.Equal(t, []string{"alice", "bob"}, names, "移行前に登録したレコードが読めるべし.")
+
+		tableScan.Insert()
+		assert.NoError(t, tableScan.SetInt("id", 3))
+		assert.NoError(t, tableScan.SetNull("name"), "書き直したテーブルには NULL を書き込めるべし.")
+	})
+
+	t.Run("ビューの定義とインデックスがそのまま読める.", func(t *testing.T) {
+		viewDef, err := metadataManager.GetViewDef("users_view", transaction)
+		if assert.NoError(t, err, "移行前に作成したビューの定義が取得できるべし.") {
+			assert.Equal(t, types.ViewDef("select id from users"), viewDef)
+		}
+		assert.True(t, metadataManager.IndexExists("users_id_index", "users", transaction), "移行前に作成したインデックスが読めるべし.")
+	})
+
+	t.Run("ベースラインに無かったカタログテーブルも作成される.", func(t *testing.T) {
+		for _, tableName := range []types.TableName{CONSTRAINT_CATALOG_TABLE_NAME, FOREIGN_KEY_CATALOG_TABLE_NAME, AUTO_INCREMENT_CATALOG_TABLE_NAME, TABLE_STAT_CATALOG_TABLE_NAME} {
+			_, err := metadataManager.GetLayout(tableName, transaction)
+			assert.NoErrorf(t, err, "table_name=%s", tableName)
+		}
+	})
+
+	t.Run("移行後は長い名前のテーブルを作成できる.", func(t *testing.T) {
+		schema := record.NewSchema()
+		schema.AddIntField("id")
+		assert.NoError(t, metadataManager.CreateTable("a_table_name_longer_than_sixteen", schema, transaction))
+	})
+}
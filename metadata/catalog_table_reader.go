@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"simple-db-go/constants"
 	"simple-db-go/query"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
@@ -9,6 +10,7 @@ import (
 
 // table_catalog テーブルの１行だけ読み取る.
 // table_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+// NOTE: 移行前の古い table_catalog には format 列が無く、そのテーブルは全て null bitmap の無い形式で作られている.
 func ReadTableCatalogRow(tableScan *query.TableScan) TableCatalogRow {
 	tableName, err := tableScan.GetString("table_name")
 	if err != nil {
@@ -20,9 +22,19 @@ func ReadTableCatalogRow(tableScan *query.TableScan) TableCatalogRow {
 		panic(fmt.Sprintf("[ReadTableCatalogRow] table_catalog テーブルの slot_size 列の読み取りに失敗しました. err=%+v", err))
 	}
 
+	format := constants.RECORD_FORMAT_WITHOUT_NULL_BITMAP
+	if tableScan.HasField("format") {
+		value, err := tableScan.GetInt("format")
+		if err != nil {
+			panic(fmt.Sprintf("[ReadTableCatalogRow] table_catalog テーブルの format 列の読み取りに失敗しました. err=%+v", err))
+		}
+		format = types.RecordFormat(value)
+	}
+
 	return TableCatalogRow{
 		TableName: types.TableName(tableName),
 		SlotSize:  types.SlotSize(slotSize),
+		Format:    format,
 	}
 }
 
@@ -33,21 +45,8 @@ func ReadTableCatalogRowFor(tableName types.TableName, transaction *transaction.
 	defer tableScan.Close()
 
 	for tableScan.Next() {
-		gotTableName, err := tableScan.GetString("table_name")
-		if err != nil {
-			panic(fmt.Sprintf("[ReadTableCatalogRowFor] field_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
-		}
-
-		gotSlotSize, err := tableScan.GetInt("slot_size")
-		if err != nil {
-			panic(fmt.Sprintf("[ReadTableCatalogRowFor] field_catalog テーブルの slot_size 列の読み取りに失敗しました. err=%+v", err))
-		}
-
-		if gotTableName == string(tableName) {
-			return TableCatalogRow{
-				TableName: tableName,
-				SlotSize:  types.SlotSize(gotSlotSize),
-			}, nil
+		if row := ReadTableCatalogRow(tableScan); row.TableName == tableName {
+			return row, nil
 		}
 	}
 
@@ -136,14 +135,23 @@ func ReadFieldCatalogRowsFor(tableName types.TableName, transaction *transaction
 	return rows
 }
 
-// view_catalog テーブルの１行だけ読み取る.
+// view_catalog テーブルの１行だけ読み取る. ViewDef は分割したビュー定義の一部になる.
 // view_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+// NOTE: 移行前の古い view_catalog には seq 列が無く、1行に定義全体が入っているので、Seq は 0 とする.
 func ReadViewCatalogRow(tableScan *query.TableScan) ViewCatalogRow {
 	viewName, err := tableScan.GetString("view_name")
 	if err != nil {
 		panic(fmt.Sprintf("[ReadViewCatalogRow] view_catalog テーブルの view_name 列の読み取りに失敗しました. err=%+v", err))
 	}
 
+	seq := types.Int(0)
+	if tableScan.HasField("seq") {
+		seq, err = tableScan.GetInt("seq")
+		if err != nil {
+			panic(fmt.Sprintf("[ReadViewCatalogRow] view_catalog テーブルの seq 列の読み取りに失敗しました. err=%+v", err))
+		}
+	}
+
 	viewDef, err := tableScan.GetString("view_def")
 	if err != nil {
 		panic(fmt.Sprintf("[ReadViewCatalogRow] view_catalog テーブルの view_def 列の読み取りに失敗しました. err=%+v", err))
@@ -151,6 +159,7 @@ func ReadViewCatalogRow(tableScan *query.TableScan) ViewCatalogRow {
 
 	return ViewCatalogRow{
 		ViewName: types.ViewName(viewName),
+		Seq:      seq,
 		ViewDef:  types.ViewDef(viewDef),
 	}
 }
@@ -177,3 +186,207 @@ func ReadIndexCatalogRow(tableScan *query.TableScan) IndexCatalogRow {
 		FieldName: types.FieldName(fieldName),
 	}
 }
+
+// constraint_catalog テーブルの１行だけ読み取る.
+// constraint_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadConstraintCatalogRow(tableScan *query.TableScan) ConstraintCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadConstraintCatalogRow] constraint_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	fieldName, err := tableScan.GetString("field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadConstraintCatalogRow] constraint_catalog テーブルの field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	constraintType, err := tableScan.GetInt("type")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadConstraintCatalogRow] constraint_catalog テーブルの type 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	definition, err := tableScan.GetString("definition")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadConstraintCatalogRow] constraint_catalog テーブルの definition 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return ConstraintCatalogRow{
+		TableName:  types.TableName(tableName),
+		FieldName:  types.FieldName(fieldName),
+		Type:       types.ConstraintType(constraintType),
+		Definition: types.ConstraintDef(definition),
+	}
+}
+
+// fk_catalog テーブルの１行だけ読み取る.
+// fk_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadForeignKeyCatalogRow(tableScan *query.TableScan) ForeignKeyCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadForeignKeyCatalogRow] fk_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	fieldName, err := tableScan.GetString("field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadForeignKeyCatalogRow] fk_catalog テーブルの field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	refTableName, err := tableScan.GetString("ref_table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadForeignKeyCatalogRow] fk_catalog テーブルの ref_table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	refFieldName, err := tableScan.GetString("ref_field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadForeignKeyCatalogRow] fk_catalog テーブルの ref_field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	onDelete, err := tableScan.GetInt("on_delete")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadForeignKeyCatalogRow] fk_catalog テーブルの on_delete 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return ForeignKeyCatalogRow{
+		TableName:    types.TableName(tableName),
+		FieldName:    types.FieldName(fieldName),
+		RefTableName: types.TableName(refTableName),
+		RefFieldName: types.FieldName(refFieldName),
+		OnDelete:     types.ReferentialAction(onDelete),
+	}
+}
+
+// autoinc_catalog テーブルの１行だけ読み取る.
+// autoinc_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadAutoIncrementCatalogRow(tableScan *query.TableScan) AutoIncrementCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadAutoIncrementCatalogRow] autoinc_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	fieldName, err := tableScan.GetString("field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadAutoIncrementCatalogRow] autoinc_catalog テーブルの field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	nextValue, err := tableScan.GetInt("next_value")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadAutoIncrementCatalogRow] autoinc_catalog テーブルの next_value 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return AutoIncrementCatalogRow{
+		TableName: types.TableName(tableName),
+		FieldName: types.FieldName(fieldName),
+		NextValue: nextValue,
+	}
+}
+
+// tblstat_catalog テーブルの１行だけ読み取る.
+// tblstat_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadTableStatCatalogRow(tableScan *query.TableScan) TableStatCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadTableStatCatalogRow] tblstat_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	numBlocks, err := tableScan.GetInt("num_blocks")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadTableStatCatalogRow] tblstat_catalog テーブルの num_blocks 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	numRecords, err := tableScan.GetInt("num_records")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadTableStatCatalogRow] tblstat_catalog テーブルの num_records 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return TableStatCatalogRow{
+		TableName:  types.TableName(tableName),
+		NumBlocks:  numBlocks,
+		NumRecords: numRecords,
+	}
+}
+
+// colstat_catalog テーブルの１行だけ読み取る.
+// colstat_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadColumnStatCatalogRow(tableScan *query.TableScan) ColumnStatCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	fieldName, err := tableScan.GetString("field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	numNulls, err := tableScan.GetInt("num_nulls")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの num_nulls 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	distinctValues, err := tableScan.GetInt("distinct_values")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの distinct_values 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	minValue, err := tableScan.GetString("min_value")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの min_value 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	maxValue, err := tableScan.GetString("max_value")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadColumnStatCatalogRow] colstat_catalog テーブルの max_value 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return ColumnStatCatalogRow{
+		TableName:      types.TableName(tableName),
+		FieldName:      types.FieldName(fieldName),
+		NumNulls:       numNulls,
+		DistinctValues: distinctValues,
+		MinValue:       minValue,
+		MaxValue:       maxValue,
+	}
+}
+
+// histogram_catalog テーブルの１行だけ読み取る.
+// histogram_catalog テーブルのスキーマは固定であるため、TableScan のメソッドではエラーは起こらない. 単に panic とする.
+func ReadHistogramCatalogRow(tableScan *query.TableScan) HistogramCatalogRow {
+	tableName, err := tableScan.GetString("table_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの table_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	fieldName, err := tableScan.GetString("field_name")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの field_name 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	seq, err := tableScan.GetInt("seq")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの seq 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	upperBound, err := tableScan.GetString("upper_bound")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの upper_bound 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	numRecords, err := tableScan.GetInt("num_records")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの num_records 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	distinctValues, err := tableScan.GetInt("distinct_values")
+	if err != nil {
+		panic(fmt.Sprintf("[ReadHistogramCatalogRow] histogram_catalog テーブルの distinct_values 列の読み取りに失敗しました. err=%+v", err))
+	}
+
+	return HistogramCatalogRow{
+		TableName:      types.TableName(tableName),
+		FieldName:      types.FieldName(fieldName),
+		Seq:            seq,
+		UpperBound:     upperBound,
+		NumRecords:     numRecords,
+		DistinctValues: distinctValues,
+	}
+}
@@ -2,7 +2,9 @@ package metadata
 
 import (
 	"fmt"
+	"simple-db-go/constants"
 	"simple-db-go/query"
+	"simple-db-go/record"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
 )
@@ -10,6 +12,8 @@ import (
 // table_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
 // 単に panic させる.
 func WriteTableCatalogRow(transaction *transaction.Transaction, tableManager *TableManager, row TableCatalogRow) {
+	tableManager.cache.markModified(transaction)
+
 	tableCatalogTableScan := query.NewTableScan(transaction, TABLE_CATALOG_TABLE_NAME, tableManager.tableCatalogLayout)
 	defer tableCatalogTableScan.Close()
 
@@ -24,11 +28,18 @@ func WriteTableCatalogRow(transaction *transaction.Transaction, tableManager *Ta
 	if err != nil {
 		panic(fmt.Sprintf("[WriteTableCatalogRow] table_catalog テーブルの slot_size に整数をセットできませんでした. row=%+v, error=%+v", row, err))
 	}
+
+	err = tableCatalogTableScan.SetInt("format", types.Int(row.Format))
+	if err != nil {
+		panic(fmt.Sprintf("[WriteTableCatalogRow] table_catalog テーブルの format に整数をセットできませんでした. row=%+v, error=%+v", row, err))
+	}
 }
 
 // field_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
 // 単に panic させる.
 func WriteFieldCatalogRows(transaction *transaction.Transaction, tableManager *TableManager, rows []FieldCatalogRow) {
+	tableManager.cache.markModified(transaction)
+
 	fieldCatalogTableScan := query.NewTableScan(transaction, FIELD_CATALOG_TABLE_NAME, tableManager.fieldCatalogLayout)
 	defer fieldCatalogTableScan.Close()
 
@@ -63,9 +74,13 @@ func WriteFieldCatalogRows(transaction *transaction.Transaction, tableManager *T
 
 }
 
+// ビューの定義を VIEW_DEF_CHUNK_LENGTH バイトごとに分割し、view_catalog の複数の行として書き込む. row.Seq は使わない.
+// 分割した位置でマルチバイト文字が切れても、読み出す時に順番に繋げれば元の定義に戻る.
 // view_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
 // 単に panic させる.
 func WriteViewCatalogRow(row ViewCatalogRow, transaction *transaction.Transaction, tableManager *TableManager) {
+	tableManager.cache.markModified(transaction)
+
 	layout, err := tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
 	if err != nil {
 		// 初期起動時に必ずカタログのレイアウトが登録されているはずなので、ここは panic にしておく.
@@ -75,15 +90,63 @@ func WriteViewCatalogRow(row ViewCatalogRow, transaction *transaction.Transactio
 	viewCatalogTableScan := query.NewTableScan(transaction, VIEW_CATALOG_TABLE_NAME, layout)
 	defer viewCatalogTableScan.Close()
 
-	viewCatalogTableScan.Insert()
+	viewDef := string(row.ViewDef)
+	// 空の定義でも1行は書き込む.
+	for seq := types.Int(0); seq == 0 || len(viewDef) > 0; seq++ {
+		chunk := viewDef[:min(len(viewDef), constants.VIEW_DEF_CHUNK_LENGTH)]
+		viewDef = viewDef[len(chunk):]
 
-	err = viewCatalogTableScan.SetString("view_name", string(row.ViewName))
-	if err != nil {
-		panic(fmt.Sprintf("[WriteViewCatalogRow] view_catalog テーブルの view_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		viewCatalogTableScan.Insert()
+
+		err = viewCatalogTableScan.SetString("view_name", string(row.ViewName))
+		if err != nil {
+			panic(fmt.Sprintf("[WriteViewCatalogRow] view_catalog テーブルの view_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		err = viewCatalogTableScan.SetInt("seq", seq)
+		if err != nil {
+			panic(fmt.Sprintf("[WriteViewCatalogRow] view_catalog テーブルの seq に整数をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		err = viewCatalogTableScan.SetString("view_def", chunk)
+		if err != nil {
+			panic(fmt.Sprintf("[WriteViewCatalogRow] view_catalog テーブルの view_def に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
 	}
+}
 
-	err = viewCatalogTableScan.SetString("view_def", string(row.ViewDef))
-	if err != nil {
-		panic(fmt.Sprintf("[WriteViewCatalogRow] view_catalog テーブルの view_def に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+// カタログテーブルから、matches が true を返す行を全て削除し、削除した行の数を返す.
+// 削除はトランザクションのログに残るので、ロールバックすると元に戻る.
+func DeleteCatalogRows(transaction *transaction.Transaction, catalogTableName types.TableName, layout *record.Layout, matches func(tableScan *query.TableScan) bool) types.Int {
+	tableScan := query.NewTableScan(transaction, catalogTableName, layout)
+	defer tableScan.Close()
+
+	count := types.Int(0)
+	for tableScan.Next() {
+		if matches(tableScan) {
+			tableScan.Delete()
+			count++
+		}
+	}
+	return count
+}
+
+// カタログテーブルの、matches が true を返す行の fieldName の列を value に書き換え、書き換えた行の数を返す.
+// テーブル名やフィールド名を変更する時に使うので、文字列の列だけを対象にする.
+func UpdateCatalogRows(transaction *transaction.Transaction, catalogTableName types.TableName, layout *record.Layout, matches func(tableScan *query.TableScan) bool, fieldName types.FieldName, value string) types.Int {
+	tableScan := query.NewTableScan(transaction, catalogTableName, layout)
+	defer tableScan.Close()
+
+	count := types.Int(0)
+	for tableScan.Next() {
+		if !matches(tableScan) {
+			continue
+		}
+
+		if err := tableScan.SetString(fieldName, value); err != nil {
+			panic(fmt.Sprintf("[UpdateCatalogRows] %s テーブルの %s に文字列をセットできませんでした. value=%s, error=%+v", catalogTableName, fieldName, value, err))
+		}
+		count++
 	}
+	return count
 }
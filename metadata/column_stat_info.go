@@ -0,0 +1,374 @@
+package metadata
+
+import (
+	"math"
+	"math/rand/v2"
+	"simple-db-go/constants"
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"slices"
+	"strconv"
+)
+
+// 列の値の分布を表すヒストグラムのバケット数の上限.
+const NUM_HISTOGRAM_BUCKETS = 20
+
+// 列の統計情報を計算する時に、メモリに保持する列の値の数の上限.
+// NULL ではない値がこれより多い列は、この数の値を無作為に選んで統計情報を推定する.
+const STAT_SAMPLE_SIZE = 10000
+
+var _ query.ColumnDistribution = (*ColumnStatInfo)(nil)
+
+// 列の統計情報.
+// 異なる値の数とヒストグラムは、NULL ではない値が STAT_SAMPLE_SIZE 個以下の列では正確に数え、それより多い列では標本から推定する.
+type ColumnStatInfo struct {
+	// NULL を含む、テーブルの全てのレコード数.
+	numRecords     types.Int
+	numNulls       types.Int
+	distinctValues types.Int
+	// NULL ではない値の最小値と最大値. 全て NULL の場合は NULL になる.
+	min query.Constant
+	max query.Constant
+	// NULL ではない値の、等深(equi-depth)ヒストグラム.
+	histogram []histogramBucket
+}
+
+// ヒストグラムの1つのバケット. 直前のバケットの upperBound より大きく、upperBound 以下の値を持つレコードを数える.
+// 同じ値が複数のバケットに分かれないようにしているので、バケットのレコード数はおおよそ等しくなる.
+type histogramBucket struct {
+	upperBound     query.Constant
+	numRecords     types.Int
+	distinctValues types.Int
+}
+
+// values は NULL ではない列の値で、昇順にソートされている必要がある.
+func newColumnStatInfo(numRecords types.Int, numNulls types.Int, values []query.Constant) *ColumnStatInfo {
+	columnStatInfo := &ColumnStatInfo{
+		numRecords: numRecords,
+		numNulls:   numNulls,
+		min:        query.NewNullConstant(),
+		max:        query.NewNullConstant(),
+	}
+	if len(values) == 0 {
+		return columnStatInfo
+	}
+
+	columnStatInfo.min = values[0]
+	columnStatInfo.max = values[len(values)-1]
+
+	bucketSize := (len(values) + NUM_HISTOGRAM_BUCKETS - 1) / NUM_HISTOGRAM_BUCKETS
+	for start := 0; start < len(values); {
+		end := min(start+bucketSize, len(values))
+		for end < len(values) && values[end] == values[end-1] {
+			end++
+		}
+
+		distinctValues := types.Int(1)
+		for i := start + 1; i < end; i++ {
+			if values[i] != values[i-1] {
+				distinctValues++
+			}
+		}
+
+		columnStatInfo.histogram = append(columnStatInfo.histogram, histogramBucket{
+			upperBound:     values[end-1],
+			numRecords:     types.Int(end - start),
+			distinctValues: distinctValues,
+		})
+		columnStatInfo.distinctValues += distinctValues
+		start = end
+	}
+
+	return columnStatInfo
+}
+
+// テーブルを読みながら、列の統計情報を計算するための値を集める.
+// メモリを抑えるため、NULL ではない値は reservoir sampling で STAT_SAMPLE_SIZE 個までを無作為に選んで保持する.
+// NULL の数、最小値と最大値は、全ての値から正確に求める.
+type columnStatCollector struct {
+	numNulls  types.Int
+	numValues types.Int
+	min       query.Constant
+	max       query.Constant
+	samples   []query.Constant
+	random    *rand.Rand
+}
+
+func newColumnStatCollector() *columnStatCollector {
+	return &columnStatCollector{
+		min: query.NewNullConstant(),
+		max: query.NewNullConstant(),
+		// 同じテーブルからは同じ統計情報を計算できるように、乱数の種は固定する.
+		random: rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+func (cc *columnStatCollector) add(value query.Constant) {
+	if query.IsNull(value) {
+		cc.numNulls++
+		return
+	}
+
+	cc.numValues++
+	if query.IsNull(cc.min) || value.CompareTo(cc.min) < 0 {
+		cc.min = value
+	}
+	if query.IsNull(cc.max) || value.CompareTo(cc.max) > 0 {
+		cc.max = value
+	}
+
+	if len(cc.samples) < STAT_SAMPLE_SIZE {
+		cc.samples = append(cc.samples, value)
+		return
+	}
+	// numValues 個目の値は、STAT_SAMPLE_SIZE / numValues の確率で標本のどれかと入れ替える.
+	if index := cc.random.Int64N(int64(cc.numValues)); index < STAT_SAMPLE_SIZE {
+		cc.samples[index] = value
+	}
+}
+
+// numRecords は NULL を含む、テーブルの全てのレコード数.
+func (cc *columnStatCollector) build(numRecords types.Int) *ColumnStatInfo {
+	slices.SortFunc(cc.samples, func(a, b query.Constant) int { return a.CompareTo(b) })
+	columnStatInfo := newColumnStatInfo(numRecords, cc.numNulls, cc.samples)
+	if types.Int(len(cc.samples)) < cc.numValues {
+		columnStatInfo.scaleSamples(cc.samples, cc.numValues, cc.min, cc.max)
+	}
+	return columnStatInfo
+}
+
+// 標本から計算した統計情報を、NULL ではない numValues 個の値全体の推定に引き伸ばす.
+// バケットのレコード数は標本の割合で按分し、異なる値の数は Haas と Stokes の推定量(Duj1)で推定する.
+// samples は昇順にソートされている必要がある.
+func (ci *ColumnStatInfo) scaleSamples(samples []query.Constant, numValues types.Int, minValue query.Constant, maxValue query.Constant) {
+	numSamples := float64(len(samples))
+
+	// 標本に1回だけ現れた値の数.
+	singletons := 0.0
+	for start := 0; start < len(samples); {
+		end := start + 1
+		for end < len(samples) && samples[end] == samples[start] {
+			end++
+		}
+		if end-start == 1 {
+			singletons++
+		}
+		start = end
+	}
+
+	sampleDistinctValues := float64(ci.distinctValues)
+	distinctValues := numSamples * sampleDistinctValues / (numSamples - singletons + singletons*numSamples/float64(numValues))
+	distinctValues = min(float64(numValues), max(sampleDistinctValues, distinctValues))
+	ci.distinctValues = types.Int(math.Round(distinctValues))
+
+	recordsScale := float64(numValues) / numSamples
+	distinctScale := distinctValues / sampleDistinctValues
+	remainingRecords := numValues
+	for i := range ci.histogram {
+		bucket := &ci.histogram[i]
+		if i == len(ci.histogram)-1 {
+			bucket.numRecords = max(1, remainingRecords)
+		} else {
+			bucket.numRecords = max(1, types.Int(math.Round(float64(bucket.numRecords)*recordsScale)))
+			remainingRecords -= bucket.numRecords
+		}
+		bucket.distinctValues = min(bucket.numRecords, max(1, types.Int(math.Round(float64(bucket.distinctValues)*distinctScale))))
+	}
+
+	// 標本に含まれなかった最小値と最大値も、ヒストグラムの範囲に含める.
+	ci.min = minValue
+	ci.max = maxValue
+	ci.histogram[len(ci.histogram)-1].upperBound = maxValue
+}
+
+func (ci *ColumnStatInfo) GetDistinctValues() types.Int {
+	return ci.distinctValues
+}
+
+func (ci *ColumnStatInfo) GetNumNulls() types.Int {
+	return ci.numNulls
+}
+
+func (ci *ColumnStatInfo) GetMin() query.Constant {
+	return ci.min
+}
+
+func (ci *ColumnStatInfo) GetMax() query.Constant {
+	return ci.max
+}
+
+// 値を含むバケットでは、バケットの中の異なる値ごとにレコードが均等にあると推定する.
+func (ci *ColumnStatInfo) EqualFraction(value query.Constant) float64 {
+	index := ci.bucketIndexOf(value)
+	if index < 0 {
+		return ci.fractionOf(0)
+	}
+	bucket := ci.histogram[index]
+	return ci.fractionOf(float64(bucket.numRecords) / float64(bucket.distinctValues))
+}
+
+// 値より小さいバケットは全て数え、値を含むバケットは、整数の場合はバケットの範囲で線形補間し、それ以外は半分と推定する.
+func (ci *ColumnStatInfo) LessThanFraction(value query.Constant, orEqual bool) float64 {
+	if len(ci.histogram) == 0 || value.CompareTo(ci.min) < 0 {
+		return ci.fractionOf(0)
+	}
+
+	index := ci.bucketIndexOf(value)
+	if index < 0 {
+		// 最大値より大きいので、NULL 以外の全てのレコードが該当する.
+		return ci.fractionOf(float64(ci.numRecords - ci.numNulls))
+	}
+
+	records := 0.0
+	for _, bucket := range ci.histogram[:index] {
+		records += float64(bucket.numRecords)
+	}
+
+	bucket := ci.histogram[index]
+	equalRecords := float64(bucket.numRecords) / float64(bucket.distinctValues)
+	if value == bucket.upperBound {
+		records += float64(bucket.numRecords) - equalRecords
+	} else {
+		records += float64(bucket.numRecords) * ci.interpolate(index, value)
+	}
+	if orEqual {
+		records += equalRecords
+	}
+
+	return ci.fractionOf(records)
+}
+
+func (ci *ColumnStatInfo) NullFraction() float64 {
+	if ci.numRecords == 0 {
+		return 0
+	}
+	return float64(ci.numNulls) / float64(ci.numRecords)
+}
+
+// value を含むバケットの位置. 最小値より小さいか最大値より大きい場合は -1 を返す.
+func (ci *ColumnStatInfo) bucketIndexOf(value query.Constant) int {
+	if len(ci.histogram) == 0 || value.CompareTo(ci.min) < 0 {
+		return -1
+	}
+	index, _ := slices.BinarySearchFunc(ci.histogram, value, func(bucket histogramBucket, value query.Constant) int {
+		return bucket.upperBound.CompareTo(value)
+	})
+	if index == len(ci.histogram) {
+		return -1
+	}
+	return index
+}
+
+// 統計情報が古い可能性もあるので、条件を満たすレコードが無いと推定した場合でも、少なくとも1レコードはあるものとして割合を返す.
+func (ci *ColumnStatInfo) fractionOf(records float64) float64 {
+	if ci.numRecords == 0 {
+		return 1
+	}
+	return min(1, max(1, records)/float64(ci.numRecords))
+}
+
+// value がバケットの範囲のどの位置にあるか、つまりバケットの中で value より小さい値のレコードの割合を 0~1 で推定する.
+// 整数以外は位置を計算できないので、範囲の中央にあるものとする.
+func (ci *ColumnStatInfo) interpolate(index int, value query.Constant) float64 {
+	// 最初のバケットは最小値を含み、それ以外のバケットは直前のバケットの upperBound を含まない.
+	lowerBound, lowerOk := ci.min.GetValue().(types.Int)
+	if index > 0 {
+		lowerBound, lowerOk = ci.histogram[index-1].upperBound.GetValue().(types.Int)
+		lowerBound++
+	}
+	upperBound, upperOk := ci.histogram[index].upperBound.GetValue().(types.Int)
+	v, valueOk := value.GetValue().(types.Int)
+	if !lowerOk || !upperOk || !valueOk {
+		return 0.5
+	}
+	return min(1, max(0, float64(v-lowerBound)/float64(upperBound-lowerBound+1)))
+}
+
+// カタログに保存するための行に変換する. ヒストグラムのバケットは Seq の順に並ぶ.
+func (ci *ColumnStatInfo) toCatalogRows(tableName types.TableName, fieldName types.FieldName) (ColumnStatCatalogRow, []HistogramCatalogRow) {
+	columnStatRow := ColumnStatCatalogRow{
+		TableName:      tableName,
+		FieldName:      fieldName,
+		NumNulls:       ci.numNulls,
+		DistinctValues: ci.distinctValues,
+	}
+	if len(ci.histogram) > 0 {
+		columnStatRow.MinValue = encodeStatValue(ci.min)
+		columnStatRow.MaxValue = encodeStatValue(ci.max)
+	}
+
+	histogramRows := make([]HistogramCatalogRow, 0, len(ci.histogram))
+	for i, bucket := range ci.histogram {
+		histogramRows = append(histogramRows, HistogramCatalogRow{
+			TableName:      tableName,
+			FieldName:      fieldName,
+			Seq:            types.Int(i),
+			UpperBound:     encodeStatValue(bucket.upperBound),
+			NumRecords:     bucket.numRecords,
+			DistinctValues: bucket.distinctValues,
+		})
+	}
+	return columnStatRow, histogramRows
+}
+
+// カタログから読み込んだ行から、列の統計情報を作り直す. histogramRows は Seq の順に並んでいる必要がある.
+// 値は文字列で保存しているので、列の型に合わせて変換する.
+func restoreColumnStatInfo(numRecords types.Int, fieldType types.FieldType, columnStatRow ColumnStatCatalogRow, histogramRows []HistogramCatalogRow) (*ColumnStatInfo, error) {
+	columnStatInfo := &ColumnStatInfo{
+		numRecords:     numRecords,
+		numNulls:       columnStatRow.NumNulls,
+		distinctValues: columnStatRow.DistinctValues,
+		min:            query.NewNullConstant(),
+		max:            query.NewNullConstant(),
+	}
+	if len(histogramRows) == 0 {
+		return columnStatInfo, nil
+	}
+
+	var err error
+	if columnStatInfo.min, err = decodeStatValue(columnStatRow.MinValue, fieldType); err != nil {
+		return nil, err
+	}
+	if columnStatInfo.max, err = decodeStatValue(columnStatRow.MaxValue, fieldType); err != nil {
+		return nil, err
+	}
+
+	for _, row := range histogramRows {
+		upperBound, err := decodeStatValue(row.UpperBound, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		columnStatInfo.histogram = append(columnStatInfo.histogram, histogramBucket{
+			upperBound:     upperBound,
+			numRecords:     row.NumRecords,
+			distinctValues: row.DistinctValues,
+		})
+	}
+	return columnStatInfo, nil
+}
+
+// 文字列は MAX_STAT_VALUE_LENGTH バイトまでに切り詰める.
+func encodeStatValue(value query.Constant) string {
+	switch value := value.GetValue().(type) {
+	case types.Int:
+		return strconv.Itoa(int(value))
+	case string:
+		if len(value) > constants.MAX_STAT_VALUE_LENGTH {
+			return value[:constants.MAX_STAT_VALUE_LENGTH]
+		}
+		return value
+	default:
+		return ""
+	}
+}
+
+func decodeStatValue(value string, fieldType types.FieldType) (query.Constant, error) {
+	if fieldType != constants.INTEGER {
+		return query.NewStrConstant(value), nil
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return query.NewIntConstant(types.Int(intValue)), nil
+}
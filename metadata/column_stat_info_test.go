@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnStatInfo(t *testing.T) {
+	t.Run("1~100 の値と 10 個の NULL を持つ列の統計情報.", func(t *testing.T) {
+		values := []query.Constant{}
+		for i := types.Int(1); i <= 100; i++ {
+			values = append(values, query.NewIntConstant(i))
+		}
+		columnStatInfo := newColumnStatInfo(110, 10, values)
+
+		assert.Equal(t, types.Int(100), columnStatInfo.GetDistinctValues())
+		assert.Equal(t, types.Int(10), columnStatInfo.GetNumNulls())
+		assert.Equal(t, query.NewIntConstant(1), columnStatInfo.GetMin())
+		assert.Equal(t, query.NewIntConstant(100), columnStatInfo.GetMax())
+		assert.Len(t, columnStatInfo.histogram, NUM_HISTOGRAM_BUCKETS, "値が均等にあるので、バケット数の上限までバケットが作られるはず.")
+
+		assert.InDelta(t, 1.0/110, columnStatInfo.EqualFraction(query.NewIntConstant(50)), 1e-9, "値ごとに1レコードあるはず.")
+		assert.InDelta(t, 1.0/110, columnStatInfo.EqualFraction(query.NewIntConstant(1000)), 1e-9, "範囲外の値でも、少なくとも1レコードはあると推定するはず.")
+		assert.InDelta(t, 10.0/110, columnStatInfo.NullFraction(), 1e-9)
+
+		assert.InDelta(t, 50.0/110, columnStatInfo.LessThanFraction(query.NewIntConstant(51), false), 1e-9, "51 より小さい値は 50 レコードあるはず.")
+		assert.InDelta(t, 51.0/110, columnStatInfo.LessThanFraction(query.NewIntConstant(51), true), 1e-9, "51 以下の値は 51 レコードあるはず.")
+		assert.InDelta(t, 52.0/110, columnStatInfo.LessThanFraction(query.NewIntConstant(53), false), 1e-9, "バケットの途中の値は、バケットの範囲で線形補間するはず.")
+		assert.InDelta(t, 100.0/110, columnStatInfo.LessThanFraction(query.NewIntConstant(1000), false), 1e-9, "最大値より大きい値では、NULL 以外の全てのレコードが該当するはず.")
+		assert.InDelta(t, 1.0/110, columnStatInfo.LessThanFraction(query.NewIntConstant(-5), false), 1e-9, "最小値より小さい値でも、少なくとも1レコードはあると推定するはず.")
+	})
+
+	t.Run("値が偏っている列では、値ごとに異なる割合を推定する.", func(t *testing.T) {
+		// 1 が 90 レコード、2~11 が 1 レコードずつ.
+		values := []query.Constant{}
+		for i := 0; i < 90; i++ {
+			values = append(values, query.NewIntConstant(1))
+		}
+		for i := types.Int(2); i <= 11; i++ {
+			values = append(values, query.NewIntConstant(i))
+		}
+		columnStatInfo := newColumnStatInfo(100, 0, values)
+
+		assert.Equal(t, types.Int(11), columnStatInfo.GetDistinctValues())
+		assert.InDelta(t, 0.9, columnStatInfo.EqualFraction(query.NewIntConstant(1)), 1e-9, "同じ値は1つのバケットにまとまるので、正確な割合になるはず.")
+		assert.Less(t, columnStatInfo.EqualFraction(query.NewIntConstant(5)), 0.1, "少ない値の割合は小さく推定するはず.")
+	})
+
+	t.Run("文字列の列でも推定できる.", func(t *testing.T) {
+		values := []query.Constant{query.NewStrConstant("a"), query.NewStrConstant("b"), query.NewStrConstant("b"), query.NewStrConstant("c")}
+		columnStatInfo := newColumnStatInfo(4, 0, values)
+
+		assert.Equal(t, types.Int(3), columnStatInfo.GetDistinctValues())
+		assert.InDelta(t, 0.5, columnStatInfo.EqualFraction(query.NewStrConstant("b")), 1e-9)
+		assert.InDelta(t, 0.25, columnStatInfo.LessThanFraction(query.NewStrConstant("b"), false), 1e-9)
+		assert.InDelta(t, 0.75, columnStatInfo.LessThanFraction(query.NewStrConstant("b"), true), 1e-9)
+	})
+
+	t.Run("全て NULL の列.", func(t *testing.T) {
+		columnStatInfo := newColumnStatInfo(5, 5, []query.Constant{})
+
+		assert.Equal(t, types.Int(0), columnStatInfo.GetDistinctValues())
+		assert.True(t, query.IsNull(columnStatInfo.GetMin()))
+		assert.InDelta(t, 1.0, columnStatInfo.NullFraction(), 1e-9)
+		assert.InDelta(t, 0.2, columnStatInfo.EqualFraction(query.NewIntConstant(1)), 1e-9, "少なくとも1レコードはあると推定するはず.")
+	})
+
+	t.Run("カタログに保存する行に変換して、元の統計情報に戻せる.", func(t *testing.T) {
+		longValue := strings.Repeat("x", constants.MAX_STAT_VALUE_LENGTH+10)
+		values := []query.Constant{query.NewStrConstant("a"), query.NewStrConstant("b"), query.NewStrConstant(longValue)}
+		columnStatInfo := newColumnStatInfo(4, 1, values)
+
+		columnStatRow, histogramRows := columnStatInfo.toCatalogRows("test_table", "A")
+		assert.Equal(t, strings.Repeat("x", constants.MAX_STAT_VALUE_LENGTH), columnStatRow.MaxValue, "長い文字列は切り詰めて保存するはず.")
+
+		restored, err := restoreColumnStatInfo(4, constants.VARCHAR, columnStatRow, histogramRows)
+		if assert.NoError(t, err) {
+			assert.Equal(t, columnStatInfo.GetDistinctValues(), restored.GetDistinctValues())
+			assert.Equal(t, columnStatInfo.GetMin(), restored.GetMin())
+			assert.InDelta(t, columnStatInfo.EqualFraction(query.NewStrConstant("a")), restored.EqualFraction(query.NewStrConstant("a")), 1e-9)
+			assert.InDelta(t, columnStatInfo.NullFraction(), restored.NullFraction(), 1e-9)
+		}
+
+		_, err = restoreColumnStatInfo(4, constants.INTEGER, columnStatRow, histogramRows)
+		assert.Error(t, err, "整数に変換できない値はエラーになるはず.")
+	})
+}
+
+func TestColumnStatCollector(t *testing.T) {
+	t.Run("値が STAT_SAMPLE_SIZE 個以下の列は、全ての値から正確に計算する.", func(t *testing.T) {
+		collector := newColumnStatCollector()
+		values := []query.Constant{}
+		for i := types.Int(100); i >= 1; i-- {
+			collector.add(query.NewIntConstant(i))
+			values = append([]query.Constant{query.NewIntConstant(i)}, values...)
+		}
+		collector.add(query.NewNullConstant())
+
+		assert.Equal(t, newColumnStatInfo(101, 1, values), collector.build(101), "値をソートして計算した統計情報と同じになるはず.")
+	})
+
+	t.Run("値が STAT_SAMPLE_SIZE 個より多い列は、保持する値を STAT_SAMPLE_SIZE 個に抑えて推定する.", func(t *testing.T) {
+		numValues := types.Int(STAT_SAMPLE_SIZE * 5)
+		uniqueCollector := newColumnStatCollector()
+		repeatedCollector := newColumnStatCollector()
+		for i := types.Int(0); i < numValues; i++ {
+			uniqueCollector.add(query.NewIntConstant(i))
+			repeatedCollector.add(query.NewIntConstant(i % 100))
+		}
+		assert.Len(t, uniqueCollector.samples, STAT_SAMPLE_SIZE)
+
+		unique := uniqueCollector.build(numValues)
+		assert.Equal(t, numValues, unique.GetDistinctValues(), "標本の値が全て異なるので、全ての値が異なると推定するはず.")
+		assert.Equal(t, query.NewIntConstant(0), unique.GetMin(), "最小値は正確に求めるはず.")
+		assert.Equal(t, query.NewIntConstant(numValues-1), unique.GetMax(), "最大値は正確に求めるはず.")
+		assert.InDelta(t, 0.5, unique.LessThanFraction(query.NewIntConstant(numValues/2), false), 0.02)
+
+		repeated := repeatedCollector.build(numValues)
+		assert.Equal(t, types.Int(100), repeated.GetDistinctValues(), "標本に全ての値が複数回現れるので、標本の異なる値の数と推定するはず.")
+		assert.InDelta(t, 0.01, repeated.EqualFraction(query.NewIntConstant(42)), 0.005)
+
+		records := types.Int(0)
+		for _, bucket := range repeated.histogram {
+			records += bucket.numRecords
+		}
+		assert.Equal(t, numValues, records, "バケットのレコード数の合計は、NULL ではない値の数になるはず.")
+	})
+}
@@ -0,0 +1,202 @@
+package metadata
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+// テーブルの制約のメタデータを管理する構造体.
+// 制約の定義は SQL の文字列として保存するだけで、解釈するのは planner の役割とする(ビューの定義と同じ).
+// 外部キーは、参照されている側のテーブルからも引けるように、別のカタログに分けて保存する.
+type ConstraintManager struct {
+	layout           *record.Layout
+	foreignKeyLayout *record.Layout
+	tableManager     *TableManager
+}
+
+// 制約カタログと外部キーカタログは後から追加したので、既存のデータベースに無い場合も作成する.
+func NewConstraintManager(isNew bool, tableManager *TableManager, transaction *transaction.Transaction) *ConstraintManager {
+	if _, err := tableManager.GetLayout(CONSTRAINT_CATALOG_TABLE_NAME, transaction); isNew || err != nil {
+		schema := record.NewSchema()
+		schema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		schema.AddStringField("field_name", constants.MAX_NAME_LENGTH)
+		schema.AddIntField("type")
+		schema.AddStringField("definition", constants.MAX_CONSTRAINT_DEF_LENGTH)
+		tableManager.CreateTable(CONSTRAINT_CATALOG_TABLE_NAME, schema, transaction)
+	}
+
+	if _, err := tableManager.GetLayout(FOREIGN_KEY_CATALOG_TABLE_NAME, transaction); isNew || err != nil {
+		foreignKeySchema := record.NewSchema()
+		foreignKeySchema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		foreignKeySchema.AddStringField("field_name", constants.MAX_NAME_LENGTH)
+		foreignKeySchema.AddStringField("ref_table_name", constants.MAX_NAME_LENGTH)
+		foreignKeySchema.AddStringField("ref_field_name", constants.MAX_NAME_LENGTH)
+		foreignKeySchema.AddIntField("on_delete")
+		tableManager.CreateTable(FOREIGN_KEY_CATALOG_TABLE_NAME, foreignKeySchema, transaction)
+	}
+
+	constraintCatalogLayout, err := tableManager.GetLayout(CONSTRAINT_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		// 制約カタログは必ず初期化するべきなので、無い場合は panic で落とす.
+		panic("ConstraintManager の初期化時に制約カタログのレイアウトが取得できませんでした.")
+	}
+
+	foreignKeyCatalogLayout, err := tableManager.GetLayout(FOREIGN_KEY_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		panic("ConstraintManager の初期化時に外部キーカタログのレイアウトが取得できませんでした.")
+	}
+
+	return &ConstraintManager{
+		layout:           constraintCatalogLayout,
+		foreignKeyLayout: foreignKeyCatalogLayout,
+		tableManager:     tableManager,
+	}
+}
+
+// constraint_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
+// 単に panic させる.
+func (cm *ConstraintManager) CreateConstraints(rows []ConstraintCatalogRow, transaction *transaction.Transaction) {
+	tableScan := query.NewTableScan(transaction, CONSTRAINT_CATALOG_TABLE_NAME, cm.layout)
+	defer tableScan.Close()
+
+	for _, row := range rows {
+		tableScan.Insert()
+
+		if err := tableScan.SetString("table_name", string(row.TableName)); err != nil {
+			panic(fmt.Sprintf("[CreateConstraints] constraint_catalog テーブルの table_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetString("field_name", string(row.FieldName)); err != nil {
+			panic(fmt.Sprintf("[CreateConstraints] constraint_catalog テーブルの field_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetInt("type", types.Int(row.Type)); err != nil {
+			panic(fmt.Sprintf("[CreateConstraints] constraint_catalog テーブルの type に整数をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetString("definition", string(row.Definition)); err != nil {
+			panic(fmt.Sprintf("[CreateConstraints] constraint_catalog テーブルの definition に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+	}
+}
+
+// 指定したテーブルの制約を、登録した順に返す.
+func (cm *ConstraintManager) GetConstraints(tableName types.TableName, transaction *transaction.Transaction) []ConstraintCatalogRow {
+	tableScan := query.NewTableScan(transaction, CONSTRAINT_CATALOG_TABLE_NAME, cm.layout)
+	defer tableScan.Close()
+
+	rows := []ConstraintCatalogRow{}
+	for tableScan.Next() {
+		row := ReadConstraintCatalogRow(tableScan)
+		if row.TableName == tableName {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// fk_catalog テーブルのスキーマは固定であるため、TableScan.SetString,SetInt のエラーは起こり得ない.
+// 単に panic させる.
+func (cm *ConstraintManager) CreateForeignKeys(rows []ForeignKeyCatalogRow, transaction *transaction.Transaction) {
+	tableScan := query.NewTableScan(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout)
+	defer tableScan.Close()
+
+	for _, row := range rows {
+		tableScan.Insert()
+
+		if err := tableScan.SetString("table_name", string(row.TableName)); err != nil {
+			panic(fmt.Sprintf("[CreateForeignKeys] fk_catalog テーブルの table_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetString("field_name", string(row.FieldName)); err != nil {
+			panic(fmt.Sprintf("[CreateForeignKeys] fk_catalog テーブルの field_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetString("ref_table_name", string(row.RefTableName)); err != nil {
+			panic(fmt.Sprintf("[CreateForeignKeys] fk_catalog テーブルの ref_table_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetString("ref_field_name", string(row.RefFieldName)); err != nil {
+			panic(fmt.Sprintf("[CreateForeignKeys] fk_catalog テーブルの ref_field_name に文字列をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+
+		if err := tableScan.SetInt("on_delete", types.Int(row.OnDelete)); err != nil {
+			panic(fmt.Sprintf("[CreateForeignKeys] fk_catalog テーブルの on_delete に整数をセットできませんでした. row=%+v, error=%+v", row, err))
+		}
+	}
+}
+
+// 指定したテーブルが、他のテーブルを参照している外部キーを返す.
+func (cm *ConstraintManager) GetForeignKeys(tableName types.TableName, transaction *transaction.Transaction) []ForeignKeyCatalogRow {
+	return cm.readForeignKeys(transaction, func(row ForeignKeyCatalogRow) bool { return row.TableName == tableName })
+}
+
+// 指定したテーブルを参照している、他のテーブル(自身を含む)の外部キーを返す.
+func (cm *ConstraintManager) GetReferencingForeignKeys(refTableName types.TableName, transaction *transaction.Transaction) []ForeignKeyCatalogRow {
+	return cm.readForeignKeys(transaction, func(row ForeignKeyCatalogRow) bool { return row.RefTableName == refTableName })
+}
+
+func (cm *ConstraintManager) readForeignKeys(transaction *transaction.Transaction, matches func(ForeignKeyCatalogRow) bool) []ForeignKeyCatalogRow {
+	tableScan := query.NewTableScan(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout)
+	defer tableScan.Close()
+
+	rows := []ForeignKeyCatalogRow{}
+	for tableScan.Next() {
+		row := ReadForeignKeyCatalogRow(tableScan)
+		if matches(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// テーブルの制約と、テーブルが他のテーブルを参照している外部キーを全て削除する.
+// 他のテーブルからこのテーブルを参照している外部キーは削除しない. 参照されているテーブルを削除できるかは planner が判断する.
+func (cm *ConstraintManager) DropConstraints(tableName types.TableName, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, CONSTRAINT_CATALOG_TABLE_NAME, cm.layout, func(tableScan *query.TableScan) bool {
+		return ReadConstraintCatalogRow(tableScan).TableName == tableName
+	})
+	DeleteCatalogRows(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout, func(tableScan *query.TableScan) bool {
+		return ReadForeignKeyCatalogRow(tableScan).TableName == tableName
+	})
+}
+
+// テーブルの制約を rows で置き換える. 外部キーはそのまま残す.
+func (cm *ConstraintManager) ReplaceConstraints(tableName types.TableName, rows []ConstraintCatalogRow, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, CONSTRAINT_CATALOG_TABLE_NAME, cm.layout, func(tableScan *query.TableScan) bool {
+		return ReadConstraintCatalogRow(tableScan).TableName == tableName
+	})
+	cm.CreateConstraints(rows, transaction)
+}
+
+// テーブル名の変更に合わせて、制約と外部キーのテーブル名を変更する. 他のテーブルからこのテーブルを参照している外部キーも変更する.
+func (cm *ConstraintManager) RenameTable(tableName types.TableName, newTableName types.TableName, transaction *transaction.Transaction) {
+	UpdateCatalogRows(transaction, CONSTRAINT_CATALOG_TABLE_NAME, cm.layout, func(tableScan *query.TableScan) bool {
+		return ReadConstraintCatalogRow(tableScan).TableName == tableName
+	}, "table_name", string(newTableName))
+	UpdateCatalogRows(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout, func(tableScan *query.TableScan) bool {
+		return ReadForeignKeyCatalogRow(tableScan).TableName == tableName
+	}, "table_name", string(newTableName))
+	UpdateCatalogRows(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout, func(tableScan *query.TableScan) bool {
+		return ReadForeignKeyCatalogRow(tableScan).RefTableName == tableName
+	}, "ref_table_name", string(newTableName))
+}
+
+// フィールド名の変更に合わせて、外部キーのフィールド名を変更する. 他のテーブルからこのフィールドを参照している外部キーも変更する.
+// NOTE: 制約は CHECK の定義にもフィールド名が含まれるので、planner が定義を書き換えて ReplaceConstraints で置き換える.
+func (cm *ConstraintManager) RenameForeignKeyField(tableName types.TableName, fieldName types.FieldName, newFieldName types.FieldName, transaction *transaction.Transaction) {
+	UpdateCatalogRows(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout, func(tableScan *query.TableScan) bool {
+		row := ReadForeignKeyCatalogRow(tableScan)
+		return row.TableName == tableName && row.FieldName == fieldName
+	}, "field_name", string(newFieldName))
+	UpdateCatalogRows(transaction, FOREIGN_KEY_CATALOG_TABLE_NAME, cm.foreignKeyLayout, func(tableScan *query.TableScan) bool {
+		row := ReadForeignKeyCatalogRow(tableScan)
+		return row.RefTableName == tableName && row.RefFieldName == fieldName
+	}, "ref_field_name", string(newFieldName))
+}
@@ -0,0 +1,128 @@
+package metadata
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintManagerNewConstraintManager(t *testing.T) {
+	transaction := newTransactionForTest(t, constraintManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	NewConstraintManager(true, tableManager, transaction)
+
+	t.Run("テーブルカタログに制約カタログのレコードが登録されている.", func(t *testing.T) {
+		row, err := ReadTableCatalogRowFor(CONSTRAINT_CATALOG_TABLE_NAME, transaction, tableManager)
+		if assert.NoError(t, err) {
+			assert.Equal(t, TableCatalogRow{CONSTRAINT_CATALOG_TABLE_NAME, 252, constants.CURRENT_RECORD_FORMAT}, row, "制約カタログのスロットサイズが期待した値であるはず.")
+		}
+	})
+
+	t.Run("フィールドカタログに制約カタログのレコードが登録されている.", func(t *testing.T) {
+		expectedRecords := []FieldCatalogRow{
+			{CONSTRAINT_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 64, 4},
+			{CONSTRAINT_CATALOG_TABLE_NAME, "field_name", constants.VARCHAR, 64, 72},
+			{CONSTRAINT_CATALOG_TABLE_NAME, "type", constants.INTEGER, 0, 140},
+			{CONSTRAINT_CATALOG_TABLE_NAME, "definition", constants.VARCHAR, 100, 144},
+		}
+		actualRecords := ReadFieldCatalogRowsFor(CONSTRAINT_CATALOG_TABLE_NAME, transaction, tableManager)
+		assert.ElementsMatch(t, expectedRecords, actualRecords, "フィールドカタログに制約カタログのレコードが登録されているはず.")
+	})
+}
+
+func TestConstraintManagerCreateGetConstraints(t *testing.T) {
+	transaction := newTransactionForTest(t, constraintManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	constraintManager := NewConstraintManager(true, tableManager, transaction)
+
+	usersConstraints := []ConstraintCatalogRow{
+		{"users", "name", constants.NOT_NULL, "NOT NULL"},
+		{"users", "age", constants.DEFAULT, "DEFAULT 20"},
+		{"users", "age", constants.CHECK, "CHECK (age IS NOT NULL)"},
+	}
+	ordersConstraints := []ConstraintCatalogRow{
+		{"orders", "status", constants.DEFAULT, "DEFAULT 'new'"},
+	}
+	constraintManager.CreateConstraints(usersConstraints, transaction)
+	constraintManager.CreateConstraints(ordersConstraints, transaction)
+
+	t.Run("指定したテーブルの制約だけを、登録した順に取得できる.", func(t *testing.T) {
+		assert.Equal(t, usersConstraints, constraintManager.GetConstraints("users", transaction))
+		assert.Equal(t, ordersConstraints, constraintManager.GetConstraints("orders", transaction))
+	})
+
+	t.Run("制約の無いテーブルでは空のスライスが返る.", func(t *testing.T) {
+		assert.Equal(t, []ConstraintCatalogRow{}, constraintManager.GetConstraints(types.TableName("menus"), transaction))
+	})
+}
+
+func TestConstraintManagerCreateGetForeignKeys(t *testing.T) {
+	transaction := newTransactionForTest(t, constraintManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	constraintManager := NewConstraintManager(true, tableManager, transaction)
+
+	ordersForeignKey := ForeignKeyCatalogRow{"orders", "user_id", "users", "id", constants.CASCADE}
+	usersForeignKey := ForeignKeyCatalogRow{"users", "parent_id", "users", "id", constants.SET_NULL}
+	constraintManager.CreateForeignKeys([]ForeignKeyCatalogRow{ordersForeignKey, usersForeignKey}, transaction)
+
+	t.Run("テーブルが参照している外部キーを取得できる.", func(t *testing.T) {
+		assert.Equal(t, []ForeignKeyCatalogRow{ordersForeignKey}, constraintManager.GetForeignKeys("orders", transaction))
+		assert.Equal(t, []ForeignKeyCatalogRow{usersForeignKey}, constraintManager.GetForeignKeys("users", transaction))
+	})
+
+	t.Run("テーブルを参照している外部キーを取得できる.", func(t *testing.T) {
+		assert.Equal(t, []ForeignKeyCatalogRow{ordersForeignKey, usersForeignKey}, constraintManager.GetReferencingForeignKeys("users", transaction))
+		assert.Equal(t, []ForeignKeyCatalogRow{}, constraintManager.GetReferencingForeignKeys("orders", transaction))
+	})
+}
+
+func TestConstraintManagerRename(t *testing.T) {
+	transaction := newTransactionForTest(t, constraintManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	constraintManager := NewConstraintManager(true, tableManager, transaction)
+
+	constraintManager.CreateConstraints([]ConstraintCatalogRow{{"users", "id", constants.PRIMARY_KEY, "PRIMARY KEY"}}, transaction)
+	constraintManager.CreateForeignKeys([]ForeignKeyCatalogRow{
+		{"orders", "user_id", "users", "id", constants.CASCADE},
+		{"users", "parent_id", "users", "id", constants.SET_NULL},
+	}, transaction)
+
+	t.Run("フィールド名を変更すると、参照している外部キーと参照されている外部キーの両方が変わる.", func(t *testing.T) {
+		constraintManager.RenameForeignKeyField("users", "id", "user_id", transaction)
+
+		assert.Equal(t, []ForeignKeyCatalogRow{
+			{"orders", "user_id", "users", "user_id", constants.CASCADE},
+			{"users", "parent_id", "users", "user_id", constants.SET_NULL},
+		}, constraintManager.GetReferencingForeignKeys("users", transaction))
+	})
+
+	t.Run("テーブル名を変更すると、制約と外部キーのテーブル名が変わる.", func(t *testing.T) {
+		constraintManager.RenameTable("users", "members", transaction)
+
+		assert.Equal(t, []ConstraintCatalogRow{{"members", "id", constants.PRIMARY_KEY, "PRIMARY KEY"}}, constraintManager.GetConstraints("members", transaction))
+		assert.Equal(t, []ForeignKeyCatalogRow{{"members", "parent_id", "members", "user_id", constants.SET_NULL}}, constraintManager.GetForeignKeys("members", transaction))
+		assert.Equal(t, []ForeignKeyCatalogRow{
+			{"orders", "user_id", "members", "user_id", constants.CASCADE},
+			{"members", "parent_id", "members", "user_id", constants.SET_NULL},
+		}, constraintManager.GetReferencingForeignKeys("members", transaction))
+		assert.Equal(t, []ConstraintCatalogRow{}, constraintManager.GetConstraints("users", transaction))
+	})
+
+	t.Run("制約を置き換えても、外部キーは残る.", func(t *testing.T) {
+		rows := []ConstraintCatalogRow{{"members", "name", constants.NOT_NULL, "NOT NULL"}}
+		constraintManager.ReplaceConstraints("members", rows, transaction)
+
+		assert.Equal(t, rows, constraintManager.GetConstraints("members", transaction))
+		assert.Len(t, constraintManager.GetForeignKeys("members", transaction), 1)
+	})
+}
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"simple-db-go/constants"
 	"simple-db-go/types"
 )
 
@@ -38,3 +39,54 @@ type CannotGetIndexInfoError struct {
 func (e CannotGetIndexInfoError) Error() string {
 	return fmt.Sprintf("[Metadata Error] インデックス統計情報の取得に失敗しました. table_name=%s, error=%+v", e.TableName, e.error)
 }
+
+type IndexNotFoundError struct {
+	IndexName types.IndexName
+	TableName types.TableName
+}
+
+func (e IndexNotFoundError) Error() string {
+	return fmt.Sprintf("[Metadata Error] インデックスが見つかりませんでした. index_name=%s, table_name=%s", e.IndexName, e.TableName)
+}
+
+type TableAlreadyExistsError struct {
+	TableName types.TableName
+}
+
+func (e TableAlreadyExistsError) Error() string {
+	return fmt.Sprintf("[Metadata Error] 同じ名前のテーブルが既に存在します. table_name=%s", e.TableName)
+}
+
+type ViewAlreadyExistsError struct {
+	ViewName types.ViewName
+}
+
+func (e ViewAlreadyExistsError) Error() string {
+	return fmt.Sprintf("[Metadata Error] 同じ名前のビューが既に存在します. view_name=%s", e.ViewName)
+}
+
+type IndexAlreadyExistsError struct {
+	IndexName types.IndexName
+	TableName types.TableName
+}
+
+func (e IndexAlreadyExistsError) Error() string {
+	return fmt.Sprintf("[Metadata Error] 同じ名前のインデックスが既に存在します. index_name=%s, table_name=%s", e.IndexName, e.TableName)
+}
+
+type IndexFieldNotFoundError struct {
+	TableName types.TableName
+	FieldName types.FieldName
+}
+
+func (e IndexFieldNotFoundError) Error() string {
+	return fmt.Sprintf("[Metadata Error] インデックスを作成するフィールドがテーブルに存在しません. table_name=%s, field_name=%s", e.TableName, e.FieldName)
+}
+
+type NameTooLongError struct {
+	Name string
+}
+
+func (e NameTooLongError) Error() string {
+	return fmt.Sprintf("[Metadata Error] 名前が長すぎます. 最大 %d 文字です. name=%s", constants.MAX_NAME_LENGTH, e.Name)
+}
@@ -53,8 +53,8 @@ func TestIndexInfoNewInedxInfo(t *testing.T) {
 		})
 
 		t.Run("インデックスのレイアウトが期待した構造になっていること.", func(t *testing.T) {
-			// flag: 4, 3つの整数型フィールド: 4 * 3 = 12, 合計 16 bytes
-			expectedSlotSize := types.SlotSize(4 + 4 + 4 + 4)
+			// flag: 4, 3つの整数型フィールド: 4 * 3 = 12, null bitmap: 4, 合計 20 bytes
+			expectedSlotSize := types.SlotSize(4 + 4 + 4 + 4 + 4)
 			expectedBlockFieldOffset := types.FieldOffsetInSlot(4)
 			expectedIdFieldOffset := types.FieldOffsetInSlot(8)
 			expectedDataValFieldOffset := types.FieldOffsetInSlot(12)
@@ -64,7 +64,7 @@ func TestIndexInfoNewInedxInfo(t *testing.T) {
 			actualIdFieldOffset, _ := actualIndexLayout.GetOffset("id")
 			actualDataValFieldOffset, _ := actualIndexLayout.GetOffset("data_val")
 
-			assert.Equal(t, expectedSlotSize, actualSlotSize, "インデックスのスロットサイズは 16 bytes であるべき.")
+			assert.Equal(t, expectedSlotSize, actualSlotSize, "インデックスのスロットサイズは 20 bytes であるべき.")
 			assert.Equal(t, expectedBlockFieldOffset, actualBlockFieldOffset, "block フィールドのオフセットは 4 bytes であるべき.")
 			assert.Equal(t, expectedIdFieldOffset, actualIdFieldOffset, "id フィールドのオフセットは 8 bytes であるべき.")
 			assert.Equal(t, expectedDataValFieldOffset, actualDataValFieldOffset, "data_val フィールドのオフセットは 12 bytes であるべき.")
@@ -103,8 +103,8 @@ func TestIndexInfoNewInedxInfo(t *testing.T) {
 		})
 
 		t.Run("インデックスのレイアウトが期待した構造になっていること.", func(t *testing.T) {
-			// flag: 4bytes, block field: 4bytes, id field: 4bytes, data_val field: (4+10)bytes, 合計 26 bytes
-			expectedSlotSize := types.SlotSize(26)
+			// flag: 4bytes, block field: 4bytes, id field: 4bytes, data_val field: (4+10)bytes, null bitmap: 4bytes, 合計 30 bytes
+			expectedSlotSize := types.SlotSize(30)
 			expectedBlockFieldOffset := types.FieldOffsetInSlot(4)
 			expectedIdFieldOffset := types.FieldOffsetInSlot(8)
 			expectedDataValFieldOffset := types.FieldOffsetInSlot(12)
@@ -114,7 +114,7 @@ func TestIndexInfoNewInedxInfo(t *testing.T) {
 			actualIdFieldOffset, _ := actualIndexLayout.GetOffset("id")
 			actualDataValFieldOffset, _ := actualIndexLayout.GetOffset("data_val")
 
-			assert.Equal(t, expectedSlotSize, actualSlotSize, "インデックスのスロットサイズは 16 bytes であるべき.")
+			assert.Equal(t, expectedSlotSize, actualSlotSize, "インデックスのスロットサイズは 30 bytes であるべき.")
 			assert.Equal(t, expectedBlockFieldOffset, actualBlockFieldOffset, "block フィールドのオフセットは 4 bytes であるべき.")
 			assert.Equal(t, expectedIdFieldOffset, actualIdFieldOffset, "id フィールドのオフセットは 8 bytes であるべき.")
 			assert.Equal(t, expectedDataValFieldOffset, actualDataValFieldOffset, "data_val フィールドのオフセットは 12 bytes であるべき.")
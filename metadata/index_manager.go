@@ -37,14 +37,62 @@ func NewIndexManager(isNew bool, tableManager *TableManager, statManager *StatMa
 	}
 }
 
-func (im *IndexManager) CreateIndex(indexName types.IndexName, tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) {
+// テーブルとフィールドが存在することと、テーブルに同じ名前のインデックスが無いことを確認してから、カタログレコードを登録する.
+// インデックス名はテーブルごとに一意であればよい.
+func (im *IndexManager) CreateIndex(indexName types.IndexName, tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) error {
+	if err := validateName(indexName); err != nil {
+		return err
+	}
+	tableLayout, err := im.tableManager.GetLayout(tableName, transaction)
+	if err != nil {
+		return err
+	}
+	if !tableLayout.GetSchema().HasField(fieldName) {
+		return IndexFieldNotFoundError{TableName: tableName, FieldName: fieldName}
+	}
+
+	if im.IndexExists(indexName, tableName, transaction) {
+		return IndexAlreadyExistsError{IndexName: indexName, TableName: tableName}
+	}
+
+	im.writeCatalogRow(IndexCatalogRow{IndexName: indexName, TableName: tableName, FieldName: fieldName}, transaction)
+	return nil
+}
+
+func (im *IndexManager) writeCatalogRow(row IndexCatalogRow, transaction *transaction.Transaction) {
 	tableScan := query.NewTableScan(transaction, INDEX_CATALOG_TABLE_NAME, im.layout)
 	defer tableScan.Close()
 
 	tableScan.Insert()
-	tableScan.SetString("index_name", string(indexName))
-	tableScan.SetString("table_name", string(tableName))
-	tableScan.SetString("field_name", string(fieldName))
+	tableScan.SetString("index_name", string(row.IndexName))
+	tableScan.SetString("table_name", string(row.TableName))
+	tableScan.SetString("field_name", string(row.FieldName))
+}
+
+// テーブルに同じ名前のインデックスのカタログレコードがあるかどうか.
+func (im *IndexManager) IndexExists(indexName types.IndexName, tableName types.TableName, transaction *transaction.Transaction) bool {
+	tableScan := query.NewTableScan(transaction, INDEX_CATALOG_TABLE_NAME, im.layout)
+	defer tableScan.Close()
+
+	for tableScan.Next() {
+		row := ReadIndexCatalogRow(tableScan)
+		if row.IndexName == indexName && row.TableName == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// 全てのテーブルのインデックスのカタログレコードを返す.
+func (im *IndexManager) GetIndexes(transaction *transaction.Transaction) []IndexCatalogRow {
+	tableScan := query.NewTableScan(transaction, INDEX_CATALOG_TABLE_NAME, im.layout)
+	defer tableScan.Close()
+
+	rows := []IndexCatalogRow{}
+	for tableScan.Next() {
+		rows = append(rows, ReadIndexCatalogRow(tableScan))
+	}
+	return rows
 }
 
 func (im *IndexManager) GetIndexInfo(tableName types.TableName, transaction *transaction.Transaction) (map[types.FieldName]*IndexInfo, error) {
@@ -73,3 +121,46 @@ func (im *IndexManager) GetIndexInfo(tableName types.TableName, transaction *tra
 
 	return result, nil
 }
+
+// インデックスのカタログレコードを削除する.
+// NOTE: インデックスの実体(ファイル)はまだ実装していないので、カタログレコードだけを削除する.
+func (im *IndexManager) DropIndex(indexName types.IndexName, tableName types.TableName, transaction *transaction.Transaction) error {
+	count := DeleteCatalogRows(transaction, INDEX_CATALOG_TABLE_NAME, im.layout, func(tableScan *query.TableScan) bool {
+		row := ReadIndexCatalogRow(tableScan)
+		return row.IndexName == indexName && row.TableName == tableName
+	})
+	if count == 0 {
+		return IndexNotFoundError{IndexName: indexName, TableName: tableName}
+	}
+	return nil
+}
+
+// テーブルに作られたインデックスのカタログレコードを全て削除する.
+func (im *IndexManager) DropIndexes(tableName types.TableName, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, INDEX_CATALOG_TABLE_NAME, im.layout, func(tableScan *query.TableScan) bool {
+		return ReadIndexCatalogRow(tableScan).TableName == tableName
+	})
+}
+
+// テーブル名の変更に合わせて、インデックスのカタログレコードのテーブル名を変更する.
+func (im *IndexManager) RenameTable(tableName types.TableName, newTableName types.TableName, transaction *transaction.Transaction) {
+	UpdateCatalogRows(transaction, INDEX_CATALOG_TABLE_NAME, im.layout, func(tableScan *query.TableScan) bool {
+		return ReadIndexCatalogRow(tableScan).TableName == tableName
+	}, "table_name", string(newTableName))
+}
+
+// フィールド名の変更に合わせて、インデックスのカタログレコードのフィールド名を変更する. インデックス名は変えない.
+func (im *IndexManager) RenameField(tableName types.TableName, fieldName types.FieldName, newFieldName types.FieldName, transaction *transaction.Transaction) {
+	UpdateCatalogRows(transaction, INDEX_CATALOG_TABLE_NAME, im.layout, func(tableScan *query.TableScan) bool {
+		row := ReadIndexCatalogRow(tableScan)
+		return row.TableName == tableName && row.FieldName == fieldName
+	}, "field_name", string(newFieldName))
+}
+
+// フィールドに作られたインデックスのカタログレコードを全て削除する.
+func (im *IndexManager) DropFieldIndexes(tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, INDEX_CATALOG_TABLE_NAME, im.layout, func(tableScan *query.TableScan) bool {
+		row := ReadIndexCatalogRow(tableScan)
+		return row.TableName == tableName && row.FieldName == fieldName
+	})
+}
@@ -16,7 +16,7 @@ func TestIndexManagerNewIndexManager(t *testing.T) {
 	defer transaction.Rollback()
 
 	tableManager := NewTableManager(true, transaction)
-	statManager := NewStatManager(tableManager, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
 
 	t.Run("インデックスカタログテーブルを初期化していないのに isNew = false で起動すると異常系としてpanicする.", func(t *testing.T) {
 		assert.Panics(t, func() { NewIndexManager(false, tableManager, statManager, transaction) })
@@ -38,7 +38,7 @@ func TestIndexManagerNewIndexManager(t *testing.T) {
 			for tableScan.Next() {
 				actualRows = append(actualRows, ReadTableCatalogRow(tableScan))
 			}
-			expectedRow := TableCatalogRow{TableName: INDEX_CATALOG_TABLE_NAME, SlotSize: 64}
+			expectedRow := TableCatalogRow{TableName: INDEX_CATALOG_TABLE_NAME, SlotSize: 212, Format: constants.CURRENT_RECORD_FORMAT}
 
 			assert.Contains(t, actualRows, expectedRow, "table_catalog テーブルに期待するレコードが入っていること.")
 		})
@@ -53,9 +53,9 @@ func TestIndexManagerNewIndexManager(t *testing.T) {
 				actualRows = append(actualRows, ReadFieldCatalogRow(tableScan))
 			}
 			expectedRows := []FieldCatalogRow{
-				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "index_name", Type: constants.VARCHAR, Length: 16, Offset: 4},
-				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "table_name", Type: constants.VARCHAR, Length: 16, Offset: 24},
-				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "field_name", Type: constants.VARCHAR, Length: 16, Offset: 44},
+				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "index_name", Type: constants.VARCHAR, Length: 64, Offset: 4},
+				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "table_name", Type: constants.VARCHAR, Length: 64, Offset: 72},
+				{TableName: INDEX_CATALOG_TABLE_NAME, FieldName: "field_name", Type: constants.VARCHAR, Length: 64, Offset: 140},
 			}
 
 			assert.Subset(t, actualRows, expectedRows, "field_catalog テーブルに期待するレコードが入っていること.")
@@ -68,7 +68,7 @@ func TestIndexManagerGetCreateIndex(t *testing.T) {
 	defer transaction.Rollback()
 
 	tableManager := NewTableManager(true, transaction)
-	statManager := NewStatManager(tableManager, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
 
 	indexManager := NewIndexManager(true, tableManager, statManager, transaction)
 
@@ -142,3 +142,84 @@ func TestIndexManagerGetCreateIndex(t *testing.T) {
 		}
 	})
 }
+
+func TestIndexManagerDropIndex(t *testing.T) {
+	transaction := newTransactionForTest(t, indexManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
+	indexManager := NewIndexManager(true, tableManager, statManager, transaction)
+
+	testTableName := types.TableName("test_idxdrop")
+	testTableSchema := record.NewSchema()
+	testTableSchema.AddIntField("id")
+	testTableSchema.AddStringField("name", 10)
+	tableManager.CreateTable(testTableName, testTableSchema, transaction)
+	indexManager.CreateIndex("test_index_1", testTableName, "id", transaction)
+	indexManager.CreateIndex("test_index_2", testTableName, "name", transaction)
+
+	t.Run("インデックスを削除すると、そのインデックスだけが無くなる.", func(t *testing.T) {
+		assert.NoError(t, indexManager.DropIndex("test_index_1", testTableName, transaction), "存在するインデックスは削除できるべし.")
+
+		indexInfoMap, err := indexManager.GetIndexInfo(testTableName, transaction)
+		if assert.NoError(t, err, "テーブルは存在するのでエラーにはならない.") {
+			assert.Len(t, indexInfoMap, 1, "削除していないインデックスだけが残っているべし.")
+			assert.Contains(t, indexInfoMap, types.FieldName("name"), "name のインデックスは残っているべし.")
+		}
+	})
+
+	t.Run("存在しないインデックスを削除しようとするとエラーになる.", func(t *testing.T) {
+		err := indexManager.DropIndex("test_index_1", testTableName, transaction)
+		assert.IsType(t, IndexNotFoundError{}, err, "存在しないインデックスの削除では IndexNotFoundError を返すべし.")
+	})
+
+	t.Run("テーブルのインデックスをまとめて削除できる.", func(t *testing.T) {
+		indexManager.DropIndexes(testTableName, transaction)
+
+		indexInfoMap, err := indexManager.GetIndexInfo(testTableName, transaction)
+		if assert.NoError(t, err, "テーブルは存在するのでエラーにはならない.") {
+			assert.Empty(t, indexInfoMap, "インデックスは全て削除されているべし.")
+		}
+	})
+}
+
+func TestIndexManagerCreateIndexValidation(t *testing.T) {
+	transaction := newTransactionForTest(t, indexManagerTestName)
+	defer transaction.Rollback()
+
+	tableManager := NewTableManager(true, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
+	indexManager := NewIndexManager(true, tableManager, statManager, transaction)
+
+	testTableName := types.TableName("test_idxcheck")
+	testTableSchema := record.NewSchema()
+	testTableSchema.AddIntField("id")
+	tableManager.CreateTable(testTableName, testTableSchema, transaction)
+	assert.NoError(t, indexManager.CreateIndex("test_index", testTableName, "id", transaction), "存在するテーブルとフィールドにはインデックスを作成できるべし.")
+	assert.True(t, indexManager.IndexExists("test_index", testTableName, transaction))
+
+	tests := []struct {
+		name      string
+		indexName types.IndexName
+		tableName types.TableName
+		fieldName types.FieldName
+		expected  error
+	}{
+		{"存在しないテーブル", "test_index_2", "hoge_table", "id", TableCatalogNotFoundError{TableName: "hoge_table"}},
+		{"存在しないフィールド", "test_index_2", testTableName, "hoge", IndexFieldNotFoundError{TableName: testTableName, FieldName: "hoge"}},
+		{"同じ名前のインデックス", "test_index", testTableName, "id", IndexAlreadyExistsError{IndexName: "test_index", TableName: testTableName}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+"にはインデックスを作成できない.", func(t *testing.T) {
+			err := indexManager.CreateIndex(test.indexName, test.tableName, test.fieldName, transaction)
+			assert.Equal(t, test.expected, err)
+		})
+	}
+
+	indexInfoMap, err := indexManager.GetIndexInfo(testTableName, transaction)
+	if assert.NoError(t, err) {
+		assert.Len(t, indexInfoMap, 1, "エラーになったインデックスは登録されないべし.")
+	}
+}
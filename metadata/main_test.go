@@ -13,6 +13,14 @@ const viewManagerTestName = "view_manager_test"
 const statManagerTestName = "stat_manager_test"
 const indexInfoTestName = "index_info_test"
 const indexManagerTestName = "index_manager_test"
+const constraintManagerTestName = "constraint_manager_test"
+const autoIncrementManagerTestName = "auto_increment_manager_test"
+const autoIncrementCounterLockTestName = "auto_increment_counter_lock_test"
+const catalogMigrationTestName = "catalog_migration_test"
+const catalogCacheTestName = "catalog_cache_test"
+const statDeltaTestName = "stat_delta_test"
+const statRecalculateTestName = "stat_recalculate_test"
+const statGenerationTestName = "stat_generation_test"
 
 func TestMain(m *testing.M) {
 	testNames := []string{
@@ -21,6 +29,14 @@ func TestMain(m *testing.M) {
 		statManagerTestName,
 		indexInfoTestName,
 		indexManagerTestName,
+		constraintManagerTestName,
+		autoIncrementManagerTestName,
+		autoIncrementCounterLockTestName,
+		catalogMigrationTestName,
+		catalogCacheTestName,
+		statDeltaTestName,
+		statRecalculateTestName,
+		statGenerationTestName,
 	}
 
 	for _, name := range testNames {
@@ -7,44 +7,96 @@ import (
 )
 
 type MetadataManager struct {
-	tableManager *TableManager
-	viewManager  *ViewManager
-	statManager  *StatManager
-	indexManager *IndexManager
+	tableManager         *TableManager
+	viewManager          *ViewManager
+	statManager          *StatManager
+	indexManager         *IndexManager
+	constraintManager    *ConstraintManager
+	autoIncrementManager *AutoIncrementManager
 }
 
+// 古い形式のカタログのデータベースは、カタログレコードを読み出してから、新しい形式のカタログを作り直して書き込む.
 func NewMetadataManager(isNew bool, transaction *transaction.Transaction) *MetadataManager {
+	var legacyCatalog *legacyCatalog
+	if !isNew && isLegacyCatalog(transaction) {
+		legacyCatalog = readAndClearLegacyCatalog(transaction)
+		isNew = true
+	}
+
 	tableManager := NewTableManager(isNew, transaction)
 	viewManager := NewViewManager(isNew, tableManager, transaction)
-	statManager := NewStatManager(tableManager, transaction)
+	statManager := NewStatManager(isNew, tableManager, transaction)
 	indexManager := NewIndexManager(isNew, tableManager, statManager, transaction)
+	constraintManager := NewConstraintManager(isNew, tableManager, transaction)
+	autoIncrementManager := NewAutoIncrementManager(isNew, tableManager, transaction)
 
-	return &MetadataManager{
-		tableManager: tableManager,
-		viewManager:  viewManager,
-		statManager:  statManager,
-		indexManager: indexManager,
+	metadataManager := &MetadataManager{
+		tableManager:         tableManager,
+		viewManager:          viewManager,
+		statManager:          statManager,
+		indexManager:         indexManager,
+		constraintManager:    constraintManager,
+		autoIncrementManager: autoIncrementManager,
 	}
+
+	if legacyCatalog != nil {
+		metadataManager.restoreLegacyCatalog(legacyCatalog, transaction)
+	}
+	return metadataManager
 }
 
-func (mm *MetadataManager) CreateTable(tableName types.TableName, schema *record.Schema, transaction *transaction.Transaction) {
-	mm.tableManager.CreateTable(tableName, schema, transaction)
+// テーブルとビューは同じ名前で参照するので、同じ名前のビューがある場合もエラーにする.
+func (mm *MetadataManager) CreateTable(tableName types.TableName, schema *record.Schema, transaction *transaction.Transaction) error {
+	if _, err := mm.viewManager.GetViewDef(types.ViewName(tableName), transaction); err == nil {
+		return ViewAlreadyExistsError{ViewName: types.ViewName(tableName)}
+	}
+	return mm.tableManager.CreateTable(tableName, schema, transaction)
 }
 
 func (mm *MetadataManager) GetLayout(tableName types.TableName, transaction *transaction.Transaction) (*record.Layout, error) {
 	return mm.tableManager.GetLayout(tableName, transaction)
 }
 
-func (mm *MetadataManager) CreateView(viewName types.ViewName, viewDef types.ViewDef, transaction *transaction.Transaction) {
-	mm.viewManager.CreateView(viewName, viewDef, transaction)
+// テーブルとビューは同じ名前で参照するので、同じ名前のテーブルがある場合もエラーにする.
+func (mm *MetadataManager) CreateView(viewName types.ViewName, viewDef types.ViewDef, transaction *transaction.Transaction) error {
+	if _, err := mm.tableManager.GetLayout(types.TableName(viewName), transaction); err == nil {
+		return TableAlreadyExistsError{TableName: types.TableName(viewName)}
+	}
+	return mm.viewManager.CreateView(viewName, viewDef, transaction)
 }
 
 func (mm *MetadataManager) GetViewDef(viewName types.ViewName, transaction *transaction.Transaction) (types.ViewDef, error) {
 	return mm.viewManager.GetViewDef(viewName, transaction)
 }
 
-func (mm *MetadataManager) CreateIndex(indexName types.IndexName, tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) {
-	mm.indexManager.CreateIndex(indexName, tableName, fieldName, transaction)
+func (mm *MetadataManager) CreateIndex(indexName types.IndexName, tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) error {
+	return mm.indexManager.CreateIndex(indexName, tableName, fieldName, transaction)
+}
+
+func (mm *MetadataManager) IndexExists(indexName types.IndexName, tableName types.TableName, transaction *transaction.Transaction) bool {
+	return mm.indexManager.IndexExists(indexName, tableName, transaction)
+}
+
+// 同じ名前のテーブルかビューが存在するかどうか.
+func (mm *MetadataManager) TableOrViewExists(tableName types.TableName, transaction *transaction.Transaction) bool {
+	if _, err := mm.tableManager.GetLayout(tableName, transaction); err == nil {
+		return true
+	}
+	_, err := mm.viewManager.GetViewDef(types.ViewName(tableName), transaction)
+	return err == nil
+}
+
+// カタログテーブルも含めて、全てのテーブル名を返す.
+func (mm *MetadataManager) GetTableNames(transaction *transaction.Transaction) []types.TableName {
+	return mm.tableManager.GetTableNames(transaction)
+}
+
+func (mm *MetadataManager) GetViewNames(transaction *transaction.Transaction) []types.ViewName {
+	return mm.viewManager.GetViewNames(transaction)
+}
+
+func (mm *MetadataManager) GetIndexes(transaction *transaction.Transaction) []IndexCatalogRow {
+	return mm.indexManager.GetIndexes(transaction)
 }
 
 func (mm *MetadataManager) GetIndexInfo(tableName types.TableName, transaction *transaction.Transaction) (map[types.FieldName]*IndexInfo, error) {
@@ -54,3 +106,169 @@ func (mm *MetadataManager) GetIndexInfo(tableName types.TableName, transaction *
 func (mm *MetadataManager) GetStatInfo(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) *StatInfo {
 	return mm.statManager.GetStatInfo(tableName, layout, transaction)
 }
+
+func (mm *MetadataManager) GetCachedStatInfo(tableName types.TableName, transaction *transaction.Transaction) (*StatInfo, bool) {
+	return mm.statManager.GetCachedStatInfo(tableName, transaction)
+}
+
+// テーブルを読んで統計情報を計算し直し、カタログに保存する.
+func (mm *MetadataManager) AnalyzeTable(tableName types.TableName, transaction *transaction.Transaction) (*StatInfo, error) {
+	layout, err := mm.tableManager.GetLayout(tableName, transaction)
+	if err != nil {
+		return nil, err
+	}
+	return mm.statManager.AnalyzeTable(tableName, layout, transaction), nil
+}
+
+// テーブルのレコードを挿入・更新・削除した数を記録する. 変更が多くなったテーブルは、次に参照された時に統計情報を計算し直す.
+func (mm *MetadataManager) AddModifiedCount(tableName types.TableName, count types.Int) {
+	mm.statManager.AddModifiedCount(tableName, count)
+}
+
+// トランザクションが挿入・削除したレコード数と、追加したブロック数を記録する. コミットした時に統計情報に反映する.
+func (mm *MetadataManager) AddStatDelta(tableName types.TableName, recordsDelta types.Int, blocksDelta types.Int, transaction *transaction.Transaction) {
+	mm.statManager.AddStatDelta(tableName, recordsDelta, blocksDelta, transaction)
+}
+
+func (mm *MetadataManager) CreateConstraints(rows []ConstraintCatalogRow, transaction *transaction.Transaction) {
+	mm.constraintManager.CreateConstraints(rows, transaction)
+}
+
+func (mm *MetadataManager) GetConstraints(tableName types.TableName, transaction *transaction.Transaction) []ConstraintCatalogRow {
+	return mm.constraintManager.GetConstraints(tableName, transaction)
+}
+
+func (mm *MetadataManager) CreateForeignKeys(rows []ForeignKeyCatalogRow, transaction *transaction.Transaction) {
+	mm.constraintManager.CreateForeignKeys(rows, transaction)
+}
+
+func (mm *MetadataManager) GetForeignKeys(tableName types.TableName, transaction *transaction.Transaction) []ForeignKeyCatalogRow {
+	return mm.constraintManager.GetForeignKeys(tableName, transaction)
+}
+
+func (mm *MetadataManager) GetReferencingForeignKeys(refTableName types.TableName, transaction *transaction.Transaction) []ForeignKeyCatalogRow {
+	return mm.constraintManager.GetReferencingForeignKeys(refTableName, transaction)
+}
+
+func (mm *MetadataManager) CreateAutoIncrement(tableName types.TableName, fieldName types.FieldName, transaction *transaction.Transaction) {
+	mm.autoIncrementManager.CreateAutoIncrement(tableName, fieldName, transaction)
+}
+
+func (mm *MetadataManager) GetAutoIncrement(tableName types.TableName, transaction *transaction.Transaction) (AutoIncrementCatalogRow, bool) {
+	return mm.autoIncrementManager.GetAutoIncrement(tableName, transaction)
+}
+
+func (mm *MetadataManager) SetAutoIncrementNextValue(tableName types.TableName, nextValue types.Int, transaction *transaction.Transaction) {
+	mm.autoIncrementManager.SetNextValue(tableName, nextValue, transaction)
+}
+
+// テーブルと、テーブルに紐づくフィールド・インデックス・制約・AUTO_INCREMENT のカタログレコードを削除する.
+// テーブルのファイルはトランザクションのコミット時に削除される.
+func (mm *MetadataManager) DropTable(tableName types.TableName, transaction *transaction.Transaction) error {
+	if err := mm.tableManager.DropTable(tableName, transaction); err != nil {
+		return err
+	}
+	mm.indexManager.DropIndexes(tableName, transaction)
+	mm.constraintManager.DropConstraints(tableName, transaction)
+	mm.autoIncrementManager.DropTable(tableName, transaction)
+	mm.statManager.RemoveStatInfo(tableName, transaction)
+	return nil
+}
+
+// TRUNCATE したテーブルの AUTO_INCREMENT のカウンタを 1 に戻し、統計情報を破棄する.
+// レコードを空にするのは呼び出し側の役割.
+// NOTE: インデックスはカタログにしか存在せず、インデックスのファイルは無いので、インデックスについてはやることがない.
+func (mm *MetadataManager) TruncateTable(tableName types.TableName, transaction *transaction.Transaction) {
+	if _, exists := mm.autoIncrementManager.GetAutoIncrement(tableName, transaction); exists {
+		mm.autoIncrementManager.SetNextValue(tableName, 1, transaction)
+	}
+	mm.statManager.RemoveStatInfo(tableName, transaction)
+}
+
+func (mm *MetadataManager) DropView(viewName types.ViewName, transaction *transaction.Transaction) error {
+	return mm.viewManager.DropView(viewName, transaction)
+}
+
+func (mm *MetadataManager) DropIndex(indexName types.IndexName, tableName types.TableName, transaction *transaction.Transaction) error {
+	return mm.indexManager.DropIndex(indexName, tableName, transaction)
+}
+
+// テーブルのレイアウトを置き換える. 既存のレコードを新しいレイアウトで書き直すのは呼び出し側の役割.
+func (mm *MetadataManager) AlterTable(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) error {
+	if err := mm.tableManager.AlterTable(tableName, tableName, layout, transaction); err != nil {
+		return err
+	}
+	mm.statManager.RemoveStatInfo(tableName, transaction)
+	return nil
+}
+
+// テーブル名を変更し、テーブルに紐づくインデックス・制約・AUTO_INCREMENT のカタログレコードも新しいテーブル名に変更する.
+// 元のテーブルのファイルはコミット時に削除されるので、レコードを新しいテーブルに書き直すのは呼び出し側の役割.
+func (mm *MetadataManager) RenameTable(tableName types.TableName, newTableName types.TableName, transaction *transaction.Transaction) error {
+	layout, err := mm.tableManager.GetLayout(tableName, transaction)
+	if err != nil {
+		return err
+	}
+	if _, err := mm.viewManager.GetViewDef(types.ViewName(newTableName), transaction); err == nil {
+		return ViewAlreadyExistsError{ViewName: types.ViewName(newTableName)}
+	}
+
+	if err := mm.tableManager.AlterTable(tableName, newTableName, layout, transaction); err != nil {
+		return err
+	}
+	mm.indexManager.RenameTable(tableName, newTableName, transaction)
+	mm.constraintManager.RenameTable(tableName, newTableName, transaction)
+	mm.autoIncrementManager.RenameTable(tableName, newTableName, transaction)
+	mm.statManager.RemoveStatInfo(tableName, transaction)
+	return nil
+}
+
+// フィールド名を変更し、インデックス・外部キー・AUTO_INCREMENT のカタログレコードも新しいフィールド名に変更する.
+// レイアウトのオフセットは変えないので、既存のレコードを書き直す必要はない.
+func (mm *MetadataManager) RenameField(tableName types.TableName, fieldName types.FieldName, newFieldName types.FieldName, transaction *transaction.Transaction) error {
+	layout, err := mm.tableManager.GetLayout(tableName, transaction)
+	if err != nil {
+		return err
+	}
+
+	schema := record.NewSchema()
+	offsets := make(map[types.FieldName]types.FieldOffsetInSlot)
+	for _, name := range layout.GetSchema().Fields() {
+		// layout 自身から取得したフィールドなので、エラーは発生し得ない.
+		fieldType, _ := layout.GetSchema().FieldType(name)
+		length, _ := layout.GetSchema().Length(name)
+		offset, _ := layout.GetOffset(name)
+
+		if name == fieldName {
+			name = newFieldName
+		}
+		schema.AddField(name, fieldType, length)
+		offsets[name] = offset
+	}
+
+	if err := mm.tableManager.AlterTable(tableName, tableName, record.NewLayoutWith(schema, offsets, layout.GetSlotSize(), layout.GetFormat()), transaction); err != nil {
+		return err
+	}
+	mm.indexManager.RenameField(tableName, fieldName, newFieldName, transaction)
+	mm.constraintManager.RenameForeignKeyField(tableName, fieldName, newFieldName, transaction)
+	mm.autoIncrementManager.RenameField(tableName, fieldName, newFieldName, transaction)
+	mm.statManager.RemoveStatInfo(tableName, transaction)
+	return nil
+}
+
+// フィールドを削除したレイアウトに置き換え、フィールドに紐づくインデックスと AUTO_INCREMENT のカタログレコードを削除する.
+// 制約は planner が ReplaceConstraints で置き換える.
+func (mm *MetadataManager) DropField(tableName types.TableName, fieldName types.FieldName, layout *record.Layout, transaction *transaction.Transaction) error {
+	if err := mm.AlterTable(tableName, layout, transaction); err != nil {
+		return err
+	}
+	mm.indexManager.DropFieldIndexes(tableName, fieldName, transaction)
+	if counter, exists := mm.autoIncrementManager.GetAutoIncrement(tableName, transaction); exists && counter.FieldName == fieldName {
+		mm.autoIncrementManager.DropAutoIncrement(tableName, transaction)
+	}
+	return nil
+}
+
+func (mm *MetadataManager) ReplaceConstraints(tableName types.TableName, rows []ConstraintCatalogRow, transaction *transaction.Transaction) {
+	mm.constraintManager.ReplaceConstraints(tableName, rows, transaction)
+}
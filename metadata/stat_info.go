@@ -8,6 +8,8 @@ import (
 type StatInfo struct {
 	numBlocks  types.Int
 	numRecords types.Int
+	// 列ごとの統計情報. テーブルを読んで計算した場合だけ持つ.
+	columnStats map[types.FieldName]*ColumnStatInfo
 }
 
 func NewStatInfo(numBLocks types.Int, numRecords types.Int) *StatInfo {
@@ -17,6 +19,19 @@ func NewStatInfo(numBLocks types.Int, numRecords types.Int) *StatInfo {
 	}
 }
 
+func newStatInfoWithColumns(numBlocks types.Int, numRecords types.Int, columnStats map[types.FieldName]*ColumnStatInfo) *StatInfo {
+	return &StatInfo{
+		numBlocks:   numBlocks,
+		numRecords:  numRecords,
+		columnStats: columnStats,
+	}
+}
+
+// レコード数とブロック数を増減した統計情報を返す. 列の統計情報はそのまま引き継ぐ.
+func (si *StatInfo) withDelta(recordsDelta types.Int, blocksDelta types.Int) *StatInfo {
+	return newStatInfoWithColumns(max(0, si.numBlocks+blocksDelta), max(0, si.numRecords+recordsDelta), si.columnStats)
+}
+
 func (si *StatInfo) GetBlocksAccessed() types.Int {
 	return si.numBlocks
 }
@@ -25,8 +40,16 @@ func (si *StatInfo) GetRecordsOutput() types.Int {
 	return si.numRecords
 }
 
-// NOTE: 雑な推定に基づいて値を返している.
-// Exercice 7.12 で改善する.
+// 列の統計情報があれば、正確な異なる値の数を返す. 全て NULL の列でも、NULL を1つの値とみなして 1 以上を返す.
+// 列の統計情報が無い場合は、雑な推定に基づいて値を返す.
 func (si *StatInfo) GetDistinctValues(fieldName types.FieldName) types.Int {
+	if columnStatInfo, exists := si.columnStats[fieldName]; exists {
+		return max(1, columnStatInfo.GetDistinctValues())
+	}
 	return 1 + (si.numRecords / 3)
 }
+
+func (si *StatInfo) GetColumnStatInfo(fieldName types.FieldName) (*ColumnStatInfo, bool) {
+	columnStatInfo, exists := si.columnStats[fieldName]
+	return columnStatInfo, exists
+}
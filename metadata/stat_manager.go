@@ -2,23 +2,44 @@ package metadata
 
 import (
 	"fmt"
+	"simple-db-go/constants"
 	"simple-db-go/query"
 	"simple-db-go/record"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
+	"slices"
 	"sync"
 )
 
+// 統計情報を計算してから、テーブルのレコード数に対してこの割合より多くのレコードを変更したら、統計情報を計算し直す.
+const STAT_REFRESH_MODIFIED_RATIO = 0.1
+
+// レコードの少ないテーブルで頻繁に計算し直さないように、少なくともこの数のレコードを変更するまでは計算し直さない.
+const STAT_REFRESH_MIN_MODIFIED_COUNT = 100
+
 type StatManager struct {
 	tableManager *TableManager
 
+	tableStatLayout  *record.Layout
+	columnStatLayout *record.Layout
+	histogramLayout  *record.Layout
+
 	// StatManager は in-memory で統計情報を保持する.
 	// また、この情報はリアルタイムで正確に更新は"しない".
+	// ANALYZE TABLE で計算した統計情報はカタログにも保存し、DB起動時にカタログから読み込む.
+	// カタログに無いテーブルは、最初に参照された時にテーブルを読んで計算する.
 	tableStats map[types.TableName]*StatInfo
 
-	// 統計情報にアクセスされた回数を記録する.
-	// 100 回を超えたタイミングで統計情報を更新する.
-	numCalls types.Int
+	// 統計情報を計算してから、テーブルのレコードを挿入・更新・削除した数.
+	modifiedCounts map[types.TableName]types.Int
+
+	// コミットしていないトランザクションが増減させた、テーブルのレコード数とブロック数.
+	// コミットした時に保持している統計情報に反映し、ロールバックした時に捨てる.
+	pendingDeltas pendingStatDeltas
+
+	// テーブルの増減をコミットしたり、統計情報を破棄したりするたびに増やす.
+	// テーブルを読んでいる間に増えた場合、読んだ統計情報にその増減が含まれているか分からないので保持しない.
+	generations map[types.TableName]types.Int
 
 	// FileManager などとは方針を変えてみて、素直に sync を使ってみます.
 	// Go言語のお勉強も目的なので、色々やってみたいというだけの理由です.
@@ -26,67 +47,381 @@ type StatManager struct {
 }
 
 // DB起動時に1度だけ呼ばれる.
-func NewStatManager(tableManager *TableManager, transaction *transaction.Transaction) *StatManager {
+// 統計情報のカタログは後から追加したので、古いデータベースに無い場合も作る.
+func NewStatManager(isNew bool, tableManager *TableManager, transaction *transaction.Transaction) *StatManager {
+	if _, err := tableManager.GetLayout(TABLE_STAT_CATALOG_TABLE_NAME, transaction); isNew || err != nil {
+		schema := record.NewSchema()
+		schema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		schema.AddIntField("num_blocks")
+		schema.AddIntField("num_records")
+		tableManager.CreateTable(TABLE_STAT_CATALOG_TABLE_NAME, schema, transaction)
+
+		schema = record.NewSchema()
+		schema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		schema.AddStringField("field_name", constants.MAX_NAME_LENGTH)
+		schema.AddIntField("num_nulls")
+		schema.AddIntField("distinct_values")
+		schema.AddStringField("min_value", constants.MAX_STAT_VALUE_LENGTH)
+		schema.AddStringField("max_value", constants.MAX_STAT_VALUE_LENGTH)
+		tableManager.CreateTable(COLUMN_STAT_CATALOG_TABLE_NAME, schema, transaction)
+
+		schema = record.NewSchema()
+		schema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
+		schema.AddStringField("field_name", constants.MAX_NAME_LENGTH)
+		schema.AddIntField("seq")
+		schema.AddStringField("upper_bound", constants.MAX_STAT_VALUE_LENGTH)
+		schema.AddIntField("num_records")
+		schema.AddIntField("distinct_values")
+		tableManager.CreateTable(HISTOGRAM_CATALOG_TABLE_NAME, schema, transaction)
+	}
+
+	tableStatLayout, err := tableManager.GetLayout(TABLE_STAT_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		// 統計情報のカタログは必ず初期化するべきなので、無い場合は panic で落とす.
+		panic("StatManager の初期化時に tblstat_catalog のレイアウトが取得できませんでした.")
+	}
+	columnStatLayout, err := tableManager.GetLayout(COLUMN_STAT_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		panic("StatManager の初期化時に colstat_catalog のレイアウトが取得できませんでした.")
+	}
+	histogramLayout, err := tableManager.GetLayout(HISTOGRAM_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		panic("StatManager の初期化時に histogram_catalog のレイアウトが取得できませんでした.")
+	}
+
 	statManager := &StatManager{
-		tableManager: tableManager,
-		tableStats:   make(map[types.TableName]*StatInfo),
-		numCalls:     0,
+		tableManager:     tableManager,
+		tableStatLayout:  tableStatLayout,
+		columnStatLayout: columnStatLayout,
+		histogramLayout:  histogramLayout,
+		tableStats:       make(map[types.TableName]*StatInfo),
+		modifiedCounts:   make(map[types.TableName]types.Int),
+		pendingDeltas:    make(pendingStatDeltas),
+		generations:      make(map[types.TableName]types.Int),
 	}
-	statManager.refreshStatistics(transaction)
+	statManager.loadStatistics(transaction)
 	return statManager
 }
 
+// 統計情報が無いテーブルと、統計情報を計算してから多くのレコードを変更したテーブルは、テーブルを読んで計算し直す.
+// トランザクション自身がまだコミットしていない増減は、返す統計情報にだけ反映する.
+// NOTE: ここで計算し直した統計情報はカタログに保存しない.
+// 問い合わせのトランザクションでカタログを書き換えると、コミットするまで他のトランザクションが統計情報のカタログを読めなくなるため.
 func (sm *StatManager) GetStatInfo(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) *StatInfo {
 	sm.mu.Lock()
+	statInfo := sm.tableStats[tableName]
+	if statInfo == nil || sm.isStale(tableName, statInfo) {
+		sm.mu.Unlock()
+		return sm.recalculate(tableName, layout, transaction)
+	}
 	defer sm.mu.Unlock()
 
-	sm.numCalls++
-	if sm.numCalls > 100 {
-		sm.refreshStatistics(transaction)
+	if delta, exists := sm.pendingDeltas[transaction][tableName]; exists && delta.hasPending() {
+		return statInfo.withDelta(delta.pendingRecords, delta.pendingBlocks)
 	}
+	return statInfo
+}
 
-	statInfo := sm.tableStats[tableName]
+// テーブルを読んで統計情報を計算し直す. 計算した統計情報には、トランザクション自身の増減も含まれている.
+// テーブルを読む間は他のトランザクションのロックを待つことがあるので、sm.mu を保持せず、他のテーブルの統計情報の取得やコミットを待たせない.
+// 読んでいる間に他のトランザクションが増減をコミットした場合は、計算した統計情報を返すだけで保持しない.
+func (sm *StatManager) recalculate(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) *StatInfo {
+	sm.mu.Lock()
+	generation := sm.generations[tableName]
+	sm.mu.Unlock()
+
+	statInfo := sm.calcTableStats(tableName, layout, transaction)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if generation == sm.generations[tableName] {
+		sm.storeCalculatedStats(tableName, statInfo, transaction)
+	}
+	return statInfo
+}
+
+// 保持している統計情報があれば返し、無ければ false を返す. テーブルを読んで計算し直すことはしない.
+// トランザクション自身がまだコミットしていない増減は、返す統計情報にだけ反映する.
+func (sm *StatManager) GetCachedStatInfo(tableName types.TableName, transaction *transaction.Transaction) (*StatInfo, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	statInfo, exists := sm.tableStats[tableName]
+	if !exists {
+		return nil, false
+	}
+	if delta, exists := sm.pendingDeltas[transaction][tableName]; exists && delta.hasPending() {
+		return statInfo.withDelta(delta.pendingRecords, delta.pendingBlocks), true
+	}
+	return statInfo, true
+}
+
+func (sm *StatManager) isStale(tableName types.TableName, statInfo *StatInfo) bool {
+	threshold := max(STAT_REFRESH_MIN_MODIFIED_COUNT, types.Int(float64(statInfo.GetRecordsOutput())*STAT_REFRESH_MODIFIED_RATIO))
+	return sm.modifiedCounts[tableName] > threshold
+}
+
+// テーブルのレコードを挿入・更新・削除した数を記録する.
+// ロールバックした変更も数えるが、統計情報を計算し直すかどうかの目安に使うだけなので問題ない.
+func (sm *StatManager) AddModifiedCount(tableName types.TableName, count types.Int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.modifiedCounts[tableName] += count
+}
+
+// トランザクションが挿入・削除したレコード数と、追加したブロック数を記録する.
+// コミットするまでは、他のトランザクションの統計情報には反映しない.
+func (sm *StatManager) AddStatDelta(tableName types.TableName, recordsDelta types.Int, blocksDelta types.Int, transaction *transaction.Transaction) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	deltas, exists := sm.pendingDeltas[transaction]
+	if !exists {
+		deltas = make(map[types.TableName]*statDelta)
+		sm.pendingDeltas[transaction] = deltas
+		transaction.OnCommit(func() { sm.finishTransaction(transaction, true) })
+		transaction.OnRollback(func() { sm.finishTransaction(transaction, false) })
+	}
+
+	delta, exists := deltas[tableName]
+	if !exists {
+		delta = &statDelta{}
+		deltas[tableName] = delta
+	}
+	delta.pendingRecords += recordsDelta
+	delta.pendingBlocks += blocksDelta
+}
+
+// コミットした場合は、まだ統計情報に反映していない増減を反映する.
+// ロールバックした場合は、トランザクションの中で計算した統計情報に含まれている増減を取り消す.
+func (sm *StatManager) finishTransaction(transaction *transaction.Transaction, committed bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for tableName, delta := range sm.pendingDeltas[transaction] {
+		if committed {
+			sm.generations[tableName]++
+		}
 
-	if statInfo == nil {
-		statInfo = sm.calcTableStats(tableName, layout, transaction)
-		sm.tableStats[tableName] = statInfo
+		statInfo, exists := sm.tableStats[tableName]
+		if !exists {
+			continue
+		}
+		if committed {
+			sm.tableStats[tableName] = statInfo.withDelta(delta.pendingRecords, delta.pendingBlocks)
+		} else {
+			sm.tableStats[tableName] = statInfo.withDelta(-delta.reflectedRecords, -delta.reflectedBlocks)
+		}
+	}
+	delete(sm.pendingDeltas, transaction)
+}
+
+// テーブルを読んで計算した統計情報を保持する.
+// 計算したトランザクション自身の変更は既に含まれているので、まだ反映していない増減を反映したものとして扱う.
+// NOTE: 他のトランザクションのコミットしていない変更は、ロックを待つので含まれない.
+func (sm *StatManager) storeCalculatedStats(tableName types.TableName, statInfo *StatInfo, transaction *transaction.Transaction) {
+	sm.tableStats[tableName] = statInfo
+	delete(sm.modifiedCounts, tableName)
+
+	if delta, exists := sm.pendingDeltas[transaction][tableName]; exists {
+		delta.reflect()
 	}
+}
+
+// テーブルを読んで統計情報を計算し直し、カタログに保存する.
+// カタログへの書き込みはトランザクションのログに残るので、ロールバックするとカタログは元に戻るが、メモリの統計情報は計算し直したものが残る.
+// NOTE: カタログの読み書きもロックを待つことがあるので、sm.mu を保持せずに行う.
+func (sm *StatManager) AnalyzeTable(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) *StatInfo {
+	statInfo := sm.recalculate(tableName, layout, transaction)
 
+	sm.deleteCatalogRows(tableName, transaction)
+	sm.writeCatalogRows(tableName, layout, statInfo, transaction)
 	return statInfo
 }
 
-func (sm *StatManager) refreshStatistics(transaction *transaction.Transaction) {
-	sm.tableStats = make(map[types.TableName]*StatInfo)
-	sm.numCalls = 0
+// カタログに保存された統計情報を読み込む.
+// 読み込めなかったテーブルや列は、単にエラーログを出力して読み飛ばす. テーブルの統計情報は、最初に参照された時に計算し直される.
+func (sm *StatManager) loadStatistics(transaction *transaction.Transaction) {
+	columnStatRows := make(map[types.TableName][]ColumnStatCatalogRow)
+	columnStatScan := query.NewTableScan(transaction, COLUMN_STAT_CATALOG_TABLE_NAME, sm.columnStatLayout)
+	for columnStatScan.Next() {
+		row := ReadColumnStatCatalogRow(columnStatScan)
+		columnStatRows[row.TableName] = append(columnStatRows[row.TableName], row)
+	}
+	columnStatScan.Close()
 
-	tableCatalogLayout := sm.tableManager.GetTableCatalogLayout()
-	tableScan := query.NewTableScan(transaction, TABLE_CATALOG_TABLE_NAME, tableCatalogLayout)
-	defer tableScan.Close()
+	histogramRows := make(map[types.TableName]map[types.FieldName][]HistogramCatalogRow)
+	histogramScan := query.NewTableScan(transaction, HISTOGRAM_CATALOG_TABLE_NAME, sm.histogramLayout)
+	for histogramScan.Next() {
+		row := ReadHistogramCatalogRow(histogramScan)
+		if histogramRows[row.TableName] == nil {
+			histogramRows[row.TableName] = make(map[types.FieldName][]HistogramCatalogRow)
+		}
+		histogramRows[row.TableName][row.FieldName] = append(histogramRows[row.TableName][row.FieldName], row)
+	}
+	histogramScan.Close()
 
-	for tableScan.Next() {
-		row := ReadTableCatalogRow(tableScan)
+	tableStatScan := query.NewTableScan(transaction, TABLE_STAT_CATALOG_TABLE_NAME, sm.tableStatLayout)
+	defer tableStatScan.Close()
+
+	for tableStatScan.Next() {
+		row := ReadTableStatCatalogRow(tableStatScan)
 
 		layout, err := sm.tableManager.GetLayout(row.TableName, transaction)
 		if err != nil {
-			// 単にエラーログを出力するだけにする.
-			fmt.Printf("統計情報の更新の際、テーブルのレイアウト取得に失敗しました. tableName=%s, err=%+v", row.TableName, err)
+			fmt.Printf("統計情報の読み込みの際、テーブルのレイアウト取得に失敗しました. tableName=%s, err=%+v", row.TableName, err)
 			continue
 		}
-		statInfo := sm.calcTableStats(row.TableName, layout, transaction)
-		sm.tableStats[row.TableName] = statInfo
+
+		columnStats := make(map[types.FieldName]*ColumnStatInfo)
+		for _, columnStatRow := range columnStatRows[row.TableName] {
+			fieldType, err := layout.GetSchema().FieldType(columnStatRow.FieldName)
+			if err != nil {
+				fmt.Printf("統計情報の読み込みの際、フィールドの型の取得に失敗しました. tableName=%s, fieldName=%s, err=%+v", row.TableName, columnStatRow.FieldName, err)
+				continue
+			}
+
+			buckets := histogramRows[row.TableName][columnStatRow.FieldName]
+			slices.SortFunc(buckets, func(a, b HistogramCatalogRow) int { return int(a.Seq - b.Seq) })
+			columnStatInfo, err := restoreColumnStatInfo(row.NumRecords, fieldType, columnStatRow, buckets)
+			if err != nil {
+				fmt.Printf("統計情報の読み込みの際、列の統計情報の変換に失敗しました. tableName=%s, fieldName=%s, err=%+v", row.TableName, columnStatRow.FieldName, err)
+				continue
+			}
+			columnStats[columnStatRow.FieldName] = columnStatInfo
+		}
+
+		sm.tableStats[row.TableName] = newStatInfoWithColumns(row.NumBlocks, row.NumRecords, columnStats)
 	}
 }
 
+// テーブルを全て読んで、ブロック数とレコード数に加えて、列ごとの統計情報を計算する.
+// 列の値は列ごとに STAT_SAMPLE_SIZE 個までしかメモリに保持しないので、大きなテーブルでもメモリの使用量は一定に収まる.
 func (sm *StatManager) calcTableStats(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) *StatInfo {
 	tableScan := query.NewTableScan(transaction, tableName, layout)
 	defer tableScan.Close()
 
+	fieldNames := layout.GetSchema().Fields()
+	collectors := make(map[types.FieldName]*columnStatCollector, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		collectors[fieldName] = newColumnStatCollector()
+	}
+
 	numBlocks := types.Int(0)
 	numRecords := types.Int(0)
 	for tableScan.Next() {
 		numRecords++
 		numBlocks = types.Int(tableScan.GetCurrentRecordID().GetBlockNumber()) + 1
+
+		for _, fieldName := range fieldNames {
+			// layout のフィールドなので、エラーは発生し得ない.
+			value, _ := tableScan.GetValue(fieldName)
+			collectors[fieldName].add(value)
+		}
+	}
+
+	columnStats := make(map[types.FieldName]*ColumnStatInfo, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		columnStats[fieldName] = collectors[fieldName].build(numRecords)
 	}
 
-	return NewStatInfo(numBlocks, numRecords)
+	return newStatInfoWithColumns(numBlocks, numRecords, columnStats)
+}
+
+// 削除・変更されたテーブルの統計情報を、カタログに保存したものも含めて破棄する.
+func (sm *StatManager) RemoveStatInfo(tableName types.TableName, transaction *transaction.Transaction) {
+	sm.mu.Lock()
+	delete(sm.tableStats, tableName)
+	delete(sm.modifiedCounts, tableName)
+	for _, deltas := range sm.pendingDeltas {
+		delete(deltas, tableName)
+	}
+	sm.generations[tableName]++
+	sm.mu.Unlock()
+
+	sm.deleteCatalogRows(tableName, transaction)
+}
+
+func (sm *StatManager) deleteCatalogRows(tableName types.TableName, transaction *transaction.Transaction) {
+	DeleteCatalogRows(transaction, TABLE_STAT_CATALOG_TABLE_NAME, sm.tableStatLayout, func(tableScan *query.TableScan) bool {
+		return ReadTableStatCatalogRow(tableScan).TableName == tableName
+	})
+	DeleteCatalogRows(transaction, COLUMN_STAT_CATALOG_TABLE_NAME, sm.columnStatLayout, func(tableScan *query.TableScan) bool {
+		return ReadColumnStatCatalogRow(tableScan).TableName == tableName
+	})
+	DeleteCatalogRows(transaction, HISTOGRAM_CATALOG_TABLE_NAME, sm.histogramLayout, func(tableScan *query.TableScan) bool {
+		return ReadHistogramCatalogRow(tableScan).TableName == tableName
+	})
+}
+
+func (sm *StatManager) writeCatalogRows(tableName types.TableName, layout *record.Layout, statInfo *StatInfo, transaction *transaction.Transaction) {
+	insertStatCatalogRow(transaction, TABLE_STAT_CATALOG_TABLE_NAME, sm.tableStatLayout, map[types.FieldName]query.Constant{
+		"table_name":  query.NewStrConstant(string(tableName)),
+		"num_blocks":  query.NewIntConstant(statInfo.GetBlocksAccessed()),
+		"num_records": query.NewIntConstant(statInfo.GetRecordsOutput()),
+	})
+
+	for _, fieldName := range layout.GetSchema().Fields() {
+		columnStatInfo, exists := statInfo.GetColumnStatInfo(fieldName)
+		if !exists {
+			continue
+		}
+
+		columnStatRow, histogramRows := columnStatInfo.toCatalogRows(tableName, fieldName)
+		insertStatCatalogRow(transaction, COLUMN_STAT_CATALOG_TABLE_NAME, sm.columnStatLayout, map[types.FieldName]query.Constant{
+			"table_name":      query.NewStrConstant(string(columnStatRow.TableName)),
+			"field_name":      query.NewStrConstant(string(columnStatRow.FieldName)),
+			"num_nulls":       query.NewIntConstant(columnStatRow.NumNulls),
+			"distinct_values": query.NewIntConstant(columnStatRow.DistinctValues),
+			"min_value":       query.NewStrConstant(columnStatRow.MinValue),
+			"max_value":       query.NewStrConstant(columnStatRow.MaxValue),
+		})
+		for _, histogramRow := range histogramRows {
+			insertStatCatalogRow(transaction, HISTOGRAM_CATALOG_TABLE_NAME, sm.histogramLayout, map[types.FieldName]query.Constant{
+				"table_name":      query.NewStrConstant(string(histogramRow.TableName)),
+				"field_name":      query.NewStrConstant(string(histogramRow.FieldName)),
+				"seq":             query.NewIntConstant(histogramRow.Seq),
+				"upper_bound":     query.NewStrConstant(histogramRow.UpperBound),
+				"num_records":     query.NewIntConstant(histogramRow.NumRecords),
+				"distinct_values": query.NewIntConstant(histogramRow.DistinctValues),
+			})
+		}
+	}
+}
+
+// 統計情報のカタログテーブルのスキーマは固定であるため、TableScan.SetValue のエラーは起こり得ない.
+// 単に panic させる.
+func insertStatCatalogRow(transaction *transaction.Transaction, catalogTableName types.TableName, layout *record.Layout, values map[types.FieldName]query.Constant) {
+	tableScan := query.NewTableScan(transaction, catalogTableName, layout)
+	defer tableScan.Close()
+
+	tableScan.Insert()
+	for fieldName, value := range values {
+		if err := tableScan.SetValue(fieldName, value); err != nil {
+			panic(fmt.Sprintf("[insertStatCatalogRow] %s テーブルの %s に値をセットできませんでした. value=%s, error=%+v", catalogTableName, fieldName, value.ToString(), err))
+		}
+	}
+}
+
+type pendingStatDeltas map[*transaction.Transaction]map[types.TableName]*statDelta
+
+// トランザクションが増減させたレコード数とブロック数.
+// pending はまだ保持している統計情報に反映していない増減で、reflected はトランザクションの中でテーブルを読んで計算し直したことで反映済みの増減.
+type statDelta struct {
+	pendingRecords   types.Int
+	pendingBlocks    types.Int
+	reflectedRecords types.Int
+	reflectedBlocks  types.Int
+}
+
+func (d *statDelta) hasPending() bool {
+	return d.pendingRecords != 0 || d.pendingBlocks != 0
+}
+
+func (d *statDelta) reflect() {
+	d.reflectedRecords += d.pendingRecords
+	d.reflectedBlocks += d.pendingBlocks
+	d.pendingRecords = 0
+	d.pendingBlocks = 0
 }
@@ -4,14 +4,16 @@ import (
 	"fmt"
 	"simple-db-go/query"
 	"simple-db-go/record"
+	"simple-db-go/transaction"
 	"simple-db-go/types"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestStatManagerNewStatManager(t *testing.T) {
-	t.Run("StatManager 初期化時に統計情報の初期値が計算される.", func(t *testing.T) {
+	t.Run("StatManager 初期化時にはテーブルを読まず、統計情報は参照された時に計算される.", func(t *testing.T) {
 		// transaction := test_util.StartNewTransaction(statManagerTestName)
 		transaction := newTransactionForTest(t, statManagerTestName)
 		defer transaction.Rollback()
@@ -20,102 +22,77 @@ func TestStatManagerNewStatManager(t *testing.T) {
 		NewViewManager(true, tableManager, transaction)
 
 		// 別のテーブルも1つ追加し、いくつかレコードを追加しておく.
-		// これで、3つのカタログテーブル＋1つのテーブルが存在することになる.
 		testTableName := types.TableName("test_statmanager")
-		testTableSchema := record.NewSchema()
-		testTableSchema.AddIntField("A")
-		testTableSchema.AddStringField("B", 7)
-		tableManager.CreateTable(testTableName, testTableSchema, transaction)
-		testTableLayout, _ := tableManager.GetLayout(testTableName, transaction)
-		testTableScan := query.NewTableScan(transaction, testTableName, testTableLayout)
-		defer testTableScan.Close()
-		for i := types.Int(0); i < 777; i++ {
-			testTableScan.Insert()
-			testTableScan.SetInt("A", i)
-			testTableScan.SetString("B", fmt.Sprintf("test%d", i))
-		}
+		insertStatManagerTestRecords(t, tableManager, testTableName, 777, transaction)
 
-		statManager := NewStatManager(tableManager, transaction)
+		statManager := NewStatManager(true, tableManager, transaction)
 
+		// これで、3つのカタログテーブル＋1つのテーブル＋3つの統計情報のカタログテーブルが存在することになる.
 		t.Run("StatManager のフィールドの大きさや値が適切に初期化されている.", func(t *testing.T) {
-			assert.Len(t, statManager.tableStats, 4, "テーブルカタログテーブル＋フィールドカタログテーブル＋ビューカタログテーブル＋テストテーブルが存在するはず.")
-			assert.Equal(t, types.Int(0), statManager.numCalls, "初期状態では numCalls は 0 であるはず.")
+			assert.Len(t, statManager.tableStats, 0, "カタログに保存された統計情報が無いので、初期状態では統計情報は無いはず.")
+			assert.Len(t, statManager.modifiedCounts, 0, "初期状態ではレコードの変更数は無いはず.")
 		})
 
-		// GetStatInfo メソッドではなく、直接見てみる。
-
 		t.Run("テーブルカタログテーブルの統計情報が正しく計算されている.", func(t *testing.T) {
-			actualStatInfo, exists := statManager.tableStats[TABLE_CATALOG_TABLE_NAME]
-			expectedStatInfo := &StatInfo{
-				// blocksize 512, slot_size 28 なので、４レコードは1つのブロックに収まる.
-				numBlocks: 1,
-				// catalog テーブル3つ＋テストテーブル1つ
-				numRecords: 4,
-			}
-			assert.True(t, exists, "テーブルカタログテーブルの統計情報が存在するはず.")
-			assert.Equal(t, expectedStatInfo, actualStatInfo, "テーブルカタログテーブルの統計情報が正しいはず.")
-
 			layout, _ := tableManager.GetLayout(TABLE_CATALOG_TABLE_NAME, transaction)
 			statInfo := statManager.GetStatInfo(TABLE_CATALOG_TABLE_NAME, layout, transaction)
-			assert.Equal(t, expectedStatInfo, statInfo, "GetStatInfo でも同じ統計情報が取得できるはず.")
-			assert.Equal(t, types.Int(2), statInfo.GetDistinctValues("table_name"), "table_name は 2 つの値を取るはず.(雑な推測値を返すだけなので、厳密な値ではない)")
+			expectedStatInfo := &StatInfo{
+				// blocksize 512, slot_size 80 なので、1ブロックに6レコードずつ、7レコードを収めるには2つブロックが必要.
+				numBlocks: 2,
+				// catalog テーブル3つ＋テストテーブル1つ＋統計情報の catalog テーブル3つ
+				numRecords: 7,
+			}
+			assert.Equal(t, expectedStatInfo, withoutColumnStats(statInfo), "テーブルカタログテーブルの統計情報が正しいはず.")
+			assert.Same(t, statInfo, statManager.tableStats[TABLE_CATALOG_TABLE_NAME], "計算した統計情報は保持されるはず.")
+			assert.Equal(t, types.Int(7), statInfo.GetDistinctValues("table_name"), "table_name は 7 つの値を取るはず.")
 		})
 
 		t.Run("フィールドカタログテーブルの統計情報が正しく計算されている.", func(t *testing.T) {
-			actualStatInfo, exists := statManager.tableStats[FIELD_CATALOG_TABLE_NAME]
+			layout, _ := tableManager.GetLayout(FIELD_CATALOG_TABLE_NAME, transaction)
+			statInfo := statManager.GetStatInfo(FIELD_CATALOG_TABLE_NAME, layout, transaction)
 			expectedStatInfo := &StatInfo{
-				// block size 512, slot_size 56 なので、11レコードを収めるには2つブロックが必要.
-				numBlocks: 2,
+				// block size 512, slot_size 156 なので、1ブロックに3レコードずつ、28レコードを収めるには10個のブロックが必要.
+				numBlocks: 10,
 				// 各テーブルのフィールド数：
-				// - table_catalog: 2
+				// - table_catalog: 3
 				// - field_catalog: 5
-				// - view_catalog: 2
+				// - view_catalog: 3
 				// - test_statmanager: 2
+				// - tblstat_catalog: 3
+				// - colstat_catalog: 6
+				// - histogram_catalog: 6
 				// 以上の合計値になるはず.
-				numRecords: 11,
+				numRecords: 28,
 			}
-			assert.True(t, exists, "フィールドカタログテーブルの統計情報が存在するはず.")
-			assert.Equal(t, expectedStatInfo, actualStatInfo, "フィールドカタログテーブルの統計情報が正しいはず.")
-
-			layout, _ := tableManager.GetLayout(FIELD_CATALOG_TABLE_NAME, transaction)
-			statInfo := statManager.GetStatInfo(FIELD_CATALOG_TABLE_NAME, layout, transaction)
-			assert.Equal(t, expectedStatInfo, statInfo, "GetStatInfo でも同じ統計情報が取得できるはず.")
-			assert.Equal(t, types.Int(4), statInfo.GetDistinctValues("table_name"), "table_name は 3 つの値を取るはず.(雑な推測値を返すだけなので、厳密な値ではない)")
+			assert.Equal(t, expectedStatInfo, withoutColumnStats(statInfo), "フィールドカタログテーブルの統計情報が正しいはず.")
+			assert.Equal(t, types.Int(7), statInfo.GetDistinctValues("table_name"), "table_name は 7 つの値を取るはず.")
 		})
 
 		t.Run("ビューカタログテーブルの統計情報が正しく計算されている.", func(t *testing.T) {
-			actualStatInfo, exists := statManager.tableStats[VIEW_CATALOG_TABLE_NAME]
+			layout, _ := tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
+			statInfo := statManager.GetStatInfo(VIEW_CATALOG_TABLE_NAME, layout, transaction)
 			expectedStatInfo := &StatInfo{
 				// レコードが1つもないはずなので.
 				numBlocks:  0,
 				numRecords: 0,
 			}
-			assert.True(t, exists, "ビューカタログテーブルの統計情報が存在するはず.")
-			assert.Equal(t, expectedStatInfo, actualStatInfo, "ビューカタログテーブルの統計情報が正しいはず.")
-
-			layout, _ := tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
-			statInfo := statManager.GetStatInfo(VIEW_CATALOG_TABLE_NAME, layout, transaction)
-			assert.Equal(t, expectedStatInfo, statInfo, "GetStatInfo でも同じ統計情報が取得できるはず.")
-			assert.Equal(t, types.Int(1), statInfo.GetDistinctValues("table_name"), "table_name は 1 つの値を取るはず.(雑な推測値を返すだけなので、厳密な値ではない)")
+			assert.Equal(t, expectedStatInfo, withoutColumnStats(statInfo), "ビューカタログテーブルの統計情報が正しいはず.")
+			assert.Equal(t, types.Int(1), statInfo.GetDistinctValues("view_name"), "レコードが無い場合も、異なる値の数は 1 とするはず.")
 		})
 
 		t.Run("テスト用のテーブルの統計情報が正しく計算されている.", func(t *testing.T) {
-			actualStatInfo, exists := statManager.tableStats[testTableName]
+			layout, _ := tableManager.GetLayout(testTableName, transaction)
+			statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
 			expectedStatInfo := &StatInfo{
-				// block_size 512, slot_size 19 (= flag: 4 + field A: 4 + field B: (4+7))
-				// 1つのブロックに入るスロット数 = floor( 512 / 19 ) = 26
-				// よって777レコード収めるのに必要なブロック数 = ceil( 777 / 26 ) = 30
-				numBlocks: 30,
+				// block_size 512, slot_size 23 (= flag: 4 + field A: 4 + field B: (4+7) + null bitmap: 4)
+				// 1つのブロックに入るスロット数 = floor( 512 / 23 ) = 22
+				// よって777レコード収めるのに必要なブロック数 = ceil( 777 / 22 ) = 36
+				numBlocks: 36,
 				// 777 個レコードをINSERTしたはずなので.
 				numRecords: 777,
 			}
-			assert.True(t, exists, "テスト用のテーブルの統計情報が存在するはず.")
-			assert.Equal(t, expectedStatInfo, actualStatInfo, "テスト用のテーブルの統計情報が正しいはず.")
-
-			layout, _ := tableManager.GetLayout(testTableName, transaction)
-			statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
-			assert.Equal(t, expectedStatInfo, statInfo, "GetStatInfo でも同じ統計情報が取得できるはず.")
-			assert.Equal(t, types.Int(260), statInfo.GetDistinctValues("A"), "A は 2 つの値を取るはず.(雑な推測値を返すだけなので、厳密な値ではない)")
+			assert.Equal(t, expectedStatInfo, withoutColumnStats(statInfo), "テスト用のテーブルの統計情報が正しいはず.")
+			assert.Equal(t, types.Int(777), statInfo.GetDistinctValues("A"), "A は 777 個の値を取るはず.")
 		})
 	})
 }
@@ -125,30 +102,248 @@ func TestStatManagerGetStatInfo(t *testing.T) {
 		t.Skip("NewStatManager のテストで実行しているのでよしとする.(こちらに移すべきかも？)")
 	})
 
-	t.Run("GetStatInfo が100回より多く参照されたときに統計情報が計算され直すこと.", func(t *testing.T) {
+	t.Run("統計情報を計算してから多くのレコードを変更したときに統計情報が計算され直すこと.", func(t *testing.T) {
 		// transaction := test_util.StartNewTransaction(statManagerTestName)
 		transaction := newTransactionForTest(t, statManagerTestName)
 		defer transaction.Rollback()
 
 		tableManager := NewTableManager(true, transaction)
-		statManager := NewStatManager(tableManager, transaction)
+		statManager := NewStatManager(true, tableManager, transaction)
 
-		// table_catalog テーブルの統計情報が更新されるかだけ確認する.細かい値までは確認しない.
-		layout, _ := tableManager.GetLayout(TABLE_CATALOG_TABLE_NAME, transaction)
-		initTableCatalogStatInfo := statManager.GetStatInfo(TABLE_CATALOG_TABLE_NAME, layout, transaction)
+		// 777 レコードの 10% は 100 より少ないので、100 レコードより多く変更すると計算し直すはず.
+		testTableName := types.TableName("test_statmanager")
+		insertStatManagerTestRecords(t, tableManager, testTableName, 777, transaction)
+		layout, _ := tableManager.GetLayout(testTableName, transaction)
+		initStatInfo := statManager.GetStatInfo(testTableName, layout, transaction)
 
-		t.Run("1~100回までは統計情報は更新されない.", func(t *testing.T) {
-			// 注意：すぐ上で GetStatInfo を1度呼んでいることに注意.
-			for i := 2; i <= 100; i++ {
-				statInfo := statManager.GetStatInfo(TABLE_CATALOG_TABLE_NAME, layout, transaction)
-				assert.Samef(t, initTableCatalogStatInfo, statInfo, "100回以下の呼び出しでは統計情報は更新されないはず.(count=%d)", i)
-			}
+		t.Run("100 レコードまでの変更では統計情報は更新されない.", func(t *testing.T) {
+			statManager.AddModifiedCount(testTableName, 60)
+			statManager.AddModifiedCount(testTableName, 40)
+			statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
+			assert.Same(t, initStatInfo, statInfo, "100 レコード以下の変更では統計情報は更新されないはず.")
 		})
 
-		t.Run("101回目に呼ばれた後は統計情報が更新されている.", func(t *testing.T) {
-			statInfo := statManager.GetStatInfo(TABLE_CATALOG_TABLE_NAME, layout, transaction)
-			assert.NotSame(t, initTableCatalogStatInfo, statInfo, "101回目の呼び出し後は統計情報が更新されている.")
-			assert.Equal(t, initTableCatalogStatInfo, statInfo, "特にレコード操作はしていないので、統計値自体は変わらない.")
+		t.Run("100 レコードより多く変更した後は統計情報が更新されている.", func(t *testing.T) {
+			statManager.AddModifiedCount(testTableName, 1)
+			statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
+			assert.NotSame(t, initStatInfo, statInfo, "100 レコードより多く変更した後は統計情報が更新されているはず.")
+			assert.Equal(t, initStatInfo, statInfo, "特にレコード操作はしていないので、統計値自体は変わらない.")
+			assert.Len(t, statManager.modifiedCounts, 0, "計算し直したテーブルの変更数はリセットされるはず.")
+		})
+
+		t.Run("他のテーブルの変更数は影響しない.", func(t *testing.T) {
+			statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
+			statManager.AddModifiedCount("other_table", 1000)
+			assert.Same(t, statInfo, statManager.GetStatInfo(testTableName, layout, transaction), "他のテーブルの変更では統計情報は更新されないはず.")
 		})
 	})
 }
+
+func TestStatManagerAnalyzeTable(t *testing.T) {
+	t.Run("ANALYZE TABLE で計算した統計情報がカタログに保存され、起動時に読み込まれること.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, statManagerTestName)
+		defer transaction.Rollback()
+
+		tableManager := NewTableManager(true, transaction)
+		statManager := NewStatManager(true, tableManager, transaction)
+
+		testTableName := types.TableName("test_statmanager")
+		insertStatManagerTestRecords(t, tableManager, testTableName, 100, transaction)
+		layout, _ := tableManager.GetLayout(testTableName, transaction)
+		statManager.AddModifiedCount(testTableName, 100)
+		analyzedStatInfo := statManager.AnalyzeTable(testTableName, layout, transaction)
+
+		assert.Same(t, analyzedStatInfo, statManager.GetStatInfo(testTableName, layout, transaction), "計算し直した統計情報を保持するはず.")
+		assert.Len(t, statManager.modifiedCounts, 0, "計算し直したテーブルの変更数はリセットされるはず.")
+
+		t.Run("再起動した StatManager がカタログから同じ統計情報を読み込む.", func(t *testing.T) {
+			restartedStatManager := NewStatManager(false, tableManager, transaction)
+			assert.Len(t, restartedStatManager.tableStats, 1, "ANALYZE したテーブルの統計情報だけが読み込まれるはず.")
+			assert.Equal(t, analyzedStatInfo, restartedStatManager.tableStats[testTableName], "列の統計情報も含めて同じ統計情報が読み込まれるはず.")
+
+			columnStatInfo, exists := restartedStatManager.tableStats[testTableName].GetColumnStatInfo("A")
+			assert.True(t, exists)
+			assert.Equal(t, query.NewIntConstant(0), columnStatInfo.GetMin(), "整数の列の値は整数として読み込まれるはず.")
+			assert.Equal(t, query.NewIntConstant(99), columnStatInfo.GetMax())
+		})
+
+		t.Run("もう一度 ANALYZE すると、カタログの統計情報が置き換わる.", func(t *testing.T) {
+			statManager.AnalyzeTable(testTableName, layout, transaction)
+			restartedStatManager := NewStatManager(false, tableManager, transaction)
+			assert.Equal(t, analyzedStatInfo, restartedStatManager.tableStats[testTableName], "古い統計情報の行は残っていないはず.")
+		})
+
+		t.Run("統計情報を破棄すると、カタログからも削除される.", func(t *testing.T) {
+			statManager.RemoveStatInfo(testTableName, transaction)
+			assert.Len(t, statManager.tableStats, 0)
+
+			restartedStatManager := NewStatManager(false, tableManager, transaction)
+			assert.Len(t, restartedStatManager.tableStats, 0, "カタログに統計情報は残っていないはず.")
+		})
+	})
+}
+
+func TestStatManagerAddStatDelta(t *testing.T) {
+	// トランザクションをコミットするので、他のテストとは別のデータベースを使う.
+	transaction := newTransactionForTest(t, statDeltaTestName)
+	tableManager := NewTableManager(true, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
+
+	testTableName := types.TableName("test_stat_delta")
+	insertStatManagerTestRecords(t, tableManager, testTableName, 100, transaction)
+	layout, _ := tableManager.GetLayout(testTableName, transaction)
+	initStatInfo := statManager.GetStatInfo(testTableName, layout, transaction)
+	transaction.Commit()
+
+	t.Run("コミットしていない増減はそのトランザクションにだけ反映され、コミットすると全てのトランザクションに反映される.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, statDeltaTestName)
+		otherTransaction := newTransactionForTest(t, statDeltaTestName)
+		defer otherTransaction.Commit()
+
+		statManager.AddStatDelta(testTableName, 10, 1, transaction)
+
+		statInfo := statManager.GetStatInfo(testTableName, layout, transaction)
+		assert.Equal(t, types.Int(110), statInfo.GetRecordsOutput(), "自分の増減は反映されるはず.")
+		assert.Equal(t, initStatInfo.GetBlocksAccessed()+1, statInfo.GetBlocksAccessed(), "自分の増減は反映されるはず.")
+		assert.Equal(t, types.Int(100), statManager.GetStatInfo(testTableName, layout, otherTransaction).GetRecordsOutput(), "他のトランザクションの増減は反映されないはず.")
+
+		transaction.Commit()
+		assert.Equal(t, types.Int(110), statManager.GetStatInfo(testTableName, layout, otherTransaction).GetRecordsOutput(), "コミットした増減は反映されるはず.")
+		assert.Len(t, statManager.pendingDeltas, 0, "コミットしたトランザクションの増減は残っていないはず.")
+	})
+
+	t.Run("ロールバックした増減は捨てられる.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, statDeltaTestName)
+		statManager.AddStatDelta(testTableName, -5, 0, transaction)
+		assert.Equal(t, types.Int(105), statManager.GetStatInfo(testTableName, layout, transaction).GetRecordsOutput())
+		transaction.Rollback()
+
+		otherTransaction := newTransactionForTest(t, statDeltaTestName)
+		defer otherTransaction.Commit()
+		assert.Equal(t, types.Int(110), statManager.GetStatInfo(testTableName, layout, otherTransaction).GetRecordsOutput(), "ロールバックした増減は反映されないはず.")
+		assert.Len(t, statManager.pendingDeltas, 0, "ロールバックしたトランザクションの増減は残っていないはず.")
+	})
+
+	t.Run("トランザクションの中で統計情報を計算し直した後にロールバックすると、計算に含まれていた増減を取り消す.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, statDeltaTestName)
+		tableScan := query.NewTableScan(transaction, testTableName, layout)
+		for i := 0; i < 20; i++ {
+			tableScan.Insert()
+			tableScan.SetInt("A", types.Int(1000+i))
+		}
+		tableScan.Close()
+		statManager.AddStatDelta(testTableName, tableScan.GetRecordsDelta(), tableScan.GetAppendedBlocks(), transaction)
+
+		// 実際のレコードは、コミット済みの 100 レコードと挿入した 20 レコード.
+		statInfo := statManager.AnalyzeTable(testTableName, layout, transaction)
+		assert.Equal(t, types.Int(120), statInfo.GetRecordsOutput())
+		assert.Same(t, statInfo, statManager.GetStatInfo(testTableName, layout, transaction), "計算し直した統計情報に増減が含まれているので、重ねて反映しないはず.")
+		transaction.Rollback()
+
+		otherTransaction := newTransactionForTest(t, statDeltaTestName)
+		defer otherTransaction.Commit()
+		assert.Equal(t, types.Int(100), statManager.GetStatInfo(testTableName, layout, otherTransaction).GetRecordsOutput(), "ロールバックで挿入したレコード数を取り消すはず.")
+	})
+}
+
+func TestStatManagerRecalculateWithoutBlocking(t *testing.T) {
+	// トランザクションをコミットするので、他のテストとは別のデータベースを使う.
+	transaction := newTransactionForTest(t, statRecalculateTestName)
+	tableManager := NewTableManager(true, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
+
+	lockedTableName := types.TableName("test_stat_locked")
+	otherTableName := types.TableName("test_stat_other")
+	insertStatManagerTestRecords(t, tableManager, lockedTableName, 10, transaction)
+	insertStatManagerTestRecords(t, tableManager, otherTableName, 10, transaction)
+	lockedLayout, _ := tableManager.GetLayout(lockedTableName, transaction)
+	otherLayout, _ := tableManager.GetLayout(otherTableName, transaction)
+	otherStatInfo := statManager.GetStatInfo(otherTableName, otherLayout, transaction)
+	transaction.Commit()
+
+	t.Run("テーブルを読んで計算し直している間も、他のテーブルの統計情報を取得できる.", func(t *testing.T) {
+		// 挿入したトランザクションがコミットするまで、lockedTableName を読むトランザクションはロックを待つ.
+		writingTransaction := newTransactionForTest(t, statRecalculateTestName)
+		tableScan := query.NewTableScan(writingTransaction, lockedTableName, lockedLayout)
+		tableScan.Insert()
+		tableScan.SetInt("A", 10)
+		tableScan.Close()
+		statManager.AddStatDelta(lockedTableName, tableScan.GetRecordsDelta(), tableScan.GetAppendedBlocks(), writingTransaction)
+
+		readingTransaction := newTransactionForTest(t, statRecalculateTestName)
+		defer readingTransaction.Commit()
+		recalculated := make(chan *StatInfo)
+		go func() {
+			recalculated <- statManager.GetStatInfo(lockedTableName, lockedLayout, readingTransaction)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		planningTransaction := newTransactionForTest(t, statRecalculateTestName)
+		defer planningTransaction.Commit()
+		assert.Same(t, otherStatInfo, statManager.GetStatInfo(otherTableName, otherLayout, planningTransaction), "計算し直しているテーブルを待たずに取得できるはず.")
+
+		writingTransaction.Commit()
+		assert.Equal(t, types.Int(11), (<-recalculated).GetRecordsOutput(), "コミットを待ってから読んだレコードが含まれるはず.")
+	})
+}
+
+func TestStatManagerCommitDuringRecalculate(t *testing.T) {
+	// トランザクションをコミットするので、他のテストとは別のデータベースを使う.
+	transaction := newTransactionForTest(t, statGenerationTestName)
+	tableManager := NewTableManager(true, transaction)
+	statManager := NewStatManager(true, tableManager, transaction)
+
+	testTableName := types.TableName("test_stat_generation")
+	insertStatManagerTestRecords(t, tableManager, testTableName, 10, transaction)
+	layout, _ := tableManager.GetLayout(testTableName, transaction)
+	statManager.GetStatInfo(testTableName, layout, transaction)
+	transaction.Commit()
+
+	t.Run("テーブルを読んでいる間に増減がコミットされた場合、計算した統計情報は保持せず、増減を重ねて反映しない.", func(t *testing.T) {
+		statManager.AddModifiedCount(testTableName, 1000)
+
+		writingTransaction := newTransactionForTest(t, statGenerationTestName)
+		tableScan := query.NewTableScan(writingTransaction, testTableName, layout)
+		tableScan.Insert()
+		tableScan.SetInt("A", 10)
+		tableScan.Close()
+		statManager.AddStatDelta(testTableName, tableScan.GetRecordsDelta(), tableScan.GetAppendedBlocks(), writingTransaction)
+
+		readingTransaction := newTransactionForTest(t, statGenerationTestName)
+		defer readingTransaction.Commit()
+		recalculated := make(chan *StatInfo)
+		go func() {
+			recalculated <- statManager.GetStatInfo(testTableName, layout, readingTransaction)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		writingTransaction.Commit()
+		statInfo := <-recalculated
+		assert.Equal(t, types.Int(11), statInfo.GetRecordsOutput(), "コミットを待ってから読んだレコードが含まれるはず.")
+		assert.NotSame(t, statInfo, statManager.tableStats[testTableName], "読んでいる間に増減がコミットされたので、計算した統計情報は保持しないはず.")
+		assert.Equal(t, types.Int(11), statManager.tableStats[testTableName].GetRecordsOutput(), "コミットした増減は1回だけ反映されるはず.")
+	})
+}
+
+// A には 0 から順に整数を、B には "test" に続けて同じ整数を入れたレコードを挿入する.
+func insertStatManagerTestRecords(t *testing.T, tableManager *TableManager, tableName types.TableName, numRecords types.Int, transaction *transaction.Transaction) {
+	t.Helper()
+
+	schema := record.NewSchema()
+	schema.AddIntField("A")
+	schema.AddStringField("B", 7)
+	tableManager.CreateTable(tableName, schema, transaction)
+	layout, _ := tableManager.GetLayout(tableName, transaction)
+	tableScan := query.NewTableScan(transaction, tableName, layout)
+	defer tableScan.Close()
+	for i := types.Int(0); i < numRecords; i++ {
+		tableScan.Insert()
+		tableScan.SetInt("A", i)
+		tableScan.SetString("B", fmt.Sprintf("test%d", i))
+	}
+}
+
+// ブロック数とレコード数だけを比較するために、列の統計情報を取り除く.
+func withoutColumnStats(statInfo *StatInfo) *StatInfo {
+	return NewStatInfo(statInfo.numBlocks, statInfo.numRecords)
+}
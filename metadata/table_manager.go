@@ -3,6 +3,7 @@ package metadata
 import (
 	"fmt"
 	"simple-db-go/constants"
+	"simple-db-go/query"
 	"simple-db-go/record"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
@@ -13,6 +14,8 @@ type TableManager struct {
 	tableCatalogLayout *record.Layout
 	// フィールドのカタログ(metadata)の物理情報
 	fieldCatalogLayout *record.Layout
+	// テーブルのレイアウトとビューの定義のキャッシュ. ViewManager と共有する.
+	cache *catalogCache
 }
 
 // NOTE: システム起動中に一度だけ呼ばれる.
@@ -20,6 +23,7 @@ func NewTableManager(isNew bool, transaction *transaction.Transaction) *TableMan
 	tableCatalogSchema := record.NewSchema()
 	tableCatalogSchema.AddStringField("table_name", constants.MAX_NAME_LENGTH)
 	tableCatalogSchema.AddIntField("slot_size")
+	tableCatalogSchema.AddIntField("format")
 	tableCatalogLayout := record.NewLayout(tableCatalogSchema)
 
 	fieldCatalogSchema := record.NewSchema()
@@ -33,6 +37,7 @@ func NewTableManager(isNew bool, transaction *transaction.Transaction) *TableMan
 	tableManager := &TableManager{
 		tableCatalogLayout: tableCatalogLayout,
 		fieldCatalogLayout: fieldCatalogLayout,
+		cache:              newCatalogCache(),
 	}
 
 	if isNew {
@@ -45,10 +50,23 @@ func NewTableManager(isNew bool, transaction *transaction.Transaction) *TableMan
 
 // schema, layout の情報をもとに、カタログレコードを登録する.
 // TableScan を利用してカタログレコードを登録する.
-func (tm *TableManager) CreateTable(tableName types.TableName, schema *record.Schema, transaction *transaction.Transaction) {
-	layout := record.NewLayout(schema)
+// 同じ名前のテーブルが既に存在する場合は、何も登録せずにエラーを返す.
+func (tm *TableManager) CreateTable(tableName types.TableName, schema *record.Schema, transaction *transaction.Transaction) error {
+	if err := validateTableNames(tableName, schema); err != nil {
+		return err
+	}
+	if _, err := ReadTableCatalogRowFor(tableName, transaction, tm); err == nil {
+		return TableAlreadyExistsError{TableName: tableName}
+	}
+
+	tm.writeCatalogRows(tableName, record.NewLayout(schema), transaction)
+	return nil
+}
 
-	WriteTableCatalogRow(transaction, tm, TableCatalogRow{TableName: tableName, SlotSize: layout.GetSlotSize()})
+func (tm *TableManager) writeCatalogRows(tableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) {
+	schema := layout.GetSchema()
+
+	WriteTableCatalogRow(transaction, tm, TableCatalogRow{TableName: tableName, SlotSize: layout.GetSlotSize(), Format: layout.GetFormat()})
 
 	rows := []FieldCatalogRow{}
 	for _, fieldName := range schema.Fields() {
@@ -73,8 +91,14 @@ func (tm *TableManager) CreateTable(tableName types.TableName, schema *record.Sc
 	WriteFieldCatalogRows(transaction, tm, rows)
 }
 
+// レイアウトはキャッシュから取得し、キャッシュに無い場合だけカタログテーブルを読む.
 func (tm *TableManager) GetLayout(tableName types.TableName, transaction *transaction.Transaction) (*record.Layout, error) {
+	return tm.cache.getLayout(tableName, transaction, func() (*record.Layout, error) {
+		return tm.readLayout(tableName, transaction)
+	})
+}
 
+func (tm *TableManager) readLayout(tableName types.TableName, transaction *transaction.Transaction) (*record.Layout, error) {
 	tableCatalogRow, err := ReadTableCatalogRowFor(tableName, transaction, tm)
 	if err != nil {
 		return nil, err
@@ -93,7 +117,19 @@ func (tm *TableManager) GetLayout(tableName types.TableName, transaction *transa
 		schema.AddField(row.FieldName, row.Type, row.Length)
 	}
 
-	return record.NewLayoutWith(schema, offsets, tableCatalogRow.SlotSize), nil
+	return record.NewLayoutWith(schema, offsets, tableCatalogRow.SlotSize, tableCatalogRow.Format), nil
+}
+
+// カタログテーブルも含めて、全てのテーブル名をテーブルカタログの順に返す.
+func (tm *TableManager) GetTableNames(transaction *transaction.Transaction) []types.TableName {
+	tableScan := query.NewTableScan(transaction, TABLE_CATALOG_TABLE_NAME, tm.tableCatalogLayout)
+	defer tableScan.Close()
+
+	tableNames := []types.TableName{}
+	for tableScan.Next() {
+		tableNames = append(tableNames, ReadTableCatalogRow(tableScan).TableName)
+	}
+	return tableNames
 }
 
 func (tm *TableManager) GetTableCatalogLayout() *record.Layout {
@@ -103,3 +139,48 @@ func (tm *TableManager) GetTableCatalogLayout() *record.Layout {
 func (tm *TableManager) GetFieldCatalogLayout() *record.Layout {
 	return tm.fieldCatalogLayout
 }
+
+// テーブルのカタログレコードを削除し、コミットした時にテーブルのファイルを削除するように登録する.
+func (tm *TableManager) DropTable(tableName types.TableName, transaction *transaction.Transaction) error {
+	if _, err := ReadTableCatalogRowFor(tableName, transaction, tm); err != nil {
+		return err
+	}
+
+	tm.deleteCatalogRows(tableName, transaction)
+	transaction.RemoveFileOnCommit(query.TableFileName(tableName))
+	return nil
+}
+
+// テーブルのカタログレコードを、newTableName と layout で置き換える.
+// テーブル名が変わる場合は、元のテーブルのファイルをコミットした時に削除するように登録する. レコードを新しいファイルに書き直すのは呼び出し側の役割.
+func (tm *TableManager) AlterTable(tableName types.TableName, newTableName types.TableName, layout *record.Layout, transaction *transaction.Transaction) error {
+	if _, err := ReadTableCatalogRowFor(tableName, transaction, tm); err != nil {
+		return err
+	}
+	if err := validateTableNames(newTableName, layout.GetSchema()); err != nil {
+		return err
+	}
+	if newTableName != tableName {
+		if _, err := ReadTableCatalogRowFor(newTableName, transaction, tm); err == nil {
+			return TableAlreadyExistsError{TableName: newTableName}
+		}
+	}
+
+	tm.deleteCatalogRows(tableName, transaction)
+	tm.writeCatalogRows(newTableName, layout, transaction)
+
+	if newTableName != tableName {
+		transaction.RemoveFileOnCommit(query.TableFileName(tableName))
+	}
+	return nil
+}
+
+func (tm *TableManager) deleteCatalogRows(tableName types.TableName, transaction *transaction.Transaction) {
+	tm.cache.markModified(transaction)
+	DeleteCatalogRows(transaction, TABLE_CATALOG_TABLE_NAME, tm.tableCatalogLayout, func(tableScan *query.TableScan) bool {
+		return ReadTableCatalogRow(tableScan).TableName == tableName
+	})
+	DeleteCatalogRows(transaction, FIELD_CATALOG_TABLE_NAME, tm.fieldCatalogLayout, func(tableScan *query.TableScan) bool {
+		return ReadFieldCatalogRow(tableScan).TableName == tableName
+	})
+}
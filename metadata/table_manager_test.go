@@ -6,6 +6,7 @@ import (
 	"simple-db-go/query"
 	"simple-db-go/record"
 	"simple-db-go/types"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,13 +30,14 @@ func TestTableManagerInitialization(t *testing.T) {
 
 		assert.True(t, tableCatalogTableScan.HasField("table_name"), "table_name フィールドが存在しているはず.")
 		assert.True(t, tableCatalogTableScan.HasField("slot_size"), "slot_size フィールドが存在しているはず.")
+		assert.True(t, tableCatalogTableScan.HasField("format"), "format フィールドが存在しているはず.")
 
 		// table_catalog には２行登録されているはず。
 		tests := []TableCatalogRow{
-			// table_name: 4+16, slot_size: 4, flag: 4
-			{TABLE_CATALOG_TABLE_NAME, 28},
-			// table_name: 4+16, field_name: 4+16, type: 4, length: 4, offset: 4, flag: 4
-			{FIELD_CATALOG_TABLE_NAME, 56},
+			// table_name: 4+64, slot_size: 4, format: 4, flag: 4, null bitmap: 4
+			{TABLE_CATALOG_TABLE_NAME, 84, constants.CURRENT_RECORD_FORMAT},
+			// table_name: 4+64, field_name: 4+64, type: 4, length: 4, offset: 4, flag: 4, null bitmap: 4
+			{FIELD_CATALOG_TABLE_NAME, 156, constants.CURRENT_RECORD_FORMAT},
 		}
 
 		for _, test := range tests {
@@ -61,14 +63,15 @@ func TestTableManagerInitialization(t *testing.T) {
 		tests := []FieldCatalogRow{
 			// 注意：INTEGER フィールドは固定長であり、length は使わないので全て0としている.
 			// table_catalog テーブルのフィールド情報
-			{TABLE_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 16, 4},
-			{TABLE_CATALOG_TABLE_NAME, "slot_size", constants.INTEGER, 0, 24},
+			{TABLE_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 64, 4},
+			{TABLE_CATALOG_TABLE_NAME, "slot_size", constants.INTEGER, 0, 72},
+			{TABLE_CATALOG_TABLE_NAME, "format", constants.INTEGER, 0, 76},
 			// field_catalog テーブルのフィールド情報
-			{FIELD_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 16, 4},
-			{FIELD_CATALOG_TABLE_NAME, "field_name", constants.VARCHAR, 16, 24},
-			{FIELD_CATALOG_TABLE_NAME, "type", constants.INTEGER, 0, 44},
-			{FIELD_CATALOG_TABLE_NAME, "length", constants.INTEGER, 0, 48},
-			{FIELD_CATALOG_TABLE_NAME, "offset", constants.INTEGER, 0, 52},
+			{FIELD_CATALOG_TABLE_NAME, "table_name", constants.VARCHAR, 64, 4},
+			{FIELD_CATALOG_TABLE_NAME, "field_name", constants.VARCHAR, 64, 72},
+			{FIELD_CATALOG_TABLE_NAME, "type", constants.INTEGER, 0, 140},
+			{FIELD_CATALOG_TABLE_NAME, "length", constants.INTEGER, 0, 144},
+			{FIELD_CATALOG_TABLE_NAME, "offset", constants.INTEGER, 0, 148},
 		}
 
 		for _, test := range tests {
@@ -79,7 +82,7 @@ func TestTableManagerInitialization(t *testing.T) {
 			assert.Equalf(t, test, actualRow, "フィールドカタログに期待したレコードが登録されているはず. table_name=%s, field_name=%s\n", test.TableName, test.FieldName)
 		}
 
-		assert.False(t, fieldCatalogTableScan.Next(), "フィールドカタログには7行しか登録されていないはず.")
+		assert.False(t, fieldCatalogTableScan.Next(), "フィールドカタログには8行しか登録されていないはず.")
 	})
 }
 
@@ -129,3 +132,152 @@ func TestTableManagerGetLayout(t *testing.T) {
 		}
 	})
 }
+
+func TestTableManagerDropTable(t *testing.T) {
+	transaction := newTransactionForTest(t, tableManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+
+	testTableName := types.TableName("drop_table")
+	testSchema := record.NewSchema()
+	testSchema.AddIntField("id")
+	testSchema.AddStringField("name", 10)
+	tableManager.CreateTable(testTableName, testSchema, transaction)
+
+	t.Run("テーブルを削除すると、テーブルカタログとフィールドカタログのレコードが無くなる.", func(t *testing.T) {
+		assert.NoError(t, tableManager.DropTable(testTableName, transaction), "存在するテーブルは削除できるべし.")
+
+		_, err := tableManager.GetLayout(testTableName, transaction)
+		assert.IsType(t, TableCatalogNotFoundError{}, err, "削除したテーブルのレイアウトは取得できないべし.")
+
+		fieldCatalogTableScan := query.NewTableScan(transaction, FIELD_CATALOG_TABLE_NAME, tableManager.fieldCatalogLayout)
+		defer fieldCatalogTableScan.Close()
+		for fieldCatalogTableScan.Next() {
+			assert.NotEqual(t, testTableName, ReadFieldCatalogRow(fieldCatalogTableScan).TableName, "削除したテーブルのフィールドカタログは残っていないべし.")
+		}
+
+		assert.True(t, transaction.IsRemovedOnCommit(query.TableFileName(testTableName)), "テーブルのファイルはコミット時に削除されるべし.")
+	})
+
+	t.Run("存在しないテーブルを削除しようとするとエラーになる.", func(t *testing.T) {
+		err := tableManager.DropTable("hoge_table", transaction)
+		assert.IsType(t, TableCatalogNotFoundError{}, err, "存在しないテーブルの削除では TableCatalogNotFoundError を返すべし.")
+	})
+}
+
+func TestTableManagerAlterTable(t *testing.T) {
+	transaction := newTransactionForTest(t, tableManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+
+	testTableName := types.TableName("alter_table")
+	testSchema := record.NewSchema()
+	testSchema.AddIntField("id")
+	tableManager.CreateTable(testTableName, testSchema, transaction)
+
+	newSchema := record.NewSchema()
+	newSchema.AddIntField("id")
+	newSchema.AddStringField("name", 10)
+	newLayout := record.NewLayout(newSchema)
+
+	t.Run("同じテーブル名で変更すると、レイアウトだけが置き換わる.", func(t *testing.T) {
+		assert.NoError(t, tableManager.AlterTable(testTableName, testTableName, newLayout, transaction))
+
+		layout, err := tableManager.GetLayout(testTableName, transaction)
+		if assert.NoError(t, err, "変更したテーブルのレイアウトは取得できるべし.") {
+			assert.Equal(t, newLayout, layout, "新しいレイアウトに置き換わっているべし.")
+		}
+		assert.False(t, transaction.IsRemovedOnCommit(query.TableFileName(testTableName)), "テーブルのファイルは削除されないべし.")
+	})
+
+	t.Run("テーブル名を変更すると、元のテーブルのファイルはコミット時に削除される.", func(t *testing.T) {
+		newTableName := types.TableName("renamed_table")
+		assert.NoError(t, tableManager.AlterTable(testTableName, newTableName, newLayout, transaction))
+
+		_, err := tableManager.GetLayout(testTableName, transaction)
+		assert.IsType(t, TableCatalogNotFoundError{}, err, "元のテーブル名ではレイアウトを取得できないべし.")
+
+		layout, err := tableManager.GetLayout(newTableName, transaction)
+		if assert.NoError(t, err, "新しいテーブル名でレイアウトを取得できるべし.") {
+			assert.Equal(t, newLayout, layout)
+		}
+		assert.True(t, transaction.IsRemovedOnCommit(query.TableFileName(testTableName)), "元のテーブルのファイルはコミット時に削除されるべし.")
+	})
+
+	t.Run("存在しないテーブルは変更できない.", func(t *testing.T) {
+		err := tableManager.AlterTable("hoge_table", "hoge_table", newLayout, transaction)
+		assert.IsType(t, TableCatalogNotFoundError{}, err)
+	})
+
+	t.Run("既に存在するテーブル名には変更できない.", func(t *testing.T) {
+		tableManager.CreateTable("other_table", testSchema, transaction)
+
+		err := tableManager.AlterTable("renamed_table", "other_table", newLayout, transaction)
+		assert.IsType(t, TableAlreadyExistsError{}, err)
+
+		_, err = tableManager.GetLayout("renamed_table", transaction)
+		assert.NoError(t, err, "変更に失敗したテーブルはそのまま残っているべし.")
+	})
+}
+
+func TestTableManagerCreateTableDuplicate(t *testing.T) {
+	transaction := newTransactionForTest(t, tableManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+
+	testTableName := types.TableName("dup_table")
+	testSchema := record.NewSchema()
+	testSchema.AddIntField("id")
+	assert.NoError(t, tableManager.CreateTable(testTableName, testSchema, transaction), "新しいテーブルは作成できるべし.")
+
+	t.Run("同じ名前のテーブルは作成できず、カタログレコードも増えない.", func(t *testing.T) {
+		otherSchema := record.NewSchema()
+		otherSchema.AddStringField("name", 10)
+
+		err := tableManager.CreateTable(testTableName, otherSchema, transaction)
+		assert.IsType(t, TableAlreadyExistsError{}, err, "同じ名前のテーブルの作成では TableAlreadyExistsError を返すべし.")
+
+		layout, err := tableManager.GetLayout(testTableName, transaction)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []types.FieldName{"id"}, layout.GetSchema().Fields(), "最初に作成したテーブルの定義のままであるべし.")
+		}
+		assert.Len(t, ReadFieldCatalogRowsFor(testTableName, transaction, tableManager), 1, "フィールドカタログのレコードは増えないべし.")
+	})
+}
+
+func TestTableManagerCreateTableNameTooLong(t *testing.T) {
+	transaction := newTransactionForTest(t, tableManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+
+	longName := strings.Repeat("a", constants.MAX_NAME_LENGTH)
+	tooLongName := longName + "a"
+
+	t.Run("MAX_NAME_LENGTH 文字のテーブル名、フィールド名は使える.", func(t *testing.T) {
+		schema := record.NewSchema()
+		schema.AddIntField(types.FieldName(longName))
+		assert.NoError(t, tableManager.CreateTable(types.TableName(longName), schema, transaction))
+
+		layout, err := tableManager.GetLayout(types.TableName(longName), transaction)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []types.FieldName{types.FieldName(longName)}, layout.GetSchema().Fields(), "長い名前も切り詰められずに登録されているべし.")
+		}
+	})
+
+	t.Run("MAX_NAME_LENGTH 文字を超えるテーブル名は使えない.", func(t *testing.T) {
+		schema := record.NewSchema()
+		schema.AddIntField("id")
+		err := tableManager.CreateTable(types.TableName(tooLongName), schema, transaction)
+		assert.IsType(t, NameTooLongError{}, err, "長すぎるテーブル名では NameTooLongError を返すべし.")
+	})
+
+	t.Run("MAX_NAME_LENGTH 文字を超えるフィールド名は使えない.", func(t *testing.T) {
+		schema := record.NewSchema()
+		schema.AddIntField(types.FieldName(tooLongName))
+		err := tableManager.CreateTable("short_table", schema, transaction)
+		assert.IsType(t, NameTooLongError{}, err, "長すぎるフィールド名では NameTooLongError を返すべし.")
+
+		_, err = tableManager.GetLayout("short_table", transaction)
+		assert.Error(t, err, "作成に失敗したテーブルは登録されていないべし.")
+	})
+}
@@ -1,12 +1,15 @@
 package metadata
 
 import (
+	"cmp"
 	"fmt"
 	"simple-db-go/constants"
 	"simple-db-go/query"
 	"simple-db-go/record"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
+	"slices"
+	"strings"
 )
 
 type ViewManager struct {
@@ -21,23 +24,45 @@ func NewViewManager(isNew bool, tableManager *TableManager, transaction *transac
 	if isNew {
 		schema := record.NewSchema()
 		schema.AddStringField("view_name", constants.MAX_NAME_LENGTH)
-		schema.AddStringField("view_def", constants.MAX_VIEW_DEF_LENGTH)
+		schema.AddIntField("seq")
+		schema.AddStringField("view_def", constants.VIEW_DEF_CHUNK_LENGTH)
 		tableManager.CreateTable(VIEW_CATALOG_TABLE_NAME, schema, transaction)
 	}
 
 	return viewManager
 }
 
-func (vm *ViewManager) CreateView(viewName types.ViewName, viewDef types.ViewDef, transaction *transaction.Transaction) {
+// 同じ名前のビューが既に存在する場合は、何も登録せずにエラーを返す.
+func (vm *ViewManager) CreateView(viewName types.ViewName, viewDef types.ViewDef, transaction *transaction.Transaction) error {
+	if err := validateName(viewName); err != nil {
+		return err
+	}
+	if _, err := vm.GetViewDef(viewName, transaction); err == nil {
+		return ViewAlreadyExistsError{ViewName: viewName}
+	}
+
 	row := ViewCatalogRow{
 		ViewName: viewName,
 		ViewDef:  viewDef,
 	}
 
 	WriteViewCatalogRow(row, transaction, vm.tableManager)
+	return nil
 }
 
+// ビューの定義はキャッシュから取得し、キャッシュに無い場合だけカタログテーブルを読む.
 func (vm *ViewManager) GetViewDef(viewName types.ViewName, transaction *transaction.Transaction) (types.ViewDef, error) {
+	cached, _ := vm.tableManager.cache.getViewDef(viewName, transaction, func() (cachedViewDef, error) {
+		viewDef, exists := vm.readViewDef(viewName, transaction)
+		return cachedViewDef{viewDef: viewDef, exists: exists}, nil
+	})
+	if !cached.exists {
+		return "", CannotGetViewError{ViewName: viewName, error: fmt.Errorf("View not found. view_name=%s", viewName)}
+	}
+	return cached.viewDef, nil
+}
+
+func (vm *ViewManager) readViewDef(viewName types.ViewName, transaction *transaction.Transaction) (types.ViewDef, bool) {
 	layout, err := vm.tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
 	if err != nil {
 		// 初期起動時に必ずカタログのレイアウトが登録されているはずなので、ここは panic にしておく.
@@ -47,12 +72,63 @@ func (vm *ViewManager) GetViewDef(viewName types.ViewName, transaction *transact
 	tableScan := query.NewTableScan(transaction, VIEW_CATALOG_TABLE_NAME, layout)
 	defer tableScan.Close()
 
+	// 分割して保存した定義を、seq の順に繋げる.
+	rows := []ViewCatalogRow{}
 	for tableScan.Next() {
 		row := ReadViewCatalogRow(tableScan)
 		if row.ViewName == viewName {
-			return row.ViewDef, nil
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) > 0 {
+		slices.SortFunc(rows, func(a, b ViewCatalogRow) int { return cmp.Compare(a.Seq, b.Seq) })
+
+		var viewDef strings.Builder
+		for _, row := range rows {
+			viewDef.WriteString(string(row.ViewDef))
+		}
+		return types.ViewDef(viewDef.String()), true
+	}
+
+	return "", false
+}
+
+// 全てのビュー名を返す. 定義を分割した行はまとめて、1つのビューにつき1つの名前を返す.
+func (vm *ViewManager) GetViewNames(transaction *transaction.Transaction) []types.ViewName {
+	layout, err := vm.tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		// 初期起動時に必ずカタログのレイアウトが登録されているはずなので、ここは panic にしておく.
+		panic(fmt.Sprintf("ビューの一覧の取得に失敗しました. err=%+v", err))
+	}
+
+	tableScan := query.NewTableScan(transaction, VIEW_CATALOG_TABLE_NAME, layout)
+	defer tableScan.Close()
+
+	viewNames := []types.ViewName{}
+	for tableScan.Next() {
+		row := ReadViewCatalogRow(tableScan)
+		if !slices.Contains(viewNames, row.ViewName) {
+			viewNames = append(viewNames, row.ViewName)
 		}
 	}
+	return viewNames
+}
+
+// ビューのカタログレコードを削除する.
+func (vm *ViewManager) DropView(viewName types.ViewName, transaction *transaction.Transaction) error {
+	layout, err := vm.tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
+	if err != nil {
+		// 初期起動時に必ずカタログのレイアウトが登録されているはずなので、ここは panic にしておく.
+		panic(fmt.Sprintf("ビューの削除に失敗しました. err=%+v", err))
+	}
+	vm.tableManager.cache.markModified(transaction)
 
-	return "", CannotGetViewError{ViewName: viewName, error: fmt.Errorf("View not found. view_name=%s", viewName)}
+	count := DeleteCatalogRows(transaction, VIEW_CATALOG_TABLE_NAME, layout, func(tableScan *query.TableScan) bool {
+		return ReadViewCatalogRow(tableScan).ViewName == viewName
+	})
+	if count == 0 {
+		return CannotGetViewError{ViewName: viewName, error: fmt.Errorf("View not found. view_name=%s", viewName)}
+	}
+	return nil
 }
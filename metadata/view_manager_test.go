@@ -5,6 +5,7 @@ import (
 	"simple-db-go/constants"
 	"simple-db-go/query"
 	"simple-db-go/types"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,7 +38,7 @@ func TestViewManagerNewViewManager(t *testing.T) {
 
 		t.Run("テーブルカタログにビューカタログのレコードが登録されている.", func(t *testing.T) {
 			expectedRecords := []TableCatalogRow{
-				{VIEW_CATALOG_TABLE_NAME, 128},
+				{VIEW_CATALOG_TABLE_NAME, 184, constants.CURRENT_RECORD_FORMAT},
 			}
 			actualRecords := make([]TableCatalogRow, 0)
 			for tableCatalogTableScan.Next() {
@@ -48,8 +49,9 @@ func TestViewManagerNewViewManager(t *testing.T) {
 
 		t.Run("フィールドカタログにビューカタログのレコードが登録されている.", func(t *testing.T) {
 			expectedRecords := []FieldCatalogRow{
-				{VIEW_CATALOG_TABLE_NAME, "view_name", constants.VARCHAR, 16, 4},
-				{VIEW_CATALOG_TABLE_NAME, "view_def", constants.VARCHAR, 100, 24},
+				{VIEW_CATALOG_TABLE_NAME, "view_name", constants.VARCHAR, 64, 4},
+				{VIEW_CATALOG_TABLE_NAME, "seq", constants.INTEGER, 0, 72},
+				{VIEW_CATALOG_TABLE_NAME, "view_def", constants.VARCHAR, 100, 76},
 			}
 			actualRecords := make([]FieldCatalogRow, 0, 2)
 			for fieldCatalogTableScan.Next() {
@@ -106,3 +108,87 @@ func TestViewManagerGetCreateView(t *testing.T) {
 		})
 	})
 }
+
+func TestViewManagerDropView(t *testing.T) {
+	transaction := newTransactionForTest(t, viewManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+	viewManager := NewViewManager(true, tableManager, transaction)
+
+	testViewName := types.ViewName("drop_view")
+	viewManager.CreateView(testViewName, "SELECT * FROM test_table;", transaction)
+
+	t.Run("ビューを削除すると取得できなくなる.", func(t *testing.T) {
+		assert.NoError(t, viewManager.DropView(testViewName, transaction), "存在するビューは削除できるべし.")
+
+		_, err := viewManager.GetViewDef(testViewName, transaction)
+		assert.IsType(t, CannotGetViewError{}, err, "削除したビューは取得できないべし.")
+	})
+
+	t.Run("存在しないビューを削除しようとするとエラーになる.", func(t *testing.T) {
+		err := viewManager.DropView(testViewName, transaction)
+		assert.IsType(t, CannotGetViewError{}, err, "存在しないビューの削除では CannotGetViewError を返すべし.")
+	})
+}
+
+func TestViewManagerCreateViewDuplicate(t *testing.T) {
+	transaction := newTransactionForTest(t, viewManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+	viewManager := NewViewManager(true, tableManager, transaction)
+
+	testViewName := types.ViewName("dup_view")
+	assert.NoError(t, viewManager.CreateView(testViewName, "SELECT a FROM test_table", transaction), "新しいビューは作成できるべし.")
+
+	t.Run("同じ名前のビューは作成できず、最初の定義のままである.", func(t *testing.T) {
+		err := viewManager.CreateView(testViewName, "SELECT b FROM test_table", transaction)
+		assert.IsType(t, ViewAlreadyExistsError{}, err, "同じ名前のビューの作成では ViewAlreadyExistsError を返すべし.")
+
+		viewDef, err := viewManager.GetViewDef(testViewName, transaction)
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.ViewDef("SELECT a FROM test_table"), viewDef)
+		}
+	})
+}
+
+func TestViewManagerLongViewDef(t *testing.T) {
+	transaction := newTransactionForTest(t, viewManagerTestName)
+	defer transaction.Rollback()
+	tableManager := NewTableManager(true, transaction)
+	viewManager := NewViewManager(true, tableManager, transaction)
+
+	testViewName := types.ViewName("long_view")
+	testViewDef := types.ViewDef("SELECT " + strings.Repeat("some_long_field_name, ", 20) + "id FROM some_long_table_name WHERE id = 1")
+	assert.NoError(t, viewManager.CreateView(testViewName, testViewDef, transaction), "長い定義のビューも作成できるべし.")
+
+	t.Run("ビューの定義は複数のレコードに分割して登録されている.", func(t *testing.T) {
+		viewCatalogLayout, _ := tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
+		viewCatalogTableScan := query.NewTableScan(transaction, VIEW_CATALOG_TABLE_NAME, viewCatalogLayout)
+		defer viewCatalogTableScan.Close()
+
+		numRows := 0
+		for viewCatalogTableScan.Next() {
+			row := ReadViewCatalogRow(viewCatalogTableScan)
+			assert.Equal(t, testViewName, row.ViewName)
+			assert.LessOrEqual(t, len(row.ViewDef), constants.VIEW_DEF_CHUNK_LENGTH, "1レコードの定義は VIEW_DEF_CHUNK_LENGTH 以下であるべし.")
+			numRows++
+		}
+		assert.Equal(t, (len(testViewDef)+constants.VIEW_DEF_CHUNK_LENGTH-1)/constants.VIEW_DEF_CHUNK_LENGTH, numRows, "定義の長さに応じたレコード数が登録されているはず.")
+	})
+
+	t.Run("分割されたビューの定義を元通りに取得できる.", func(t *testing.T) {
+		actualViewDef, err := viewManager.GetViewDef(testViewName, transaction)
+		if assert.NoError(t, err) {
+			assert.Equal(t, testViewDef, actualViewDef, "切り詰められずに元の定義が取得できるべし.")
+		}
+	})
+
+	t.Run("分割されたビューを削除すると、全てのレコードが削除される.", func(t *testing.T) {
+		assert.NoError(t, viewManager.DropView(testViewName, transaction))
+
+		viewCatalogLayout, _ := tableManager.GetLayout(VIEW_CATALOG_TABLE_NAME, transaction)
+		viewCatalogTableScan := query.NewTableScan(transaction, VIEW_CATALOG_TABLE_NAME, viewCatalogLayout)
+		defer viewCatalogTableScan.Close()
+		assert.False(t, viewCatalogTableScan.Next(), "ビューカタログにはレコードが残っていないはず.")
+	})
+}
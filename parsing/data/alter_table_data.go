@@ -0,0 +1,47 @@
+package data
+
+import "simple-db-go/types"
+
+// ALTER TABLE の文. 1つの文で1つの操作だけを指定できる.
+type AlterTableData struct {
+	TableName types.TableName
+	Action    AlterTableAction
+}
+
+func (*AlterTableData) SQLData() {}
+
+// ALTER TABLE の操作. AddColumnData, DropColumnData, RenameColumnData, RenameTableData のいずれか.
+type AlterTableAction interface{ AlterTableAction() }
+
+// `ADD [COLUMN] <field definition>`. フィールド定義に続けて、CREATE TABLE と同じ制約を指定できる.
+type AddColumnData struct {
+	FieldName   types.FieldName
+	FieldType   types.FieldType
+	FieldLength types.FieldLength
+	Constraints []*ColumnConstraint
+	ForeignKeys []*ForeignKey
+}
+
+func (*AddColumnData) AlterTableAction() {}
+
+// `DROP [COLUMN] <field>`.
+type DropColumnData struct {
+	FieldName types.FieldName
+}
+
+func (*DropColumnData) AlterTableAction() {}
+
+// `RENAME COLUMN <field> TO <new field>`.
+type RenameColumnData struct {
+	FieldName    types.FieldName
+	NewFieldName types.FieldName
+}
+
+func (*RenameColumnData) AlterTableAction() {}
+
+// `RENAME TO <new table>`.
+type RenameTableData struct {
+	NewTableName types.TableName
+}
+
+func (*RenameTableData) AlterTableAction() {}
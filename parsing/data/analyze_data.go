@@ -0,0 +1,9 @@
+package data
+
+import "simple-db-go/types"
+
+type AnalyzeTableData struct {
+	TableNames []types.TableName
+}
+
+func (*AnalyzeTableData) SQLData() {}
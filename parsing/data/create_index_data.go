@@ -6,6 +6,8 @@ type CreateIndexData struct {
 	IndexName types.IndexName
 	TableName types.TableName
 	FieldName types.FieldName
+	// `CREATE INDEX IF NOT EXISTS` の場合、テーブルに同じ名前のインデックスがあれば何もしない.
+	IfNotExists bool
 }
 
 func (*CreateIndexData) SQLData() {}
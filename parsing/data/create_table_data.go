@@ -1,13 +1,68 @@
 package data
 
 import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/query"
 	"simple-db-go/record"
 	"simple-db-go/types"
 )
 
 type CreateTableData struct {
-	TableName types.TableName
-	Schema    *record.Schema
+	TableName   types.TableName
+	Schema      *record.Schema
+	Constraints []*ColumnConstraint
+	ForeignKeys []*ForeignKey
+	// `CREATE TABLE IF NOT EXISTS` の場合、同じ名前のテーブルかビューがあれば何もしない.
+	IfNotExists bool
 }
 
 func (*CreateTableData) SQLData() {}
+
+// フィールド定義に続けて指定する制約. `NOT NULL`, `DEFAULT <constant>`, `CHECK (<predicate>)`, `PRIMARY KEY`, `UNIQUE`, `AUTO_INCREMENT` のいずれか.
+// `PRIMARY KEY (<field>)` のようにテーブルに対して指定した制約も、そのフィールドの制約として扱う.
+type ColumnConstraint struct {
+	FieldName types.FieldName
+	Type      types.ConstraintType
+	// Type が DEFAULT の場合だけ使う.
+	DefaultValue query.Constant
+	// Type が CHECK の場合だけ使う.
+	Check *query.Predicate
+}
+
+// 制約カタログに保存する、SQL で表した制約の定義.
+func (c *ColumnConstraint) GetConstraintDef() types.ConstraintDef {
+	switch c.Type {
+	case constants.DEFAULT:
+		return types.ConstraintDef("DEFAULT " + c.DefaultValue.ToString())
+	case constants.CHECK:
+		return types.ConstraintDef("CHECK (" + c.Check.ToString() + ")")
+	case constants.PRIMARY_KEY:
+		return "PRIMARY KEY"
+	case constants.UNIQUE:
+		return "UNIQUE"
+	case constants.AUTO_INCREMENT:
+		return "AUTO_INCREMENT"
+	default:
+		return "NOT NULL"
+	}
+}
+
+// `FOREIGN KEY (<field>) REFERENCES <table>(<field>)`、またはフィールド定義に続けて指定した `REFERENCES <table>(<field>)`.
+type ForeignKey struct {
+	FieldName    types.FieldName
+	RefTableName types.TableName
+	RefFieldName types.FieldName
+	OnDelete     types.ReferentialAction
+}
+
+func (f *ForeignKey) ToString() string {
+	onDelete := "RESTRICT"
+	switch f.OnDelete {
+	case constants.CASCADE:
+		onDelete = "CASCADE"
+	case constants.SET_NULL:
+		onDelete = "SET NULL"
+	}
+	return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s) ON DELETE %s", f.FieldName, f.RefTableName, f.RefFieldName, onDelete)
+}
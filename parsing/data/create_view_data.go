@@ -5,6 +5,8 @@ import "simple-db-go/types"
 type CreateViewData struct {
 	ViewName  types.ViewName
 	QueryData *QueryData
+	// `CREATE VIEW IF NOT EXISTS` の場合、同じ名前のテーブルかビューがあれば何もしない.
+	IfNotExists bool
 }
 
 func (*CreateViewData) SQLData() {}
@@ -0,0 +1,28 @@
+package data
+
+import "simple-db-go/types"
+
+type DropTableData struct {
+	TableName types.TableName
+	// `DROP TABLE IF EXISTS` の場合、テーブルが存在しなくてもエラーにしない.
+	IfExists bool
+}
+
+func (*DropTableData) SQLData() {}
+
+type DropViewData struct {
+	ViewName types.ViewName
+	// `DROP VIEW IF EXISTS` の場合、ビューが存在しなくてもエラーにしない.
+	IfExists bool
+}
+
+func (*DropViewData) SQLData() {}
+
+type DropIndexData struct {
+	IndexName types.IndexName
+	TableName types.TableName
+	// `DROP INDEX IF EXISTS` の場合、インデックスが存在しなくてもエラーにしない.
+	IfExists bool
+}
+
+func (*DropIndexData) SQLData() {}
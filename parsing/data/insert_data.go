@@ -8,7 +8,11 @@ import (
 type InsertData struct {
 	TableName  types.TableName
 	FieldNames []types.FieldName
-	Values     []query.Constant
+	// VALUES に指定されたレコード. 各レコードの値は FieldNames と位置で対応する.
+	Rows [][]query.Constant
+	// `INSERT INTO t (a, b) SELECT ...` の場合の SELECT 文. 指定された場合は Rows は使わない.
+	// SELECT 文の結果のフィールドは、FieldNames と位置で対応する.
+	Query *QueryData
 }
 
 func (*InsertData) SQLData() {}
@@ -7,9 +7,15 @@ import (
 
 type ModifyData struct {
 	TableName types.TableName
-	FieldName types.FieldName
-	NewValue  query.Expression
-	Predicate *query.Predicate
+	// SET 句の代入. 右辺は全て更新前のレコードで評価する.
+	Assignments []*AssignmentData
+	Predicate   *query.Predicate
 }
 
 func (*ModifyData) SQLData() {}
+
+// SET 句の `field = expression` を表す.
+type AssignmentData struct {
+	FieldName types.FieldName
+	NewValue  query.Expression
+}
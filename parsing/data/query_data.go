@@ -10,13 +10,61 @@ import (
 var _ SQLData = (*QueryData)(nil)
 
 type QueryData struct {
+	// `WITH` 句で定義された、このクエリの中で使える名前付きのサブクエリ.
+	With     *WithData
+	Distinct bool
+	// SELECT 句で指定された出力フィールド. 集約関数は `count(a)` のような結果のフィールド名で含まれる.
+	// window 関数も `rank() over (order by a)` のような結果のフィールド名で含まれる.
+	FieldNames           []types.FieldName
+	AggregationFunctions []query.AggregationFunction
+	// window 関数は、集約関数を適用した後のレコードに対して計算する.
+	WindowFunctions []query.WindowFunction
+	Queryables      []Queryable
+	// FROM 句に `(SELECT ...) AS t` で指定されたサブクエリ. キーは Queryables に含まれる別名.
+	DerivedTables map[Queryable]*QueryData
+	Predicate     *query.Predicate
+	// UNION などの集合演算で後ろにつなげた SELECT 文. 左から順に適用する.
+	// UNION, EXCEPT の右辺に続く INTERSECT は、右辺の SetOperations に含まれる.
+	SetOperations []*SetOperationData
+	// LIMIT は集合演算の結果全体に適用する.
+	Limit *LimitData
+}
+
+// `WITH [RECURSIVE] name (field, ...) AS (SELECT ...), ...` を表す.
+type WithData struct {
+	Recursive    bool
+	CommonTables []*CommonTableData
+}
+
+// WITH 句で定義される、名前付きのサブクエリ(CTE).
+// FieldNames が指定された場合は、サブクエリの結果のフィールドをその名前で参照する.
+type CommonTableData struct {
+	Name       Queryable
 	FieldNames []types.FieldName
-	Queryables []Queryable
-	Predicate  *query.Predicate
+	Query      *QueryData
+}
+
+// `UNION [ALL] SELECT ...` を表す. Query には LIMIT は含まれない.
+// Query の SetOperations には、INTERSECT で先に計算する SELECT 文だけが含まれる.
+type SetOperationData struct {
+	Operator query.SetOperator
+	All      bool
+	Query    *QueryData
+}
+
+// `LIMIT count OFFSET offset` を表す. OFFSET が省略された場合は 0 とする.
+type LimitData struct {
+	Count  types.Int
+	Offset types.Int
 }
 
 func (*QueryData) SQLData() {}
 
+// 集約関数があれば、集約が必要なクエリである.
+func (q *QueryData) HasAggregation() bool {
+	return len(q.AggregationFunctions) > 0
+}
+
 func (q *QueryData) ToString() string {
 	fieldNames := make([]string, 0, len(q.FieldNames))
 	for _, fieldName := range q.FieldNames {
@@ -24,22 +72,69 @@ func (q *QueryData) ToString() string {
 	}
 
 	queryables := make([]string, 0, len(q.Queryables))
-	for _, tableName := range q.Queryables {
-		queryables = append(queryables, string(tableName.ToString()))
+	for _, queryable := range q.Queryables {
+		if derivedTable, ok := q.DerivedTables[queryable]; ok {
+			queryables = append(queryables, fmt.Sprintf("(%s) AS %s", strings.TrimSuffix(derivedTable.ToString(), ";"), queryable.ToString()))
+		} else {
+			queryables = append(queryables, queryable.ToString())
+		}
+	}
+
+	selectClause := "SELECT"
+	if q.Distinct {
+		selectClause = "SELECT DISTINCT"
 	}
 
-	if q.Predicate == nil {
-		return fmt.Sprintf(
-			"SELECT %s FROM %s;",
-			strings.Join(fieldNames, ", "),
-			strings.Join(queryables, ", "),
-		)
+	if q.With != nil {
+		selectClause = q.With.ToString() + " " + selectClause
 	}
 
-	return fmt.Sprintf(
-		"SELECT %s FROM %s WHERE %s;",
+	sql := fmt.Sprintf(
+		"%s %s FROM %s",
+		selectClause,
 		strings.Join(fieldNames, ", "),
 		strings.Join(queryables, ", "),
-		q.Predicate.ToString(),
 	)
+
+	if q.Predicate != nil {
+		sql += fmt.Sprintf(" WHERE %s", q.Predicate.ToString())
+	}
+
+	for _, setOperation := range q.SetOperations {
+		sql += fmt.Sprintf(" %s ", setOperation.Operator)
+		if setOperation.All {
+			sql += "ALL "
+		}
+		sql += strings.TrimSuffix(setOperation.Query.ToString(), ";")
+	}
+
+	if q.Limit != nil {
+		sql += fmt.Sprintf(" LIMIT %d", q.Limit.Count)
+		if q.Limit.Offset > 0 {
+			sql += fmt.Sprintf(" OFFSET %d", q.Limit.Offset)
+		}
+	}
+
+	return sql + ";"
+}
+
+func (w *WithData) ToString() string {
+	commonTables := make([]string, 0, len(w.CommonTables))
+	for _, commonTable := range w.CommonTables {
+		name := commonTable.Name.ToString()
+		if len(commonTable.FieldNames) > 0 {
+			fieldNames := make([]string, 0, len(commonTable.FieldNames))
+			for _, fieldName := range commonTable.FieldNames {
+				fieldNames = append(fieldNames, string(fieldName))
+			}
+			name += fmt.Sprintf(" (%s)", strings.Join(fieldNames, ", "))
+		}
+		commonTables = append(commonTables, fmt.Sprintf("%s AS (%s)", name, strings.TrimSuffix(commonTable.Query.ToString(), ";")))
+	}
+
+	with := "WITH"
+	if w.Recursive {
+		with = "WITH RECURSIVE"
+	}
+	return fmt.Sprintf("%s %s", with, strings.Join(commonTables, ", "))
 }
@@ -4,6 +4,7 @@ import "simple-db-go/types"
 
 // SELECT 句においては、FROM の後にテーブル名もしくはビュー名を指定できる.
 // パース時点では区別がつかないので、この識別子を扱うための構造体として用意する.
+// FROM 句のサブクエリ(derived table)の場合は、`AS` で付けた別名を表す.
 type Queryable string
 
 func (q Queryable) ToTableName() types.TableName {
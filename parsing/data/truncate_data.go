@@ -0,0 +1,9 @@
+package data
+
+import "simple-db-go/types"
+
+type TruncateTableData struct {
+	TableName types.TableName
+}
+
+func (*TruncateTableData) SQLData() {}
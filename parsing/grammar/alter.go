@@ -0,0 +1,91 @@
+package grammar
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/types"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+func AlterTableActionUnion() participle.Option {
+	return participle.Union[AlterTableAction](
+		&AddColumn{},
+		&DropColumn{},
+		&RenameColumn{},
+		&RenameTable{},
+	)
+}
+
+type AlterTableCmd struct {
+	TableName types.TableName  `"ALTER" "TABLE" @Ident`
+	Action    AlterTableAction `@@ ";"?`
+}
+
+func (*AlterTableCmd) GrammarUpdateCmd() {}
+func (*AlterTableCmd) GrammarStatement() {}
+func (a *AlterTableCmd) ToData() data.SQLData {
+	return &data.AlterTableData{
+		TableName: a.TableName,
+		Action:    a.Action.ToData(),
+	}
+}
+
+type AlterTableAction interface {
+	GrammarAlterTableAction()
+	ToData() data.AlterTableAction
+}
+
+type AddColumn struct {
+	FieldDef FieldDef `"ADD" "COLUMN"? @@`
+}
+
+func (*AddColumn) GrammarAlterTableAction() {}
+func (a *AddColumn) ToData() data.AlterTableAction {
+	fieldName := a.FieldDef.GetFieldName()
+
+	var constraints []*data.ColumnConstraint
+	var foreignKeys []*data.ForeignKey
+	for _, constraint := range a.FieldDef.GetConstraints() {
+		if constraint.References != nil {
+			foreignKeys = append(foreignKeys, constraint.References.ToData(fieldName))
+			continue
+		}
+		constraints = append(constraints, constraint.ToData(fieldName))
+	}
+
+	return &data.AddColumnData{
+		FieldName:   fieldName,
+		FieldType:   a.FieldDef.GetFieldType(),
+		FieldLength: a.FieldDef.GetFieldLength(),
+		Constraints: constraints,
+		ForeignKeys: foreignKeys,
+	}
+}
+
+type DropColumn struct {
+	FieldName types.FieldName `"DROP" "COLUMN"? @Ident`
+}
+
+func (*DropColumn) GrammarAlterTableAction() {}
+func (d *DropColumn) ToData() data.AlterTableAction {
+	return &data.DropColumnData{FieldName: d.FieldName}
+}
+
+type RenameColumn struct {
+	FieldName    types.FieldName `"RENAME" "COLUMN" @Ident`
+	NewFieldName types.FieldName `"TO" @Ident`
+}
+
+func (*RenameColumn) GrammarAlterTableAction() {}
+func (r *RenameColumn) ToData() data.AlterTableAction {
+	return &data.RenameColumnData{FieldName: r.FieldName, NewFieldName: r.NewFieldName}
+}
+
+type RenameTable struct {
+	NewTableName types.TableName `"RENAME" "TO" @Ident`
+}
+
+func (*RenameTable) GrammarAlterTableAction() {}
+func (r *RenameTable) ToData() data.AlterTableAction {
+	return &data.RenameTableData{NewTableName: r.NewTableName}
+}
@@ -0,0 +1,18 @@
+package grammar
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/types"
+)
+
+type AnalyzeTableCmd struct {
+	TableNames []types.TableName `"ANALYZE" "TABLE" @Ident ( "," @Ident )* ";"?`
+}
+
+func (*AnalyzeTableCmd) GrammarUpdateCmd() {}
+func (*AnalyzeTableCmd) GrammarStatement() {}
+func (a *AnalyzeTableCmd) ToData() data.SQLData {
+	return &data.AnalyzeTableData{
+		TableNames: a.TableNames,
+	}
+}
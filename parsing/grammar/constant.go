@@ -8,7 +8,7 @@ import (
 )
 
 func ConstantUnion() participle.Option {
-	return participle.Union[Constant](IntConstant{}, StrConstant{})
+	return participle.Union[Constant](IntConstant{}, StrConstant{}, NullConstant{})
 }
 
 type Constant interface {
@@ -17,7 +17,7 @@ type Constant interface {
 }
 
 type IntConstant struct {
-	Value types.Int `@Int`
+	Value types.Int `@( "-"? Int )`
 }
 
 func (i IntConstant) GrammarExpression()                  {}
@@ -33,3 +33,12 @@ func (s StrConstant) GrammarExpression()                  {}
 func (s StrConstant) ToQueryExpression() query.Expression { return query.NewStrConstant(s.Value) }
 func (s StrConstant) GrammarConstant()                    {}
 func (s StrConstant) ToQueryConstant() query.Constant     { return query.NewStrConstant(s.Value) }
+
+type NullConstant struct {
+	Null bool `@"NULL"`
+}
+
+func (n NullConstant) GrammarExpression()                  {}
+func (n NullConstant) ToQueryExpression() query.Expression { return query.NewNullConstant() }
+func (n NullConstant) GrammarConstant()                    {}
+func (n NullConstant) ToQueryConstant() query.Constant     { return query.NewNullConstant() }
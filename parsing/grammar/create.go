@@ -16,8 +16,41 @@ func FieldDefUnion() participle.Option {
 type CreateCmd interface{ GrammarCreateCmd() }
 
 type CreateTableCmd struct {
-	TableName types.TableName `"CREATE" "TABLE" @Ident`
-	FieldDefs []FieldDef      `"(" @@ ( "," @@ )* ")" ";"?`
+	IfNotExists bool            `"CREATE" "TABLE" @( "IF" "NOT" "EXISTS" )?`
+	TableName   types.TableName `@Ident`
+	Elements    []*TableElement `"(" @@ ( "," @@ )* ")" ";"?`
+}
+
+// CREATE TABLE の括弧の中には、フィールド定義と、テーブルに対する制約を並べられる.
+type TableElement struct {
+	Key        *KeyDef        `  @@`
+	ForeignKey *ForeignKeyDef `| @@`
+	FieldDef   FieldDef       `| @@`
+}
+
+// `PRIMARY KEY (<field>)` または `UNIQUE (<field>)`.
+type KeyDef struct {
+	PrimaryKey bool            `( @( "PRIMARY" "KEY" )`
+	Unique     bool            `| @"UNIQUE" )`
+	FieldName  types.FieldName `"(" @Ident ")"`
+}
+
+// `FOREIGN KEY (<field>) REFERENCES <table>(<field>) [ON DELETE ...]`.
+type ForeignKeyDef struct {
+	FieldName  types.FieldName `"FOREIGN" "KEY" "(" @Ident ")"`
+	References *References     `@@`
+}
+
+type References struct {
+	TableName types.TableName    `"REFERENCES" @Ident`
+	FieldName types.FieldName    `"(" @Ident ")"`
+	OnDelete  *ReferentialAction `( "ON" "DELETE" @@ )?`
+}
+
+type ReferentialAction struct {
+	Restrict bool `  @"RESTRICT"`
+	Cascade  bool `| @"CASCADE"`
+	SetNull  bool `| @( "SET" "NULL" )`
 }
 
 func (*CreateTableCmd) GrammarUpdateCmd() {}
@@ -25,22 +58,47 @@ func (*CreateTableCmd) GrammarCreateCmd() {}
 func (*CreateTableCmd) GrammarStatement() {}
 func (c *CreateTableCmd) ToData() data.SQLData {
 	schema := record.NewSchema()
-	for _, fieldDef := range c.FieldDefs {
+	var constraints []*data.ColumnConstraint
+	var foreignKeys []*data.ForeignKey
+	for _, element := range c.Elements {
+		if element.Key != nil {
+			constraints = append(constraints, element.Key.ToData())
+			continue
+		}
+
+		if element.ForeignKey != nil {
+			foreignKeys = append(foreignKeys, element.ForeignKey.References.ToData(element.ForeignKey.FieldName))
+			continue
+		}
+
+		fieldDef := element.FieldDef
 		schema.AddField(
 			fieldDef.GetFieldName(),
 			fieldDef.GetFieldType(),
 			fieldDef.GetFieldLength(),
 		)
+
+		for _, constraint := range fieldDef.GetConstraints() {
+			if constraint.References != nil {
+				foreignKeys = append(foreignKeys, constraint.References.ToData(fieldDef.GetFieldName()))
+				continue
+			}
+			constraints = append(constraints, constraint.ToData(fieldDef.GetFieldName()))
+		}
 	}
 
 	return &data.CreateTableData{
-		TableName: c.TableName,
-		Schema:    schema,
+		TableName:   c.TableName,
+		Schema:      schema,
+		Constraints: constraints,
+		ForeignKeys: foreignKeys,
+		IfNotExists: c.IfNotExists,
 	}
 }
 
 type CreateViewCmd struct {
-	ViewName     types.ViewName `"CREATE" "VIEW" @Ident`
+	IfNotExists  bool           `"CREATE" "VIEW" @( "IF" "NOT" "EXISTS" )?`
+	ViewName     types.ViewName `@Ident`
 	ViewDefQuery *Query         `"AS" @@`
 }
 
@@ -49,15 +107,17 @@ func (*CreateViewCmd) GrammarCreateCmd() {}
 func (*CreateViewCmd) GrammarStatement() {}
 func (c *CreateViewCmd) ToData() data.SQLData {
 	return &data.CreateViewData{
-		ViewName:  c.ViewName,
-		QueryData: c.ViewDefQuery.ToData().(*data.QueryData),
+		ViewName:    c.ViewName,
+		QueryData:   c.ViewDefQuery.ToData().(*data.QueryData),
+		IfNotExists: c.IfNotExists,
 	}
 }
 
 type CreateIndexCmd struct {
-	IndexName types.IndexName `"CREATE" "INDEX" @Ident`
-	TableName types.TableName `"ON" @Ident`
-	FieldName types.FieldName `"(" @Ident ")" ";"?`
+	IfNotExists bool            `"CREATE" "INDEX" @( "IF" "NOT" "EXISTS" )?`
+	IndexName   types.IndexName `@Ident`
+	TableName   types.TableName `"ON" @Ident`
+	FieldName   types.FieldName `"(" @Ident ")" ";"?`
 }
 
 func (*CreateIndexCmd) GrammarUpdateCmd() {}
@@ -65,9 +125,10 @@ func (*CreateIndexCmd) GrammarCreateCmd() {}
 func (*CreateIndexCmd) GrammarStatement() {}
 func (c *CreateIndexCmd) ToData() data.SQLData {
 	return &data.CreateIndexData{
-		IndexName: c.IndexName,
-		TableName: c.TableName,
-		FieldName: c.FieldName,
+		IndexName:   c.IndexName,
+		TableName:   c.TableName,
+		FieldName:   c.FieldName,
+		IfNotExists: c.IfNotExists,
 	}
 }
 
@@ -76,10 +137,12 @@ type FieldDef interface {
 	GetFieldName() types.FieldName
 	GetFieldType() types.FieldType
 	GetFieldLength() types.FieldLength
+	GetConstraints() []*ColumnConstraint
 }
 
 type IntFieldDef struct {
-	FieldName types.FieldName `@Ident "INT"`
+	FieldName   types.FieldName     `@Ident "INT"`
+	Constraints []*ColumnConstraint `@@*`
 }
 
 func (IntFieldDef) GrammarFieldDef() {}
@@ -92,10 +155,14 @@ func (IntFieldDef) GetFieldType() types.FieldType {
 func (IntFieldDef) GetFieldLength() types.FieldLength {
 	return record.INTEGER_FIELD_LENGTH
 }
+func (i IntFieldDef) GetConstraints() []*ColumnConstraint {
+	return i.Constraints
+}
 
 type VarcharFieldDef struct {
-	FieldName   types.FieldName   `@Ident "VARCHAR"`
-	FieldLength types.FieldLength `"(" @Int ")"`
+	FieldName   types.FieldName     `@Ident "VARCHAR"`
+	FieldLength types.FieldLength   `"(" @( "-"? Int ) ")"`
+	Constraints []*ColumnConstraint `@@*`
 }
 
 func (VarcharFieldDef) GrammarFieldDef() {}
@@ -108,3 +175,61 @@ func (VarcharFieldDef) GetFieldType() types.FieldType {
 func (v VarcharFieldDef) GetFieldLength() types.FieldLength {
 	return v.FieldLength
 }
+func (v VarcharFieldDef) GetConstraints() []*ColumnConstraint {
+	return v.Constraints
+}
+
+// NOTE: 制約カタログに保存した定義を読み込む時も、この構造体でパースする.
+// References は外部キーとして別に扱うので、ToData では変換しない.
+type ColumnConstraint struct {
+	NotNull       bool        `  @( "NOT" "NULL" )`
+	Default       Constant    `| "DEFAULT" @@`
+	Check         *Predicate  `| "CHECK" "(" @@ ")"`
+	PrimaryKey    bool        `| @( "PRIMARY" "KEY" )`
+	Unique        bool        `| @"UNIQUE"`
+	AutoIncrement bool        `| @"AUTO_INCREMENT"`
+	References    *References `| @@`
+}
+
+func (c *ColumnConstraint) ToData(fieldName types.FieldName) *data.ColumnConstraint {
+	switch {
+	case c.Default != nil:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.DEFAULT, DefaultValue: c.Default.ToQueryConstant()}
+	case c.Check != nil:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.CHECK, Check: c.Check.ToQueryPredicate()}
+	case c.PrimaryKey:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.PRIMARY_KEY}
+	case c.Unique:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.UNIQUE}
+	case c.AutoIncrement:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.AUTO_INCREMENT}
+	default:
+		return &data.ColumnConstraint{FieldName: fieldName, Type: constants.NOT_NULL}
+	}
+}
+
+func (k *KeyDef) ToData() *data.ColumnConstraint {
+	if k.PrimaryKey {
+		return &data.ColumnConstraint{FieldName: k.FieldName, Type: constants.PRIMARY_KEY}
+	}
+	return &data.ColumnConstraint{FieldName: k.FieldName, Type: constants.UNIQUE}
+}
+
+// ON DELETE が省略された場合は RESTRICT とする.
+func (r *References) ToData(fieldName types.FieldName) *data.ForeignKey {
+	onDelete := constants.RESTRICT
+	switch {
+	case r.OnDelete == nil:
+	case r.OnDelete.Cascade:
+		onDelete = constants.CASCADE
+	case r.OnDelete.SetNull:
+		onDelete = constants.SET_NULL
+	}
+
+	return &data.ForeignKey{
+		FieldName:    fieldName,
+		RefTableName: r.TableName,
+		RefFieldName: r.FieldName,
+		OnDelete:     onDelete,
+	}
+}
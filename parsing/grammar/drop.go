@@ -0,0 +1,50 @@
+package grammar
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/types"
+)
+
+type DropTableCmd struct {
+	IfExists  bool            `"DROP" "TABLE" @( "IF" "EXISTS" )?`
+	TableName types.TableName `@Ident ";"?`
+}
+
+func (*DropTableCmd) GrammarUpdateCmd() {}
+func (*DropTableCmd) GrammarStatement() {}
+func (d *DropTableCmd) ToData() data.SQLData {
+	return &data.DropTableData{
+		TableName: d.TableName,
+		IfExists:  d.IfExists,
+	}
+}
+
+type DropViewCmd struct {
+	IfExists bool           `"DROP" "VIEW" @( "IF" "EXISTS" )?`
+	ViewName types.ViewName `@Ident ";"?`
+}
+
+func (*DropViewCmd) GrammarUpdateCmd() {}
+func (*DropViewCmd) GrammarStatement() {}
+func (d *DropViewCmd) ToData() data.SQLData {
+	return &data.DropViewData{
+		ViewName: d.ViewName,
+		IfExists: d.IfExists,
+	}
+}
+
+type DropIndexCmd struct {
+	IfExists  bool            `"DROP" "INDEX" @( "IF" "EXISTS" )?`
+	IndexName types.IndexName `@Ident`
+	TableName types.TableName `"ON" @Ident ";"?`
+}
+
+func (*DropIndexCmd) GrammarUpdateCmd() {}
+func (*DropIndexCmd) GrammarStatement() {}
+func (d *DropIndexCmd) ToData() data.SQLData {
+	return &data.DropIndexData{
+		IndexName: d.IndexName,
+		TableName: d.TableName,
+		IfExists:  d.IfExists,
+	}
+}
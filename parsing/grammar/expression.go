@@ -8,7 +8,7 @@ import (
 )
 
 func ExpressionUnion() participle.Option {
-	return participle.Union[GrammarExpression](IntConstant{}, StrConstant{}, FieldNameExpression{})
+	return participle.Union[GrammarExpression](IntConstant{}, StrConstant{}, NullConstant{}, FieldNameExpression{})
 }
 
 type GrammarExpression interface {
@@ -25,3 +25,54 @@ func (FieldNameExpression) GrammarExpression() {}
 func (f FieldNameExpression) ToQueryExpression() query.Expression {
 	return query.NewFieldNameExpression(f.Value)
 }
+
+// `a + 1`, `(a - b) * 2` のような整数の四則演算. `*`, `/` は `+`, `-` より先に計算し、同じ優先順位の演算は左から計算する.
+type ArithmeticExpression struct {
+	Left  *ArithmeticTerm        `@@`
+	Right []*ArithmeticOperation `( @@ )*`
+}
+
+// `*`, `/` だけで結合された式.
+type ArithmeticTerm struct {
+	Left  *ArithmeticOperand           `@@`
+	Right []*ArithmeticFactorOperation `( @@ )*`
+}
+
+type ArithmeticOperation struct {
+	Operator query.ArithmeticOperator `@( "+" | "-" )`
+	Term     *ArithmeticTerm          `@@`
+}
+
+type ArithmeticFactorOperation struct {
+	Operator query.ArithmeticOperator `@( "*" | "/" )`
+	Operand  *ArithmeticOperand       `@@`
+}
+
+type ArithmeticOperand struct {
+	Parenthesized *ArithmeticExpression `  "(" @@ ")"`
+	Expression    GrammarExpression     `| @@`
+}
+
+// 演算子が無い場合は、元の Expression をそのまま返す.
+func (a *ArithmeticExpression) ToQueryExpression() query.Expression {
+	expression := a.Left.ToQueryExpression()
+	for _, operation := range a.Right {
+		expression = query.NewArithmeticExpression(operation.Operator, expression, operation.Term.ToQueryExpression())
+	}
+	return expression
+}
+
+func (a *ArithmeticTerm) ToQueryExpression() query.Expression {
+	expression := a.Left.ToQueryExpression()
+	for _, operation := range a.Right {
+		expression = query.NewArithmeticExpression(operation.Operator, expression, operation.Operand.ToQueryExpression())
+	}
+	return expression
+}
+
+func (a *ArithmeticOperand) ToQueryExpression() query.Expression {
+	if a.Parenthesized != nil {
+		return a.Parenthesized.ToQueryExpression()
+	}
+	return a.Expression.ToQueryExpression()
+}
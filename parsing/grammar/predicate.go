@@ -1,6 +1,7 @@
 package grammar
 
 import (
+	"simple-db-go/parsing/data"
 	"simple-db-go/query"
 	"simple-db-go/types"
 )
@@ -10,20 +11,52 @@ type Predicate struct {
 	Terms []*Term `@@ ( "AND" @@ )*`
 }
 
-// NOTE: SimpleDB では `=` だけサポートされる.
+// NOTE: SimpleDB では比較演算子 `=`, `<`, `<=`, `>`, `>=` がサポートされる.
+// 加えて、サブクエリを使った `IN (SELECT ...)`, `EXISTS (SELECT ...)`, `= (SELECT ...)` と、`IS [NOT] NULL` をサポートする.
 type Term struct {
-	FieldName  types.FieldName   `@Ident "=" `
-	Expression GrammarExpression `@@`
+	Exists     *Query             `  "EXISTS" "(" @@ ")"`
+	FieldName  types.FieldName    `| @Ident`
+	IsNull     *IsNull            `  ( "IS" @@`
+	In         *Query             `  | "IN" "(" @@ ")"`
+	Operator   ComparisonOperator `  | @( "=" | "<=" | ">=" | "<" | ">" )`
+	Subquery   *Query             `    ( "(" @@ ")"`
+	Expression GrammarExpression  `    | @@ ) )`
+}
+
+type ComparisonOperator query.ComparisonOperator
+
+// `IS NULL` または `IS NOT NULL`.
+type IsNull struct {
+	Not bool `@"NOT"? "NULL"`
 }
 
 func (p *Predicate) ToQueryPredicate() *query.Predicate {
 	queryTerms := make([]*query.Term, 0, len(p.Terms))
 	for _, grammarTerm := range p.Terms {
-		queryTerms = append(queryTerms, query.NewTerm(
-			query.NewFieldNameExpression(grammarTerm.FieldName),
-			grammarTerm.Expression.ToQueryExpression(),
-		))
+		queryTerms = append(queryTerms, grammarTerm.toQueryTerm())
 	}
 
 	return query.NewPredicateFrom(queryTerms)
 }
+
+func (t *Term) toQueryTerm() *query.Term {
+	switch {
+	case t.Exists != nil:
+		return query.NewExistsTerm(newSubqueryExpression(t.Exists))
+	case t.IsNull != nil && t.IsNull.Not:
+		return query.NewIsNotNullTerm(query.NewFieldNameExpression(t.FieldName))
+	case t.IsNull != nil:
+		return query.NewIsNullTerm(query.NewFieldNameExpression(t.FieldName))
+	case t.In != nil:
+		return query.NewInTerm(query.NewFieldNameExpression(t.FieldName), newSubqueryExpression(t.In))
+	case t.Subquery != nil:
+		return query.NewComparisonTerm(query.ComparisonOperator(t.Operator), query.NewFieldNameExpression(t.FieldName), newSubqueryExpression(t.Subquery))
+	default:
+		return query.NewComparisonTerm(query.ComparisonOperator(t.Operator), query.NewFieldNameExpression(t.FieldName), t.Expression.ToQueryExpression())
+	}
+}
+
+func newSubqueryExpression(q *Query) *query.SubqueryExpression {
+	// NOTE: Query.ToData は必ず QueryData を返す.
+	return query.NewSubqueryExpression(q.ToData().(*data.QueryData))
+}
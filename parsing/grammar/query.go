@@ -2,15 +2,167 @@ package grammar
 
 import (
 	"simple-db-go/parsing/data"
+	"simple-db-go/query"
 	"simple-db-go/types"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 )
 
 var _ Statement = (*Query)(nil)
 
+// SELECT 文. UNION などの集合演算で複数の SELECT 文をつなげることができ、LIMIT はその結果全体に適用される.
+// INTERSECT は UNION, EXCEPT より優先され、同じ優先度の演算は左から順に適用する.
 type Query struct {
-	FieldNames []types.FieldName `"SELECT" @Ident ( "," @Ident )*`
-	Queryables []data.Queryable  `"FROM" @Ident ( "," @Ident )*`
-	Where      *Predicate        `( "WHERE" @@ ( "AND" @@ )* )? ";"?`
+	With          *With           `( "WITH" @@ )?`
+	Select        *SelectCore     `@@`
+	SetOperations []*SetOperation `@@*`
+	Limit         *Limit          `( "LIMIT" @@ )? ";"?`
+}
+
+// `WITH [RECURSIVE] name (field, ...) AS (SELECT ...), ...`
+type With struct {
+	Recursive    bool           `@"RECURSIVE"?`
+	CommonTables []*CommonTable `@@ ( "," @@ )*`
+}
+
+type CommonTable struct {
+	Name       data.Queryable    `@Ident`
+	FieldNames []types.FieldName `( "(" @Ident ( "," @Ident )* ")" )?`
+	Query      *Query            `"AS" "(" @@ ")"`
+}
+
+// 集合演算でつなげる、1つ1つの SELECT 文.
+type SelectCore struct {
+	Distinct    bool          `"SELECT" @"DISTINCT"?`
+	SelectItems []*SelectItem `@@ ( "," @@ )*`
+	FromItems   []*FromItem   `"FROM" @@ ( "," @@ )*`
+	Where       *Predicate    `( "WHERE" @@ ( "AND" @@ )* )?`
+}
+
+// `UNION ALL SELECT ...` のように、集合演算で後ろにつなげる SELECT 文.
+type SetOperation struct {
+	Operator SetOperator `@( "UNION" | "INTERSECT" | "EXCEPT" )`
+	All      bool        `@"ALL"?`
+	Select   *SelectCore `@@`
+}
+
+// キーワードは大文字小文字を区別しないので、大文字にそろえておく.
+type SetOperator query.SetOperator
+
+func (o *SetOperator) Capture(values []string) error {
+	*o = SetOperator(strings.ToUpper(values[0]))
+	return nil
+}
+
+// FROM 句の各項目. テーブル名かビュー名、もしくは `(SELECT ...) AS t` のようなサブクエリ(derived table).
+// `information_schema.tables` のようにスキーマ名を付けた名前は、"." も含めて1つの名前になる.
+type FromItem struct {
+	Subquery *Query         `( "(" @@ ")" "AS"? )?`
+	Name     data.Queryable `@Ident ( @"." @Ident )?`
+}
+
+type Limit struct {
+	Count  types.Int `@( "-"? Int )`
+	Offset types.Int `( "OFFSET" @( "-"? Int ) )?`
+}
+
+// SELECT 句の各項目. フィールド名か集約関数、window 関数のいずれか.
+type SelectItem struct {
+	RankingFunction         *RankingFunction         `  @@`
+	OffsetFunction          *OffsetFunction          `| @@`
+	AggregateWindowFunction *AggregateWindowFunction `| @@`
+	Aggregation             *Aggregation             `| @@`
+	FieldName               types.FieldName          `| @Ident`
+}
+
+// `COUNT(DISTINCT a)` や `MAX(a)` のような集約関数の呼び出し.
+type Aggregation struct {
+	FunctionName AggregationFunctionName `@@ "("`
+	Distinct     bool                    `@"DISTINCT"?`
+	FieldName    types.FieldName         `@( Ident | "*" ) ")"`
+}
+
+// `SUM(a) OVER (...)` のような、集約関数を window ごとに計算する window 関数.
+type AggregateWindowFunction struct {
+	FunctionName AggregateWindowFunctionName `@@ "("`
+	Distinct     bool                        `@"DISTINCT"?`
+	FieldName    types.FieldName             `@( Ident | "*" ) ")"`
+	Over         *Over                       `"OVER" @@`
+}
+
+// `ROW_NUMBER() OVER (...)` のような、引数をとらない順位付けの window 関数.
+type RankingFunction struct {
+	FunctionName RankingFunctionName `@@ "(" ")"`
+	Over         *Over               `"OVER" @@`
+}
+
+// `LAG(a, 1, 0) OVER (...)` のような、前後のレコードの値を読む window 関数.
+type OffsetFunction struct {
+	FunctionName OffsetFunctionName `@@ "("`
+	FieldName    types.FieldName    `@Ident`
+	Offset       *types.Int         `( "," @( "-"? Int )`
+	Default      Constant           `( "," @@ )? )? ")"`
+	Over         *Over              `"OVER" @@`
+}
+
+// `OVER (PARTITION BY a, b ORDER BY c DESC, d)`
+type Over struct {
+	PartitionFields []types.FieldName `"(" ( "PARTITION" "BY" @Ident ( "," @Ident )* )?`
+	OrderItems      []*OrderItem      `( "ORDER" "BY" @@ ( "," @@ )* )? ")"`
+}
+
+type OrderItem struct {
+	FieldName  types.FieldName `@Ident`
+	Descending bool            `( @"DESC" | "ASC" )?`
+}
+
+// 集約関数の名前. 関数名はキーワードにせず、同名のフィールドを使えるようにしておく.
+// Capture は構文木ができた後で呼ばれるので、関数名で構文を選び分けられるように Parseable として実装する.
+// MAX は `WHERE a = (SELECT MAX(a) FROM ...)` のようなスカラーサブクエリで使う.
+type AggregationFunctionName string
+
+func (n *AggregationFunctionName) Parse(lex *lexer.PeekingLexer) error {
+	name, err := parseFunctionName(lex, "count", "max")
+	*n = AggregationFunctionName(name)
+	return err
+}
+
+// window 関数の名前. 集約関数と同じく、同名のフィールドを使えるようにキーワードにはしない.
+type AggregateWindowFunctionName string
+
+func (n *AggregateWindowFunctionName) Parse(lex *lexer.PeekingLexer) error {
+	name, err := parseFunctionName(lex, "count", "sum", "max", "min")
+	*n = AggregateWindowFunctionName(name)
+	return err
+}
+
+type RankingFunctionName string
+
+func (n *RankingFunctionName) Parse(lex *lexer.PeekingLexer) error {
+	name, err := parseFunctionName(lex, "row_number", "rank", "dense_rank")
+	*n = RankingFunctionName(name)
+	return err
+}
+
+type OffsetFunctionName string
+
+func (n *OffsetFunctionName) Parse(lex *lexer.PeekingLexer) error {
+	name, err := parseFunctionName(lex, "lag", "lead")
+	*n = OffsetFunctionName(name)
+	return err
+}
+
+// 次のトークンが names のいずれかであれば読み進める. そうでなければ、他の構文を試すように participle.NextMatch を返す.
+func parseFunctionName(lex *lexer.PeekingLexer, names ...string) (string, error) {
+	name := strings.ToLower(lex.Peek().Value)
+	if !slices.Contains(names, name) {
+		return "", participle.NextMatch
+	}
+	lex.Next()
+	return name, nil
 }
 
 type FieldNameList struct {
@@ -24,17 +176,126 @@ type TableNameList struct {
 func (*Query) GrammarStatement() {}
 
 func (q *Query) ToData() data.SQLData {
-	if q.Where == nil {
-		return &data.QueryData{
-			FieldNames: q.FieldNames,
-			Queryables: q.Queryables,
-			Predicate:  nil,
+	queryData := q.Select.toData()
+
+	if q.With != nil {
+		queryData.With = &data.WithData{Recursive: q.With.Recursive}
+		for _, commonTable := range q.With.CommonTables {
+			queryData.With.CommonTables = append(queryData.With.CommonTables, &data.CommonTableData{
+				Name:       commonTable.Name,
+				FieldNames: commonTable.FieldNames,
+				Query:      commonTable.Query.ToData().(*data.QueryData),
+			})
 		}
 	}
 
-	return &data.QueryData{
-		FieldNames: q.FieldNames,
-		Queryables: q.Queryables,
-		Predicate:  q.Where.ToQueryPredicate(),
+	// INTERSECT は UNION, EXCEPT より優先するので、UNION, EXCEPT の後に続く INTERSECT は、その右辺の SetOperations に入れる.
+	// `a UNION b INTERSECT c` は `a UNION (b INTERSECT c)` になる.
+	for _, setOperation := range q.SetOperations {
+		operator := query.SetOperator(setOperation.Operator)
+		setOperationData := &data.SetOperationData{
+			Operator: operator,
+			All:      setOperation.All,
+			Query:    setOperation.Select.toData(),
+		}
+
+		if last := len(queryData.SetOperations) - 1; operator == query.INTERSECT && last >= 0 {
+			rhs := queryData.SetOperations[last].Query
+			rhs.SetOperations = append(rhs.SetOperations, setOperationData)
+			continue
+		}
+		queryData.SetOperations = append(queryData.SetOperations, setOperationData)
+	}
+
+	if q.Limit != nil {
+		queryData.Limit = &data.LimitData{Count: q.Limit.Count, Offset: q.Limit.Offset}
+	}
+
+	return queryData
+}
+
+func (q *SelectCore) toData() *data.QueryData {
+	fieldNames := make([]types.FieldName, 0, len(q.SelectItems))
+	var aggregationFunctions []query.AggregationFunction
+	var windowFunctions []query.WindowFunction
+	for _, item := range q.SelectItems {
+		// 関数名は Capture で検証済みなので、エラーにはならない.
+		switch {
+		case item.RankingFunction != nil:
+			fn, _ := query.NewRankingWindowFunction(string(item.RankingFunction.FunctionName), item.RankingFunction.Over.toWindow())
+			fieldNames = append(fieldNames, fn.GetFieldName())
+			windowFunctions = append(windowFunctions, fn)
+		case item.OffsetFunction != nil:
+			fn := item.OffsetFunction.toWindowFunction()
+			fieldNames = append(fieldNames, fn.GetFieldName())
+			windowFunctions = append(windowFunctions, fn)
+		case item.AggregateWindowFunction != nil:
+			fn, _ := query.NewAggregationFunction(string(item.AggregateWindowFunction.FunctionName), item.AggregateWindowFunction.FieldName, item.AggregateWindowFunction.Distinct)
+			windowFunction := query.NewAggregateWindowFunction(fn, item.AggregateWindowFunction.Over.toWindow())
+			fieldNames = append(fieldNames, windowFunction.GetFieldName())
+			windowFunctions = append(windowFunctions, windowFunction)
+		case item.Aggregation != nil:
+			fn, _ := query.NewAggregationFunction(string(item.Aggregation.FunctionName), item.Aggregation.FieldName, item.Aggregation.Distinct)
+			fieldNames = append(fieldNames, fn.GetFieldName())
+			aggregationFunctions = append(aggregationFunctions, fn)
+		default:
+			fieldNames = append(fieldNames, item.FieldName)
+		}
+	}
+
+	queryables := make([]data.Queryable, 0, len(q.FromItems))
+	var derivedTables map[data.Queryable]*data.QueryData
+	for _, item := range q.FromItems {
+		queryables = append(queryables, item.Name)
+		if item.Subquery == nil {
+			continue
+		}
+
+		// 同じ別名が複数ある場合は、Queryables に重複が残るので planner でエラーにする.
+		if derivedTables == nil {
+			derivedTables = make(map[data.Queryable]*data.QueryData)
+		}
+		derivedTables[item.Name] = item.Subquery.ToData().(*data.QueryData)
+	}
+
+	queryData := &data.QueryData{
+		Distinct:             q.Distinct,
+		FieldNames:           fieldNames,
+		AggregationFunctions: aggregationFunctions,
+		WindowFunctions:      windowFunctions,
+		Queryables:           queryables,
+		DerivedTables:        derivedTables,
+		Predicate:            nil,
+	}
+
+	if q.Where != nil {
+		queryData.Predicate = q.Where.ToQueryPredicate()
+	}
+
+	return queryData
+}
+
+func (f *OffsetFunction) toWindowFunction() query.WindowFunction {
+	offset := query.DEFAULT_WINDOW_OFFSET
+	if f.Offset != nil {
+		offset = *f.Offset
+	}
+
+	var defaultValue query.Constant
+	if f.Default != nil {
+		defaultValue = f.Default.ToQueryConstant()
+	}
+
+	fn, _ := query.NewOffsetWindowFunction(string(f.FunctionName), f.FieldName, offset, defaultValue, f.Over.toWindow())
+	return fn
+}
+
+func (o *Over) toWindow() *query.Window {
+	orderFields := make([]types.FieldName, 0, len(o.OrderItems))
+	descending := make([]bool, 0, len(o.OrderItems))
+	for _, item := range o.OrderItems {
+		orderFields = append(orderFields, item.FieldName)
+		descending = append(descending, item.Descending)
 	}
+	return query.NewWindow(o.PartitionFields, orderFields, descending)
 }
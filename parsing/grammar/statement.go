@@ -20,6 +20,12 @@ func StatementUnion() participle.Option {
 		&CreateTableCmd{},
 		&CreateViewCmd{},
 		&CreateIndexCmd{},
+		&DropTableCmd{},
+		&DropViewCmd{},
+		&DropIndexCmd{},
+		&AlterTableCmd{},
+		&TruncateTableCmd{},
+		&AnalyzeTableCmd{},
 		&Commit{},
 		&Rollback{},
 	)
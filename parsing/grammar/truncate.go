@@ -0,0 +1,18 @@
+package grammar
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/types"
+)
+
+type TruncateTableCmd struct {
+	TableName types.TableName `"TRUNCATE" "TABLE" @Ident ";"?`
+}
+
+func (*TruncateTableCmd) GrammarUpdateCmd() {}
+func (*TruncateTableCmd) GrammarStatement() {}
+func (t *TruncateTableCmd) ToData() data.SQLData {
+	return &data.TruncateTableData{
+		TableName: t.TableName,
+	}
+}
@@ -16,29 +16,54 @@ func UpdateCmdUnion() participle.Option {
 		&CreateTableCmd{},
 		&CreateViewCmd{},
 		&CreateIndexCmd{},
+		&DropTableCmd{},
+		&DropViewCmd{},
+		&DropIndexCmd{},
+		&AlterTableCmd{},
+		&TruncateTableCmd{},
+		&AnalyzeTableCmd{},
 	)
 }
 
 type UpdateCmd interface{ GrammarUpdateCmd() }
 
+// `INSERT INTO t (a, b) VALUES (1, 2), (3, 4)` または `INSERT INTO t (a, b) SELECT ...`.
 type InsertCmd struct {
 	TableName  types.TableName   `"INSERT" "INTO" @Ident`
 	FieldNames []types.FieldName `"(" @Ident ( "," @Ident )* ")"`
-	Constants  []Constant        `"VALUES" "(" @@ ( "," @@ )* ")" ";"?`
+	Rows       []*InsertRow      `( "VALUES" @@ ( "," @@ )* ";"?`
+	Query      *Query            `| @@ )`
+}
+
+// VALUES に指定する1レコード分の値.
+type InsertRow struct {
+	Constants []Constant `"(" @@ ( "," @@ )* ")"`
 }
 
 func (*InsertCmd) GrammarUpdateCmd() {}
 func (*InsertCmd) GrammarStatement() {}
 func (i *InsertCmd) ToData() data.SQLData {
-	values := make([]query.Constant, 0, len(i.Constants))
-	for _, c := range i.Constants {
-		values = append(values, c.ToQueryConstant())
+	if i.Query != nil {
+		return &data.InsertData{
+			TableName:  i.TableName,
+			FieldNames: i.FieldNames,
+			Query:      i.Query.ToData().(*data.QueryData),
+		}
+	}
+
+	rows := make([][]query.Constant, 0, len(i.Rows))
+	for _, row := range i.Rows {
+		values := make([]query.Constant, 0, len(row.Constants))
+		for _, c := range row.Constants {
+			values = append(values, c.ToQueryConstant())
+		}
+		rows = append(rows, values)
 	}
 
 	return &data.InsertData{
 		TableName:  i.TableName,
 		FieldNames: i.FieldNames,
-		Values:     values,
+		Rows:       rows,
 	}
 }
 
@@ -64,28 +89,39 @@ func (d *DeleteCmd) ToData() data.SQLData {
 }
 
 type ModifyCmd struct {
-	TableName  types.TableName   `"UPDATE" @Ident`
-	FieldName  types.FieldName   `"SET" @Ident "="`
-	Expression GrammarExpression `@@`
-	Where      *Predicate        `( "WHERE" @@ ( "AND" @@ )* )? ";"?`
+	TableName   types.TableName `"UPDATE" @Ident`
+	Assignments []*Assignment   `"SET" @@ ( "," @@ )*`
+	Where       *Predicate      `( "WHERE" @@ ( "AND" @@ )* )? ";"?`
+}
+
+// SET 句の `field = expression`. 右辺には四則演算を書ける.
+type Assignment struct {
+	FieldName  types.FieldName       `@Ident "="`
+	Expression *ArithmeticExpression `@@`
 }
 
 func (*ModifyCmd) GrammarUpdateCmd() {}
 func (*ModifyCmd) GrammarStatement() {}
 func (m *ModifyCmd) ToData() data.SQLData {
+	assignments := make([]*data.AssignmentData, 0, len(m.Assignments))
+	for _, assignment := range m.Assignments {
+		assignments = append(assignments, &data.AssignmentData{
+			FieldName: assignment.FieldName,
+			NewValue:  assignment.Expression.ToQueryExpression(),
+		})
+	}
+
 	if m.Where == nil {
 		return &data.ModifyData{
-			TableName: m.TableName,
-			FieldName: m.FieldName,
-			NewValue:  m.Expression.ToQueryExpression(),
-			Predicate: nil,
+			TableName:   m.TableName,
+			Assignments: assignments,
+			Predicate:   nil,
 		}
 	}
 
 	return &data.ModifyData{
-		TableName: m.TableName,
-		FieldName: m.FieldName,
-		NewValue:  m.Expression.ToQueryExpression(),
-		Predicate: m.Where.ToQueryPredicate(),
+		TableName:   m.TableName,
+		Assignments: assignments,
+		Predicate:   m.Where.ToQueryPredicate(),
 	}
 }
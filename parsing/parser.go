@@ -3,35 +3,46 @@ package parsing
 import (
 	"simple-db-go/parsing/data"
 	"simple-db-go/parsing/grammar"
+	"simple-db-go/types"
+	"strings"
 
 	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
 )
 
 type Parser struct {
-	parser *participle.Parser[grammar.SimpleDBSQL]
+	parser           *participle.Parser[grammar.SimpleDBSQL]
+	constraintParser *participle.Parser[grammar.ColumnConstraint]
 }
 
 func NewParser() *Parser {
 	initLexer := lexer.MustSimple([]lexer.SimpleRule{
-		{Name: `Keyword`, Pattern: `(?i)\b(SELECT|FROM|WHERE|AND|AS|CREATE|INSERT|INTO|VALUES|UPDATE|SET|DELETE|INDEX|ON|VIEW|TABLE|INT|VARCHAR|COMMIT|ROLLBACK)\b`},
+		{Name: `Keyword`, Pattern: `(?i)\b(WITH|RECURSIVE|SELECT|DISTINCT|FROM|WHERE|AND|IN|EXISTS|IF|IS|NOT|NULL|BY|OVER|PARTITION|ORDER|ASC|DESC|UNION|INTERSECT|EXCEPT|ALL|LIMIT|OFFSET|AS|CREATE|INSERT|INTO|VALUES|UPDATE|SET|DELETE|INDEX|ON|VIEW|TABLE|INT|VARCHAR|DEFAULT|CHECK|PRIMARY|KEY|UNIQUE|AUTO_INCREMENT|FOREIGN|REFERENCES|RESTRICT|CASCADE|DROP|ALTER|ADD|COLUMN|RENAME|TO|TRUNCATE|ANALYZE|COMMIT|ROLLBACK)\b`},
 		{Name: `Ident`, Pattern: `[a-zA-Z][a-zA-Z_\d]*`},
-		{Name: `String`, Pattern: `'[^']*'|"[^"]*"`},
-		{Name: `Int`, Pattern: `-?[1-9][0-9]*`},
-		{Name: `Operators`, Pattern: `[,=;()]`},
+		{Name: `String`, Pattern: `'(?:[^']|'')*'|"(?:[^"]|"")*"`},
+		{Name: `Int`, Pattern: `0|[1-9][0-9]*`},
+		{Name: `Operators`, Pattern: `<=|>=|[,=;()*<>.+\-/]`},
 		{Name: `whitespace`, Pattern: `\s+`},
 	})
 
-	return &Parser{participle.MustBuild[grammar.SimpleDBSQL](
+	options := []participle.Option{
 		participle.Lexer(initLexer),
-		participle.Unquote("String"),
+		participle.Map(unquoteString, "String"),
 		participle.CaseInsensitive("Keyword"),
+		// `COUNT(DISTINCT a)` と `COUNT(DISTINCT a) OVER (...)` は OVER まで読まないと区別できないので、その分だけ先読みする.
+		participle.UseLookahead(6),
 		grammar.ExpressionUnion(),
 		grammar.UpdateCmdUnion(),
 		grammar.FieldDefUnion(),
 		grammar.ConstantUnion(),
 		grammar.StatementUnion(),
-	)}
+		grammar.AlterTableActionUnion(),
+	}
+
+	return &Parser{
+		parser:           participle.MustBuild[grammar.SimpleDBSQL](options...),
+		constraintParser: participle.MustBuild[grammar.ColumnConstraint](options...),
+	}
 }
 
 func (p *Parser) Parse(sql string) (data.SQLData, error) {
@@ -42,3 +53,20 @@ func (p *Parser) Parse(sql string) (data.SQLData, error) {
 
 	return parsedSql.Statement.ToData(), nil
 }
+
+// 文字列リテラルの前後の引用符を取り除く. SQL と同じく、文字列の中の引用符は2つ重ねて表す.
+func unquoteString(token lexer.Token) (lexer.Token, error) {
+	quote := token.Value[:1]
+	token.Value = strings.ReplaceAll(token.Value[1:len(token.Value)-1], quote+quote, quote)
+	return token, nil
+}
+
+// 制約カタログに保存した制約の定義をパースする.
+func (p *Parser) ParseConstraint(fieldName types.FieldName, constraintDef types.ConstraintDef) (*data.ColumnConstraint, error) {
+	constraint, err := p.constraintParser.ParseString("SimpleDB Constraint Parser", string(constraintDef))
+	if err != nil {
+		return nil, err
+	}
+
+	return constraint.ToData(fieldName), nil
+}
@@ -1,6 +1,7 @@
 package parsing
 
 import (
+	"simple-db-go/constants"
 	"simple-db-go/parsing/data"
 	"simple-db-go/query"
 	"simple-db-go/record"
@@ -36,6 +37,15 @@ func TestParserParseQuery(t *testing.T) {
 			},
 			`SELECT id, name, age FROM users, orders;`,
 		},
+		{
+			`SELECT table_name FROM information_schema.tables`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"table_name"},
+				Queryables: []data.Queryable{"information_schema.tables"},
+				Predicate:  nil,
+			},
+			`SELECT table_name FROM information_schema.tables;`,
+		},
 		{
 			`SELECT id, name, age FROM users WHERE name = 'hoge'`,
 			&data.QueryData{
@@ -50,6 +60,26 @@ func TestParserParseQuery(t *testing.T) {
 			},
 			`SELECT id, name, age FROM users WHERE name = 'hoge';`,
 		},
+		{
+			`SELECT id FROM users WHERE name = 'it''s' AND nickname = "say ""hi"""`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateFrom(
+					[]*query.Term{
+						query.NewTerm(
+							query.NewFieldNameExpression("name"),
+							query.NewStrConstant("it's"),
+						),
+						query.NewTerm(
+							query.NewFieldNameExpression("nickname"),
+							query.NewStrConstant(`say "hi"`),
+						),
+					},
+				),
+			},
+			`SELECT id FROM users WHERE name = 'it''s' AND nickname = 'say "hi"';`,
+		},
 		{
 			`SELECT id, name, age FROM users WHERE id = 1;`,
 			&data.QueryData{
@@ -84,6 +114,442 @@ func TestParserParseQuery(t *testing.T) {
 			},
 			`SELECT id, name, age FROM users WHERE id = 1 AND name = 'hoge';`,
 		},
+		{
+			`select distinct name, age from users`,
+			&data.QueryData{
+				Distinct:   true,
+				FieldNames: []types.FieldName{"name", "age"},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+			},
+			`SELECT DISTINCT name, age FROM users;`,
+		},
+		{
+			`SELECT COUNT(DISTINCT age), count(*), max(name) FROM users WHERE id = 1`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"count(distinct age)", "count(*)", "max(name)"},
+				AggregationFunctions: []query.AggregationFunction{
+					query.NewCountFunction("age", true),
+					query.NewCountFunction("*", false),
+					query.NewMaxFunction("name"),
+				},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("id"),
+						query.NewIntConstant(1),
+					),
+				),
+			},
+			`SELECT count(distinct age), count(*), max(name) FROM users WHERE id = 1;`,
+		},
+		{
+			`SELECT id FROM users WHERE age = 0 LIMIT 10 OFFSET 20;`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("age"),
+						query.NewIntConstant(0),
+					),
+				),
+				Limit: &data.LimitData{Count: 10, Offset: 20},
+			},
+			`SELECT id FROM users WHERE age = 0 LIMIT 10 OFFSET 20;`,
+		},
+		{
+			`select id from users limit 5`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+				Limit:      &data.LimitData{Count: 5, Offset: 0},
+			},
+			`SELECT id FROM users LIMIT 5;`,
+		},
+		{
+			`SELECT id, ROW_NUMBER() OVER (PARTITION BY age ORDER BY name DESC, id), rank() over (order by age asc) FROM users`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{
+					"id",
+					"row_number() over (partition by age order by name desc, id)",
+					"rank() over (order by age)",
+				},
+				WindowFunctions: []query.WindowFunction{
+					query.NewRowNumberFunction(query.NewWindow([]types.FieldName{"age"}, []types.FieldName{"name", "id"}, []bool{true, false})),
+					query.NewRankFunction(query.NewWindow(nil, []types.FieldName{"age"}, []bool{false})),
+				},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+			},
+			`SELECT id, row_number() over (partition by age order by name desc, id), rank() over (order by age) FROM users;`,
+		},
+		{
+			`SELECT lag(name) OVER (ORDER BY id), LEAD(age, 2, 0) OVER (ORDER BY id), sum(age) OVER (PARTITION BY name ORDER BY id), count(*) OVER () FROM users`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{
+					"lag(name) over (order by id)",
+					"lead(age, 2, 0) over (order by id)",
+					"sum(age) over (partition by name order by id)",
+					"count(*) over ()",
+				},
+				WindowFunctions: []query.WindowFunction{
+					query.NewLagFunction("name", 1, nil, query.NewWindow(nil, []types.FieldName{"id"}, []bool{false})),
+					query.NewLeadFunction("age", 2, query.NewIntConstant(0), query.NewWindow(nil, []types.FieldName{"id"}, []bool{false})),
+					query.NewAggregateWindowFunction(
+						query.NewSumFunction("age", false),
+						query.NewWindow([]types.FieldName{"name"}, []types.FieldName{"id"}, []bool{false}),
+					),
+					query.NewAggregateWindowFunction(
+						query.NewCountFunction("*", false),
+						query.NewWindow(nil, []types.FieldName{}, []bool{}),
+					),
+				},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+			},
+			`SELECT lag(name) over (order by id), lead(age, 2, 0) over (order by id), sum(age) over (partition by name order by id), count(*) over () FROM users;`,
+		},
+		{
+			`SELECT rank, lag FROM users`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"rank", "lag"},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+			},
+			`SELECT rank, lag FROM users;`,
+		},
+		{
+			`SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE amount = 100)`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"name"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateWith(
+					query.NewInTerm(
+						query.NewFieldNameExpression("id"),
+						query.NewSubqueryExpression(&data.QueryData{
+							FieldNames: []types.FieldName{"user_id"},
+							Queryables: []data.Queryable{"orders"},
+							Predicate: query.NewPredicateWith(
+								query.NewTerm(
+									query.NewFieldNameExpression("amount"),
+									query.NewIntConstant(100),
+								),
+							),
+						}),
+					),
+				),
+			},
+			`SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE amount = 100);`,
+		},
+		{
+			`select name from users where exists (select order_id from orders where user_id = id) and age = 20`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"name"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateFrom([]*query.Term{
+					query.NewExistsTerm(
+						query.NewSubqueryExpression(&data.QueryData{
+							FieldNames: []types.FieldName{"order_id"},
+							Queryables: []data.Queryable{"orders"},
+							Predicate: query.NewPredicateWith(
+								query.NewTerm(
+									query.NewFieldNameExpression("user_id"),
+									query.NewFieldNameExpression("id"),
+								),
+							),
+						}),
+					),
+					query.NewTerm(
+						query.NewFieldNameExpression("age"),
+						query.NewIntConstant(20),
+					),
+				}),
+			},
+			`SELECT name FROM users WHERE EXISTS (SELECT order_id FROM orders WHERE user_id = id) AND age = 20;`,
+		},
+		{
+			`SELECT name FROM users WHERE age = (SELECT max(age) FROM users);`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"name"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("age"),
+						query.NewSubqueryExpression(&data.QueryData{
+							FieldNames:           []types.FieldName{"max(age)"},
+							AggregationFunctions: []query.AggregationFunction{query.NewMaxFunction("age")},
+							Queryables:           []data.Queryable{"users"},
+						}),
+					),
+				),
+			},
+			`SELECT name FROM users WHERE age = (SELECT max(age) FROM users);`,
+		},
+		{
+			`SELECT name, amount FROM (SELECT user_id, amount FROM orders WHERE amount = 100) AS big_orders, users WHERE user_id = id`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"name", "amount"},
+				Queryables: []data.Queryable{"big_orders", "users"},
+				DerivedTables: map[data.Queryable]*data.QueryData{
+					"big_orders": {
+						FieldNames: []types.FieldName{"user_id", "amount"},
+						Queryables: []data.Queryable{"orders"},
+						Predicate: query.NewPredicateWith(
+							query.NewTerm(
+								query.NewFieldNameExpression("amount"),
+								query.NewIntConstant(100),
+							),
+						),
+					},
+				},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("user_id"),
+						query.NewFieldNameExpression("id"),
+					),
+				),
+			},
+			`SELECT name, amount FROM (SELECT user_id, amount FROM orders WHERE amount = 100) AS big_orders, users WHERE user_id = id;`,
+		},
+		{
+			`select id from (select id from users) u`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"u"},
+				DerivedTables: map[data.Queryable]*data.QueryData{
+					"u": {
+						FieldNames: []types.FieldName{"id"},
+						Queryables: []data.Queryable{"users"},
+					},
+				},
+			},
+			`SELECT id FROM (SELECT id FROM users) AS u;`,
+		},
+		{
+			`SELECT id FROM users UNION ALL SELECT user_id FROM orders union select id from admins LIMIT 10`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				SetOperations: []*data.SetOperationData{
+					{
+						Operator: query.UNION,
+						All:      true,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"user_id"},
+							Queryables: []data.Queryable{"orders"},
+						},
+					},
+					{
+						Operator: query.UNION,
+						All:      false,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"id"},
+							Queryables: []data.Queryable{"admins"},
+						},
+					},
+				},
+				Limit: &data.LimitData{Count: 10, Offset: 0},
+			},
+			`SELECT id FROM users UNION ALL SELECT user_id FROM orders UNION SELECT id FROM admins LIMIT 10;`,
+		},
+		{
+			`SELECT id FROM users WHERE age = 20 INTERSECT SELECT user_id FROM orders EXCEPT ALL SELECT id FROM admins;`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("age"),
+						query.NewIntConstant(20),
+					),
+				),
+				SetOperations: []*data.SetOperationData{
+					{
+						Operator: query.INTERSECT,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"user_id"},
+							Queryables: []data.Queryable{"orders"},
+						},
+					},
+					{
+						Operator: query.EXCEPT,
+						All:      true,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"id"},
+							Queryables: []data.Queryable{"admins"},
+						},
+					},
+				},
+			},
+			`SELECT id FROM users WHERE age = 20 INTERSECT SELECT user_id FROM orders EXCEPT ALL SELECT id FROM admins;`,
+		},
+		{
+			`SELECT id FROM users UNION SELECT user_id FROM orders INTERSECT SELECT id FROM admins EXCEPT SELECT id FROM guests`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				SetOperations: []*data.SetOperationData{
+					{
+						Operator: query.UNION,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"user_id"},
+							Queryables: []data.Queryable{"orders"},
+							SetOperations: []*data.SetOperationData{
+								{
+									Operator: query.INTERSECT,
+									Query: &data.QueryData{
+										FieldNames: []types.FieldName{"id"},
+										Queryables: []data.Queryable{"admins"},
+									},
+								},
+							},
+						},
+					},
+					{
+						Operator: query.EXCEPT,
+						Query: &data.QueryData{
+							FieldNames: []types.FieldName{"id"},
+							Queryables: []data.Queryable{"guests"},
+						},
+					},
+				},
+			},
+			`SELECT id FROM users UNION SELECT user_id FROM orders INTERSECT SELECT id FROM admins EXCEPT SELECT id FROM guests;`,
+		},
+		{
+			`WITH RECURSIVE subordinates (sub_id) AS (SELECT id FROM users WHERE id = 1 UNION ALL SELECT id FROM users, subordinates WHERE manager_id = sub_id) SELECT name FROM users, subordinates WHERE id = sub_id`,
+			&data.QueryData{
+				With: &data.WithData{
+					Recursive: true,
+					CommonTables: []*data.CommonTableData{
+						{
+							Name:       "subordinates",
+							FieldNames: []types.FieldName{"sub_id"},
+							Query: &data.QueryData{
+								FieldNames: []types.FieldName{"id"},
+								Queryables: []data.Queryable{"users"},
+								Predicate: query.NewPredicateWith(
+									query.NewTerm(
+										query.NewFieldNameExpression("id"),
+										query.NewIntConstant(1),
+									),
+								),
+								SetOperations: []*data.SetOperationData{
+									{
+										Operator: query.UNION,
+										All:      true,
+										Query: &data.QueryData{
+											FieldNames: []types.FieldName{"id"},
+											Queryables: []data.Queryable{"users", "subordinates"},
+											Predicate: query.NewPredicateWith(
+												query.NewTerm(
+													query.NewFieldNameExpression("manager_id"),
+													query.NewFieldNameExpression("sub_id"),
+												),
+											),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				FieldNames: []types.FieldName{"name"},
+				Queryables: []data.Queryable{"users", "subordinates"},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("id"),
+						query.NewFieldNameExpression("sub_id"),
+					),
+				),
+			},
+			`WITH RECURSIVE subordinates (sub_id) AS (SELECT id FROM users WHERE id = 1 UNION ALL SELECT id FROM users, subordinates WHERE manager_id = sub_id) SELECT name FROM users, subordinates WHERE id = sub_id;`,
+		},
+		{
+			`with adults as (select id from users where age = 20), admins as (select id from adults) select id from admins;`,
+			&data.QueryData{
+				With: &data.WithData{
+					CommonTables: []*data.CommonTableData{
+						{
+							Name: "adults",
+							Query: &data.QueryData{
+								FieldNames: []types.FieldName{"id"},
+								Queryables: []data.Queryable{"users"},
+								Predicate: query.NewPredicateWith(
+									query.NewTerm(
+										query.NewFieldNameExpression("age"),
+										query.NewIntConstant(20),
+									),
+								),
+							},
+						},
+						{
+							Name: "admins",
+							Query: &data.QueryData{
+								FieldNames: []types.FieldName{"id"},
+								Queryables: []data.Queryable{"adults"},
+							},
+						},
+					},
+				},
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"admins"},
+			},
+			`WITH adults AS (SELECT id FROM users WHERE age = 20), admins AS (SELECT id FROM adults) SELECT id FROM admins;`,
+		},
+		{
+			`SELECT count FROM users`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"count"},
+				Queryables: []data.Queryable{"users"},
+				Predicate:  nil,
+			},
+			`SELECT count FROM users;`,
+		},
+		{
+			`SELECT id FROM users WHERE name IS NULL AND age is not null AND id = NULL`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateFrom(
+					[]*query.Term{
+						query.NewIsNullTerm(query.NewFieldNameExpression("name")),
+						query.NewIsNotNullTerm(query.NewFieldNameExpression("age")),
+						query.NewTerm(
+							query.NewFieldNameExpression("id"),
+							query.NewNullConstant(),
+						),
+					},
+				),
+			},
+			`SELECT id FROM users WHERE name IS NULL AND age IS NOT NULL AND id = NULL;`,
+		},
+		{
+			`SELECT id FROM users WHERE age >= 20 AND age<30 AND name > 'm' AND id <= (SELECT id FROM admins) AND score < 100`,
+			&data.QueryData{
+				FieldNames: []types.FieldName{"id"},
+				Queryables: []data.Queryable{"users"},
+				Predicate: query.NewPredicateFrom(
+					[]*query.Term{
+						query.NewComparisonTerm(query.GREATER_EQUAL, query.NewFieldNameExpression("age"), query.NewIntConstant(20)),
+						query.NewComparisonTerm(query.LESS_THAN, query.NewFieldNameExpression("age"), query.NewIntConstant(30)),
+						query.NewComparisonTerm(query.GREATER_THAN, query.NewFieldNameExpression("name"), query.NewStrConstant("m")),
+						query.NewComparisonTerm(
+							query.LESS_EQUAL,
+							query.NewFieldNameExpression("id"),
+							query.NewSubqueryExpression(&data.QueryData{
+								FieldNames: []types.FieldName{"id"},
+								Queryables: []data.Queryable{"admins"},
+							}),
+						),
+						query.NewComparisonTerm(query.LESS_THAN, query.NewFieldNameExpression("score"), query.NewIntConstant(100)),
+					},
+				),
+			},
+			`SELECT id FROM users WHERE age >= 20 AND age < 30 AND name > 'm' AND id <= (SELECT id FROM admins) AND score < 100;`,
+		},
 	}
 
 	for i, test := range tests {
@@ -96,6 +562,19 @@ func TestParserParseQuery(t *testing.T) {
 	}
 }
 
+func TestParserParseQueryWithUnknownAggregationFunction(t *testing.T) {
+	parser := NewParser()
+
+	_, err := parser.Parse(`SELECT avg(age) FROM users`)
+	assert.Error(t, err, "サポートしていない集約関数はパースエラーになること.")
+
+	_, err = parser.Parse(`SELECT sum(age) FROM users`)
+	assert.Error(t, err, "window 関数としてだけ使える関数は、OVER が無ければパースエラーになること.")
+
+	_, err = parser.Parse(`SELECT age, count(*) FROM users GROUP BY age`)
+	assert.Error(t, err, "GROUP BY はサポートしていないのでパースエラーになること.")
+}
+
 func TestParserParseInsert(t *testing.T) {
 	parser := NewParser()
 
@@ -108,7 +587,7 @@ func TestParserParseInsert(t *testing.T) {
 			&data.InsertData{
 				TableName:  "users",
 				FieldNames: []types.FieldName{"id"},
-				Values:     []query.Constant{query.NewIntConstant(1)},
+				Rows:       [][]query.Constant{{query.NewIntConstant(1)}},
 			},
 		},
 		{
@@ -116,10 +595,48 @@ func TestParserParseInsert(t *testing.T) {
 			&data.InsertData{
 				TableName:  "users",
 				FieldNames: []types.FieldName{"id", "name", "age"},
-				Values: []query.Constant{
-					query.NewIntConstant(1),
-					query.NewStrConstant("hoge"),
-					query.NewIntConstant(20),
+				Rows: [][]query.Constant{
+					{
+						query.NewIntConstant(1),
+						query.NewStrConstant("hoge"),
+						query.NewIntConstant(20),
+					},
+				},
+			},
+		},
+		{
+			`INSERT INTO users (id, name) VALUES (1, NULL)`,
+			&data.InsertData{
+				TableName:  "users",
+				FieldNames: []types.FieldName{"id", "name"},
+				Rows: [][]query.Constant{
+					{
+						query.NewIntConstant(1),
+						query.NewNullConstant(),
+					},
+				},
+			},
+		},
+		{
+			`INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b');`,
+			&data.InsertData{
+				TableName:  "users",
+				FieldNames: []types.FieldName{"id", "name"},
+				Rows: [][]query.Constant{
+					{query.NewIntConstant(1), query.NewStrConstant("a")},
+					{query.NewIntConstant(2), query.NewStrConstant("b")},
+				},
+			},
+		},
+		{
+			`INSERT INTO users (id, name) SELECT uid, uname FROM members WHERE age = 20;`,
+			&data.InsertData{
+				TableName:  "users",
+				FieldNames: []types.FieldName{"id", "name"},
+				Query: &data.QueryData{
+					FieldNames: []types.FieldName{"uid", "uname"},
+					Queryables: []data.Queryable{"members"},
+					Predicate:  query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("age"), query.NewIntConstant(20))),
 				},
 			},
 		},
@@ -200,8 +717,9 @@ func TestParserParseModify(t *testing.T) {
 			`UPDATE users SET name = 'hoge';`,
 			&data.ModifyData{
 				TableName: "users",
-				FieldName: "name",
-				NewValue:  query.NewStrConstant("hoge"),
+				Assignments: []*data.AssignmentData{
+					{FieldName: "name", NewValue: query.NewStrConstant("hoge")},
+				},
 				Predicate: nil,
 			},
 		},
@@ -209,8 +727,9 @@ func TestParserParseModify(t *testing.T) {
 			`update orders set quantity = 10 WHERE id = 1`,
 			&data.ModifyData{
 				TableName: "orders",
-				FieldName: "quantity",
-				NewValue:  query.NewIntConstant(10),
+				Assignments: []*data.AssignmentData{
+					{FieldName: "quantity", NewValue: query.NewIntConstant(10)},
+				},
 				Predicate: query.NewPredicateWith(
 					query.NewTerm(
 						query.NewFieldNameExpression("id"),
@@ -223,8 +742,9 @@ func TestParserParseModify(t *testing.T) {
 			`update menus set tag = 'piyo' where id = 1 and name = 'fuga'`,
 			&data.ModifyData{
 				TableName: "menus",
-				FieldName: "tag",
-				NewValue:  query.NewStrConstant("piyo"),
+				Assignments: []*data.AssignmentData{
+					{FieldName: "tag", NewValue: query.NewStrConstant("piyo")},
+				},
 				Predicate: query.NewPredicateFrom(
 					[]*query.Term{
 						query.NewTerm(
@@ -239,6 +759,57 @@ func TestParserParseModify(t *testing.T) {
 				),
 			},
 		},
+		{
+			`UPDATE counters SET b = b + 1, c = (b - 2) * c / 3, d = d-1 WHERE id = -1`,
+			&data.ModifyData{
+				TableName: "counters",
+				Assignments: []*data.AssignmentData{
+					{
+						FieldName: "b",
+						NewValue:  query.NewArithmeticExpression(query.ADD, query.NewFieldNameExpression("b"), query.NewIntConstant(1)),
+					},
+					{
+						FieldName: "c",
+						NewValue: query.NewArithmeticExpression(
+							query.DIVIDE,
+							query.NewArithmeticExpression(
+								query.MULTIPLY,
+								query.NewArithmeticExpression(query.SUBTRACT, query.NewFieldNameExpression("b"), query.NewIntConstant(2)),
+								query.NewFieldNameExpression("c"),
+							),
+							query.NewIntConstant(3),
+						),
+					},
+					{
+						FieldName: "d",
+						NewValue:  query.NewArithmeticExpression(query.SUBTRACT, query.NewFieldNameExpression("d"), query.NewIntConstant(1)),
+					},
+				},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("id"),
+						query.NewIntConstant(-1),
+					),
+				),
+			},
+		},
+		{
+			`UPDATE users SET name = nickname, nickname = name, age = 20 WHERE id = 1`,
+			&data.ModifyData{
+				TableName: "users",
+				Assignments: []*data.AssignmentData{
+					{FieldName: "name", NewValue: query.NewFieldNameExpression("nickname")},
+					{FieldName: "nickname", NewValue: query.NewFieldNameExpression("name")},
+					{FieldName: "age", NewValue: query.NewIntConstant(20)},
+				},
+				Predicate: query.NewPredicateWith(
+					query.NewTerm(
+						query.NewFieldNameExpression("id"),
+						query.NewIntConstant(1),
+					),
+				),
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -285,6 +856,97 @@ func TestParserParseCreateTable(t *testing.T) {
 				return &data.CreateTableData{TableName: "users", Schema: schema}
 			}(),
 		},
+		{
+			`CREATE TABLE IF NOT EXISTS users (id INT);`,
+			func() *data.CreateTableData {
+				schema := record.NewSchema()
+				schema.AddIntField("id")
+				return &data.CreateTableData{TableName: "users", Schema: schema, IfNotExists: true}
+			}(),
+		},
+		{
+			`CREATE TABLE users (id INT NOT NULL, name VARCHAR(10) DEFAULT 'anon' not null, age INT CHECK (age IS NOT NULL AND age = 20) default NULL)`,
+			func() *data.CreateTableData {
+				schema := record.NewSchema()
+				schema.AddIntField("id")
+				schema.AddStringField("name", 10)
+				schema.AddIntField("age")
+				return &data.CreateTableData{
+					TableName: "users",
+					Schema:    schema,
+					Constraints: []*data.ColumnConstraint{
+						{FieldName: "id", Type: constants.NOT_NULL},
+						{FieldName: "name", Type: constants.DEFAULT, DefaultValue: query.NewStrConstant("anon")},
+						{FieldName: "name", Type: constants.NOT_NULL},
+						{
+							FieldName: "age",
+							Type:      constants.CHECK,
+							Check: query.NewPredicateFrom([]*query.Term{
+								query.NewIsNotNullTerm(query.NewFieldNameExpression("age")),
+								query.NewTerm(query.NewFieldNameExpression("age"), query.NewIntConstant(20)),
+							}),
+						},
+						{FieldName: "age", Type: constants.DEFAULT, DefaultValue: query.NewNullConstant()},
+					},
+				}
+			}(),
+		},
+		{
+			`CREATE TABLE users (id INT, email VARCHAR(20) UNIQUE, PRIMARY KEY (id), code INT, unique (code))`,
+			func() *data.CreateTableData {
+				schema := record.NewSchema()
+				schema.AddIntField("id")
+				schema.AddStringField("email", 20)
+				schema.AddIntField("code")
+				return &data.CreateTableData{
+					TableName: "users",
+					Schema:    schema,
+					Constraints: []*data.ColumnConstraint{
+						{FieldName: "email", Type: constants.UNIQUE},
+						{FieldName: "id", Type: constants.PRIMARY_KEY},
+						{FieldName: "code", Type: constants.UNIQUE},
+					},
+				}
+			}(),
+		},
+		{
+			`CREATE TABLE users (id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(10))`,
+			func() *data.CreateTableData {
+				schema := record.NewSchema()
+				schema.AddIntField("id")
+				schema.AddStringField("name", 10)
+				return &data.CreateTableData{
+					TableName: "users",
+					Schema:    schema,
+					Constraints: []*data.ColumnConstraint{
+						{FieldName: "id", Type: constants.AUTO_INCREMENT},
+						{FieldName: "id", Type: constants.PRIMARY_KEY},
+					},
+				}
+			}(),
+		},
+		{
+			`CREATE TABLE orders (id INT PRIMARY KEY, user_id INT REFERENCES users(id), item_id INT, parent_id INT, FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE, foreign key (parent_id) references orders(id) on delete set null)`,
+			func() *data.CreateTableData {
+				schema := record.NewSchema()
+				schema.AddIntField("id")
+				schema.AddIntField("user_id")
+				schema.AddIntField("item_id")
+				schema.AddIntField("parent_id")
+				return &data.CreateTableData{
+					TableName: "orders",
+					Schema:    schema,
+					Constraints: []*data.ColumnConstraint{
+						{FieldName: "id", Type: constants.PRIMARY_KEY},
+					},
+					ForeignKeys: []*data.ForeignKey{
+						{FieldName: "user_id", RefTableName: "users", RefFieldName: "id", OnDelete: constants.RESTRICT},
+						{FieldName: "item_id", RefTableName: "items", RefFieldName: "id", OnDelete: constants.CASCADE},
+						{FieldName: "parent_id", RefTableName: "orders", RefFieldName: "id", OnDelete: constants.SET_NULL},
+					},
+				}
+			}(),
+		},
 	}
 
 	for i, test := range tests {
@@ -296,6 +958,39 @@ func TestParserParseCreateTable(t *testing.T) {
 	}
 }
 
+func TestParserParseConstraint(t *testing.T) {
+	parser := NewParser()
+
+	t.Run("制約カタログに保存した定義から、元の制約を復元できること.", func(t *testing.T) {
+		constraints := []*data.ColumnConstraint{
+			{FieldName: "id", Type: constants.NOT_NULL},
+			{FieldName: "id", Type: constants.PRIMARY_KEY},
+			{FieldName: "email", Type: constants.UNIQUE},
+			{FieldName: "id", Type: constants.AUTO_INCREMENT},
+			{FieldName: "name", Type: constants.DEFAULT, DefaultValue: query.NewStrConstant("anon")},
+			{FieldName: "name", Type: constants.DEFAULT, DefaultValue: query.NewStrConstant("it's")},
+			{FieldName: "age", Type: constants.DEFAULT, DefaultValue: query.NewIntConstant(-1)},
+			{
+				FieldName: "age",
+				Type:      constants.CHECK,
+				Check:     query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("age"), query.NewIntConstant(20))),
+			},
+		}
+
+		for i, constraint := range constraints {
+			result, err := parser.ParseConstraint(constraint.FieldName, constraint.GetConstraintDef())
+			if assert.NoErrorf(t, err, "[i=%d] パースエラーが起きないこと.", i) {
+				assert.Equalf(t, constraint, result, "[i=%d] 制約が復元できること. def=%s", i, constraint.GetConstraintDef())
+			}
+		}
+	})
+
+	t.Run("制約ではない定義はパースエラーになること.", func(t *testing.T) {
+		_, err := parser.ParseConstraint("id", "INDEX")
+		assert.Error(t, err)
+	})
+}
+
 func TestParserParseCreateView(t *testing.T) {
 	parser := NewParser()
 
@@ -345,6 +1040,19 @@ func TestParserParseCreateView(t *testing.T) {
 			},
 			`SELECT id, name FROM users;`,
 		},
+		{
+			`CREATE VIEW IF NOT EXISTS view1 AS SELECT id FROM users;`,
+			&data.CreateViewData{
+				ViewName: "view1",
+				QueryData: &data.QueryData{
+					FieldNames: []types.FieldName{"id"},
+					Queryables: []data.Queryable{"users"},
+					Predicate:  nil,
+				},
+				IfNotExists: true,
+			},
+			`SELECT id FROM users;`,
+		},
 	}
 
 	for i, test := range tests {
@@ -380,6 +1088,15 @@ func TestParserParseCreateIndex(t *testing.T) {
 				FieldName: "id",
 			},
 		},
+		{
+			`CREATE INDEX IF NOT EXISTS idx1 ON users (id);`,
+			&data.CreateIndexData{
+				IndexName:   "idx1",
+				TableName:   "users",
+				FieldName:   "id",
+				IfNotExists: true,
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -391,6 +1108,131 @@ func TestParserParseCreateIndex(t *testing.T) {
 	}
 }
 
+func TestParserParseDrop(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		sql      string
+		expected data.SQLData
+	}{
+		{`DROP TABLE users;`, &data.DropTableData{TableName: "users"}},
+		{`drop table users`, &data.DropTableData{TableName: "users"}},
+		{`DROP VIEW user_view;`, &data.DropViewData{ViewName: "user_view"}},
+		{`DROP INDEX idx1 ON users;`, &data.DropIndexData{IndexName: "idx1", TableName: "users"}},
+		{`drop index idx1 on users`, &data.DropIndexData{IndexName: "idx1", TableName: "users"}},
+		{`DROP TABLE IF EXISTS users;`, &data.DropTableData{TableName: "users", IfExists: true}},
+		{`drop view if exists user_view`, &data.DropViewData{ViewName: "user_view", IfExists: true}},
+		{`DROP INDEX IF EXISTS idx1 ON users;`, &data.DropIndexData{IndexName: "idx1", TableName: "users", IfExists: true}},
+	}
+
+	for i, test := range tests {
+		result, err := parser.Parse(test.sql)
+		if assert.NoErrorf(t, err, "[i=%d] パースエラーが起きないこと.", i) {
+			assert.Equalf(t, test.expected, result, "[i=%d] DROP 文のデータが期待通りであること.", i)
+		}
+	}
+}
+
+func TestParserParseAlterTable(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		sql      string
+		expected *data.AlterTableData
+	}{
+		{
+			`ALTER TABLE users ADD COLUMN note VARCHAR(20) DEFAULT 'none';`,
+			&data.AlterTableData{
+				TableName: "users",
+				Action: &data.AddColumnData{
+					FieldName:   "note",
+					FieldType:   constants.VARCHAR,
+					FieldLength: 20,
+					Constraints: []*data.ColumnConstraint{
+						{FieldName: "note", Type: constants.DEFAULT, DefaultValue: query.NewStrConstant("none")},
+					},
+				},
+			},
+		},
+		{
+			`alter table users add dept_id int references depts(id)`,
+			&data.AlterTableData{
+				TableName: "users",
+				Action: &data.AddColumnData{
+					FieldName:   "dept_id",
+					FieldType:   constants.INTEGER,
+					FieldLength: record.INTEGER_FIELD_LENGTH,
+					ForeignKeys: []*data.ForeignKey{
+						{FieldName: "dept_id", RefTableName: "depts", RefFieldName: "id", OnDelete: constants.RESTRICT},
+					},
+				},
+			},
+		},
+		{
+			`ALTER TABLE users DROP COLUMN note;`,
+			&data.AlterTableData{TableName: "users", Action: &data.DropColumnData{FieldName: "note"}},
+		},
+		{
+			`ALTER TABLE users DROP note`,
+			&data.AlterTableData{TableName: "users", Action: &data.DropColumnData{FieldName: "note"}},
+		},
+		{
+			`ALTER TABLE users RENAME COLUMN name TO full_name;`,
+			&data.AlterTableData{TableName: "users", Action: &data.RenameColumnData{FieldName: "name", NewFieldName: "full_name"}},
+		},
+		{
+			`ALTER TABLE users RENAME TO members;`,
+			&data.AlterTableData{TableName: "users", Action: &data.RenameTableData{NewTableName: "members"}},
+		},
+	}
+
+	for i, test := range tests {
+		result, err := parser.Parse(test.sql)
+		if assert.NoErrorf(t, err, "[i=%d] パースエラーが起きないこと.", i) {
+			assert.IsTypef(t, &data.AlterTableData{}, result, "[i=%d] result が *AlterTableData であること.", i)
+			assert.Equalf(t, test.expected, result, "[i=%d] AlterTableData が期待通りであること.", i)
+		}
+	}
+}
+
+func TestParserParseTruncateTable(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		sql      string
+		expected *data.TruncateTableData
+	}{
+		{`TRUNCATE TABLE users;`, &data.TruncateTableData{TableName: "users"}},
+		{`truncate table users`, &data.TruncateTableData{TableName: "users"}},
+	}
+
+	for i, test := range tests {
+		result, err := parser.Parse(test.sql)
+		if assert.NoErrorf(t, err, "[i=%d] パースエラーが起きないこと.", i) {
+			assert.Equalf(t, test.expected, result, "[i=%d] TruncateTableData が期待通りであること.", i)
+		}
+	}
+}
+
+func TestParserParseAnalyzeTable(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		sql      string
+		expected *data.AnalyzeTableData
+	}{
+		{`ANALYZE TABLE users;`, &data.AnalyzeTableData{TableNames: []types.TableName{"users"}}},
+		{`analyze table users, orders`, &data.AnalyzeTableData{TableNames: []types.TableName{"users", "orders"}}},
+	}
+
+	for i, test := range tests {
+		result, err := parser.Parse(test.sql)
+		if assert.NoErrorf(t, err, "[i=%d] パースエラーが起きないこと.", i) {
+			assert.Equalf(t, test.expected, result, "[i=%d] AnalyzeTableData が期待通りであること.", i)
+		}
+	}
+}
+
 func TestParserParseCommit(t *testing.T) {
 	parser := NewParser()
 
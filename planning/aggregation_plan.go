@@ -0,0 +1,55 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*AggregationPlan)(nil)
+
+// 集約関数を処理する plan. 元の plan のレコード全体を1つのグループとして集約し、1レコードを出力する.
+type AggregationPlan struct {
+	plan                 query.Plan
+	aggregationFunctions []query.AggregationFunction
+	schema               *record.Schema
+}
+
+func NewAggregationPlan(plan query.Plan, aggregationFunctions []query.AggregationFunction) (query.Plan, error) {
+	schema := record.NewSchema()
+	for _, fn := range aggregationFunctions {
+		fieldType, fieldLength, err := fn.GetFieldType(plan.GetSchema())
+		if err != nil {
+			return nil, err
+		}
+		schema.AddField(fn.GetFieldName(), fieldType, fieldLength)
+	}
+
+	return &AggregationPlan{
+		plan:                 plan,
+		aggregationFunctions: aggregationFunctions,
+		schema:               schema,
+	}, nil
+}
+
+func (p *AggregationPlan) Open() query.Scan {
+	return query.NewGroupByScan(p.plan.Open(), nil, p.aggregationFunctions)
+}
+
+// 元の結果を1回読むだけなので、元の plan のコストと同じになる.
+func (p *AggregationPlan) GetBlocksAccessed() types.Int {
+	return p.plan.GetBlocksAccessed()
+}
+
+// レコードが無くても、集約した結果を1レコード出力する.
+func (p *AggregationPlan) GetRecordsOutput() types.Int {
+	return 1
+}
+
+func (p *AggregationPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return 1
+}
+
+func (p *AggregationPlan) GetSchema() *record.Schema {
+	return p.schema
+}
@@ -0,0 +1,318 @@
+package planning
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/metadata"
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"slices"
+)
+
+// ALTER TABLE は、テーブルのカタログを書き換えた後、既存のレコードを新しいレイアウトで書き直す.
+// 書き直しも同じトランザクションの中でログを残して行うので、ロールバックすると元のカタログとレコードに戻る.
+// 制約に違反するレコードがあれば何も変更しないように、カタログやレコードを書き換える前に全て確認する.
+//
+// NOTE: ビューの定義は書き換えないので、テーブル名やフィールド名を変更すると、それを参照しているビューは使えなくなる.
+func (up *BasicUpdatePlanner) ExecuteAlterTable(alterTableData *data.AlterTableData, transaction *transaction.Transaction) (types.Int, error) {
+	plan, err := NewTablePlan(transaction, alterTableData.TableName, up.metadataManager)
+	if err != nil {
+		return 0, err
+	}
+
+	switch action := alterTableData.Action.(type) {
+	case *data.AddColumnData:
+		return 0, up.addColumn(alterTableData.TableName, plan, action, transaction)
+	case *data.DropColumnData:
+		return 0, up.dropColumn(alterTableData.TableName, plan, action, transaction)
+	case *data.RenameColumnData:
+		return 0, up.renameColumn(alterTableData.TableName, plan, action, transaction)
+	case *data.RenameTableData:
+		return 0, up.renameTable(alterTableData.TableName, plan, action, transaction)
+	default:
+		// パーサーが返す操作は上のいずれかなので、ここには来ない.
+		panic(fmt.Sprintf("[ExecuteAlterTable] 想定していない ALTER TABLE の操作です. action=%+v", action))
+	}
+}
+
+// 既存のレコードの新しいフィールドは、DEFAULT の値か NULL にする. AUTO_INCREMENT の場合は 1 から順に採番する.
+func (up *BasicUpdatePlanner) addColumn(tableName types.TableName, plan query.Plan, addColumnData *data.AddColumnData, transaction *transaction.Transaction) error {
+	fieldName := addColumnData.FieldName
+	if plan.GetSchema().HasField(fieldName) {
+		return DuplicateFieldError{tableName, fieldName}
+	}
+
+	schema := record.NewSchema()
+	schema.AddAll(plan.GetSchema())
+	schema.AddField(fieldName, addColumnData.FieldType, addColumnData.FieldLength)
+
+	currentConstraints, err := newTableConstraints(tableName, up.metadataManager, transaction)
+	if err != nil {
+		return err
+	}
+	constraints := &tableConstraints{
+		tableName:   tableName,
+		constraints: append(slices.Clone(currentConstraints.constraints), addColumnData.Constraints...),
+	}
+
+	if err := validateColumnConstraints(schema, constraints.constraints); err != nil {
+		return err
+	}
+
+	createTableData := &data.CreateTableData{TableName: tableName, Schema: schema, Constraints: constraints.constraints, ForeignKeys: addColumnData.ForeignKeys}
+	if err := validateForeignKeys(createTableData, up.metadataManager, transaction); err != nil {
+		return err
+	}
+
+	keyIndexes := newKeyIndexes(tableName, addColumnData.Constraints)
+	for _, index := range keyIndexes {
+		if up.metadataManager.IndexExists(index.IndexName, tableName, transaction) {
+			return metadata.IndexAlreadyExistsError{IndexName: index.IndexName, TableName: tableName}
+		}
+	}
+
+	recordIDs, rows, err := readTableRows(plan)
+	if err != nil {
+		return err
+	}
+
+	_, isAutoIncrement := autoIncrementField(addColumnData.Constraints)
+	fieldNames := schema.Fields()
+	for i := range rows {
+		value := constraints.defaultValue(fieldName)
+		if isAutoIncrement {
+			value = query.NewIntConstant(types.Int(i + 1))
+		}
+		rows[i] = append(rows[i], value)
+
+		if err := validateFieldValues(tableName, schema, rows[i]); err != nil {
+			return err
+		}
+
+		if err := constraints.validate(query.NewRowScan(fieldNames, rows[i])); err != nil {
+			return err
+		}
+	}
+
+	// 既存のレコードは全て rows に置き換わるので、rows の中で重複しないかだけを確認する.
+	if err := constraints.validateUniqueness(plan, []types.FieldName{fieldName}, fieldNames, rows, recordIDs); err != nil {
+		return err
+	}
+
+	foreignKeys := &tableForeignKeys{
+		tableName:       tableName,
+		references:      newForeignKeyCatalogRows(tableName, addColumnData.ForeignKeys),
+		metadataManager: up.metadataManager,
+		transaction:     transaction,
+	}
+	if err := foreignKeys.validateReferences([]types.FieldName{fieldName}, fieldNames, rows); err != nil {
+		return err
+	}
+
+	layout := record.NewLayout(schema)
+	if err := up.metadataManager.AlterTable(tableName, layout, transaction); err != nil {
+		return err
+	}
+	up.metadataManager.CreateConstraints(newConstraintCatalogRows(tableName, addColumnData.Constraints), transaction)
+	up.metadataManager.CreateForeignKeys(foreignKeys.references, transaction)
+	if isAutoIncrement {
+		up.metadataManager.CreateAutoIncrement(tableName, fieldName, transaction)
+		up.metadataManager.SetAutoIncrementNextValue(tableName, types.Int(len(rows)+1), transaction)
+	}
+	for _, index := range keyIndexes {
+		if _, err := up.ExecuteCreateIndex(index, transaction); err != nil {
+			return err
+		}
+	}
+
+	return rewriteTable(tableName, layout, rows, transaction)
+}
+
+// フィールドの制約、インデックス、AUTO_INCREMENT も一緒に削除する.
+// 外部キーに使われているフィールドと、他のフィールドの CHECK 制約で使われているフィールドは削除できない.
+func (up *BasicUpdatePlanner) dropColumn(tableName types.TableName, plan query.Plan, dropColumnData *data.DropColumnData, transaction *transaction.Transaction) error {
+	fieldName := dropColumnData.FieldName
+	if err := validateFieldNames(tableName, plan.GetSchema(), []types.FieldName{fieldName}); err != nil {
+		return err
+	}
+	if len(plan.GetSchema().Fields()) == 1 {
+		return CannotDropAllFieldsError{tableName}
+	}
+
+	for _, foreignKey := range up.metadataManager.GetForeignKeys(tableName, transaction) {
+		if foreignKey.FieldName == fieldName {
+			return FieldInForeignKeyError{tableName, fieldName, foreignKeyString(foreignKey)}
+		}
+	}
+	for _, foreignKey := range up.metadataManager.GetReferencingForeignKeys(tableName, transaction) {
+		if foreignKey.RefFieldName == fieldName {
+			return FieldInForeignKeyError{tableName, fieldName, foreignKeyString(foreignKey)}
+		}
+	}
+
+	schema := record.NewSchema()
+	for _, name := range plan.GetSchema().Fields() {
+		if name != fieldName {
+			schema.Add(name, plan.GetSchema())
+		}
+	}
+
+	currentConstraints, err := newTableConstraints(tableName, up.metadataManager, transaction)
+	if err != nil {
+		return err
+	}
+	constraints := []*data.ColumnConstraint{}
+	for _, constraint := range currentConstraints.constraints {
+		if constraint.FieldName == fieldName {
+			continue
+		}
+		if constraint.Type == constants.CHECK && !constraint.Check.AppliesTo(schema) {
+			return FieldInCheckConstraintError{tableName, fieldName, constraint.Check.ToString()}
+		}
+		constraints = append(constraints, constraint)
+	}
+
+	_, rows, err := readTableRows(plan)
+	if err != nil {
+		return err
+	}
+	index := slices.Index(plan.GetSchema().Fields(), fieldName)
+	for i := range rows {
+		rows[i] = slices.Delete(rows[i], index, index+1)
+	}
+
+	layout := record.NewLayout(schema)
+	if err := up.metadataManager.DropField(tableName, fieldName, layout, transaction); err != nil {
+		return err
+	}
+	up.metadataManager.ReplaceConstraints(tableName, newConstraintCatalogRows(tableName, constraints), transaction)
+
+	return rewriteTable(tableName, layout, rows, transaction)
+}
+
+// フィールド名を変更するだけで、レイアウトのオフセットは変わらないので、レコードは書き直さない.
+// CHECK 制約の定義に含まれるフィールド名も変更する.
+func (up *BasicUpdatePlanner) renameColumn(tableName types.TableName, plan query.Plan, renameColumnData *data.RenameColumnData, transaction *transaction.Transaction) error {
+	fieldName, newFieldName := renameColumnData.FieldName, renameColumnData.NewFieldName
+	if err := validateFieldNames(tableName, plan.GetSchema(), []types.FieldName{fieldName}); err != nil {
+		return err
+	}
+	if plan.GetSchema().HasField(newFieldName) {
+		return DuplicateFieldError{tableName, newFieldName}
+	}
+
+	schema := record.NewSchema()
+	for _, name := range plan.GetSchema().Fields() {
+		fieldType, _ := plan.GetSchema().FieldType(name)
+		length, _ := plan.GetSchema().Length(name)
+		if name == fieldName {
+			name = newFieldName
+		}
+		schema.AddField(name, fieldType, length)
+	}
+
+	constraints, err := newTableConstraints(tableName, up.metadataManager, transaction)
+	if err != nil {
+		return err
+	}
+	for _, constraint := range constraints.constraints {
+		if constraint.FieldName == fieldName {
+			constraint.FieldName = newFieldName
+		}
+		if constraint.Type == constants.CHECK {
+			constraint.Check.ReplaceFieldNames(func(expression query.FieldNameExpression) query.Expression {
+				if expression.GetFieldName() == fieldName {
+					return query.NewFieldNameExpression(newFieldName)
+				}
+				return expression
+			})
+		}
+	}
+
+	// フィールド名が長くなると、CHECK 制約の定義が保存できる長さを超えることがある.
+	if err := validateColumnConstraints(schema, constraints.constraints); err != nil {
+		return err
+	}
+
+	if err := up.metadataManager.RenameField(tableName, fieldName, newFieldName, transaction); err != nil {
+		return err
+	}
+	up.metadataManager.ReplaceConstraints(tableName, newConstraintCatalogRows(tableName, constraints.constraints), transaction)
+	return nil
+}
+
+// テーブルのファイル名はテーブル名から決まるので、レコードを新しいテーブルのファイルに書き直す.
+// 元のテーブルのファイルは、コミットした時に削除される.
+func (up *BasicUpdatePlanner) renameTable(tableName types.TableName, plan query.Plan, renameTableData *data.RenameTableData, transaction *transaction.Transaction) error {
+	newTableName := renameTableData.NewTableName
+	if transaction.IsRemovedOnCommit(query.TableFileName(newTableName)) {
+		return TableDroppedInTransactionError{newTableName}
+	}
+
+	_, rows, err := readTableRows(plan)
+	if err != nil {
+		return err
+	}
+
+	if err := up.metadataManager.RenameTable(tableName, newTableName, transaction); err != nil {
+		return err
+	}
+
+	layout, err := up.metadataManager.GetLayout(newTableName, transaction)
+	if err != nil {
+		return err
+	}
+	return rewriteTable(newTableName, layout, rows, transaction)
+}
+
+// テーブルの全てのレコードを読み込む. 値は plan のスキーマのフィールドと位置で対応する.
+func readTableRows(plan query.Plan) ([]record.RecordID, [][]query.Constant, error) {
+	// NOTE: plan は TablePlan なので、UpdateScan として扱える.
+	tableScan := plan.Open().(query.UpdateScan)
+	defer tableScan.Close()
+
+	fieldNames := plan.GetSchema().Fields()
+	recordIDs := []record.RecordID{}
+	rows := [][]query.Constant{}
+	for tableScan.Next() {
+		values := make([]query.Constant, 0, len(fieldNames))
+		for _, fieldName := range fieldNames {
+			value, err := tableScan.GetValue(fieldName)
+			if err != nil {
+				return nil, nil, err
+			}
+			values = append(values, value)
+		}
+		recordIDs = append(recordIDs, tableScan.GetCurrentRecordID())
+		rows = append(rows, values)
+	}
+	return recordIDs, rows, nil
+}
+
+// テーブルのファイルを空にしてから、rows を layout で書き込む. rows は layout のフィールドと位置で対応する.
+// レコードを1件ずつ削除すると、テーブルの大きさに比例したログが残るので、TRUNCATE と同じようにファイルごと空にする.
+func rewriteTable(tableName types.TableName, layout *record.Layout, rows [][]query.Constant, transaction *transaction.Transaction) error {
+	transaction.TruncateFile(query.TableFileName(tableName))
+
+	tableScan := query.NewTableScan(transaction, tableName, layout)
+	defer tableScan.Close()
+
+	fieldNames := layout.GetSchema().Fields()
+	for _, values := range rows {
+		tableScan.Insert()
+		for i, fieldName := range fieldNames {
+			if err := tableScan.SetValue(fieldName, values[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func foreignKeyString(row metadata.ForeignKeyCatalogRow) string {
+	foreignKey := &data.ForeignKey{FieldName: row.FieldName, RefTableName: row.RefTableName, RefFieldName: row.RefFieldName, OnDelete: row.OnDelete}
+	return fmt.Sprintf("%s.%s", row.TableName, foreignKey.ToString())
+}
@@ -0,0 +1,97 @@
+package planning
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlterTable(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, alterTableTestName)
+	mustExecuteUpdate(t, planner, "CREATE TABLE departments (id INT PRIMARY KEY)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT, age INT CHECK (age >= 0), max_age INT CHECK (max_age >= age), department_id INT REFERENCES departments(id))", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO departments (id) VALUES (1)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO users (id, age, max_age, department_id) VALUES (1, 20, 30, 1), (2, 40, 50, NULL)", transaction)
+	transaction.Commit()
+
+	t.Run("ADD COLUMN で、既存のレコードの新しいフィールドが DEFAULT の値になること.", func(t *testing.T) {
+		planner, transaction := newPlannerForTest(t, alterTableTestName)
+		mustExecuteUpdate(t, planner, "ALTER TABLE users ADD COLUMN level INT NOT NULL DEFAULT 1", transaction)
+		transaction.Commit()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		assert.Equal(t, []string{"1, 20, 1", "2, 40, 1"}, queryRows(t, planner, "SELECT id, age, level FROM users", transaction))
+
+		mustExecuteUpdate(t, planner, "ALTER TABLE users ADD COLUMN nickname VARCHAR(10) DEFAULT 'none'", transaction)
+		assert.Equal(t, []string{"1, 'none'", "2, 'none'"}, queryRows(t, planner, "SELECT id, nickname FROM users", transaction))
+		transaction.Rollback()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		defer transaction.Rollback()
+		_, err := planner.CreateQueryPlan("SELECT nickname FROM users", transaction)
+		assert.Error(t, err, "ロールバックすると、追加したフィールドは無くなる.")
+		assert.Equal(t, []string{"1, 20, 1", "2, 40, 1"}, queryRows(t, planner, "SELECT id, age, level FROM users", transaction))
+	})
+
+	t.Run("DROP COLUMN で、フィールドを削除したレコードに書き直されること.", func(t *testing.T) {
+		planner, transaction := newPlannerForTest(t, alterTableTestName)
+		_, err := planner.ExecuteUpdate("ALTER TABLE users DROP COLUMN age", transaction)
+		assert.IsType(t, FieldInCheckConstraintError{}, err, "他のフィールドの CHECK 制約で使われているフィールドは削除できない.")
+
+		_, err = planner.ExecuteUpdate("ALTER TABLE users DROP COLUMN department_id", transaction)
+		assert.IsType(t, FieldInForeignKeyError{}, err, "外部キーのフィールドは削除できない.")
+
+		mustExecuteUpdate(t, planner, "ALTER TABLE users DROP COLUMN level", transaction)
+		transaction.Commit()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		_, err = planner.CreateQueryPlan("SELECT level FROM users", transaction)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"1, 20, 30, 1", "2, 40, 50, NULL"}, queryRows(t, planner, "SELECT id, age, max_age, department_id FROM users", transaction))
+
+		mustExecuteUpdate(t, planner, "ALTER TABLE users DROP COLUMN max_age", transaction)
+		assert.Equal(t, []string{"1, 20, 1", "2, 40, NULL"}, queryRows(t, planner, "SELECT id, age, department_id FROM users", transaction))
+		transaction.Rollback()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		defer transaction.Rollback()
+		assert.Equal(t, []string{"1, 20, 30, 1", "2, 40, 50, NULL"}, queryRows(t, planner, "SELECT id, age, max_age, department_id FROM users", transaction), "ロールバックすると、削除したフィールドと値が元に戻る.")
+	})
+
+	t.Run("RENAME COLUMN で、CHECK 制約の定義のフィールド名も変更されること.", func(t *testing.T) {
+		planner, transaction := newPlannerForTest(t, alterTableTestName)
+		mustExecuteUpdate(t, planner, "ALTER TABLE users RENAME COLUMN age TO years", transaction)
+		transaction.Commit()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		assert.Equal(t, []string{"CHECK (years >= 0)", "CHECK (max_age >= years)"}, checkDefinitions(planner, "users", transaction))
+		assert.Equal(t, []string{"1, 20, 30", "2, 40, 50"}, queryRows(t, planner, "SELECT id, years, max_age FROM users", transaction))
+
+		_, err := planner.ExecuteUpdate("UPDATE users SET years = 35 WHERE id = 1", transaction)
+		assert.IsType(t, CheckConstraintViolationError{}, err, "変更したフィールド名で CHECK 制約が確認される.")
+
+		mustExecuteUpdate(t, planner, "ALTER TABLE users RENAME COLUMN years TO age", transaction)
+		assert.Equal(t, []string{"CHECK (age >= 0)", "CHECK (max_age >= age)"}, checkDefinitions(planner, "users", transaction))
+		transaction.Rollback()
+
+		planner, transaction = newPlannerForTest(t, alterTableTestName)
+		defer transaction.Rollback()
+		assert.Equal(t, []string{"CHECK (years >= 0)", "CHECK (max_age >= years)"}, checkDefinitions(planner, "users", transaction))
+		assert.Equal(t, []string{"1, 20, 30", "2, 40, 50"}, queryRows(t, planner, "SELECT id, years, max_age FROM users", transaction))
+	})
+}
+
+// テーブルの CHECK 制約の定義を、制約カタログに保存されている順に返す.
+func checkDefinitions(planner *Planner, tableName types.TableName, transaction *transaction.Transaction) []string {
+	metadataManager := planner.updatePlanner.(*BasicUpdatePlanner).metadataManager
+	definitions := []string{}
+	for _, row := range metadataManager.GetConstraints(tableName, transaction) {
+		if row.Type == constants.CHECK {
+			definitions = append(definitions, string(row.Definition))
+		}
+	}
+	return definitions
+}
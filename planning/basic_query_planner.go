@@ -19,9 +19,53 @@ func NewBasicQueryPlanner(metadataManager *metadata.MetadataManager) *BasicQuery
 }
 
 func (p *BasicQueryPlanner) CreatePlan(queryData *data.QueryData, transaction *transaction.Transaction) (query.Plan, error) {
+	return p.createPlan(queryData, transaction, nil, nil)
+}
+
+// outerQueries は、サブクエリを plan する場合の外側のクエリ.
+// commonTables は、外側のクエリの WITH 句で定義された名前付きのサブクエリ.
+func (p *BasicQueryPlanner) createPlan(queryData *data.QueryData, transaction *transaction.Transaction, outerQueries []outerQuery, commonTables *commonTableScope) (query.Plan, error) {
+	// Step0: WITH 句の定義を、このクエリの中で参照できるようにする.
+	commonTables = withCommonTables(transaction, queryData.With, commonTables, func(commonTableData *data.QueryData, commonTables *commonTableScope) (query.Plan, error) {
+		return p.createPlan(commonTableData, transaction, nil, commonTables)
+	})
+
 	// Step1: FROM 句で指定されるテーブル、ビューのプランを作る.
+	if err := validateQueryables(queryData.Queryables); err != nil {
+		return nil, err
+	}
 	plans := make([]query.Plan, 0, len(queryData.Queryables))
 	for _, queryable := range queryData.Queryables {
+		// FROM 句のサブクエリ(derived table)は、ビューと同じように再帰的に plan する.
+		if derivedTableData, ok := queryData.DerivedTables[queryable]; ok {
+			derivedTablePlan, err := p.createPlan(derivedTableData, transaction, nil, commonTables)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, derivedTablePlan)
+			continue
+		}
+
+		// WITH 句で定義された名前は、ビューやテーブルより優先する.
+		if createCommonTablePlan, ok := commonTables.lookup(queryable); ok {
+			commonTablePlan, err := createCommonTablePlan()
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, commonTablePlan)
+			continue
+		}
+
+		// information_schema のシステムビューは、カタログから組み立てる.
+		if isSystemView(queryable) {
+			systemViewPlan, err := newInformationSchemaPlan(queryable, transaction, p.metadataManager)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, systemViewPlan)
+			continue
+		}
+
 		viewDef, err := p.metadataManager.GetViewDef(queryable.ToViewName(), transaction)
 		if err == nil { // queryable is view.
 			parser := parsing.NewParser()
@@ -50,14 +94,66 @@ func (p *BasicQueryPlanner) CreatePlan(queryData *data.QueryData, transaction *t
 		plan = NewProductPlan(plan, newPlan)
 	}
 
-	// Step3: WHERE 句で指定される条件を適用する.
+	// Step3: WHERE 句に含まれるサブクエリの plan を作る.
+	err := planSubqueries(queryData.Predicate, plan.GetSchema(), outerQueries, func(subqueryData *data.QueryData, outerQueries []outerQuery) (query.Plan, error) {
+		return p.createPlan(subqueryData, transaction, outerQueries, commonTables)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step4: WHERE 句で指定される条件を適用する.
 	plan = NewSelectPlan(plan, queryData.Predicate)
 
-	// Step4: Projection する.
+	// Step5: 集約関数を適用する.
+	if queryData.HasAggregation() {
+		aggregationPlan, err := NewAggregationPlan(plan, queryData.AggregationFunctions)
+		if err != nil {
+			return nil, err
+		}
+		plan = aggregationPlan
+	}
+
+	// Step6: window 関数を適用する. 同じ window を使う関数は、1回のソートでまとめて計算する.
+	if len(queryData.WindowFunctions) > 0 {
+		windowPlan, err := newWindowPlans(transaction, plan, queryData.WindowFunctions)
+		if err != nil {
+			return nil, err
+		}
+		plan = windowPlan
+	}
+
+	// Step7: Projection する.
 	result, err := NewProjectPlan(plan, queryData.FieldNames)
 	if err != nil {
 		return nil, err
 	}
 
+	// Step8: DISTINCT が指定されていれば、重複したレコードを取り除く.
+	if queryData.Distinct {
+		result = NewDistinctPlan(transaction, result)
+	}
+
+	// Step9: UNION などの集合演算でつなげた SELECT 文を、左から順に適用する.
+	for _, setOperation := range queryData.SetOperations {
+		rhs, err := p.createPlan(setOperation.Query, transaction, outerQueries, commonTables)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = NewSetOperationPlan(transaction, setOperation.Operator, setOperation.All, result, rhs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Step10: LIMIT が指定されていれば、必要な数だけレコードを読み出す.
+	if queryData.Limit != nil {
+		result, err = NewLimitPlan(result, queryData.Limit.Count, queryData.Limit.Offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return result, nil
 }
@@ -4,8 +4,10 @@ import (
 	"simple-db-go/metadata"
 	"simple-db-go/parsing/data"
 	"simple-db-go/query"
+	"simple-db-go/record"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
+	"slices"
 )
 
 var _ UpdatePlanner = (*BasicUpdatePlanner)(nil)
@@ -24,6 +26,10 @@ func (up *BasicUpdatePlanner) ExecuteDelete(deleteData *data.DeleteData, transac
 		return 0, err
 	}
 
+	if err := up.planSubqueries(deleteData.Predicate, plan, transaction); err != nil {
+		return 0, err
+	}
+
 	plan = NewSelectPlan(plan, deleteData.Predicate)
 
 	// NOTE: テーブル名であることは、NewTablePlanが成功していることからわかる.
@@ -31,13 +37,24 @@ func (up *BasicUpdatePlanner) ExecuteDelete(deleteData *data.DeleteData, transac
 	updateScan := plan.Open().(query.UpdateScan)
 	defer updateScan.Close()
 
-	count := types.Int(0)
+	// 外部キーで参照されているレコードがあれば、ON DELETE の動作で削除・更新されるレコードも含めて先に集めてから削除する.
+	recordIDs := []record.RecordID{}
 	for updateScan.Next() {
-		updateScan.Delete()
-		count++
+		recordIDs = append(recordIDs, updateScan.GetCurrentRecordID())
+	}
+	if err := updateScan.Err(); err != nil {
+		return 0, err
+	}
+
+	deletion := newDeletion(up.metadataManager, transaction)
+	if err := deletion.add(deleteData.TableName, recordIDs); err != nil {
+		return 0, err
+	}
+	if err := deletion.execute(); err != nil {
+		return 0, err
 	}
 
-	return count, nil
+	return types.Int(len(recordIDs)), nil
 }
 
 func (up *BasicUpdatePlanner) ExecuteModify(modifyData *data.ModifyData, transaction *transaction.Transaction) (types.Int, error) {
@@ -46,6 +63,30 @@ func (up *BasicUpdatePlanner) ExecuteModify(modifyData *data.ModifyData, transac
 		return 0, err
 	}
 
+	constraints, err := newTableConstraints(modifyData.TableName, up.metadataManager, transaction)
+	if err != nil {
+		return 0, err
+	}
+
+	foreignKeys := newTableForeignKeys(modifyData.TableName, up.metadataManager, transaction)
+
+	changedFields := make([]types.FieldName, 0, len(modifyData.Assignments))
+	for _, assignment := range modifyData.Assignments {
+		if slices.Contains(changedFields, assignment.FieldName) {
+			return 0, DuplicateAssignmentError{modifyData.TableName, assignment.FieldName}
+		}
+		changedFields = append(changedFields, assignment.FieldName)
+	}
+
+	if err := validateFieldNames(modifyData.TableName, plan.GetSchema(), changedFields); err != nil {
+		return 0, err
+	}
+
+	if err := up.planSubqueries(modifyData.Predicate, plan, transaction); err != nil {
+		return 0, err
+	}
+
+	tablePlan := plan
 	plan = NewSelectPlan(plan, modifyData.Predicate)
 
 	// NOTE: テーブル名であることは、NewTablePlanが成功していることからわかる.
@@ -53,48 +94,350 @@ func (up *BasicUpdatePlanner) ExecuteModify(modifyData *data.ModifyData, transac
 	updateScan := plan.Open().(query.UpdateScan)
 	defer updateScan.Close()
 
-	count := types.Int(0)
+	// SET 句の右辺は、scan を1回読む間に更新前のレコードで全て評価するので、他の代入の結果には影響されない.
+	// ただし、PRIMARY KEY, UNIQUE や外部キーは更新する全てのレコードを見ないと確認できないので、書き込みは2回目の走査で行う.
+	// 制約を満たさないレコードが1件でもあれば何も更新しないように、書き込む前に全て確認しておく.
+	// 2回目の走査までは、レコードのレコード ID と、変更するフィールドの更新前・更新後の値だけを保持する.
+	fieldNames := plan.GetSchema().Fields()
+	recordIDs := []record.RecordID{}
+	oldRows := [][]query.Constant{}
+	rows := [][]query.Constant{}
 	for updateScan.Next() {
-		newValue, err := modifyData.NewValue.Evaluate(updateScan)
-		if err != nil {
+		newValues := make(map[types.FieldName]query.Constant, len(modifyData.Assignments))
+		for _, assignment := range modifyData.Assignments {
+			newValue, err := assignment.NewValue.Evaluate(updateScan)
+			if err != nil {
+				return 0, err
+			}
+			newValues[assignment.FieldName] = newValue
+		}
+
+		values := make([]query.Constant, 0, len(fieldNames))
+		for _, fieldName := range fieldNames {
+			value, err := updateScan.GetValue(fieldName)
+			if err != nil {
+				return 0, err
+			}
+			if newValue, exists := newValues[fieldName]; exists {
+				value = newValue
+			}
+			values = append(values, value)
+		}
+
+		if err := validateFieldValues(modifyData.TableName, plan.GetSchema(), values); err != nil {
 			return 0, err
 		}
-		updateScan.SetValue(modifyData.FieldName, newValue)
-		count++
+
+		if err := constraints.validate(query.NewRowScan(fieldNames, values)); err != nil {
+			return 0, err
+		}
+
+		oldValues := make([]query.Constant, 0, len(changedFields))
+		changedValues := make([]query.Constant, 0, len(changedFields))
+		for _, fieldName := range changedFields {
+			oldValue, err := updateScan.GetValue(fieldName)
+			if err != nil {
+				return 0, err
+			}
+			oldValues = append(oldValues, oldValue)
+			changedValues = append(changedValues, newValues[fieldName])
+		}
+
+		recordIDs = append(recordIDs, updateScan.GetCurrentRecordID())
+		oldRows = append(oldRows, oldValues)
+		rows = append(rows, changedValues)
+	}
+	if err := updateScan.Err(); err != nil {
+		return 0, err
+	}
+
+	// 以降の rows, oldRows は changedFields と位置で対応する.
+	if err := constraints.validateUniqueness(tablePlan, changedFields, changedFields, rows, recordIDs); err != nil {
+		return 0, err
+	}
+
+	if err := foreignKeys.validateReferences(changedFields, changedFields, rows); err != nil {
+		return 0, err
+	}
+
+	if err := foreignKeys.validateNotReferenced(changedFields, changedFields, oldRows, rows); err != nil {
+		return 0, err
+	}
+
+	advanceAutoIncrement(modifyData.TableName, changedFields, changedFields, rows, up.metadataManager, transaction)
+
+	for i, recordID := range recordIDs {
+		updateScan.MoveToRecordID(recordID)
+		for j, fieldName := range changedFields {
+			if err := updateScan.SetValue(fieldName, rows[i][j]); err != nil {
+				return 0, err
+			}
+		}
 	}
+	up.metadataManager.AddModifiedCount(modifyData.TableName, types.Int(len(recordIDs)))
+
+	return types.Int(len(recordIDs)), nil
+}
 
-	return count, nil
+// WHERE 句に含まれるサブクエリの plan を作る. サブクエリ自体は SELECT 文なので、query planner で plan する.
+func (up *BasicUpdatePlanner) planSubqueries(predicate *query.Predicate, plan query.Plan, transaction *transaction.Transaction) error {
+	queryPlanner := NewBasicQueryPlanner(up.metadataManager)
+	return planSubqueries(predicate, plan.GetSchema(), nil, func(subqueryData *data.QueryData, outerQueries []outerQuery) (query.Plan, error) {
+		return queryPlanner.createPlan(subqueryData, transaction, outerQueries, nil)
+	})
 }
 
-func (up *BasicUpdatePlanner) ExecuteInsert(insertData *data.InsertData, transaction *transaction.Transaction) (types.Int, error) {
+func (up *BasicUpdatePlanner) ExecuteInsert(insertData *data.InsertData, transaction *transaction.Transaction) (types.Int, types.Int, error) {
 	plan, err := NewTablePlan(transaction, insertData.TableName, up.metadataManager)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	updateScan := plan.Open().(query.UpdateScan)
-	defer updateScan.Close()
+	constraints, err := newTableConstraints(insertData.TableName, up.metadataManager, transaction)
+	if err != nil {
+		return 0, 0, err
+	}
 
-	updateScan.Insert()
+	if err := validateFieldNames(insertData.TableName, plan.GetSchema(), insertData.FieldNames); err != nil {
+		return 0, 0, err
+	}
 	for i, fieldName := range insertData.FieldNames {
-		value := insertData.Values[i]
-		updateScan.SetValue(fieldName, value)
+		if slices.Contains(insertData.FieldNames[:i], fieldName) {
+			return 0, 0, DuplicateInsertFieldError{insertData.TableName, fieldName}
+		}
+	}
+
+	inputRows := insertData.Rows
+	if insertData.Query != nil {
+		inputRows, err = up.readInsertQuery(insertData.Query, transaction)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// 指定されなかったフィールドは、DEFAULT の値か NULL にする.
+	fieldNames := plan.GetSchema().Fields()
+	rows := make([][]query.Constant, 0, len(inputRows))
+	for _, inputRow := range inputRows {
+		if len(inputRow) != len(insertData.FieldNames) {
+			return 0, 0, InsertValueCountError{insertData.TableName, len(insertData.FieldNames), len(inputRow)}
+		}
+
+		values := make([]query.Constant, 0, len(fieldNames))
+		for _, fieldName := range fieldNames {
+			if i := slices.Index(insertData.FieldNames, fieldName); i >= 0 {
+				values = append(values, inputRow[i])
+			} else {
+				values = append(values, constraints.defaultValue(fieldName))
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	lastInsertID := assignAutoIncrement(insertData.TableName, fieldNames, rows, up.metadataManager, transaction)
+
+	// 制約を満たさないレコードが1件でもあれば何も挿入しないように、先に全てのレコードを確認しておく.
+	for _, values := range rows {
+		if err := validateFieldValues(insertData.TableName, plan.GetSchema(), values); err != nil {
+			return 0, 0, err
+		}
+
+		if err := constraints.validate(query.NewRowScan(fieldNames, values)); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := constraints.validateUniqueness(plan, fieldNames, fieldNames, rows, nil); err != nil {
+		return 0, 0, err
+	}
+
+	foreignKeys := newTableForeignKeys(insertData.TableName, up.metadataManager, transaction)
+	if err := foreignKeys.validateReferences(fieldNames, fieldNames, rows); err != nil {
+		return 0, 0, err
 	}
 
-	return 1, nil
+	// NOTE: TablePlan の scan は TableScan なので、キャストして問題ない.
+	tableScan := plan.Open().(*query.TableScan)
+	defer tableScan.Close()
+
+	for _, values := range rows {
+		tableScan.Insert()
+		for i, fieldName := range fieldNames {
+			if err := tableScan.SetValue(fieldName, values[i]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	up.metadataManager.AddModifiedCount(insertData.TableName, types.Int(len(rows)))
+	up.metadataManager.AddStatDelta(insertData.TableName, tableScan.GetRecordsDelta(), tableScan.GetAppendedBlocks(), transaction)
+
+	return types.Int(len(rows)), lastInsertID, nil
+}
+
+// INSERT ... SELECT の SELECT 文を実行して、結果を全てメモリに読み込む.
+// 挿入先のテーブルを SELECT 文で読んでいる場合に、挿入したレコードを読まないように、挿入する前に全て読んでおく.
+func (up *BasicUpdatePlanner) readInsertQuery(queryData *data.QueryData, transaction *transaction.Transaction) ([][]query.Constant, error) {
+	plan, err := NewBasicQueryPlanner(up.metadataManager).CreatePlan(queryData, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := plan.Open()
+	defer scan.Close()
+
+	fieldNames := plan.GetSchema().Fields()
+	rows := [][]query.Constant{}
+	for scan.Next() {
+		values := make([]query.Constant, 0, len(fieldNames))
+		for _, fieldName := range fieldNames {
+			value, err := scan.GetValue(fieldName)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (up *BasicUpdatePlanner) ExecuteCreateTable(createTableData *data.CreateTableData, transaction *transaction.Transaction) (types.Int, error) {
+	// 制約の検証より先に確認して、既にあるテーブルの定義と違っていてもエラーにしない.
+	if createTableData.IfNotExists && up.metadataManager.TableOrViewExists(createTableData.TableName, transaction) {
+		return 0, nil
+	}
+
+	// 削除したテーブルのファイルはコミット時に消されるので、同じ名前のテーブルを作ってもデータが残らない.
+	if transaction.IsRemovedOnCommit(query.TableFileName(createTableData.TableName)) {
+		return 0, TableDroppedInTransactionError{createTableData.TableName}
+	}
+
+	if err := validateColumnConstraints(createTableData.Schema, createTableData.Constraints); err != nil {
+		return 0, err
+	}
+
+	if err := validateForeignKeys(createTableData, up.metadataManager, transaction); err != nil {
+		return 0, err
+	}
+
+	if err := up.metadataManager.CreateTable(createTableData.TableName, createTableData.Schema, transaction); err != nil {
+		return 0, err
+	}
+	up.metadataManager.CreateConstraints(newConstraintCatalogRows(createTableData.TableName, createTableData.Constraints), transaction)
+	up.metadataManager.CreateForeignKeys(newForeignKeyCatalogRows(createTableData.TableName, createTableData.ForeignKeys), transaction)
+	if fieldName, exists := autoIncrementField(createTableData.Constraints); exists {
+		up.metadataManager.CreateAutoIncrement(createTableData.TableName, fieldName, transaction)
+	}
+	for _, index := range newKeyIndexes(createTableData.TableName, createTableData.Constraints) {
+		if _, err := up.ExecuteCreateIndex(index, transaction); err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+func (up *BasicUpdatePlanner) ExecuteCreateView(createViewData *data.CreateViewData, transaction *transaction.Transaction) (types.Int, error) {
+	if createViewData.IfNotExists && up.metadataManager.TableOrViewExists(types.TableName(createViewData.ViewName), transaction) {
+		return 0, nil
+	}
+
+	if err := up.metadataManager.CreateView(createViewData.ViewName, createViewData.GetViewDef(), transaction); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+func (up *BasicUpdatePlanner) ExecuteCreateIndex(createIndexData *data.CreateIndexData, transaction *transaction.Transaction) (types.Int, error) {
+	if createIndexData.IfNotExists && up.metadataManager.IndexExists(createIndexData.IndexName, createIndexData.TableName, transaction) {
+		return 0, nil
+	}
+
+	if err := up.metadataManager.CreateIndex(createIndexData.IndexName, createIndexData.TableName, createIndexData.FieldName, transaction); err != nil {
+		return 0, err
+	}
+	return 0, nil
 }
 
-func (up *BasicUpdatePlanner) ExecuteCreateTable(createTableData *data.CreateTableData, transaction *transaction.Transaction) types.Int {
-	up.metadataManager.CreateTable(createTableData.TableName, createTableData.Schema, transaction)
-	return 0
+func (up *BasicUpdatePlanner) ExecuteDropTable(dropTableData *data.DropTableData, transaction *transaction.Transaction) (types.Int, error) {
+	if dropTableData.IfExists {
+		if _, err := up.metadataManager.GetLayout(dropTableData.TableName, transaction); err != nil {
+			return 0, nil
+		}
+	}
+
+	for _, foreignKey := range up.metadataManager.GetReferencingForeignKeys(dropTableData.TableName, transaction) {
+		// 自分自身への参照は、テーブルと一緒に消えるので問題ない.
+		if foreignKey.TableName != dropTableData.TableName {
+			return 0, TableIsReferencedError{dropTableData.TableName, foreignKey.TableName}
+		}
+	}
+
+	if err := up.metadataManager.DropTable(dropTableData.TableName, transaction); err != nil {
+		return 0, err
+	}
+	return 0, nil
 }
 
-func (up *BasicUpdatePlanner) ExecuteCreateView(createViewData *data.CreateViewData, transaction *transaction.Transaction) types.Int {
-	up.metadataManager.CreateView(createViewData.ViewName, createViewData.GetViewDef(), transaction)
-	return 0
+func (up *BasicUpdatePlanner) ExecuteDropView(dropViewData *data.DropViewData, transaction *transaction.Transaction) (types.Int, error) {
+	if dropViewData.IfExists {
+		if _, err := up.metadataManager.GetViewDef(dropViewData.ViewName, transaction); err != nil {
+			return 0, nil
+		}
+	}
+
+	if err := up.metadataManager.DropView(dropViewData.ViewName, transaction); err != nil {
+		return 0, err
+	}
+	return 0, nil
 }
 
-func (up *BasicUpdatePlanner) ExecuteCreateIndex(createIndexData *data.CreateIndexData, transaction *transaction.Transaction) types.Int {
-	up.metadataManager.CreateIndex(createIndexData.IndexName, createIndexData.TableName, createIndexData.FieldName, transaction)
-	return 0
+func (up *BasicUpdatePlanner) ExecuteDropIndex(dropIndexData *data.DropIndexData, transaction *transaction.Transaction) (types.Int, error) {
+	if dropIndexData.IfExists && !up.metadataManager.IndexExists(dropIndexData.IndexName, dropIndexData.TableName, transaction) {
+		return 0, nil
+	}
+
+	if err := up.metadataManager.DropIndex(dropIndexData.IndexName, dropIndexData.TableName, transaction); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// テーブルのファイルを空にする. 空にしたことは1つのログレコードに記録するので、ロールバックやクラッシュからのリカバリで元のレコードに戻る.
+// レコードを1件ずつ削除しないので ON DELETE は処理できず、他のテーブルから外部キーで参照されているテーブルは TRUNCATE できない.
+func (up *BasicUpdatePlanner) ExecuteTruncateTable(truncateTableData *data.TruncateTableData, transaction *transaction.Transaction) (types.Int, error) {
+	if _, err := up.metadataManager.GetLayout(truncateTableData.TableName, transaction); err != nil {
+		return 0, err
+	}
+
+	for _, foreignKey := range up.metadataManager.GetReferencingForeignKeys(truncateTableData.TableName, transaction) {
+		// 自分自身への参照は、全てのレコードが一緒に消えるので問題ない.
+		if foreignKey.TableName != truncateTableData.TableName {
+			return 0, CannotTruncateReferencedTableError{truncateTableData.TableName, foreignKey.TableName}
+		}
+	}
+
+	transaction.TruncateFile(query.TableFileName(truncateTableData.TableName))
+
+	up.metadataManager.TruncateTable(truncateTableData.TableName, transaction)
+	return 0, nil
+}
+
+// テーブルを全て読んで統計情報を計算し直し、カタログに保存する. 保存した統計情報は、DBを再起動しても読み込まれる.
+// 存在しないテーブルがあれば、どのテーブルの統計情報も計算しない.
+func (up *BasicUpdatePlanner) ExecuteAnalyzeTable(analyzeTableData *data.AnalyzeTableData, transaction *transaction.Transaction) (types.Int, error) {
+	for _, tableName := range analyzeTableData.TableNames {
+		if _, err := up.metadataManager.GetLayout(tableName, transaction); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, tableName := range analyzeTableData.TableNames {
+		if _, err := up.metadataManager.AnalyzeTable(tableName, transaction); err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
 }
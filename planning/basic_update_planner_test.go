@@ -0,0 +1,113 @@
+package planning
+
+import (
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAssignments(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, updateAssignmentTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE pairs (id INT PRIMARY KEY, a INT, b INT UNIQUE, c INT)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO pairs (id, a, b, c) VALUES (1, 10, 20, 0), (2, 30, 40, 0)", transaction)
+
+	t.Run("SET 句の右辺は、他の代入の結果ではなく更新前のレコードで評価されること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "UPDATE pairs SET a = b, b = a", transaction)
+		assert.Equal(t, []string{"1, 20, 10, 0", "2, 40, 30, 0"}, queryRows(t, planner, "SELECT id, a, b, c FROM pairs", transaction))
+
+		mustExecuteUpdate(t, planner, "UPDATE pairs SET b = b + 1, c = b * 2 WHERE id = 1", transaction)
+		assert.Equal(t, []string{"1, 20, 11, 20", "2, 40, 30, 0"}, queryRows(t, planner, "SELECT id, a, b, c FROM pairs", transaction))
+	})
+
+	t.Run("更新するレコード同士で値を入れ替えても、UNIQUE 制約に違反しないこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "UPDATE pairs SET b = 41 - b", transaction)
+		assert.Equal(t, []string{"1, 20, 30, 20", "2, 40, 11, 0"}, queryRows(t, planner, "SELECT id, a, b, c FROM pairs", transaction))
+	})
+
+	t.Run("制約に違反するレコードが1件でもあれば、どのレコードも更新しないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("UPDATE pairs SET b = 30, a = a + 1", transaction)
+		assert.IsType(t, DuplicateKeyError{}, err)
+		assert.Equal(t, []string{"1, 20, 30, 20", "2, 40, 11, 0"}, queryRows(t, planner, "SELECT id, a, b, c FROM pairs", transaction))
+	})
+
+	t.Run("自身を参照する外部キーのフィールドだけを更新する場合は、既存のレコードを参照できること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "CREATE TABLE nodes (id INT PRIMARY KEY, parent_id INT REFERENCES nodes(id))", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO nodes (id, parent_id) VALUES (1, NULL), (2, NULL)", transaction)
+
+		mustExecuteUpdate(t, planner, "UPDATE nodes SET parent_id = 1 WHERE id = 2", transaction)
+		assert.Equal(t, []string{"1, NULL", "2, 1"}, queryRows(t, planner, "SELECT id, parent_id FROM nodes", transaction))
+
+		_, err := planner.ExecuteUpdate("UPDATE nodes SET parent_id = 3", transaction)
+		assert.IsType(t, NoReferencedRowError{}, err)
+	})
+}
+
+func TestFieldValueValidation(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, fieldValueTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT, name VARCHAR(5))", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO users (id, name) VALUES (1, 'alice')", transaction)
+
+	t.Run("INSERT するレコードの値の数がフィールドの数と異なる場合は、どのレコードも書き込まないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, name) VALUES (2, 'bob'), (3)", transaction)
+		assert.IsType(t, InsertValueCountError{}, err)
+		assert.Equal(t, []string{"1, 'alice'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("フィールドの型と異なる値は書き込めないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, name) VALUES (2, 'bob'), ('three', 'carol')", transaction)
+		assert.IsType(t, FieldValueTypeError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET id = 'one'", transaction)
+		assert.IsType(t, FieldValueTypeError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET name = 1", transaction)
+		assert.IsType(t, FieldValueTypeError{}, err)
+		assert.Equal(t, []string{"1, 'alice'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("VARCHAR のフィールドの長さを超える文字列は書き込めないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, name) VALUES (2, 'bob'), (3, 'charlie')", transaction)
+		assert.IsType(t, FieldValueTooLongError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET name = 'alice1'", transaction)
+		assert.IsType(t, FieldValueTooLongError{}, err)
+		assert.Equal(t, []string{"1, 'alice'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+}
+
+func TestInsertSelect(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, insertSelectTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE members (uid INT, uname VARCHAR(10), age INT)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT, name VARCHAR(10))", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO members (uid, uname, age) VALUES (1, 'alice', 20), (2, 'bob', 30), (3, 'carol', 20)", transaction)
+
+	t.Run("SELECT 文の結果を、他のテーブルに挿入できること.", func(t *testing.T) {
+		affected, err := planner.ExecuteUpdate("INSERT INTO users (id, name) SELECT uid, uname FROM members WHERE age = 20", transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, types.Int(2), affected)
+		assert.Equal(t, []string{"1, 'alice'", "3, 'carol'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("挿入先のテーブルを SELECT 文で読む場合は、挿入する前に全て読むこと.", func(t *testing.T) {
+		affected, err := planner.ExecuteUpdate("INSERT INTO users (id, name) SELECT id, name FROM users", transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, types.Int(2), affected, "挿入したレコードは読まないので、元のレコードの数だけ挿入される.")
+		assert.Equal(t, []string{"1, 'alice'", "3, 'carol'", "1, 'alice'", "3, 'carol'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("SELECT 文のフィールドの数が、挿入するフィールドの数と異なる場合はエラーになること.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, name) SELECT uid FROM members", transaction)
+		assert.IsType(t, InsertValueCountError{}, err)
+
+		_, err = planner.ExecuteUpdate("INSERT INTO users (id) SELECT uid, uname FROM members", transaction)
+		assert.IsType(t, InsertValueCountError{}, err)
+		assert.Len(t, queryRows(t, planner, "SELECT id, name FROM users", transaction), 4)
+	})
+}
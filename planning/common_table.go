@@ -0,0 +1,147 @@
+package planning
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"slices"
+)
+
+// WITH 句で定義された名前付きのサブクエリ(CTE)のスコープ.
+// 内側のクエリ(サブクエリや集合演算でつなげた SELECT 文)からは、外側の WITH 句で定義されたものも参照できる.
+type commonTableScope struct {
+	parent *commonTableScope
+	name   data.Queryable
+	// 参照されるたびに plan を作る. ビューと同じく、参照ごとに別の plan になる.
+	createPlan func() (query.Plan, error)
+}
+
+func (s *commonTableScope) lookup(name data.Queryable) (func() (query.Plan, error), bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.name == name {
+			return scope.createPlan, true
+		}
+	}
+	return nil, false
+}
+
+type commonTablePlanFunc func(queryData *data.QueryData, commonTables *commonTableScope) (query.Plan, error)
+
+// WITH 句の定義をスコープに追加する. 各定義からは、それより前に定義されたものを参照できる.
+// WITH RECURSIVE の場合、自身を参照している定義は RecursivePlan で評価する.
+func withCommonTables(transaction *transaction.Transaction, withData *data.WithData, scope *commonTableScope, createPlan commonTablePlanFunc) *commonTableScope {
+	if withData == nil {
+		return scope
+	}
+
+	for _, commonTable := range withData.CommonTables {
+		definitionScope := scope
+
+		createCommonTablePlan := func() (query.Plan, error) {
+			plan, err := createPlan(commonTable.Query, definitionScope)
+			if err != nil {
+				return nil, err
+			}
+			if len(commonTable.FieldNames) == 0 {
+				return plan, nil
+			}
+			return NewRenamePlan(commonTable.Name, plan, commonTable.FieldNames)
+		}
+
+		if withData.Recursive && referencesItself(commonTable) {
+			createCommonTablePlan = func() (query.Plan, error) {
+				return newRecursivePlan(transaction, commonTable, definitionScope, createPlan)
+			}
+		}
+
+		scope = &commonTableScope{parent: scope, name: commonTable.Name, createPlan: createCommonTablePlan}
+	}
+
+	return scope
+}
+
+// 集合演算でつなげた SELECT 文の FROM 句で、自身の名前を参照しているか.
+func referencesItself(commonTable *data.CommonTableData) bool {
+	for _, setOperation := range commonTable.Query.SetOperations {
+		if slices.Contains(setOperation.Query.Queryables, commonTable.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// WITH RECURSIVE の定義から plan を作る.
+// 最初の SELECT 文を anchor とし、UNION [ALL] でつなげた SELECT 文を再帰部分とする.
+// 再帰部分から自身の名前を参照すると、直前の繰り返しで追加されたレコード(WorkingTable)を読む.
+func newRecursivePlan(transaction *transaction.Transaction, commonTable *data.CommonTableData, scope *commonTableScope, createPlan commonTablePlanFunc) (query.Plan, error) {
+	anchorData := *commonTable.Query
+	anchorData.SetOperations = nil
+	anchorData.Limit = nil
+	anchorPlan, err := createPlan(&anchorData, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	anchorSchema := anchorPlan.GetSchema()
+	fieldNames := commonTable.FieldNames
+	if len(fieldNames) == 0 {
+		fieldNames = anchorSchema.Fields()
+	}
+	if len(fieldNames) != len(anchorSchema.Fields()) {
+		return nil, CommonTableFieldCountError{commonTable.Name, len(fieldNames), len(anchorSchema.Fields())}
+	}
+
+	schema := record.NewSchema()
+	for i, anchorFieldName := range anchorSchema.Fields() {
+		// Fields() から取得したフィールドなので、エラーは発生しない.
+		fieldType, _ := anchorSchema.FieldType(anchorFieldName)
+		fieldLength, _ := anchorSchema.Length(anchorFieldName)
+		schema.AddField(fieldNames[i], fieldType, fieldLength)
+	}
+
+	workingTable := query.NewWorkingTable(transaction, schema)
+	selfScope := &commonTableScope{
+		parent: scope,
+		name:   commonTable.Name,
+		createPlan: func() (query.Plan, error) {
+			return &WorkingTablePlan{workingTable}, nil
+		},
+	}
+
+	distinct := false
+	recursivePlans := make([]query.Plan, 0, len(commonTable.Query.SetOperations))
+	for _, setOperation := range commonTable.Query.SetOperations {
+		if setOperation.Operator != query.UNION {
+			return nil, RecursiveCommonTableOperatorError{commonTable.Name, setOperation.Operator}
+		}
+		distinct = distinct || !setOperation.All
+
+		recursivePlan, err := createPlan(setOperation.Query, selfScope)
+		if err != nil {
+			return nil, err
+		}
+
+		// 再帰部分の結果も入るように、文字列のフィールドは長い方に合わせる.
+		schema, err = setOperationSchema(query.UNION, schema, recursivePlan.GetSchema())
+		if err != nil {
+			return nil, err
+		}
+		recursivePlans = append(recursivePlans, recursivePlan)
+	}
+
+	plan := query.Plan(&RecursivePlan{
+		transaction:    transaction,
+		schema:         schema,
+		anchorPlan:     anchorPlan,
+		recursivePlans: recursivePlans,
+		workingTable:   workingTable,
+		distinct:       distinct,
+	})
+
+	// LIMIT は再帰の結果全体に適用する. 必要な数だけ読んだら再帰を打ち切れる.
+	if commonTable.Query.Limit != nil {
+		return NewLimitPlan(plan, commonTable.Query.Limit.Count, commonTable.Query.Limit.Offset)
+	}
+	return plan, nil
+}
@@ -0,0 +1,375 @@
+package planning
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/metadata"
+	"simple-db-go/parsing"
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"simple-db-go/util"
+	"slices"
+)
+
+// PRIMARY KEY に対して自動で作るインデックスの名前. UNIQUE の場合はフィールド名をインデックス名にする.
+const PRIMARY_KEY_INDEX_NAME types.IndexName = "primary"
+
+// CREATE TABLE で指定された制約が、テーブルの定義と矛盾しないか確認する.
+func validateColumnConstraints(schema *record.Schema, constraints []*data.ColumnConstraint) error {
+	parser := parsing.NewParser()
+	hasPrimaryKey := false
+	hasAutoIncrement := false
+	for _, constraint := range constraints {
+		// `PRIMARY KEY (<field>)` のようにテーブルに対して指定した制約は、存在しないフィールドを指定できてしまう.
+		if !schema.HasField(constraint.FieldName) {
+			return ConstraintFieldNotFoundError{constraint.FieldName, constraint.GetConstraintDef()}
+		}
+
+		if len(constraint.GetConstraintDef()) > constants.MAX_CONSTRAINT_DEF_LENGTH {
+			return ConstraintDefTooLongError{constraint.FieldName, constraint.GetConstraintDef()}
+		}
+
+		// 制約カタログに保存した定義は INSERT, UPDATE のたびにパースし直すので、同じ定義に戻らないものは保存しない.
+		parsed, err := parser.ParseConstraint(constraint.FieldName, constraint.GetConstraintDef())
+		if err != nil || parsed.GetConstraintDef() != constraint.GetConstraintDef() {
+			return InvalidConstraintDefError{constraint.FieldName, constraint.GetConstraintDef()}
+		}
+
+		switch constraint.Type {
+		case constants.PRIMARY_KEY:
+			if hasPrimaryKey {
+				return MultiplePrimaryKeysError{constraint.FieldName}
+			}
+			hasPrimaryKey = true
+		case constants.AUTO_INCREMENT:
+			if hasAutoIncrement {
+				return MultipleAutoIncrementError{constraint.FieldName}
+			}
+			hasAutoIncrement = true
+
+			if fieldType, _ := schema.FieldType(constraint.FieldName); fieldType != constants.INTEGER {
+				return AutoIncrementTypeError{constraint.FieldName}
+			}
+		case constants.DEFAULT:
+			if query.IsNull(constraint.DefaultValue) {
+				continue
+			}
+
+			fieldType, _ := schema.FieldType(constraint.FieldName)
+			_, isInt := constraint.DefaultValue.(query.IntConstant)
+			if isInt != (fieldType == constants.INTEGER) {
+				return DefaultValueTypeError{constraint.FieldName, constraint.DefaultValue.ToString()}
+			}
+		case constants.CHECK:
+			if len(constraint.Check.Subqueries()) > 0 || !constraint.Check.AppliesTo(schema) {
+				return InvalidCheckConstraintError{constraint.FieldName, constraint.Check.ToString()}
+			}
+		}
+	}
+	return nil
+}
+
+func newConstraintCatalogRows(tableName types.TableName, constraints []*data.ColumnConstraint) []metadata.ConstraintCatalogRow {
+	rows := make([]metadata.ConstraintCatalogRow, 0, len(constraints))
+	for _, constraint := range constraints {
+		rows = append(rows, metadata.ConstraintCatalogRow{
+			TableName:  tableName,
+			FieldName:  constraint.FieldName,
+			Type:       constraint.Type,
+			Definition: constraint.GetConstraintDef(),
+		})
+	}
+	return rows
+}
+
+// INSERT, UPDATE で指定されたフィールドが、テーブルに存在するか確認する.
+func validateFieldNames(tableName types.TableName, schema *record.Schema, fieldNames []types.FieldName) error {
+	for _, fieldName := range fieldNames {
+		if !schema.HasField(fieldName) {
+			return FieldNotFoundInTableError{tableName, fieldName}
+		}
+	}
+	return nil
+}
+
+// 書き込むレコードの値が、テーブルのフィールドの型と長さに合うか確認する. NULL はどのフィールドにも書き込める.
+// values は schema のフィールドと位置で対応する.
+func validateFieldValues(tableName types.TableName, schema *record.Schema, values []query.Constant) error {
+	for i, fieldName := range schema.Fields() {
+		value := values[i]
+		if query.IsNull(value) {
+			continue
+		}
+
+		fieldType, _ := schema.FieldType(fieldName)
+		if fieldType == constants.INTEGER {
+			if _, isInt := value.GetValue().(types.Int); !isInt {
+				return FieldValueTypeError{tableName, fieldName, value.ToString()}
+			}
+			continue
+		}
+
+		stringValue, isString := value.GetValue().(string)
+		if !isString {
+			return FieldValueTypeError{tableName, fieldName, value.ToString()}
+		}
+
+		// NOTE: フィールドの長さはバイト数で確保しているので、文字数ではなくバイト数で比べる.
+		length, _ := schema.Length(fieldName)
+		if util.Len(stringValue) > types.Int(length)*constants.CharByteSize {
+			return FieldValueTooLongError{tableName, fieldName, length, stringValue}
+		}
+	}
+	return nil
+}
+
+// AUTO_INCREMENT のフィールド. 無い場合は false を返す.
+func autoIncrementField(constraints []*data.ColumnConstraint) (types.FieldName, bool) {
+	for _, constraint := range constraints {
+		if constraint.Type == constants.AUTO_INCREMENT {
+			return constraint.FieldName, true
+		}
+	}
+	return "", false
+}
+
+// INSERT するレコードの AUTO_INCREMENT のフィールドに値を割り当て、カウンタを進める.
+// 値が指定されなかった(NULL の)レコードにはカウンタの値を採番し、値が指定されたレコードがあれば、カウンタをその値の次まで進める.
+// rows は fieldNames と位置で対応し、採番した値で書き換える. 採番した最初の値を返し、採番しなかった場合は 0 を返す.
+// NOTE: カウンタはこの後の制約の確認より先に進めるので、制約違反で INSERT できなかった場合も採番した値は使われずに飛ぶ(MySQL と同じ).
+func assignAutoIncrement(
+	tableName types.TableName,
+	fieldNames []types.FieldName,
+	rows [][]query.Constant,
+	metadataManager *metadata.MetadataManager,
+	transaction *transaction.Transaction,
+) types.Int {
+	counter, exists := metadataManager.GetAutoIncrement(tableName, transaction)
+	if !exists {
+		return 0
+	}
+
+	index := slices.Index(fieldNames, counter.FieldName)
+	nextValue := counter.NextValue
+	firstValue := types.Int(0)
+	for _, row := range rows {
+		if query.IsNull(row[index]) {
+			row[index] = query.NewIntConstant(nextValue)
+			if firstValue == 0 {
+				firstValue = nextValue
+			}
+			nextValue++
+		} else if value, isInt := row[index].GetValue().(types.Int); isInt && value >= nextValue {
+			nextValue = value + 1
+		}
+	}
+
+	if nextValue != counter.NextValue {
+		metadataManager.SetAutoIncrementNextValue(tableName, nextValue, transaction)
+	}
+	return firstValue
+}
+
+// UPDATE で AUTO_INCREMENT のフィールドにカウンタ以上の値を書き込む場合は、カウンタをその値の次まで進める.
+// rows は fieldNames と位置で対応する、更新後のレコードの値.
+func advanceAutoIncrement(
+	tableName types.TableName,
+	changedFields []types.FieldName,
+	fieldNames []types.FieldName,
+	rows [][]query.Constant,
+	metadataManager *metadata.MetadataManager,
+	transaction *transaction.Transaction,
+) {
+	counter, exists := metadataManager.GetAutoIncrement(tableName, transaction)
+	if !exists || !slices.Contains(changedFields, counter.FieldName) {
+		return
+	}
+
+	index := slices.Index(fieldNames, counter.FieldName)
+	nextValue := counter.NextValue
+	for _, row := range rows {
+		if value, isInt := row[index].GetValue().(types.Int); isInt && value >= nextValue {
+			nextValue = value + 1
+		}
+	}
+
+	if nextValue != counter.NextValue {
+		metadataManager.SetAutoIncrementNextValue(tableName, nextValue, transaction)
+	}
+}
+
+// PRIMARY KEY, UNIQUE の制約には、インデックスを自動で作る.
+func newKeyIndexes(tableName types.TableName, constraints []*data.ColumnConstraint) []*data.CreateIndexData {
+	indexes := []*data.CreateIndexData{}
+	for _, constraint := range constraints {
+		switch constraint.Type {
+		case constants.PRIMARY_KEY:
+			indexes = append(indexes, &data.CreateIndexData{IndexName: PRIMARY_KEY_INDEX_NAME, TableName: tableName, FieldName: constraint.FieldName})
+		case constants.UNIQUE:
+			// `a INT UNIQUE, UNIQUE (a)` のように同じフィールドに UNIQUE を重ねて指定しても、インデックスは1つだけ作る.
+			indexName := types.IndexName(constraint.FieldName)
+			if slices.ContainsFunc(indexes, func(index *data.CreateIndexData) bool { return index.IndexName == indexName }) {
+				continue
+			}
+			indexes = append(indexes, &data.CreateIndexData{IndexName: indexName, TableName: tableName, FieldName: constraint.FieldName})
+		}
+	}
+	return indexes
+}
+
+// テーブルに定義された制約.
+// INSERT, UPDATE では、書き込む前にレコードが制約を満たすか確認し、満たさない場合は何も書き込まずにエラーを返す.
+type tableConstraints struct {
+	tableName   types.TableName
+	constraints []*data.ColumnConstraint
+}
+
+// 制約カタログに保存された定義をパースして、テーブルの制約を読み込む.
+func newTableConstraints(tableName types.TableName, metadataManager *metadata.MetadataManager, transaction *transaction.Transaction) (*tableConstraints, error) {
+	parser := parsing.NewParser()
+	rows := metadataManager.GetConstraints(tableName, transaction)
+
+	constraints := make([]*data.ColumnConstraint, 0, len(rows))
+	for _, row := range rows {
+		constraint, err := parser.ParseConstraint(row.FieldName, row.Definition)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, constraint)
+	}
+
+	return &tableConstraints{tableName: tableName, constraints: constraints}, nil
+}
+
+// INSERT でフィールドが指定されなかった場合の値. DEFAULT 制約が無ければ NULL になる.
+func (tc *tableConstraints) defaultValue(fieldName types.FieldName) query.Constant {
+	for _, constraint := range tc.constraints {
+		if constraint.Type == constants.DEFAULT && constraint.FieldName == fieldName {
+			return constraint.DefaultValue
+		}
+	}
+	return query.NewNullConstant()
+}
+
+// scan の current record が制約を満たすか確認する.
+// CHECK 制約は、条件が FALSE にならなければ満たすものとする. NULL を含んで UNKNOWN になる場合は満たす.
+func (tc *tableConstraints) validate(scan query.Scan) error {
+	for _, constraint := range tc.constraints {
+		switch constraint.Type {
+		case constants.NOT_NULL, constants.PRIMARY_KEY:
+			value, err := scan.GetValue(constraint.FieldName)
+			if err != nil {
+				return err
+			}
+			if query.IsNull(value) {
+				return NotNullConstraintViolationError{tc.tableName, constraint.FieldName}
+			}
+		case constants.CHECK:
+			result, err := constraint.Check.Evaluate(scan)
+			if err != nil {
+				return err
+			}
+			if result == query.TRUTH_FALSE {
+				return CheckConstraintViolationError{tc.tableName, constraint.FieldName, constraint.Check.ToString()}
+			}
+		}
+	}
+	return nil
+}
+
+// NOT NULL または PRIMARY KEY のフィールドかどうか.
+func (tc *tableConstraints) isNotNull(fieldName types.FieldName) bool {
+	for _, constraint := range tc.constraints {
+		if constraint.FieldName == fieldName && (constraint.Type == constants.NOT_NULL || constraint.Type == constants.PRIMARY_KEY) {
+			return true
+		}
+	}
+	return false
+}
+
+// PRIMARY KEY, UNIQUE のフィールド.
+func (tc *tableConstraints) keyFields() []types.FieldName {
+	fieldNames := []types.FieldName{}
+	for _, constraint := range tc.constraints {
+		if constraint.Type == constants.PRIMARY_KEY || constraint.Type == constants.UNIQUE {
+			fieldNames = append(fieldNames, constraint.FieldName)
+		}
+	}
+	return fieldNames
+}
+
+// 書き込んだ後のテーブルで、PRIMARY KEY, UNIQUE のフィールドの値が重複しないか確認する.
+// rows は書き込むレコードの値で、fieldNames と位置で対応する. replacedRecordIDs は UPDATE で rows に置き換わる既存のレコード.
+// changedFields に含まれない key は、書き込みで値が変わらないので確認しない.
+// NULL は他のどの値とも重複しないものとする.
+//
+// NOTE: インデックスはカタログに登録するだけで実体が無いので、tablePlan を全件走査して確認する.
+func (tc *tableConstraints) validateUniqueness(
+	tablePlan query.Plan,
+	changedFields []types.FieldName,
+	fieldNames []types.FieldName,
+	rows [][]query.Constant,
+	replacedRecordIDs []record.RecordID,
+) error {
+	keyFields := []types.FieldName{}
+	for _, fieldName := range tc.keyFields() {
+		if slices.Contains(changedFields, fieldName) {
+			keyFields = append(keyFields, fieldName)
+		}
+	}
+	if len(keyFields) == 0 {
+		return nil
+	}
+
+	seenValues := make(map[types.FieldName]map[query.Constant]bool)
+	for _, fieldName := range keyFields {
+		seenValues[fieldName] = make(map[query.Constant]bool)
+	}
+	addValue := func(fieldName types.FieldName, value query.Constant) error {
+		if query.IsNull(value) {
+			return nil
+		}
+		if seenValues[fieldName][value] {
+			return DuplicateKeyError{tc.tableName, fieldName, value.ToString()}
+		}
+		seenValues[fieldName][value] = true
+		return nil
+	}
+
+	for _, row := range rows {
+		for _, fieldName := range keyFields {
+			if err := addValue(fieldName, row[slices.Index(fieldNames, fieldName)]); err != nil {
+				return err
+			}
+		}
+	}
+
+	replaced := make(map[record.RecordID]bool, len(replacedRecordIDs))
+	for _, recordID := range replacedRecordIDs {
+		replaced[recordID] = true
+	}
+
+	// NOTE: tablePlan は TablePlan なので、UpdateScan として扱える.
+	tableScan := tablePlan.Open().(query.UpdateScan)
+	defer tableScan.Close()
+
+	for tableScan.Next() {
+		if replaced[tableScan.GetCurrentRecordID()] {
+			continue
+		}
+
+		for _, fieldName := range keyFields {
+			value, err := tableScan.GetValue(fieldName)
+			if err != nil {
+				return err
+			}
+			if err := addValue(fieldName, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package planning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnConstraints(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, columnConstraintTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT NOT NULL, name VARCHAR(10) DEFAULT 'it''s', age INT DEFAULT 20 CHECK (age >= 0))", transaction)
+
+	t.Run("指定しなかったフィールドには DEFAULT の値が入り、DEFAULT が無ければ NULL になること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "INSERT INTO users (id) VALUES (1)", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO users (id, name, age) VALUES (2, 'bob', NULL)", transaction)
+
+		assert.Equal(t, []string{"1, 'it''s', 20", "2, 'bob', NULL"}, queryRows(t, planner, "SELECT id, name, age FROM users", transaction))
+	})
+
+	t.Run("NOT NULL のフィールドに NULL は書き込めないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (name) VALUES ('carol')", transaction)
+		assert.IsType(t, NotNullConstraintViolationError{}, err, "DEFAULT の無い NOT NULL のフィールドを省略した場合.")
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET id = NULL WHERE id = 2", transaction)
+		assert.IsType(t, NotNullConstraintViolationError{}, err)
+	})
+
+	t.Run("CHECK 制約が FALSE になるレコードは書き込めず、UNKNOWN になるレコードは書き込めること.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, age) VALUES (3, -1)", transaction)
+		assert.IsType(t, CheckConstraintViolationError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET age = age - 30", transaction)
+		assert.IsType(t, CheckConstraintViolationError{}, err, "1件でも違反すれば、どのレコードも更新されない.")
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET age = age + 1", transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1, 'it''s', 21", "2, 'bob', NULL"}, queryRows(t, planner, "SELECT id, name, age FROM users", transaction))
+	})
+
+	t.Run("フィールドの型と異なる DEFAULT の値は指定できないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("CREATE TABLE invalid_defaults (id INT DEFAULT 'one')", transaction)
+		assert.IsType(t, DefaultValueTypeError{}, err)
+	})
+}
+
+func TestKeyConstraints(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, keyConstraintTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(20) UNIQUE)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO users (id, email) VALUES (1, 'alice@example.com')", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO users (id, email) VALUES (2, NULL)", transaction)
+
+	t.Run("PRIMARY KEY, UNIQUE のフィールドに既存のレコードと重複する値は書き込めないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (id, email) VALUES (1, 'bob@example.com')", transaction)
+		assert.IsType(t, DuplicateKeyError{}, err)
+
+		_, err = planner.ExecuteUpdate("INSERT INTO users (id, email) VALUES (3, 'alice@example.com')", transaction)
+		assert.IsType(t, DuplicateKeyError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET email = 'alice@example.com' WHERE id = 2", transaction)
+		assert.IsType(t, DuplicateKeyError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE users SET id = 1", transaction)
+		assert.IsType(t, DuplicateKeyError{}, err, "更新するレコード同士で重複する場合.")
+
+		assert.Equal(t, []string{"1, 'alice@example.com'", "2, NULL"}, queryRows(t, planner, "SELECT id, email FROM users", transaction))
+	})
+
+	t.Run("PRIMARY KEY のフィールドに NULL は書き込めないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO users (email) VALUES ('carol@example.com')", transaction)
+		assert.IsType(t, NotNullConstraintViolationError{}, err)
+	})
+
+	t.Run("UNIQUE のフィールドの NULL は他の NULL と重複しないこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "INSERT INTO users (id, email) VALUES (3, NULL)", transaction)
+	})
+
+	t.Run("更新前の値とは重複しないものとして扱うこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "UPDATE users SET id = id + 1", transaction)
+		assert.Equal(t, []string{"2, 'alice@example.com'", "3, NULL", "4, NULL"}, queryRows(t, planner, "SELECT id, email FROM users", transaction))
+	})
+
+	t.Run("PRIMARY KEY は1つしか指定できないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("CREATE TABLE invalid_keys (id INT PRIMARY KEY, code INT PRIMARY KEY)", transaction)
+		assert.IsType(t, MultiplePrimaryKeysError{}, err)
+	})
+}
+
+func TestAutoIncrement(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, autoIncrementTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(10))", transaction)
+
+	t.Run("値を指定しなかったレコードには、1 から順に採番されること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "INSERT INTO users (name) VALUES ('alice'), ('bob')", transaction)
+		assert.Equal(t, []string{"1, 'alice'", "2, 'bob'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("UPDATE でカウンタ以上の値を書き込むと、カウンタがその値の次まで進むこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "UPDATE users SET id = 10 WHERE id = 2", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO users (name) VALUES ('carol')", transaction)
+		assert.Equal(t, []string{"1, 'alice'", "10, 'bob'", "11, 'carol'"}, queryRows(t, planner, "SELECT id, name FROM users", transaction))
+	})
+
+	t.Run("テーブル名を変更しても、カウンタが引き継がれること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "ALTER TABLE users RENAME TO members", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO members (name) VALUES ('dave')", transaction)
+		assert.Equal(t, []string{"1, 'alice'", "10, 'bob'", "11, 'carol'", "12, 'dave'"}, queryRows(t, planner, "SELECT id, name FROM members", transaction))
+	})
+
+	t.Run("TRUNCATE するとカウンタが 1 に戻ること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "TRUNCATE TABLE members", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO members (name) VALUES ('erin')", transaction)
+		assert.Equal(t, []string{"1, 'erin'"}, queryRows(t, planner, "SELECT id, name FROM members", transaction))
+	})
+}
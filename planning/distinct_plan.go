@@ -0,0 +1,67 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+// 結果のレコード数がこれ以下と見積もられる場合は、ソートせずにメモリ上で重複を取り除く.
+const HASH_DISTINCT_MAX_RECORDS types.Int = 1000
+
+var _ query.Plan = (*DistinctPlan)(nil)
+
+// SELECT DISTINCT のための plan. 元の plan の全フィールドについて、重複したレコードを取り除く.
+// 結果が小さいと見積もられる場合はメモリ上のハッシュで、そうでなければ一時テーブルでソートしてから重複を取り除く.
+type DistinctPlan struct {
+	plan     query.Plan
+	sortPlan query.Plan
+}
+
+func NewDistinctPlan(transaction *transaction.Transaction, plan query.Plan) query.Plan {
+	return &DistinctPlan{
+		plan:     plan,
+		sortPlan: NewSortPlan(transaction, plan, plan.GetSchema().Fields()),
+	}
+}
+
+func (p *DistinctPlan) Open() query.Scan {
+	fieldNames := p.plan.GetSchema().Fields()
+	if p.usesHash() {
+		return query.NewHashDistinctScan(p.plan.Open(), fieldNames)
+	}
+	return query.NewDistinctScan(p.sortPlan.Open(), fieldNames)
+}
+
+func (p *DistinctPlan) GetBlocksAccessed() types.Int {
+	if p.usesHash() {
+		return p.plan.GetBlocksAccessed()
+	}
+	return p.sortPlan.GetBlocksAccessed()
+}
+
+// 重複を取り除いた後のレコード数は、各フィールドがとりうる値の組み合わせの数を超えない.
+func (p *DistinctPlan) GetRecordsOutput() types.Int {
+	combinations := types.Int(1)
+	for _, fieldName := range p.plan.GetSchema().Fields() {
+		combinations *= max(p.plan.GetDistinctValues(fieldName), 1)
+		// 桁あふれを避けるため、元のレコード数を超えた時点で打ち切る.
+		if combinations >= p.plan.GetRecordsOutput() {
+			return p.plan.GetRecordsOutput()
+		}
+	}
+	return combinations
+}
+
+func (p *DistinctPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return min(p.plan.GetDistinctValues(fieldName), p.GetRecordsOutput())
+}
+
+func (p *DistinctPlan) GetSchema() *record.Schema {
+	return p.plan.GetSchema()
+}
+
+func (p *DistinctPlan) usesHash() bool {
+	return p.plan.GetRecordsOutput() <= HASH_DISTINCT_MAX_RECORDS
+}
@@ -2,6 +2,10 @@ package planning
 
 import (
 	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/types"
 )
 
 type NotQueryStatementError struct {
@@ -19,3 +23,360 @@ type NotUpdateStatementError struct {
 func (e NotUpdateStatementError) Error() string {
 	return fmt.Sprintf("UPDATE文でないSQLで UpdatePlan を作成しようとしました. sql=%s", e.sql)
 }
+
+type InvalidLimitError struct {
+	limit  types.Int
+	offset types.Int
+}
+
+func (e InvalidLimitError) Error() string {
+	return fmt.Sprintf("LIMIT, OFFSET には 0 以上の値を指定してください. limit=%d, offset=%d", e.limit, e.offset)
+}
+
+type UnknownSetOperatorError struct {
+	operator query.SetOperator
+}
+
+func (e UnknownSetOperatorError) Error() string {
+	return fmt.Sprintf("不明な集合演算が指定されました. operator=%s", e.operator)
+}
+
+type SetOperationFieldCountError struct {
+	operator query.SetOperator
+	lhsCount int
+	rhsCount int
+}
+
+func (e SetOperationFieldCountError) Error() string {
+	return fmt.Sprintf("%s の両側の SELECT 文で、フィールドの数が一致しません. lhs=%d, rhs=%d", e.operator, e.lhsCount, e.rhsCount)
+}
+
+type SetOperationFieldTypeError struct {
+	operator     query.SetOperator
+	lhsFieldName types.FieldName
+	rhsFieldName types.FieldName
+}
+
+func (e SetOperationFieldTypeError) Error() string {
+	return fmt.Sprintf("%s の両側の SELECT 文で、フィールドの型が一致しません. lhs=%s, rhs=%s", e.operator, e.lhsFieldName, e.rhsFieldName)
+}
+
+type CommonTableFieldCountError struct {
+	name          data.Queryable
+	expectedCount int
+	actualCount   int
+}
+
+func (e CommonTableFieldCountError) Error() string {
+	return fmt.Sprintf("WITH 句で指定したフィールドの数が、SELECT 文のフィールドの数と一致しません. name=%s, expected=%d, actual=%d", e.name, e.expectedCount, e.actualCount)
+}
+
+type RecursiveCommonTableOperatorError struct {
+	name     data.Queryable
+	operator query.SetOperator
+}
+
+func (e RecursiveCommonTableOperatorError) Error() string {
+	return fmt.Sprintf("WITH RECURSIVE では UNION [ALL] だけ使用できます. name=%s, operator=%s", e.name, e.operator)
+}
+
+type FieldNotFoundInWindowError struct {
+	fieldName types.FieldName
+	window    string
+}
+
+func (e FieldNotFoundInWindowError) Error() string {
+	return fmt.Sprintf("PARTITION BY, ORDER BY に指定されたフィールドがありません. field_name=%s, window=%s", e.fieldName, e.window)
+}
+
+type FieldNotFoundInTableError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+}
+
+func (e FieldNotFoundInTableError) Error() string {
+	return fmt.Sprintf("テーブルに存在しないフィールドが指定されました. table_name=%s, field_name=%s", e.tableName, e.fieldName)
+}
+
+type DuplicateInsertFieldError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+}
+
+func (e DuplicateInsertFieldError) Error() string {
+	return fmt.Sprintf("INSERT で同じフィールドが複数回指定されています. table_name=%s, field_name=%s", e.tableName, e.fieldName)
+}
+
+type FieldValueTypeError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+	value     string
+}
+
+func (e FieldValueTypeError) Error() string {
+	return fmt.Sprintf("フィールドの型と異なる型の値は書き込めません. table_name=%s, field_name=%s, value=%s", e.tableName, e.fieldName, e.value)
+}
+
+type FieldValueTooLongError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+	length    types.FieldLength
+	value     string
+}
+
+func (e FieldValueTooLongError) Error() string {
+	return fmt.Sprintf("フィールドの長さを超える値は書き込めません. table_name=%s, field_name=%s, length=%d, value=%s", e.tableName, e.fieldName, e.length, e.value)
+}
+
+type InsertValueCountError struct {
+	tableName     types.TableName
+	expectedCount int
+	actualCount   int
+}
+
+func (e InsertValueCountError) Error() string {
+	return fmt.Sprintf("INSERT で指定したフィールドの数と、値の数が一致しません. table_name=%s, expected=%d, actual=%d", e.tableName, e.expectedCount, e.actualCount)
+}
+
+type DuplicateAssignmentError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+}
+
+func (e DuplicateAssignmentError) Error() string {
+	return fmt.Sprintf("UPDATE の SET 句で、同じフィールドに複数回代入しています. table_name=%s, field_name=%s", e.tableName, e.fieldName)
+}
+
+type ConstraintDefTooLongError struct {
+	fieldName     types.FieldName
+	constraintDef types.ConstraintDef
+}
+
+func (e ConstraintDefTooLongError) Error() string {
+	return fmt.Sprintf("制約の定義が長すぎます. 最大 %d 文字です. field_name=%s, constraint=%s", constants.MAX_CONSTRAINT_DEF_LENGTH, e.fieldName, e.constraintDef)
+}
+
+type InvalidConstraintDefError struct {
+	fieldName     types.FieldName
+	constraintDef types.ConstraintDef
+}
+
+func (e InvalidConstraintDefError) Error() string {
+	return fmt.Sprintf("制約の定義をパースし直せません. field_name=%s, constraint=%s", e.fieldName, e.constraintDef)
+}
+
+type DefaultValueTypeError struct {
+	fieldName    types.FieldName
+	defaultValue string
+}
+
+func (e DefaultValueTypeError) Error() string {
+	return fmt.Sprintf("DEFAULT の値の型がフィールドの型と一致しません. field_name=%s, default=%s", e.fieldName, e.defaultValue)
+}
+
+type InvalidCheckConstraintError struct {
+	fieldName types.FieldName
+	check     string
+}
+
+func (e InvalidCheckConstraintError) Error() string {
+	return fmt.Sprintf("CHECK 制約には、サブクエリを含まない、テーブルのフィールドだけを使った条件を指定してください. field_name=%s, check=%s", e.fieldName, e.check)
+}
+
+type NotNullConstraintViolationError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+}
+
+func (e NotNullConstraintViolationError) Error() string {
+	return fmt.Sprintf("NOT NULL 制約のフィールドに NULL を書き込もうとしました. table_name=%s, field_name=%s", e.tableName, e.fieldName)
+}
+
+type CheckConstraintViolationError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+	check     string
+}
+
+func (e CheckConstraintViolationError) Error() string {
+	return fmt.Sprintf("CHECK 制約を満たさないレコードを書き込もうとしました. table_name=%s, field_name=%s, check=%s", e.tableName, e.fieldName, e.check)
+}
+
+type ConstraintFieldNotFoundError struct {
+	fieldName     types.FieldName
+	constraintDef types.ConstraintDef
+}
+
+func (e ConstraintFieldNotFoundError) Error() string {
+	return fmt.Sprintf("制約に指定されたフィールドがテーブルにありません. field_name=%s, constraint=%s", e.fieldName, e.constraintDef)
+}
+
+type MultiplePrimaryKeysError struct {
+	fieldName types.FieldName
+}
+
+func (e MultiplePrimaryKeysError) Error() string {
+	return fmt.Sprintf("PRIMARY KEY はテーブルに1つだけ指定できます. field_name=%s", e.fieldName)
+}
+
+type AutoIncrementTypeError struct {
+	fieldName types.FieldName
+}
+
+func (e AutoIncrementTypeError) Error() string {
+	return fmt.Sprintf("AUTO_INCREMENT は INT のフィールドにだけ指定できます. field_name=%s", e.fieldName)
+}
+
+type MultipleAutoIncrementError struct {
+	fieldName types.FieldName
+}
+
+func (e MultipleAutoIncrementError) Error() string {
+	return fmt.Sprintf("AUTO_INCREMENT はテーブルに1つだけ指定できます. field_name=%s", e.fieldName)
+}
+
+type DuplicateKeyError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+	value     string
+}
+
+func (e DuplicateKeyError) Error() string {
+	return fmt.Sprintf("PRIMARY KEY, UNIQUE のフィールドに重複する値を書き込もうとしました. table_name=%s, field_name=%s, value=%s", e.tableName, e.fieldName, e.value)
+}
+
+type ReferencedTableNotFoundError struct {
+	foreignKey string
+	error      error
+}
+
+func (e ReferencedTableNotFoundError) Error() string {
+	return fmt.Sprintf("外部キーが参照するテーブルがありません. foreign_key=%s, error=%+v", e.foreignKey, e.error)
+}
+
+type ReferencedFieldNotKeyError struct {
+	foreignKey string
+}
+
+func (e ReferencedFieldNotKeyError) Error() string {
+	return fmt.Sprintf("外部キーが参照するフィールドは、PRIMARY KEY か UNIQUE でなければなりません. foreign_key=%s", e.foreignKey)
+}
+
+type ForeignKeyTypeMismatchError struct {
+	foreignKey string
+}
+
+func (e ForeignKeyTypeMismatchError) Error() string {
+	return fmt.Sprintf("外部キーのフィールドと、参照するフィールドの型が一致しません. foreign_key=%s", e.foreignKey)
+}
+
+type SetNullOnNotNullFieldError struct {
+	foreignKey string
+}
+
+func (e SetNullOnNotNullFieldError) Error() string {
+	return fmt.Sprintf("NOT NULL のフィールドには ON DELETE SET NULL を指定できません. foreign_key=%s", e.foreignKey)
+}
+
+type NoReferencedRowError struct {
+	tableName    types.TableName
+	fieldName    types.FieldName
+	refTableName types.TableName
+	refFieldName types.FieldName
+	value        string
+}
+
+func (e NoReferencedRowError) Error() string {
+	return fmt.Sprintf("外部キーが参照するレコードがありません. table_name=%s, field_name=%s, ref=%s(%s), value=%s", e.tableName, e.fieldName, e.refTableName, e.refFieldName, e.value)
+}
+
+type RowIsReferencedError struct {
+	tableName        types.TableName
+	fieldName        types.FieldName
+	referencingTable types.TableName
+	referencingField types.FieldName
+	value            string
+}
+
+func (e RowIsReferencedError) Error() string {
+	return fmt.Sprintf("他のテーブルから外部キーで参照されているレコードは、削除や変更ができません. table_name=%s, field_name=%s, referenced_by=%s(%s), value=%s", e.tableName, e.fieldName, e.referencingTable, e.referencingField, e.value)
+}
+
+type TableIsReferencedError struct {
+	tableName        types.TableName
+	referencingTable types.TableName
+}
+
+func (e TableIsReferencedError) Error() string {
+	return fmt.Sprintf("他のテーブルから外部キーで参照されているテーブルは削除できません. table_name=%s, referenced_by=%s", e.tableName, e.referencingTable)
+}
+
+type CannotTruncateReferencedTableError struct {
+	tableName        types.TableName
+	referencingTable types.TableName
+}
+
+func (e CannotTruncateReferencedTableError) Error() string {
+	return fmt.Sprintf("他のテーブルから外部キーで参照されているテーブルは TRUNCATE できません. table_name=%s, referenced_by=%s", e.tableName, e.referencingTable)
+}
+
+type TableDroppedInTransactionError struct {
+	tableName types.TableName
+}
+
+func (e TableDroppedInTransactionError) Error() string {
+	return fmt.Sprintf("同じトランザクションで削除したテーブルと同じ名前のテーブルは、コミットするまで作成できません. table_name=%s", e.tableName)
+}
+
+type DuplicateFieldError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+}
+
+func (e DuplicateFieldError) Error() string {
+	return fmt.Sprintf("テーブルに同じ名前のフィールドが既にあります. table_name=%s, field_name=%s", e.tableName, e.fieldName)
+}
+
+type CannotDropAllFieldsError struct {
+	tableName types.TableName
+}
+
+func (e CannotDropAllFieldsError) Error() string {
+	return fmt.Sprintf("テーブルの全てのフィールドは削除できません. DROP TABLE を使ってください. table_name=%s", e.tableName)
+}
+
+type FieldInForeignKeyError struct {
+	tableName  types.TableName
+	fieldName  types.FieldName
+	foreignKey string
+}
+
+func (e FieldInForeignKeyError) Error() string {
+	return fmt.Sprintf("外部キーに使われているフィールドは削除できません. table_name=%s, field_name=%s, foreign_key=%s", e.tableName, e.fieldName, e.foreignKey)
+}
+
+type FieldInCheckConstraintError struct {
+	tableName types.TableName
+	fieldName types.FieldName
+	check     string
+}
+
+func (e FieldInCheckConstraintError) Error() string {
+	return fmt.Sprintf("他のフィールドの CHECK 制約で使われているフィールドは削除できません. table_name=%s, field_name=%s, check=%s", e.tableName, e.fieldName, e.check)
+}
+
+type DuplicateQueryableError struct {
+	name data.Queryable
+}
+
+func (e DuplicateQueryableError) Error() string {
+	return fmt.Sprintf("FROM 句に同じ名前のテーブルまたは別名が複数あります. name=%s", e.name)
+}
+
+type UnknownSystemViewError struct {
+	name data.Queryable
+}
+
+func (e UnknownSystemViewError) Error() string {
+	return fmt.Sprintf("不明な information_schema のシステムビューが指定されました. name=%s", e.name)
+}
@@ -0,0 +1,400 @@
+package planning
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/metadata"
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"slices"
+)
+
+// CREATE TABLE で指定された外部キーが、参照先のテーブルの定義と矛盾しないか確認する.
+// 参照先のフィールドは PRIMARY KEY か UNIQUE で、外部キーのフィールドと同じ型でなければならない.
+// 自身を参照する場合は、作成しようとしているテーブルの定義で確認する.
+func validateForeignKeys(createTableData *data.CreateTableData, metadataManager *metadata.MetadataManager, transaction *transaction.Transaction) error {
+	constraints := &tableConstraints{tableName: createTableData.TableName, constraints: createTableData.Constraints}
+
+	for _, foreignKey := range createTableData.ForeignKeys {
+		fieldType, err := createTableData.Schema.FieldType(foreignKey.FieldName)
+		if err != nil {
+			return ConstraintFieldNotFoundError{foreignKey.FieldName, types.ConstraintDef(foreignKey.ToString())}
+		}
+
+		refSchema, refConstraints := createTableData.Schema, constraints
+		if foreignKey.RefTableName != createTableData.TableName {
+			layout, err := metadataManager.GetLayout(foreignKey.RefTableName, transaction)
+			if err != nil {
+				return ReferencedTableNotFoundError{foreignKey.ToString(), err}
+			}
+			refSchema = layout.GetSchema()
+
+			refConstraints, err = newTableConstraints(foreignKey.RefTableName, metadataManager, transaction)
+			if err != nil {
+				return err
+			}
+		}
+
+		refFieldType, err := refSchema.FieldType(foreignKey.RefFieldName)
+		if err != nil {
+			return ReferencedTableNotFoundError{foreignKey.ToString(), err}
+		}
+		if refFieldType != fieldType {
+			return ForeignKeyTypeMismatchError{foreignKey.ToString()}
+		}
+		if !slices.Contains(refConstraints.keyFields(), foreignKey.RefFieldName) {
+			return ReferencedFieldNotKeyError{foreignKey.ToString()}
+		}
+
+		if foreignKey.OnDelete == constants.SET_NULL && constraints.isNotNull(foreignKey.FieldName) {
+			return SetNullOnNotNullFieldError{foreignKey.ToString()}
+		}
+	}
+	return nil
+}
+
+func newForeignKeyCatalogRows(tableName types.TableName, foreignKeys []*data.ForeignKey) []metadata.ForeignKeyCatalogRow {
+	rows := make([]metadata.ForeignKeyCatalogRow, 0, len(foreignKeys))
+	for _, foreignKey := range foreignKeys {
+		rows = append(rows, metadata.ForeignKeyCatalogRow{
+			TableName:    tableName,
+			FieldName:    foreignKey.FieldName,
+			RefTableName: foreignKey.RefTableName,
+			RefFieldName: foreignKey.RefFieldName,
+			OnDelete:     foreignKey.OnDelete,
+		})
+	}
+	return rows
+}
+
+// テーブルの外部キー. テーブルが他のテーブルを参照しているものと、他のテーブルから参照されているものの両方を持つ.
+// 制約と同じく、書き込む前にレコードを確認し、違反する場合は何も書き込まずにエラーを返す.
+type tableForeignKeys struct {
+	tableName       types.TableName
+	references      []metadata.ForeignKeyCatalogRow
+	referencedBy    []metadata.ForeignKeyCatalogRow
+	metadataManager *metadata.MetadataManager
+	transaction     *transaction.Transaction
+}
+
+func newTableForeignKeys(tableName types.TableName, metadataManager *metadata.MetadataManager, transaction *transaction.Transaction) *tableForeignKeys {
+	return &tableForeignKeys{
+		tableName:       tableName,
+		references:      metadataManager.GetForeignKeys(tableName, transaction),
+		referencedBy:    metadataManager.GetReferencingForeignKeys(tableName, transaction),
+		metadataManager: metadataManager,
+		transaction:     transaction,
+	}
+}
+
+// 書き込むレコードの外部キーのフィールドの値が、参照先のテーブルにあるか確認する. NULL は何も参照しないので確認しない.
+// rows は書き込むレコードの値で、fieldNames と位置で対応する. changedFields に含まれない外部キーは確認しない.
+func (tf *tableForeignKeys) validateReferences(changedFields []types.FieldName, fieldNames []types.FieldName, rows [][]query.Constant) error {
+	for _, foreignKey := range tf.references {
+		if !slices.Contains(changedFields, foreignKey.FieldName) {
+			continue
+		}
+
+		refValues, err := readFieldValues(foreignKey.RefTableName, foreignKey.RefFieldName, tf.metadataManager, tf.transaction)
+		if err != nil {
+			return err
+		}
+
+		// 自身を参照する場合は、同時に書き込むレコードも参照できる.
+		// 参照先のフィールドが fieldNames に無ければ、その値は書き込みで変わらず、既にテーブルにある.
+		if refIndex := slices.Index(fieldNames, foreignKey.RefFieldName); foreignKey.RefTableName == tf.tableName && refIndex >= 0 {
+			for _, row := range rows {
+				refValues[row[refIndex]] = true
+			}
+		}
+
+		index := slices.Index(fieldNames, foreignKey.FieldName)
+		for _, row := range rows {
+			if value := row[index]; !query.IsNull(value) && !refValues[value] {
+				return NoReferencedRowError{tf.tableName, foreignKey.FieldName, foreignKey.RefTableName, foreignKey.RefFieldName, value.ToString()}
+			}
+		}
+	}
+	return nil
+}
+
+// UPDATE で参照されているフィールドの値を変えようとしていないか確認する. ON UPDATE は RESTRICT の動作だけをサポートする.
+// oldRows, newRows は更新前と更新後のレコードの値で、fieldNames と位置で対応する.
+func (tf *tableForeignKeys) validateNotReferenced(changedFields []types.FieldName, fieldNames []types.FieldName, oldRows [][]query.Constant, newRows [][]query.Constant) error {
+	for _, foreignKey := range tf.referencedBy {
+		if !slices.Contains(changedFields, foreignKey.RefFieldName) {
+			continue
+		}
+
+		referencingValues, err := readFieldValues(foreignKey.TableName, foreignKey.FieldName, tf.metadataManager, tf.transaction)
+		if err != nil {
+			return err
+		}
+
+		index := slices.Index(fieldNames, foreignKey.RefFieldName)
+		for i, oldRow := range oldRows {
+			if oldValue := oldRow[index]; oldValue != newRows[i][index] && referencingValues[oldValue] {
+				return RowIsReferencedError{tf.tableName, foreignKey.RefFieldName, foreignKey.TableName, foreignKey.FieldName, oldValue.ToString()}
+			}
+		}
+	}
+	return nil
+}
+
+// テーブルのフィールドの、NULL 以外の値の集合を返す.
+func readFieldValues(tableName types.TableName, fieldName types.FieldName, metadataManager *metadata.MetadataManager, transaction *transaction.Transaction) (map[query.Constant]bool, error) {
+	plan, err := NewTablePlan(transaction, tableName, metadataManager)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := plan.Open()
+	defer scan.Close()
+
+	values := make(map[query.Constant]bool)
+	for scan.Next() {
+		value, err := scan.GetValue(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		if !query.IsNull(value) {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// DELETE で削除するレコードと、ON DELETE の動作によって削除・更新される、参照しているテーブルのレコードをまとめたもの.
+// RESTRICT に違反するレコードが見つかった場合に何も削除しないように、全て集めてから実行する.
+type deletion struct {
+	// 削除するレコードを、テーブルごとに削除対象に加えた順に持つ.
+	tableNames []types.TableName
+	deletes    map[types.TableName][]record.RecordID
+	isDeleted  map[types.TableName]map[record.RecordID]bool
+	// ON DELETE SET NULL で NULL にするフィールドを、テーブル、レコードごとに持つ.
+	setNulls        map[types.TableName]map[record.RecordID][]types.FieldName
+	metadataManager *metadata.MetadataManager
+	transaction     *transaction.Transaction
+}
+
+func newDeletion(metadataManager *metadata.MetadataManager, transaction *transaction.Transaction) *deletion {
+	return &deletion{
+		deletes:         make(map[types.TableName][]record.RecordID),
+		isDeleted:       make(map[types.TableName]map[record.RecordID]bool),
+		setNulls:        make(map[types.TableName]map[record.RecordID][]types.FieldName),
+		metadataManager: metadataManager,
+		transaction:     transaction,
+	}
+}
+
+// tableName の recordIDs のレコードを削除対象に加え、それらを参照しているレコードに ON DELETE の動作を適用する.
+// CASCADE で削除されるレコードも、再帰的に削除対象に加える.
+func (d *deletion) add(tableName types.TableName, recordIDs []record.RecordID) error {
+	if _, exists := d.isDeleted[tableName]; !exists {
+		d.tableNames = append(d.tableNames, tableName)
+		d.isDeleted[tableName] = make(map[record.RecordID]bool)
+	}
+
+	added := []record.RecordID{}
+	for _, recordID := range recordIDs {
+		if !d.isDeleted[tableName][recordID] {
+			d.isDeleted[tableName][recordID] = true
+			added = append(added, recordID)
+		}
+	}
+	d.deletes[tableName] = append(d.deletes[tableName], added...)
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	for _, foreignKey := range d.metadataManager.GetReferencingForeignKeys(tableName, d.transaction) {
+		deletedValues, err := d.readValues(tableName, added, foreignKey.RefFieldName)
+		if err != nil {
+			return err
+		}
+		if len(deletedValues) == 0 {
+			continue
+		}
+
+		referencingRecordIDs, err := d.findReferencingRecords(foreignKey, deletedValues)
+		if err != nil {
+			return err
+		}
+		if len(referencingRecordIDs) == 0 {
+			continue
+		}
+
+		switch foreignKey.OnDelete {
+		case constants.CASCADE:
+			if err := d.add(foreignKey.TableName, referencingRecordIDs); err != nil {
+				return err
+			}
+		case constants.SET_NULL:
+			if _, exists := d.setNulls[foreignKey.TableName]; !exists {
+				d.setNulls[foreignKey.TableName] = make(map[record.RecordID][]types.FieldName)
+			}
+			for _, recordID := range referencingRecordIDs {
+				d.setNulls[foreignKey.TableName][recordID] = append(d.setNulls[foreignKey.TableName][recordID], foreignKey.FieldName)
+			}
+		default:
+			value, err := d.readValues(foreignKey.TableName, referencingRecordIDs[:1], foreignKey.FieldName)
+			if err != nil {
+				return err
+			}
+			for referencedValue := range value {
+				return RowIsReferencedError{tableName, foreignKey.RefFieldName, foreignKey.TableName, foreignKey.FieldName, referencedValue.ToString()}
+			}
+		}
+	}
+	return nil
+}
+
+// 集めた削除と SET NULL を実行する. 削除されるレコードは SET NULL しない.
+// SET NULL した後のレコードが制約に違反する場合は、何も書き込まずにエラーを返す.
+func (d *deletion) execute() error {
+	if err := d.validateSetNulls(); err != nil {
+		return err
+	}
+
+	for tableName, setNulls := range d.setNulls {
+		updateScan, err := d.openTable(tableName)
+		if err != nil {
+			return err
+		}
+
+		for recordID, fieldNames := range setNulls {
+			if d.isDeleted[tableName][recordID] {
+				continue
+			}
+			updateScan.MoveToRecordID(recordID)
+			for _, fieldName := range fieldNames {
+				if err := updateScan.SetValue(fieldName, query.NewNullConstant()); err != nil {
+					updateScan.Close()
+					return err
+				}
+			}
+		}
+		updateScan.Close()
+		d.metadataManager.AddModifiedCount(tableName, types.Int(len(setNulls)))
+	}
+
+	for _, tableName := range d.tableNames {
+		updateScan, err := d.openTable(tableName)
+		if err != nil {
+			return err
+		}
+
+		for _, recordID := range d.deletes[tableName] {
+			updateScan.MoveToRecordID(recordID)
+			updateScan.Delete()
+		}
+		updateScan.Close()
+		d.metadataManager.AddModifiedCount(tableName, types.Int(len(d.deletes[tableName])))
+		d.metadataManager.AddStatDelta(tableName, updateScan.GetRecordsDelta(), updateScan.GetAppendedBlocks(), d.transaction)
+	}
+	return nil
+}
+
+// SET NULL した後のレコードが、参照しているテーブルの制約を満たすか確認する.
+// NOT NULL のフィールドへの SET NULL は CREATE TABLE で拒否しているが、CHECK 制約はレコードの値によるのでここで確認する.
+func (d *deletion) validateSetNulls() error {
+	for tableName, setNulls := range d.setNulls {
+		constraints, err := newTableConstraints(tableName, d.metadataManager, d.transaction)
+		if err != nil {
+			return err
+		}
+
+		updateScan, err := d.openTable(tableName)
+		if err != nil {
+			return err
+		}
+		fieldNames := updateScan.GetFields()
+
+		for recordID, nullFields := range setNulls {
+			if d.isDeleted[tableName][recordID] {
+				continue
+			}
+			updateScan.MoveToRecordID(recordID)
+
+			values := make([]query.Constant, 0, len(fieldNames))
+			for _, fieldName := range fieldNames {
+				value, err := updateScan.GetValue(fieldName)
+				if err != nil {
+					updateScan.Close()
+					return err
+				}
+				if slices.Contains(nullFields, fieldName) {
+					value = query.NewNullConstant()
+				}
+				values = append(values, value)
+			}
+
+			if err := constraints.validate(query.NewRowScan(fieldNames, values)); err != nil {
+				updateScan.Close()
+				return err
+			}
+		}
+		updateScan.Close()
+	}
+	return nil
+}
+
+// 外部キーのフィールドの値が values のいずれかである、まだ削除対象になっていないレコードを探す.
+func (d *deletion) findReferencingRecords(foreignKey metadata.ForeignKeyCatalogRow, values map[query.Constant]bool) ([]record.RecordID, error) {
+	updateScan, err := d.openTable(foreignKey.TableName)
+	if err != nil {
+		return nil, err
+	}
+	defer updateScan.Close()
+
+	recordIDs := []record.RecordID{}
+	for updateScan.Next() {
+		recordID := updateScan.GetCurrentRecordID()
+		if d.isDeleted[foreignKey.TableName][recordID] {
+			continue
+		}
+
+		value, err := updateScan.GetValue(foreignKey.FieldName)
+		if err != nil {
+			return nil, err
+		}
+		if values[value] {
+			recordIDs = append(recordIDs, recordID)
+		}
+	}
+	return recordIDs, nil
+}
+
+// recordIDs のレコードの、フィールドの NULL 以外の値の集合を返す.
+func (d *deletion) readValues(tableName types.TableName, recordIDs []record.RecordID, fieldName types.FieldName) (map[query.Constant]bool, error) {
+	updateScan, err := d.openTable(tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer updateScan.Close()
+
+	values := make(map[query.Constant]bool)
+	for _, recordID := range recordIDs {
+		updateScan.MoveToRecordID(recordID)
+		value, err := updateScan.GetValue(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		if !query.IsNull(value) {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+func (d *deletion) openTable(tableName types.TableName) (*query.TableScan, error) {
+	plan, err := NewTablePlan(d.transaction, tableName, d.metadataManager)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: TablePlan の scan は TableScan なので、キャストして問題ない.
+	return plan.Open().(*query.TableScan), nil
+}
@@ -0,0 +1,84 @@
+package planning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForeignKey(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, foreignKeyTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE departments (id INT PRIMARY KEY, name VARCHAR(10))", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE employees (id INT PRIMARY KEY, department_id INT REFERENCES departments(id))", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE projects (id INT PRIMARY KEY, department_id INT REFERENCES departments(id) ON DELETE CASCADE)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE tasks (id INT PRIMARY KEY, project_id INT REFERENCES projects(id) ON DELETE CASCADE)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE rooms (id INT PRIMARY KEY, department_id INT REFERENCES departments(id) ON DELETE SET NULL)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO departments (id, name) VALUES (1, 'sales'), (2, 'dev'), (3, 'hr'), (4, 'ops')", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO employees (id, department_id) VALUES (1, 1), (2, NULL)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO projects (id, department_id) VALUES (1, 2), (2, 2), (3, 3)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO tasks (id, project_id) VALUES (1, 1), (2, 2), (3, 3)", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO rooms (id, department_id) VALUES (1, 2), (2, 3)", transaction)
+
+	t.Run("参照先に無い値は書き込めず、NULL は書き込めること.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("INSERT INTO employees (id, department_id) VALUES (3, 9)", transaction)
+		assert.IsType(t, NoReferencedRowError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE employees SET department_id = 9 WHERE id = 1", transaction)
+		assert.IsType(t, NoReferencedRowError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE employees SET department_id = NULL WHERE id = 1", transaction)
+		assert.NoError(t, err)
+		mustExecuteUpdate(t, planner, "UPDATE employees SET department_id = 1 WHERE id = 1", transaction)
+		assert.Equal(t, []string{"1, 1", "2, NULL"}, queryRows(t, planner, "SELECT id, department_id FROM employees", transaction))
+	})
+
+	t.Run("RESTRICT で参照されているレコードは、削除も参照されている値の更新もできないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("DELETE FROM departments WHERE id = 1", transaction)
+		assert.IsType(t, RowIsReferencedError{}, err)
+
+		_, err = planner.ExecuteUpdate("UPDATE departments SET id = 9 WHERE id = 1", transaction)
+		assert.IsType(t, RowIsReferencedError{}, err)
+
+		assert.Equal(t, []string{"1, 'sales'", "2, 'dev'", "3, 'hr'", "4, 'ops'"}, queryRows(t, planner, "SELECT id, name FROM departments", transaction), "何も変更されないこと.")
+	})
+
+	t.Run("CASCADE で参照しているレコードが再帰的に削除され、SET NULL で参照している値が NULL になること.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("DELETE FROM departments WHERE id = 2", transaction)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"1, 'sales'", "3, 'hr'", "4, 'ops'"}, queryRows(t, planner, "SELECT id, name FROM departments", transaction))
+		assert.Equal(t, []string{"3, 3"}, queryRows(t, planner, "SELECT id, department_id FROM projects", transaction))
+		assert.Equal(t, []string{"3, 3"}, queryRows(t, planner, "SELECT id, project_id FROM tasks", transaction))
+		assert.Equal(t, []string{"1, NULL", "2, 3"}, queryRows(t, planner, "SELECT id, department_id FROM rooms", transaction))
+	})
+
+	t.Run("CASCADE の先で RESTRICT に違反する場合は、何も削除されないこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "CREATE TABLE reviews (id INT PRIMARY KEY, task_id INT REFERENCES tasks(id))", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO reviews (id, task_id) VALUES (1, 3)", transaction)
+
+		_, err := planner.ExecuteUpdate("DELETE FROM departments WHERE id = 3", transaction)
+		assert.IsType(t, RowIsReferencedError{}, err)
+
+		assert.Equal(t, []string{"1, 'sales'", "3, 'hr'", "4, 'ops'"}, queryRows(t, planner, "SELECT id, name FROM departments", transaction))
+		assert.Equal(t, []string{"3, 3"}, queryRows(t, planner, "SELECT id, department_id FROM projects", transaction))
+		assert.Equal(t, []string{"1, NULL", "2, 3"}, queryRows(t, planner, "SELECT id, department_id FROM rooms", transaction))
+	})
+
+	t.Run("NOT NULL のフィールドには ON DELETE SET NULL を指定できないこと.", func(t *testing.T) {
+		_, err := planner.ExecuteUpdate("CREATE TABLE desks (id INT PRIMARY KEY, department_id INT NOT NULL REFERENCES departments(id) ON DELETE SET NULL)", transaction)
+		assert.IsType(t, SetNullOnNotNullFieldError{}, err)
+	})
+
+	t.Run("SET NULL した後のレコードが CHECK 制約に違反する場合は、何も削除されないこと.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "CREATE TABLE lockers (id INT PRIMARY KEY, department_id INT CHECK (department_id IS NOT NULL) REFERENCES departments(id) ON DELETE SET NULL)", transaction)
+		mustExecuteUpdate(t, planner, "INSERT INTO lockers (id, department_id) VALUES (1, 4)", transaction)
+
+		_, err := planner.ExecuteUpdate("DELETE FROM departments WHERE id = 4", transaction)
+		assert.IsType(t, CheckConstraintViolationError{}, err)
+
+		assert.Equal(t, []string{"1, 'sales'", "3, 'hr'", "4, 'ops'"}, queryRows(t, planner, "SELECT id, name FROM departments", transaction))
+		assert.Equal(t, []string{"1, 4"}, queryRows(t, planner, "SELECT id, department_id FROM lockers", transaction))
+	})
+}
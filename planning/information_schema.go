@@ -0,0 +1,351 @@
+package planning
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/metadata"
+	"simple-db-go/parsing"
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"slices"
+	"strings"
+)
+
+// システムビューの名前に付けるスキーマ名. `information_schema.tables` のように指定する.
+const INFORMATION_SCHEMA = "information_schema"
+
+// FROM 句の名前が information_schema のシステムビューかどうか. スキーマ名は大文字小文字を区別しない.
+func isSystemView(queryable data.Queryable) bool {
+	return strings.HasPrefix(strings.ToLower(queryable.ToString()), INFORMATION_SCHEMA+".")
+}
+
+var _ query.Plan = (*SystemViewPlan)(nil)
+
+// information_schema のシステムビューの plan.
+// plan を作る時にカタログと統計情報からレコードを全て組み立てておき、Open ではそれを順に出力する.
+// NOTE: レコードはテーブルに保存しないので、ブロックにはアクセスしない.
+type SystemViewPlan struct {
+	schema     *record.Schema
+	fieldNames []types.FieldName
+	rows       [][]query.Constant
+}
+
+// システムビューの列の定義.
+type systemViewColumn struct {
+	fieldName types.FieldName
+	fieldType types.FieldType
+}
+
+// 文字列の列の長さは、レコードの中で最も長い値に合わせる.
+func newSystemViewPlan(columns []systemViewColumn, rows [][]query.Constant) *SystemViewPlan {
+	schema := record.NewSchema()
+	fieldNames := make([]types.FieldName, 0, len(columns))
+	for i, column := range columns {
+		fieldNames = append(fieldNames, column.fieldName)
+		if column.fieldType == constants.INTEGER {
+			schema.AddIntField(column.fieldName)
+			continue
+		}
+
+		length := 1
+		for _, row := range rows {
+			if value, ok := row[i].GetValue().(string); ok {
+				length = max(length, len(value))
+			}
+		}
+		schema.AddStringField(column.fieldName, types.FieldLength(length))
+	}
+
+	return &SystemViewPlan{schema: schema, fieldNames: fieldNames, rows: rows}
+}
+
+func (p *SystemViewPlan) Open() query.Scan {
+	return query.NewValuesScan(p.fieldNames, p.rows)
+}
+
+func (p *SystemViewPlan) GetBlocksAccessed() types.Int {
+	return 0
+}
+
+func (p *SystemViewPlan) GetRecordsOutput() types.Int {
+	return types.Int(len(p.rows))
+}
+
+// レコードは全てメモリ上にあるので、異なる値の数を正確に数える.
+func (p *SystemViewPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	index := slices.Index(p.fieldNames, fieldName)
+	if index < 0 {
+		return 0
+	}
+
+	values := make(map[query.Constant]bool)
+	for _, row := range p.rows {
+		values[row[index]] = true
+	}
+	return types.Int(len(values))
+}
+
+func (p *SystemViewPlan) GetSchema() *record.Schema {
+	return p.schema
+}
+
+// information_schema のシステムビューの plan を作る.
+//   - tables: テーブルとビューの一覧. テーブルのレコード数は統計情報から、データサイズはファイルのブロック数から求める.
+//   - columns: テーブルとビューのフィールドの一覧と、フィールドに定義された制約.
+//   - statistics: インデックスの一覧. cardinality は統計情報のインデックスのフィールドの異なる値の数.
+//   - views: ビューの一覧と定義.
+func newInformationSchemaPlan(queryable data.Queryable, transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	switch strings.ToLower(queryable.ToString()) {
+	case INFORMATION_SCHEMA + ".tables":
+		return newTablesSystemViewPlan(transaction, metadataManager)
+	case INFORMATION_SCHEMA + ".columns":
+		return newColumnsSystemViewPlan(transaction, metadataManager)
+	case INFORMATION_SCHEMA + ".statistics":
+		return newStatisticsSystemViewPlan(transaction, metadataManager)
+	case INFORMATION_SCHEMA + ".views":
+		return newViewsSystemViewPlan(transaction, metadataManager)
+	default:
+		return nil, UnknownSystemViewError{queryable}
+	}
+}
+
+func newTablesSystemViewPlan(transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	columns := []systemViewColumn{
+		{"table_name", constants.VARCHAR},
+		{"table_type", constants.VARCHAR},
+		{"table_rows", constants.INTEGER},
+		{"data_length", constants.INTEGER},
+		{"auto_increment_value", constants.INTEGER},
+	}
+
+	// table_rows は保持している統計情報のレコード数で、統計情報が無いテーブルは NULL にする.
+	// 統計情報を計算するためにテーブルを全て読むことはしない.
+	rows := [][]query.Constant{}
+	for _, tableName := range metadataManager.GetTableNames(transaction) {
+		var tableRows query.Constant = query.NewNullConstant()
+		if statInfo, exists := metadataManager.GetCachedStatInfo(tableName, transaction); exists {
+			tableRows = query.NewIntConstant(statInfo.GetRecordsOutput())
+		}
+
+		tableType := "BASE TABLE"
+		if metadata.IsCatalogTable(tableName) {
+			tableType = "SYSTEM TABLE"
+		}
+		var autoIncrement query.Constant = query.NewNullConstant()
+		if row, exists := metadataManager.GetAutoIncrement(tableName, transaction); exists {
+			autoIncrement = query.NewIntConstant(row.NextValue)
+		}
+
+		rows = append(rows, []query.Constant{
+			query.NewStrConstant(string(tableName)),
+			query.NewStrConstant(tableType),
+			tableRows,
+			query.NewIntConstant(transaction.Size(query.TableFileName(tableName)) * transaction.BlockSize()),
+			autoIncrement,
+		})
+	}
+
+	// ビューはレコードを持たないので、レコード数などは NULL にする.
+	for _, viewName := range metadataManager.GetViewNames(transaction) {
+		rows = append(rows, []query.Constant{
+			query.NewStrConstant(string(viewName)),
+			query.NewStrConstant("VIEW"),
+			query.NewNullConstant(),
+			query.NewNullConstant(),
+			query.NewNullConstant(),
+		})
+	}
+
+	return newSystemViewPlan(columns, rows), nil
+}
+
+func newColumnsSystemViewPlan(transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	columns := []systemViewColumn{
+		{"table_name", constants.VARCHAR},
+		{"column_name", constants.VARCHAR},
+		{"ordinal_position", constants.INTEGER},
+		{"data_type", constants.VARCHAR},
+		{"character_maximum_length", constants.INTEGER},
+		{"is_nullable", constants.VARCHAR},
+		{"column_default", constants.VARCHAR},
+		{"column_key", constants.VARCHAR},
+		{"extra", constants.VARCHAR},
+	}
+
+	// 制約の定義を読めないテーブルも、フィールドは表示して、制約から求める列を NULL にする.
+	rows := [][]query.Constant{}
+	for _, tableName := range metadataManager.GetTableNames(transaction) {
+		layout, err := metadataManager.GetLayout(tableName, transaction)
+		if err != nil {
+			return nil, err
+		}
+		// 制約の定義をパースできない場合は nil になる.
+		constraints, _ := newTableConstraints(tableName, metadataManager, transaction)
+		tableRows, err := newColumnRows(string(tableName), layout.GetSchema(), constraints)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, tableRows...)
+	}
+
+	// ビューのフィールドは、ビューの定義を plan して求める. ビューには制約が無い.
+	// 参照するテーブルを削除したなどで plan できないビューは、フィールドが分からないので表示しない.
+	for _, viewName := range metadataManager.GetViewNames(transaction) {
+		viewPlan, err := newViewPlan(viewName, transaction, metadataManager)
+		if err != nil {
+			continue
+		}
+		viewRows, err := newColumnRows(string(viewName), viewPlan.GetSchema(), &tableConstraints{tableName: types.TableName(viewName)})
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, viewRows...)
+	}
+
+	return newSystemViewPlan(columns, rows), nil
+}
+
+// information_schema.columns の、1つのテーブルかビューのレコード. ordinal_position は 1 から数える.
+// constraints が nil の場合は、制約から求める is_nullable, column_default, column_key, extra を NULL にする.
+func newColumnRows(tableName string, schema *record.Schema, constraints *tableConstraints) ([][]query.Constant, error) {
+	rows := make([][]query.Constant, 0, len(schema.Fields()))
+	for i, fieldName := range schema.Fields() {
+		fieldType, err := schema.FieldType(fieldName)
+		if err != nil {
+			return nil, err
+		}
+
+		dataType := "int"
+		var maximumLength query.Constant = query.NewNullConstant()
+		if fieldType == constants.VARCHAR {
+			length, err := schema.Length(fieldName)
+			if err != nil {
+				return nil, err
+			}
+			dataType = "varchar"
+			maximumLength = query.NewIntConstant(types.Int(length))
+		}
+
+		rows = append(rows, append([]query.Constant{
+			query.NewStrConstant(tableName),
+			query.NewStrConstant(string(fieldName)),
+			query.NewIntConstant(types.Int(i + 1)),
+			query.NewStrConstant(dataType),
+			maximumLength,
+		}, newConstraintColumns(fieldName, constraints)...))
+	}
+	return rows, nil
+}
+
+// information_schema.columns の、制約から求める is_nullable, column_default, column_key, extra の値.
+func newConstraintColumns(fieldName types.FieldName, constraints *tableConstraints) []query.Constant {
+	if constraints == nil {
+		return []query.Constant{query.NewNullConstant(), query.NewNullConstant(), query.NewNullConstant(), query.NewNullConstant()}
+	}
+
+	isNullable := "YES"
+	if constraints.isNotNull(fieldName) {
+		isNullable = "NO"
+	}
+
+	// 既定値は、文字列の場合も引用符を付けずに表す.
+	var columnDefault query.Constant = query.NewNullConstant()
+	switch defaultValue := constraints.defaultValue(fieldName).GetValue().(type) {
+	case string:
+		columnDefault = query.NewStrConstant(defaultValue)
+	case types.Int:
+		columnDefault = query.NewStrConstant(defaultValue.ToString())
+	}
+
+	columnKey := ""
+	for _, constraint := range constraints.constraints {
+		if constraint.FieldName != fieldName {
+			continue
+		}
+		if constraint.Type == constants.PRIMARY_KEY {
+			columnKey = "PRI"
+		} else if constraint.Type == constants.UNIQUE && columnKey == "" {
+			columnKey = "UNI"
+		}
+	}
+
+	extra := ""
+	if autoIncrementFieldName, exists := autoIncrementField(constraints.constraints); exists && autoIncrementFieldName == fieldName {
+		extra = "auto_increment"
+	}
+
+	return []query.Constant{
+		query.NewStrConstant(isNullable),
+		columnDefault,
+		query.NewStrConstant(columnKey),
+		query.NewStrConstant(extra),
+	}
+}
+
+// ビューの定義をパースして plan する.
+func newViewPlan(viewName types.ViewName, transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	viewDef, err := metadataManager.GetViewDef(viewName, transaction)
+	if err != nil {
+		return nil, err
+	}
+	viewData, err := parsing.NewParser().Parse(string(viewDef))
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: ビューの定義は SELECT 文だけ許可するようパースしているので、QueryData と強制してOK.
+	return NewBasicQueryPlanner(metadataManager).CreatePlan(viewData.(*data.QueryData), transaction)
+}
+
+func newStatisticsSystemViewPlan(transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	columns := []systemViewColumn{
+		{"table_name", constants.VARCHAR},
+		{"index_name", constants.VARCHAR},
+		{"seq_in_index", constants.INTEGER},
+		{"column_name", constants.VARCHAR},
+		{"cardinality", constants.INTEGER},
+	}
+
+	// cardinality は保持している統計情報の異なる値の数で、統計情報が無いテーブルは NULL にする.
+	// 統計情報を計算するためにテーブルを全て読むことはしない.
+	rows := [][]query.Constant{}
+	for _, index := range metadataManager.GetIndexes(transaction) {
+		var cardinality query.Constant = query.NewNullConstant()
+		if statInfo, exists := metadataManager.GetCachedStatInfo(index.TableName, transaction); exists {
+			cardinality = query.NewIntConstant(statInfo.GetDistinctValues(index.FieldName))
+		}
+
+		// インデックスは1つのフィールドにだけ作れるので、seq_in_index は常に 1 になる.
+		rows = append(rows, []query.Constant{
+			query.NewStrConstant(string(index.TableName)),
+			query.NewStrConstant(string(index.IndexName)),
+			query.NewIntConstant(1),
+			query.NewStrConstant(string(index.FieldName)),
+			cardinality,
+		})
+	}
+
+	return newSystemViewPlan(columns, rows), nil
+}
+
+func newViewsSystemViewPlan(transaction *transaction.Transaction, metadataManager *metadata.MetadataManager) (query.Plan, error) {
+	columns := []systemViewColumn{
+		{"table_name", constants.VARCHAR},
+		{"view_definition", constants.VARCHAR},
+	}
+
+	rows := [][]query.Constant{}
+	for _, viewName := range metadataManager.GetViewNames(transaction) {
+		viewDef, err := metadataManager.GetViewDef(viewName, transaction)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, []query.Constant{
+			query.NewStrConstant(string(viewName)),
+			query.NewStrConstant(string(viewDef)),
+		})
+	}
+
+	return newSystemViewPlan(columns, rows), nil
+}
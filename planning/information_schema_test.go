@@ -0,0 +1,52 @@
+package planning
+
+import (
+	"simple-db-go/constants"
+	"simple-db-go/metadata"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInformationSchema(t *testing.T) {
+	planner, transaction := newPlannerForTest(t, informationSchemaTestName)
+	defer transaction.Rollback()
+
+	mustExecuteUpdate(t, planner, "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(10) NOT NULL)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE TABLE items (id INT, price INT DEFAULT 0)", transaction)
+	mustExecuteUpdate(t, planner, "CREATE VIEW user_names AS SELECT name FROM users", transaction)
+	mustExecuteUpdate(t, planner, "CREATE VIEW item_prices AS SELECT price FROM items", transaction)
+	mustExecuteUpdate(t, planner, "INSERT INTO users (id, name) VALUES (1, 'alice'), (2, 'bob')", transaction)
+
+	t.Run("table_rows は保持している統計情報のレコード数で、統計情報が無ければ NULL になること.", func(t *testing.T) {
+		assert.Equal(t, []string{"'items', NULL"}, queryRows(t, planner, "SELECT table_name, table_rows FROM information_schema.tables WHERE table_name = 'items'", transaction))
+
+		mustExecuteUpdate(t, planner, "ANALYZE TABLE items", transaction)
+		assert.Equal(t, []string{"'items', 0"}, queryRows(t, planner, "SELECT table_name, table_rows FROM information_schema.tables WHERE table_name = 'items'", transaction))
+	})
+
+	t.Run("制約の定義を読めないテーブルは、制約から求める列を NULL にすること.", func(t *testing.T) {
+		metadataManager := planner.updatePlanner.(*BasicUpdatePlanner).metadataManager
+		metadataManager.ReplaceConstraints("items", []metadata.ConstraintCatalogRow{
+			{TableName: "items", FieldName: "price", Type: constants.DEFAULT, Definition: "DEFAULT"},
+		}, transaction)
+
+		assert.Equal(t, []string{
+			"'id', NULL, NULL",
+			"'price', NULL, NULL",
+		}, queryRows(t, planner, "SELECT column_name, is_nullable, column_default FROM information_schema.columns WHERE table_name = 'items'", transaction))
+		assert.Equal(t, []string{
+			"'id', 'NO', 'PRI'",
+			"'name', 'NO', ''",
+		}, queryRows(t, planner, "SELECT column_name, is_nullable, column_key FROM information_schema.columns WHERE table_name = 'users'", transaction))
+	})
+
+	t.Run("plan できないビューは表示せず、他のテーブルとビューは表示すること.", func(t *testing.T) {
+		mustExecuteUpdate(t, planner, "DROP TABLE items", transaction)
+
+		tableNames := queryRows(t, planner, "SELECT table_name FROM information_schema.columns", transaction)
+		assert.Contains(t, tableNames, "'users'")
+		assert.Contains(t, tableNames, "'user_names'")
+		assert.NotContains(t, tableNames, "'item_prices'")
+	})
+}
@@ -0,0 +1,45 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*LimitPlan)(nil)
+
+type LimitPlan struct {
+	plan   query.Plan
+	limit  types.Int
+	offset types.Int
+}
+
+func NewLimitPlan(plan query.Plan, limit types.Int, offset types.Int) (query.Plan, error) {
+	if limit < 0 || offset < 0 {
+		return nil, InvalidLimitError{limit, offset}
+	}
+
+	return &LimitPlan{plan: plan, limit: limit, offset: offset}, nil
+}
+
+func (p *LimitPlan) Open() query.Scan {
+	return query.NewLimitScan(p.plan.Open(), p.limit, p.offset)
+}
+
+// 途中で読むのをやめるので、実際にはもっと少なくなることが多い.
+// ただし、ソートなどで元の plan が全てのレコードを読む場合もあるので、上限として元の plan の値を返す.
+func (p *LimitPlan) GetBlocksAccessed() types.Int {
+	return p.plan.GetBlocksAccessed()
+}
+
+func (p *LimitPlan) GetRecordsOutput() types.Int {
+	return min(max(p.plan.GetRecordsOutput()-p.offset, 0), p.limit)
+}
+
+func (p *LimitPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return min(p.plan.GetDistinctValues(fieldName), p.GetRecordsOutput())
+}
+
+func (p *LimitPlan) GetSchema() *record.Schema {
+	return p.plan.GetSchema()
+}
@@ -0,0 +1,93 @@
+package planning
+
+import (
+	"os"
+	"simple-db-go/config"
+	"simple-db-go/file"
+	"simple-db-go/metadata"
+	"simple-db-go/transaction"
+	"simple-db-go/util"
+	"strings"
+	"testing"
+)
+
+const alterTableTestName = "alter_table_test"
+const autoIncrementTestName = "auto_increment_test"
+const columnConstraintTestName = "column_constraint_test"
+const fieldValueTestName = "field_value_test"
+const foreignKeyTestName = "foreign_key_test"
+const informationSchemaTestName = "information_schema_test"
+const insertSelectTestName = "insert_select_test"
+const keyConstraintTestName = "key_constraint_test"
+const updateAssignmentTestName = "update_assignment_test"
+
+func TestMain(m *testing.M) {
+	testNames := []string{
+		alterTableTestName,
+		autoIncrementTestName,
+		columnConstraintTestName,
+		fieldValueTestName,
+		foreignKeyTestName,
+		informationSchemaTestName,
+		insertSelectTestName,
+		keyConstraintTestName,
+		updateAssignmentTestName,
+	}
+
+	for _, name := range testNames {
+		util.Cleanup(name)
+	}
+
+	code := m.Run()
+
+	for _, name := range testNames {
+		util.Cleanup(name)
+	}
+	os.Exit(code)
+}
+
+// テスト用のデータベースを作り、SQL を実行する Planner とトランザクションを返す.
+func newPlannerForTest(t *testing.T, testName string) (*Planner, *transaction.Transaction) {
+	config := config.NewDBConfigForTest(t, testName, 512, 30)
+	transaction := transaction.NewTransactionForTest(testName, config)
+	fileManager := file.GetManagerForTest(testName)
+	metadataManager := metadata.StartManagerForTest(testName, config, fileManager.IsNew(), transaction)
+	return NewPlanner(NewBasicQueryPlanner(metadataManager), NewBasicUpdatePlanner(metadataManager)), transaction
+}
+
+// テストの準備のための SQL を実行する. 失敗した場合はテストを中断する.
+func mustExecuteUpdate(t *testing.T, planner *Planner, sql string, transaction *transaction.Transaction) {
+	t.Helper()
+	if _, err := planner.ExecuteUpdate(sql, transaction); err != nil {
+		t.Fatalf("SQL の実行に失敗しました. sql=%s, err=%v", sql, err)
+	}
+}
+
+// クエリの結果を、1レコードを `, ` で結合した文字列にして返す.
+func queryRows(t *testing.T, planner *Planner, sql string, transaction *transaction.Transaction) []string {
+	t.Helper()
+	plan, err := planner.CreateQueryPlan(sql, transaction)
+	if err != nil {
+		t.Fatalf("クエリの実行に失敗しました. sql=%s, err=%v", sql, err)
+	}
+
+	scan := plan.Open()
+	defer scan.Close()
+
+	rows := []string{}
+	for scan.Next() {
+		values := []string{}
+		for _, fieldName := range plan.GetSchema().Fields() {
+			value, err := scan.GetValue(fieldName)
+			if err != nil {
+				t.Fatalf("値の読み込みに失敗しました. sql=%s, err=%v", sql, err)
+			}
+			values = append(values, value.ToString())
+		}
+		rows = append(rows, strings.Join(values, ", "))
+	}
+	if err := scan.Err(); err != nil {
+		t.Fatalf("クエリの実行に失敗しました. sql=%s, err=%v", sql, err)
+	}
+	return rows
+}
@@ -0,0 +1,83 @@
+package planning
+
+import (
+	"fmt"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*MaterializePlan)(nil)
+
+// 元の plan の結果を一時テーブルに書き出しておく plan.
+// 同じ結果を何度も読み出す場合に、元の plan を何度も実行しなくて済むようにする.
+type MaterializePlan struct {
+	transaction *transaction.Transaction
+	plan        query.Plan
+}
+
+func NewMaterializePlan(transaction *transaction.Transaction, plan query.Plan) *MaterializePlan {
+	return &MaterializePlan{transaction: transaction, plan: plan}
+}
+
+func (p *MaterializePlan) Open() query.Scan {
+	schema := p.plan.GetSchema()
+	tempTable := query.NewTempTable(p.transaction, schema)
+
+	src := p.plan.Open()
+	defer src.Close()
+
+	dest := tempTable.Open()
+	for src.Next() {
+		dest.Insert()
+		if err := copyRecord(src, dest, schema); err != nil {
+			panic(fmt.Sprintf("[MaterializePlan] 一時テーブルへのコピーに失敗しました. plan=%+v, error=%+v", p, err))
+		}
+	}
+	if err := src.Err(); err != nil {
+		dest.Close()
+		return query.NewFailedScan(schema.Fields(), err)
+	}
+	dest.BeforeFirst()
+
+	return dest
+}
+
+// 一時テーブルに書き出すコストを、書き出されるブロック数として見積もる.
+// 一度書き出してしまえば、元の plan にアクセスする必要はない.
+func (p *MaterializePlan) GetBlocksAccessed() types.Int {
+	layout := record.NewLayout(p.plan.GetSchema())
+	recordsPerBlock := p.transaction.BlockSize() / types.Int(layout.GetSlotSize())
+	if recordsPerBlock <= 0 {
+		recordsPerBlock = 1
+	}
+	return (p.plan.GetRecordsOutput() + recordsPerBlock - 1) / recordsPerBlock
+}
+
+func (p *MaterializePlan) GetRecordsOutput() types.Int {
+	return p.plan.GetRecordsOutput()
+}
+
+func (p *MaterializePlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return p.plan.GetDistinctValues(fieldName)
+}
+
+func (p *MaterializePlan) GetSchema() *record.Schema {
+	return p.plan.GetSchema()
+}
+
+// src の current record を dest の current record にコピーする.
+func copyRecord(src query.Scan, dest query.UpdateScan, schema *record.Schema) error {
+	for _, fieldName := range schema.Fields() {
+		value, err := src.GetValue(fieldName)
+		if err != nil {
+			return err
+		}
+
+		if err := dest.SetValue(fieldName, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,10 +11,12 @@ import (
 type Planner struct {
 	queryPlanner  QueryPlanner
 	updatePlanner UpdatePlanner
+	// 直前に実行した更新系の SQL で、AUTO_INCREMENT によって採番した最初の値. 採番しなかった場合は 0.
+	lastInsertID types.Int
 }
 
 func NewPlanner(queryPlanner QueryPlanner, updatePlanner UpdatePlanner) *Planner {
-	return &Planner{queryPlanner, updatePlanner}
+	return &Planner{queryPlanner: queryPlanner, updatePlanner: updatePlanner}
 }
 
 func (p *Planner) CreateQueryPlan(sql string, transaction *transaction.Transaction) (query.Plan, error) {
@@ -32,6 +34,8 @@ func (p *Planner) CreateQueryPlan(sql string, transaction *transaction.Transacti
 }
 
 func (p *Planner) ExecuteUpdate(sql string, transaction *transaction.Transaction) (types.Int, error) {
+	p.lastInsertID = 0
+
 	parser := parsing.NewParser()
 	sqlData, err := parser.Parse(sql)
 	if err != nil {
@@ -40,18 +44,37 @@ func (p *Planner) ExecuteUpdate(sql string, transaction *transaction.Transaction
 
 	switch sqlData := sqlData.(type) {
 	case *data.InsertData:
-		return p.updatePlanner.ExecuteInsert(sqlData, transaction)
+		count, lastInsertID, err := p.updatePlanner.ExecuteInsert(sqlData, transaction)
+		p.lastInsertID = lastInsertID
+		return count, err
 	case *data.DeleteData:
 		return p.updatePlanner.ExecuteDelete(sqlData, transaction)
 	case *data.ModifyData:
 		return p.updatePlanner.ExecuteModify(sqlData, transaction)
 	case *data.CreateTableData:
-		return p.updatePlanner.ExecuteCreateTable(sqlData, transaction), nil
+		return p.updatePlanner.ExecuteCreateTable(sqlData, transaction)
 	case *data.CreateViewData:
-		return p.updatePlanner.ExecuteCreateView(sqlData, transaction), nil
+		return p.updatePlanner.ExecuteCreateView(sqlData, transaction)
 	case *data.CreateIndexData:
-		return p.updatePlanner.ExecuteCreateIndex(sqlData, transaction), nil
+		return p.updatePlanner.ExecuteCreateIndex(sqlData, transaction)
+	case *data.DropTableData:
+		return p.updatePlanner.ExecuteDropTable(sqlData, transaction)
+	case *data.DropViewData:
+		return p.updatePlanner.ExecuteDropView(sqlData, transaction)
+	case *data.DropIndexData:
+		return p.updatePlanner.ExecuteDropIndex(sqlData, transaction)
+	case *data.AlterTableData:
+		return p.updatePlanner.ExecuteAlterTable(sqlData, transaction)
+	case *data.TruncateTableData:
+		return p.updatePlanner.ExecuteTruncateTable(sqlData, transaction)
+	case *data.AnalyzeTableData:
+		return p.updatePlanner.ExecuteAnalyzeTable(sqlData, transaction)
 	default:
 		return 0, NotUpdateStatementError{sql}
 	}
 }
+
+// MySQL の LAST_INSERT_ID に相当する値. Planner は接続ごとに作るので、接続ごとの値になる.
+func (p *Planner) GetLastInsertID() types.Int {
+	return p.lastInsertID
+}
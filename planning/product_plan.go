@@ -7,6 +7,7 @@ import (
 )
 
 var _ query.Plan = (*ProductPlan)(nil)
+var _ query.ColumnDistributionPlan = (*ProductPlan)(nil)
 
 type ProductPlan struct {
 	plan1  query.Plan
@@ -44,6 +45,15 @@ func (p *ProductPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
 	}
 }
 
+// 直積では、それぞれの値の割合は元の plan と変わらない.
+func (p *ProductPlan) GetColumnDistribution(fieldName types.FieldName) (query.ColumnDistribution, bool) {
+	if p.plan1.GetSchema().HasField(fieldName) {
+		return query.ColumnDistributionOf(p.plan1, fieldName)
+	} else {
+		return query.ColumnDistributionOf(p.plan2, fieldName)
+	}
+}
+
 func (p *ProductPlan) GetSchema() *record.Schema {
 	return p.schema
 }
@@ -9,3 +9,15 @@ import (
 type QueryPlanner interface {
 	CreatePlan(data *data.QueryData, transaction *transaction.Transaction) (query.Plan, error)
 }
+
+// FROM 句に同じ名前(テーブル名、ビュー名、サブクエリの別名)が複数あれば、どれを指すか区別できないのでエラーにする.
+func validateQueryables(queryables []data.Queryable) error {
+	seen := make(map[data.Queryable]bool, len(queryables))
+	for _, queryable := range queryables {
+		if seen[queryable] {
+			return DuplicateQueryableError{queryable}
+		}
+		seen[queryable] = true
+	}
+	return nil
+}
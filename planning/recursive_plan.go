@@ -0,0 +1,79 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*RecursivePlan)(nil)
+
+// WITH RECURSIVE で定義されたサブクエリの plan.
+// 新しいレコードが無くなるまで再帰部分を繰り返し評価する. 詳細は RecursiveScan を参照.
+type RecursivePlan struct {
+	transaction    *transaction.Transaction
+	schema         *record.Schema
+	anchorPlan     query.Plan
+	recursivePlans []query.Plan
+	workingTable   *query.WorkingTable
+	distinct       bool
+}
+
+func (p *RecursivePlan) Open() query.Scan {
+	return query.NewRecursiveScan(p.transaction, p.schema, p.anchorPlan, p.recursivePlans, p.workingTable, p.distinct)
+}
+
+// 繰り返しの回数は実行するまでわからないので、1回分だけ繰り返すものとして見積もる.
+func (p *RecursivePlan) GetBlocksAccessed() types.Int {
+	blocks := p.anchorPlan.GetBlocksAccessed()
+	for _, plan := range p.recursivePlans {
+		blocks += plan.GetBlocksAccessed()
+	}
+	return blocks
+}
+
+func (p *RecursivePlan) GetRecordsOutput() types.Int {
+	records := p.anchorPlan.GetRecordsOutput()
+	for _, plan := range p.recursivePlans {
+		records += plan.GetRecordsOutput()
+	}
+	return records
+}
+
+func (p *RecursivePlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return p.GetRecordsOutput()
+}
+
+func (p *RecursivePlan) GetSchema() *record.Schema {
+	return p.schema
+}
+
+var _ query.Plan = (*WorkingTablePlan)(nil)
+
+// WITH RECURSIVE の再帰部分から、自身の名前を参照した時の plan.
+// 直前の繰り返しで追加されたレコードを読む.
+type WorkingTablePlan struct {
+	workingTable *query.WorkingTable
+}
+
+func (p *WorkingTablePlan) Open() query.Scan {
+	return p.workingTable.Open()
+}
+
+// 中身は繰り返しごとに変わるので、1ブロック程度の小さなテーブルとして見積もる.
+func (p *WorkingTablePlan) GetBlocksAccessed() types.Int {
+	return 1
+}
+
+func (p *WorkingTablePlan) GetRecordsOutput() types.Int {
+	return 1
+}
+
+func (p *WorkingTablePlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return 1
+}
+
+func (p *WorkingTablePlan) GetSchema() *record.Schema {
+	return p.workingTable.GetSchema()
+}
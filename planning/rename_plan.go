@@ -0,0 +1,58 @@
+package planning
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*RenamePlan)(nil)
+
+// `WITH t (a, b) AS (SELECT ...)` のように、サブクエリの結果のフィールドに別名を付ける plan.
+type RenamePlan struct {
+	plan   query.Plan
+	schema *record.Schema
+}
+
+func NewRenamePlan(name data.Queryable, plan query.Plan, fieldNames []types.FieldName) (query.Plan, error) {
+	sourceFieldNames := plan.GetSchema().Fields()
+	if len(fieldNames) != len(sourceFieldNames) {
+		return nil, CommonTableFieldCountError{name, len(fieldNames), len(sourceFieldNames)}
+	}
+
+	schema := record.NewSchema()
+	for i, sourceFieldName := range sourceFieldNames {
+		// Fields() から取得したフィールドなので、エラーは発生しない.
+		fieldType, _ := plan.GetSchema().FieldType(sourceFieldName)
+		fieldLength, _ := plan.GetSchema().Length(sourceFieldName)
+		schema.AddField(fieldNames[i], fieldType, fieldLength)
+	}
+
+	return &RenamePlan{plan: plan, schema: schema}, nil
+}
+
+func (p *RenamePlan) Open() query.Scan {
+	return query.NewRenameScan(p.plan.Open(), p.schema.Fields(), p.plan.GetSchema().Fields())
+}
+
+func (p *RenamePlan) GetBlocksAccessed() types.Int {
+	return p.plan.GetBlocksAccessed()
+}
+
+func (p *RenamePlan) GetRecordsOutput() types.Int {
+	return p.plan.GetRecordsOutput()
+}
+
+func (p *RenamePlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	for i, f := range p.schema.Fields() {
+		if f == fieldName {
+			return p.plan.GetDistinctValues(p.plan.GetSchema().Fields()[i])
+		}
+	}
+	return p.plan.GetDistinctValues(fieldName)
+}
+
+func (p *RenamePlan) GetSchema() *record.Schema {
+	return p.schema
+}
@@ -7,6 +7,7 @@ import (
 )
 
 var _ query.Plan = (*SelectPlan)(nil)
+var _ query.ColumnDistributionPlan = (*SelectPlan)(nil)
 
 type SelectPlan struct {
 	plan      query.Plan
@@ -30,12 +31,20 @@ func (p *SelectPlan) GetBlocksAccessed() types.Int {
 // Predicate によってどの程度レコード数が削減されるのか？が考慮すべきことになる.
 // そのために、predicate.ReductionFactor() を呼び出す.
 // 具体的には、predicate で参照されるフィールド（列）がとりうる値の数を見て、どの程度フィルタリングされるか割り算して計算する.
-// SimpleDB では等価比較(`=`)しかサポートしていないので、シンプルな計算になる.
+// 範囲の比較(`<` など)は、列の値の分布が分かればそれを使って推定する.
 func (p *SelectPlan) GetRecordsOutput() types.Int {
+	// WHERE 句が無い場合は、何もフィルタリングしない.
+	if p.predicate == nil {
+		return p.plan.GetRecordsOutput()
+	}
 	return p.plan.GetRecordsOutput() / p.predicate.GetReductionFactor(p.plan)
 }
 
 func (p *SelectPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	if p.predicate == nil {
+		return p.plan.GetDistinctValues(fieldName)
+	}
+
 	_, err := p.predicate.EquatesWithConstant(fieldName)
 	if err == nil {
 		// Predicate において、引数の`fieldName`は何かしらの定数と等価比較されている.
@@ -59,6 +68,12 @@ func (p *SelectPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
 	}
 }
 
+// NOTE: 条件で絞り込んだ後も、それぞれの値の割合は変わらないものとして、元の plan の値の分布を返す.
+// 複数の条件を重ねた場合も、条件同士は独立しているとみなして絞り込み率を掛け合わせることになる.
+func (p *SelectPlan) GetColumnDistribution(fieldName types.FieldName) (query.ColumnDistribution, bool) {
+	return query.ColumnDistributionOf(p.plan, fieldName)
+}
+
 func (p *SelectPlan) GetSchema() *record.Schema {
 	return p.plan.GetSchema()
 }
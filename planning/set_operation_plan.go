@@ -0,0 +1,134 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+// `lhs UNION [ALL] rhs` のような集合演算の plan を作る.
+// 2つの plan はフィールドの数と各位置の型が一致している必要がある. 結果のフィールド名は lhs のものを使う.
+//
+// UNION ALL は単に連結するだけで、UNION はその結果から DistinctPlan で重複を取り除く.
+// INTERSECT, EXCEPT は rhs の結果をメモリ上のハッシュに記録して、lhs のレコードと突き合わせる.
+func NewSetOperationPlan(transaction *transaction.Transaction, operator query.SetOperator, all bool, lhs query.Plan, rhs query.Plan) (query.Plan, error) {
+	schema, err := setOperationSchema(operator, lhs.GetSchema(), rhs.GetSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator {
+	case query.UNION:
+		unionPlan := &UnionPlan{lhs: lhs, rhs: rhs, schema: schema}
+		if all {
+			return unionPlan, nil
+		}
+		return NewDistinctPlan(transaction, unionPlan), nil
+	case query.INTERSECT, query.EXCEPT:
+		return &HashSetOperationPlan{operator: operator, all: all, lhs: lhs, rhs: rhs, schema: schema}, nil
+	default:
+		return nil, UnknownSetOperatorError{operator}
+	}
+}
+
+// 2つの schema が集合演算できるか確認し、結果の schema を作る.
+// 文字列のフィールドは、両方の値が入るように長い方の長さにする.
+func setOperationSchema(operator query.SetOperator, lhs *record.Schema, rhs *record.Schema) (*record.Schema, error) {
+	lhsFields := lhs.Fields()
+	rhsFields := rhs.Fields()
+	if len(lhsFields) != len(rhsFields) {
+		return nil, SetOperationFieldCountError{operator, len(lhsFields), len(rhsFields)}
+	}
+
+	schema := record.NewSchema()
+	for i := range lhsFields {
+		// Fields() から取得したフィールドなので、エラーは発生しない.
+		lhsType, _ := lhs.FieldType(lhsFields[i])
+		rhsType, _ := rhs.FieldType(rhsFields[i])
+		if lhsType != rhsType {
+			return nil, SetOperationFieldTypeError{operator, lhsFields[i], rhsFields[i]}
+		}
+
+		lhsLength, _ := lhs.Length(lhsFields[i])
+		rhsLength, _ := rhs.Length(rhsFields[i])
+		schema.AddField(lhsFields[i], lhsType, max(lhsLength, rhsLength))
+	}
+
+	return schema, nil
+}
+
+var _ query.Plan = (*UnionPlan)(nil)
+
+// UNION ALL のための plan. lhs の結果に続けて rhs の結果を出力する.
+type UnionPlan struct {
+	lhs    query.Plan
+	rhs    query.Plan
+	schema *record.Schema
+}
+
+func (p *UnionPlan) Open() query.Scan {
+	return query.NewUnionScan(p.lhs.Open(), p.lhs.GetSchema().Fields(), p.rhs.Open(), p.rhs.GetSchema().Fields())
+}
+
+func (p *UnionPlan) GetBlocksAccessed() types.Int {
+	return p.lhs.GetBlocksAccessed() + p.rhs.GetBlocksAccessed()
+}
+
+func (p *UnionPlan) GetRecordsOutput() types.Int {
+	return p.lhs.GetRecordsOutput() + p.rhs.GetRecordsOutput()
+}
+
+func (p *UnionPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return p.lhs.GetDistinctValues(fieldName) + p.rhs.GetDistinctValues(rhsFieldName(p.lhs, p.rhs, fieldName))
+}
+
+func (p *UnionPlan) GetSchema() *record.Schema {
+	return p.schema
+}
+
+var _ query.Plan = (*HashSetOperationPlan)(nil)
+
+// INTERSECT [ALL], EXCEPT [ALL] のための plan.
+type HashSetOperationPlan struct {
+	operator query.SetOperator
+	all      bool
+	lhs      query.Plan
+	rhs      query.Plan
+	schema   *record.Schema
+}
+
+func (p *HashSetOperationPlan) Open() query.Scan {
+	return query.NewHashSetOperationScan(p.operator, p.all, p.lhs.Open(), p.lhs.GetSchema().Fields(), p.rhs.Open(), p.rhs.GetSchema().Fields())
+}
+
+// 両方の結果を一度ずつ読む.
+func (p *HashSetOperationPlan) GetBlocksAccessed() types.Int {
+	return p.lhs.GetBlocksAccessed() + p.rhs.GetBlocksAccessed()
+}
+
+// INTERSECT の結果はどちらか小さい方を、EXCEPT の結果は lhs を超えない.
+func (p *HashSetOperationPlan) GetRecordsOutput() types.Int {
+	if p.operator == query.INTERSECT {
+		return min(p.lhs.GetRecordsOutput(), p.rhs.GetRecordsOutput())
+	}
+	return p.lhs.GetRecordsOutput()
+}
+
+func (p *HashSetOperationPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return min(p.lhs.GetDistinctValues(fieldName), p.GetRecordsOutput())
+}
+
+func (p *HashSetOperationPlan) GetSchema() *record.Schema {
+	return p.schema
+}
+
+// lhs のフィールドに、位置で対応する rhs のフィールド名を返す.
+func rhsFieldName(lhs query.Plan, rhs query.Plan, fieldName types.FieldName) types.FieldName {
+	for i, lhsFieldName := range lhs.GetSchema().Fields() {
+		if lhsFieldName == fieldName {
+			return rhs.GetSchema().Fields()[i]
+		}
+	}
+	return fieldName
+}
@@ -0,0 +1,157 @@
+package planning
+
+import (
+	"fmt"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+var _ query.Plan = (*SortPlan)(nil)
+
+// 指定されたフィールドの順でレコードをソートする plan.
+// 教科書 13.4 のマージソートに従い、元の plan をソート済みの run (一時テーブル) に分割し、
+// run が 2 つ以下になるまでマージを繰り返す. 最後のマージは SortScan が行う.
+type SortPlan struct {
+	transaction *transaction.Transaction
+	plan        query.Plan
+	schema      *record.Schema
+	comparator  *query.RecordComparator
+}
+
+func NewSortPlan(transaction *transaction.Transaction, plan query.Plan, sortFields []types.FieldName) *SortPlan {
+	return NewSortPlanWithOrder(transaction, plan, sortFields, nil)
+}
+
+// descending は sortFields と位置で対応し、true のフィールドは降順でソートする.
+func NewSortPlanWithOrder(transaction *transaction.Transaction, plan query.Plan, sortFields []types.FieldName, descending []bool) *SortPlan {
+	return &SortPlan{
+		transaction: transaction,
+		plan:        plan,
+		schema:      plan.GetSchema(),
+		comparator:  query.NewRecordComparatorWithOrder(sortFields, descending),
+	}
+}
+
+// 元の plan を読む途中でエラーが発生した場合は、そのエラーを返す FailedScan を返す.
+func (p *SortPlan) Open() query.Scan {
+	src := p.plan.Open()
+	runs := p.splitIntoRuns(src)
+	err := src.Err()
+	src.Close()
+	if err != nil {
+		return query.NewFailedScan(p.schema.Fields(), err)
+	}
+
+	for len(runs) > 2 {
+		runs = p.doAMergeIteration(runs)
+	}
+
+	return query.NewSortScan(runs, p.comparator)
+}
+
+// ソートのための前処理(run の作成とマージ)のコストは含めず、
+// ソート済みの結果を読み出すコストだけを見積もる. 教科書 13.4.3 を参照.
+func (p *SortPlan) GetBlocksAccessed() types.Int {
+	return NewMaterializePlan(p.transaction, p.plan).GetBlocksAccessed()
+}
+
+func (p *SortPlan) GetRecordsOutput() types.Int {
+	return p.plan.GetRecordsOutput()
+}
+
+func (p *SortPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return p.plan.GetDistinctValues(fieldName)
+}
+
+func (p *SortPlan) GetSchema() *record.Schema {
+	return p.schema
+}
+
+// src を、ソート済みのレコードが連続する区間(run)ごとに一時テーブルへ書き出す.
+// src が空の場合でも、SortScan が読めるように空の run を1つ返す.
+func (p *SortPlan) splitIntoRuns(src query.Scan) []*query.TempTable {
+	src.BeforeFirst()
+
+	currentTemp := query.NewTempTable(p.transaction, p.schema)
+	runs := []*query.TempTable{currentTemp}
+	if !src.Next() {
+		return runs
+	}
+
+	currentScan := currentTemp.Open()
+	for p.copy(src, currentScan) {
+		result, err := p.comparator.Compare(src, currentScan)
+		if err != nil {
+			panic(fmt.Sprintf("[SortPlan] レコードの比較でエラーが発生しました. sort_plan=%+v, error=%+v", p, err))
+		}
+
+		// 直前に書き出したレコードより小さいので、新しい run を始める.
+		if result < 0 {
+			currentScan.Close()
+			currentTemp = query.NewTempTable(p.transaction, p.schema)
+			runs = append(runs, currentTemp)
+			currentScan = currentTemp.Open()
+		}
+	}
+	currentScan.Close()
+
+	return runs
+}
+
+// run を2つずつマージして、run の数をおよそ半分にする.
+func (p *SortPlan) doAMergeIteration(runs []*query.TempTable) []*query.TempTable {
+	result := make([]*query.TempTable, 0, (len(runs)+1)/2)
+	for len(runs) > 1 {
+		result = append(result, p.mergeTwoRuns(runs[0], runs[1]))
+		runs = runs[2:]
+	}
+	if len(runs) == 1 {
+		result = append(result, runs[0])
+	}
+	return result
+}
+
+func (p *SortPlan) mergeTwoRuns(run1 *query.TempTable, run2 *query.TempTable) *query.TempTable {
+	src1 := run1.Open()
+	src2 := run2.Open()
+	result := query.NewTempTable(p.transaction, p.schema)
+	dest := result.Open()
+
+	hasMore1 := src1.Next()
+	hasMore2 := src2.Next()
+	for hasMore1 && hasMore2 {
+		compared, err := p.comparator.Compare(src1, src2)
+		if err != nil {
+			panic(fmt.Sprintf("[SortPlan] レコードの比較でエラーが発生しました. sort_plan=%+v, error=%+v", p, err))
+		}
+
+		if compared < 0 {
+			hasMore1 = p.copy(src1, dest)
+		} else {
+			hasMore2 = p.copy(src2, dest)
+		}
+	}
+
+	for hasMore1 {
+		hasMore1 = p.copy(src1, dest)
+	}
+	for hasMore2 {
+		hasMore2 = p.copy(src2, dest)
+	}
+
+	src1.Close()
+	src2.Close()
+	dest.Close()
+	return result
+}
+
+// src の current record を dest に追加し、src を次のレコードに進める.
+func (p *SortPlan) copy(src query.Scan, dest query.UpdateScan) bool {
+	dest.Insert()
+	if err := copyRecord(src, dest, p.schema); err != nil {
+		panic(fmt.Sprintf("[SortPlan] 一時テーブルへのコピーに失敗しました. sort_plan=%+v, error=%+v", p, err))
+	}
+	return src.Next()
+}
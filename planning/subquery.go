@@ -0,0 +1,62 @@
+package planning
+
+import (
+	"simple-db-go/parsing/data"
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"slices"
+)
+
+// サブクエリを plan する際の、外側のクエリの情報.
+type outerQuery struct {
+	schema *record.Schema
+	// 外側のクエリの WHERE 句に含まれる、plan しようとしているクエリを表すサブクエリ.
+	// 外側のクエリの current record は、このサブクエリを評価する時に渡される.
+	subquery *query.SubqueryExpression
+}
+
+type subqueryPlanFunc func(queryData *data.QueryData, outerQueries []outerQuery) (query.Plan, error)
+
+// WHERE 句に含まれるサブクエリの plan を作る.
+// schema は WHERE 句を適用するクエリの schema(FROM 句のテーブルの product)で、outerQueries はその外側のクエリ.
+//
+// schema に無いフィールドは外側のクエリのフィールドとして解決し、OuterFieldExpression に置き換える.
+// この時、参照先のクエリより内側にある全てのサブクエリは、外側のレコードごとに評価し直す相関サブクエリになる.
+func planSubqueries(predicate *query.Predicate, schema *record.Schema, outerQueries []outerQuery, createPlan subqueryPlanFunc) error {
+	if predicate == nil {
+		return nil
+	}
+
+	predicate.ReplaceFieldNames(func(expression query.FieldNameExpression) query.Expression {
+		fieldName := expression.GetFieldName()
+		if schema.HasField(fieldName) {
+			return expression
+		}
+
+		// 内側のクエリから順に探す.
+		for i := len(outerQueries) - 1; i >= 0; i-- {
+			if outerQueries[i].schema.HasField(fieldName) {
+				for _, outer := range outerQueries[i:] {
+					outer.subquery.MarkCorrelated()
+				}
+				return query.NewOuterFieldExpression(fieldName, outerQueries[i].subquery)
+			}
+		}
+
+		// どこにも無いフィールドは、これまで通り評価時にエラーにする.
+		return expression
+	})
+
+	for _, subquery := range predicate.Subqueries() {
+		// NOTE: サブクエリの定義は、パーサーが QueryData として作っているので強制してOK.
+		subqueryData := subquery.GetDefinition().(*data.QueryData)
+
+		subqueryPlan, err := createPlan(subqueryData, append(slices.Clone(outerQueries), outerQuery{schema, subquery}))
+		if err != nil {
+			return err
+		}
+		subquery.SetPlan(subqueryPlan)
+	}
+
+	return predicate.CheckSubqueries()
+}
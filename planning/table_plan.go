@@ -9,6 +9,7 @@ import (
 )
 
 var _ query.Plan = (*TablePlan)(nil)
+var _ query.ColumnDistributionPlan = (*TablePlan)(nil)
 
 type TablePlan struct {
 	transaction *transaction.Transaction
@@ -49,6 +50,14 @@ func (p *TablePlan) GetDistinctValues(fieldName types.FieldName) types.Int {
 	return p.statInfo.GetDistinctValues(fieldName)
 }
 
+func (p *TablePlan) GetColumnDistribution(fieldName types.FieldName) (query.ColumnDistribution, bool) {
+	columnStatInfo, exists := p.statInfo.GetColumnStatInfo(fieldName)
+	if !exists {
+		return nil, false
+	}
+	return columnStatInfo, true
+}
+
 func (p *TablePlan) GetSchema() *record.Schema {
 	return p.layout.GetSchema()
 }
@@ -7,11 +7,18 @@ import (
 )
 
 // 全てのメソッドで、影響のあったレコード数を返す.
+// ExecuteInsert は、AUTO_INCREMENT で採番した最初の値も返す(採番しなかった場合は 0).
 type UpdatePlanner interface {
-	ExecuteInsert(data *data.InsertData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteInsert(data *data.InsertData, transaction *transaction.Transaction) (types.Int, types.Int, error)
 	ExecuteDelete(data *data.DeleteData, transaction *transaction.Transaction) (types.Int, error)
 	ExecuteModify(data *data.ModifyData, transaction *transaction.Transaction) (types.Int, error)
-	ExecuteCreateTable(data *data.CreateTableData, transaction *transaction.Transaction) types.Int
-	ExecuteCreateView(data *data.CreateViewData, transaction *transaction.Transaction) types.Int
-	ExecuteCreateIndex(data *data.CreateIndexData, transaction *transaction.Transaction) types.Int
+	ExecuteCreateTable(data *data.CreateTableData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteCreateView(data *data.CreateViewData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteCreateIndex(data *data.CreateIndexData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteDropTable(data *data.DropTableData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteDropView(data *data.DropViewData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteDropIndex(data *data.DropIndexData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteAlterTable(data *data.AlterTableData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteTruncateTable(data *data.TruncateTableData, transaction *transaction.Transaction) (types.Int, error)
+	ExecuteAnalyzeTable(data *data.AnalyzeTableData, transaction *transaction.Transaction) (types.Int, error)
 }
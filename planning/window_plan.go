@@ -0,0 +1,104 @@
+package planning
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ query.Plan = (*WindowPlan)(nil)
+
+// 同じ window を使う window 関数をまとめて計算する plan.
+// PARTITION BY と ORDER BY のフィールドで元の plan をソートしてから、WindowScan でパーティションごとに計算する.
+type WindowPlan struct {
+	plan      query.Plan
+	window    *query.Window
+	functions []query.WindowFunction
+	schema    *record.Schema
+}
+
+func NewWindowPlan(
+	transaction *transaction.Transaction,
+	plan query.Plan,
+	window *query.Window,
+	functions []query.WindowFunction,
+) (query.Plan, error) {
+	sortFields, descending := window.GetSortOrder()
+	for _, fieldName := range sortFields {
+		if !plan.GetSchema().HasField(fieldName) {
+			return nil, FieldNotFoundInWindowError{fieldName, window.ToString()}
+		}
+	}
+
+	schema := record.NewSchema()
+	schema.AddAll(plan.GetSchema())
+	for _, fn := range functions {
+		fieldType, fieldLength, err := fn.GetFieldType(plan.GetSchema())
+		if err != nil {
+			return nil, err
+		}
+		schema.AddField(fn.GetFieldName(), fieldType, fieldLength)
+	}
+
+	// PARTITION BY も ORDER BY も無い場合は全体で1パーティションなので、ソートする必要はない.
+	if len(sortFields) > 0 {
+		plan = NewSortPlanWithOrder(transaction, plan, sortFields, descending)
+	}
+
+	return &WindowPlan{
+		plan:      plan,
+		window:    window,
+		functions: functions,
+		schema:    schema,
+	}, nil
+}
+
+// SELECT 句の window 関数を、window ごとに WindowPlan にまとめて plan に適用する.
+// window は SELECT 句に現れた順に適用する.
+func newWindowPlans(transaction *transaction.Transaction, plan query.Plan, functions []query.WindowFunction) (query.Plan, error) {
+	var windows []*query.Window
+	functionsByWindow := make(map[string][]query.WindowFunction)
+	for _, fn := range functions {
+		key := fn.GetWindow().ToString()
+		if _, exists := functionsByWindow[key]; !exists {
+			windows = append(windows, fn.GetWindow())
+		}
+		functionsByWindow[key] = append(functionsByWindow[key], fn)
+	}
+
+	for _, window := range windows {
+		windowPlan, err := NewWindowPlan(transaction, plan, window, functionsByWindow[window.ToString()])
+		if err != nil {
+			return nil, err
+		}
+		plan = windowPlan
+	}
+	return plan, nil
+}
+
+func (p *WindowPlan) Open() query.Scan {
+	return query.NewWindowScan(p.plan.Open(), p.window, p.functions)
+}
+
+// ソート済みの結果を1回読むだけなので、ソートのコストと同じになる.
+func (p *WindowPlan) GetBlocksAccessed() types.Int {
+	return p.plan.GetBlocksAccessed()
+}
+
+func (p *WindowPlan) GetRecordsOutput() types.Int {
+	return p.plan.GetRecordsOutput()
+}
+
+// window 関数の結果は、最大でレコードの数だけ異なる値をとる.
+func (p *WindowPlan) GetDistinctValues(fieldName types.FieldName) types.Int {
+	if slices.ContainsFunc(p.functions, func(fn query.WindowFunction) bool { return fn.GetFieldName() == fieldName }) {
+		return p.GetRecordsOutput()
+	}
+	return p.plan.GetDistinctValues(fieldName)
+}
+
+func (p *WindowPlan) GetSchema() *record.Schema {
+	return p.schema
+}
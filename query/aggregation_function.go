@@ -0,0 +1,272 @@
+package query
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/record"
+	"simple-db-go/types"
+)
+
+// COUNT(*) のように、フィールドではなくレコードそのものを対象とする場合のフィールド名.
+const ALL_FIELDS types.FieldName = "*"
+
+// GroupByScan で、グループごとに値を集計するための関数.
+type AggregationFunction interface {
+	// 新しいグループの集計を開始する. それまでの集計結果は破棄される.
+	Reset()
+
+	// scan の current record を集計に加える.
+	Process(scan Scan) error
+
+	// 集計結果を保持するフィールド名. `count(distinct a)` のように SQL での表記をそのまま使う.
+	GetFieldName() types.FieldName
+
+	GetValue() Constant
+
+	// 集計結果のフィールドの型と長さを、集計対象のテーブルの schema から決める.
+	GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error)
+}
+
+func NewAggregationFunction(functionName string, fieldName types.FieldName, distinct bool) (AggregationFunction, error) {
+	switch functionName {
+	case "count":
+		return NewCountFunction(fieldName, distinct), nil
+	case "sum":
+		return NewSumFunction(fieldName, distinct), nil
+	case "max":
+		return NewMaxFunction(fieldName), nil
+	case "min":
+		return NewMinFunction(fieldName), nil
+	default:
+		return nil, &UnknownAggregationFunctionError{functionName}
+	}
+}
+
+func aggregationFieldName(functionName string, fieldName types.FieldName, distinct bool) types.FieldName {
+	if distinct {
+		return types.FieldName(fmt.Sprintf("%s(distinct %s)", functionName, fieldName))
+	}
+	return types.FieldName(fmt.Sprintf("%s(%s)", functionName, fieldName))
+}
+
+var _ AggregationFunction = (*CountFunction)(nil)
+
+type CountFunction struct {
+	fieldName types.FieldName
+	distinct  bool
+	count     types.Int
+	// COUNT(DISTINCT a) の場合に、既に数えた値を記録する.
+	seen map[Constant]struct{}
+}
+
+func NewCountFunction(fieldName types.FieldName, distinct bool) *CountFunction {
+	return &CountFunction{fieldName: fieldName, distinct: distinct}
+}
+
+func (f *CountFunction) Reset() {
+	f.count = 0
+	f.seen = make(map[Constant]struct{})
+}
+
+func (f *CountFunction) Process(scan Scan) error {
+	if f.fieldName == ALL_FIELDS {
+		f.count++
+		return nil
+	}
+
+	value, err := scan.GetValue(f.fieldName)
+	if err != nil || IsNull(value) {
+		return err
+	}
+
+	if f.distinct {
+		if _, exists := f.seen[value]; exists {
+			return nil
+		}
+		f.seen[value] = struct{}{}
+	}
+
+	f.count++
+	return nil
+}
+
+func (f *CountFunction) GetFieldName() types.FieldName {
+	return aggregationFieldName("count", f.fieldName, f.distinct)
+}
+
+func (f *CountFunction) GetValue() Constant {
+	return NewIntConstant(f.count)
+}
+
+func (f *CountFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	if f.fieldName != ALL_FIELDS && !schema.HasField(f.fieldName) {
+		return 0, 0, &InvalidAggregationFieldError{f.GetFieldName(), f.fieldName}
+	}
+	return constants.INTEGER, record.INTEGER_FIELD_LENGTH, nil
+}
+
+var _ AggregationFunction = (*SumFunction)(nil)
+
+type SumFunction struct {
+	fieldName types.FieldName
+	distinct  bool
+	sum       types.Int
+	// NULL でない値を1つでも集計したかどうか.
+	hasValue bool
+	seen     map[Constant]struct{}
+}
+
+func NewSumFunction(fieldName types.FieldName, distinct bool) *SumFunction {
+	return &SumFunction{fieldName: fieldName, distinct: distinct}
+}
+
+func (f *SumFunction) Reset() {
+	f.sum = 0
+	f.hasValue = false
+	f.seen = make(map[Constant]struct{})
+}
+
+func (f *SumFunction) Process(scan Scan) error {
+	value, err := scan.GetValue(f.fieldName)
+	if err != nil || IsNull(value) {
+		return err
+	}
+
+	if f.distinct {
+		if _, exists := f.seen[value]; exists {
+			return nil
+		}
+		f.seen[value] = struct{}{}
+	}
+
+	intValue, ok := value.GetValue().(types.Int)
+	if !ok {
+		return &InvalidAggregationFieldError{f.GetFieldName(), f.fieldName}
+	}
+
+	f.sum += intValue
+	f.hasValue = true
+	return nil
+}
+
+func (f *SumFunction) GetFieldName() types.FieldName {
+	return aggregationFieldName("sum", f.fieldName, f.distinct)
+}
+
+// 集計対象の値が NULL しか無い場合は NULL を返す.
+func (f *SumFunction) GetValue() Constant {
+	if !f.hasValue {
+		return NewNullConstant()
+	}
+	return NewIntConstant(f.sum)
+}
+
+func (f *SumFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	isInt, err := schema.IsIntField(f.fieldName)
+	if err != nil || !isInt {
+		return 0, 0, &InvalidAggregationFieldError{f.GetFieldName(), f.fieldName}
+	}
+	return constants.INTEGER, record.INTEGER_FIELD_LENGTH, nil
+}
+
+var _ AggregationFunction = (*MaxFunction)(nil)
+
+type MaxFunction struct {
+	fieldName types.FieldName
+	value     Constant
+}
+
+func NewMaxFunction(fieldName types.FieldName) *MaxFunction {
+	return &MaxFunction{fieldName: fieldName}
+}
+
+func (f *MaxFunction) Reset() {
+	f.value = nil
+}
+
+func (f *MaxFunction) Process(scan Scan) error {
+	value, err := scan.GetValue(f.fieldName)
+	if err != nil || IsNull(value) {
+		return err
+	}
+
+	if f.value == nil || value.CompareTo(f.value) > 0 {
+		f.value = value
+	}
+	return nil
+}
+
+func (f *MaxFunction) GetFieldName() types.FieldName {
+	return aggregationFieldName("max", f.fieldName, false)
+}
+
+// 集計対象の値が NULL しか無い場合は NULL を返す.
+func (f *MaxFunction) GetValue() Constant {
+	if f.value == nil {
+		return NewNullConstant()
+	}
+	return f.value
+}
+
+// MAX の結果は集計対象のフィールドと同じ型になる.
+func (f *MaxFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return aggregationSourceFieldType(f.GetFieldName(), f.fieldName, schema)
+}
+
+var _ AggregationFunction = (*MinFunction)(nil)
+
+type MinFunction struct {
+	fieldName types.FieldName
+	value     Constant
+}
+
+func NewMinFunction(fieldName types.FieldName) *MinFunction {
+	return &MinFunction{fieldName: fieldName}
+}
+
+func (f *MinFunction) Reset() {
+	f.value = nil
+}
+
+func (f *MinFunction) Process(scan Scan) error {
+	value, err := scan.GetValue(f.fieldName)
+	if err != nil || IsNull(value) {
+		return err
+	}
+
+	if f.value == nil || value.CompareTo(f.value) < 0 {
+		f.value = value
+	}
+	return nil
+}
+
+func (f *MinFunction) GetFieldName() types.FieldName {
+	return aggregationFieldName("min", f.fieldName, false)
+}
+
+// 集計対象の値が NULL しか無い場合は NULL を返す.
+func (f *MinFunction) GetValue() Constant {
+	if f.value == nil {
+		return NewNullConstant()
+	}
+	return f.value
+}
+
+// MIN の結果は集計対象のフィールドと同じ型になる.
+func (f *MinFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return aggregationSourceFieldType(f.GetFieldName(), f.fieldName, schema)
+}
+
+func aggregationSourceFieldType(aggregationFieldName types.FieldName, fieldName types.FieldName, schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	fieldType, err := schema.FieldType(fieldName)
+	if err != nil {
+		return 0, 0, &InvalidAggregationFieldError{aggregationFieldName, fieldName}
+	}
+
+	fieldLength, err := schema.Length(fieldName)
+	if err != nil {
+		return 0, 0, &InvalidAggregationFieldError{aggregationFieldName, fieldName}
+	}
+
+	return fieldType, fieldLength, nil
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"simple-db-go/record"
+	"simple-db-go/types"
+)
+
+type ArithmeticOperator string
+
+const (
+	ADD      ArithmeticOperator = "+"
+	SUBTRACT ArithmeticOperator = "-"
+	MULTIPLY ArithmeticOperator = "*"
+	DIVIDE   ArithmeticOperator = "/"
+)
+
+// 演算子の優先順位. 大きいほど先に計算する.
+var arithmeticOperatorPrecedences = map[ArithmeticOperator]int{
+	ADD:      1,
+	SUBTRACT: 1,
+	MULTIPLY: 2,
+	DIVIDE:   2,
+}
+
+// 2つの Expression の整数の四則演算. レコードごとに評価する.
+// SQL と同じく、どちらかが NULL の場合は NULL になる. 割り算は整数の割り算で、小数点以下は切り捨てる.
+type ArithmeticExpression struct {
+	operator ArithmeticOperator
+	lhs      Expression
+	rhs      Expression
+}
+
+func NewArithmeticExpression(operator ArithmeticOperator, lhs Expression, rhs Expression) ArithmeticExpression {
+	return ArithmeticExpression{operator: operator, lhs: lhs, rhs: rhs}
+}
+
+func (e ArithmeticExpression) Evaluate(scan Scan) (Constant, error) {
+	lhs, err := e.lhs.Evaluate(scan)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := e.rhs.Evaluate(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsNull(lhs) || IsNull(rhs) {
+		return NewNullConstant(), nil
+	}
+
+	lhsValue, lhsOk := lhs.GetValue().(types.Int)
+	rhsValue, rhsOk := rhs.GetValue().(types.Int)
+	if !lhsOk || !rhsOk {
+		return nil, &ArithmeticTypeError{e.ToString(), lhs, rhs}
+	}
+
+	var result int64
+	switch e.operator {
+	case ADD:
+		result = int64(lhsValue) + int64(rhsValue)
+	case SUBTRACT:
+		result = int64(lhsValue) - int64(rhsValue)
+	case MULTIPLY:
+		result = int64(lhsValue) * int64(rhsValue)
+	case DIVIDE:
+		if rhsValue == 0 {
+			return nil, &DivisionByZeroError{e.ToString()}
+		}
+		result = int64(lhsValue) / int64(rhsValue)
+	default:
+		panic(fmt.Sprintf("Unexpected arithmetic operator: %s", e.operator))
+	}
+
+	if result < math.MinInt32 || math.MaxInt32 < result {
+		return nil, &ArithmeticOverflowError{e.ToString(), result}
+	}
+	return NewIntConstant(types.Int(result)), nil
+}
+
+func (e ArithmeticExpression) AppliesTo(schema *record.Schema) bool {
+	return e.lhs.AppliesTo(schema) && e.rhs.AppliesTo(schema)
+}
+
+// 演算子の優先順位が低い子の Expression は括弧で囲む. 右側は、同じ優先順位でも `a - (b - c)` のように括弧が必要.
+func (e ArithmeticExpression) ToString() string {
+	precedence := arithmeticOperatorPrecedences[e.operator]
+
+	lhs := e.lhs.ToString()
+	if child, ok := e.lhs.(ArithmeticExpression); ok && arithmeticOperatorPrecedences[child.operator] < precedence {
+		lhs = fmt.Sprintf("(%s)", lhs)
+	}
+	rhs := e.rhs.ToString()
+	if child, ok := e.rhs.(ArithmeticExpression); ok && arithmeticOperatorPrecedences[child.operator] <= precedence {
+		rhs = fmt.Sprintf("(%s)", rhs)
+	}
+	return fmt.Sprintf("%s %s %s", lhs, e.operator, rhs)
+}
@@ -1,9 +1,11 @@
 package query
 
 import (
+	"cmp"
 	"fmt"
 	"simple-db-go/record"
 	"simple-db-go/types"
+	"strings"
 )
 
 func NewIntConstant(value types.Int) IntConstant {
@@ -14,8 +16,13 @@ func NewStrConstant(value string) StrConstant {
 	return StrConstant{value: value}
 }
 
+func NewNullConstant() NullConstant {
+	return NullConstant{}
+}
+
 var _ Constant = (*IntConstant)(nil)
 var _ Constant = (*StrConstant)(nil)
+var _ Constant = (*NullConstant)(nil)
 
 type IntConstant struct {
 	value types.Int
@@ -26,6 +33,16 @@ func (ic IntConstant) Constant()        {}
 func (ic IntConstant) ToString() string { return ic.value.ToString() }
 func (ic IntConstant) GetValue() any    { return ic.value }
 func (ic IntConstant) GetRawValue() any { return int(ic.value) }
+func (ic IntConstant) CompareTo(other Constant) int {
+	switch other := other.(type) {
+	case IntConstant:
+		return cmp.Compare(ic.value, other.value)
+	case NullConstant:
+		return 1
+	default:
+		return -1
+	}
+}
 
 // For Expression interface
 func (ic IntConstant) Evaluate(scan Scan) (Constant, error) { return ic, nil }
@@ -37,10 +54,60 @@ type StrConstant struct {
 
 // For Constant interface
 func (sc StrConstant) Constant()        {}
-func (sc StrConstant) ToString() string { return fmt.Sprintf("'%s'", sc.value) }
 func (sc StrConstant) GetValue() any    { return sc.value }
 func (sc StrConstant) GetRawValue() any { return sc.value }
 
+// 文字列の中の引用符は2つ重ねて、パースし直せる SQL の文字列リテラルにする.
+func (sc StrConstant) ToString() string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(sc.value, "'", "''"))
+}
+
+func (sc StrConstant) CompareTo(other Constant) int {
+	if other, ok := other.(StrConstant); ok {
+		return strings.Compare(sc.value, other.value)
+	}
+	return 1
+}
+
 // For Expression interface
 func (sc StrConstant) Evaluate(scan Scan) (Constant, error) { return sc, nil }
 func (sc StrConstant) AppliesTo(schema *record.Schema) bool { return true }
+
+// フィールドの値が無いことを表す. 型を持たず、どの型のフィールドにも入れることができる.
+// ソートや重複排除では、NULL 同士は等しく、他のどの値よりも小さいものとして扱う.
+// 等価比較(`=`)では、NULL との比較結果は常に UNKNOWN になる. Term を参照.
+type NullConstant struct{}
+
+// For Constant interface
+func (nc NullConstant) Constant()        {}
+func (nc NullConstant) ToString() string { return "NULL" }
+func (nc NullConstant) GetValue() any    { return nil }
+
+// MySQL のクライアントには nil が NULL として返される.
+func (nc NullConstant) GetRawValue() any { return nil }
+func (nc NullConstant) CompareTo(other Constant) int {
+	if IsNull(other) {
+		return 0
+	}
+	return -1
+}
+
+// For Expression interface
+func (nc NullConstant) Evaluate(scan Scan) (Constant, error) { return nc, nil }
+func (nc NullConstant) AppliesTo(schema *record.Schema) bool { return true }
+
+func IsNull(value Constant) bool {
+	_, ok := value.(NullConstant)
+	return ok
+}
+
+// GetInt, GetString のために値を取り出す. NULL の場合はゼロ値を返す.
+func intValueOf(value Constant) types.Int {
+	intValue, _ := value.GetValue().(types.Int)
+	return intValue
+}
+
+func stringValueOf(value Constant) string {
+	stringValue, _ := value.GetValue().(string)
+	return stringValue
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"fmt"
+	"simple-db-go/types"
+	"strings"
+)
+
+var _ Scan = (*DistinctScan)(nil)
+var _ Scan = (*HashDistinctScan)(nil)
+
+// 全フィールドでソート済みの scan から、重複したレコードを取り除く.
+// ソート済みなので、直前のレコードと比較するだけで良い.
+type DistinctScan struct {
+	scan           Scan
+	fieldNames     []types.FieldName
+	previousValues []Constant
+	err            error
+}
+
+func NewDistinctScan(scan Scan, fieldNames []types.FieldName) *DistinctScan {
+	return &DistinctScan{scan: scan, fieldNames: fieldNames}
+}
+
+func (ds *DistinctScan) BeforeFirst() {
+	ds.scan.BeforeFirst()
+	ds.previousValues = nil
+}
+
+func (ds *DistinctScan) Next() bool {
+	if ds.err != nil {
+		return false
+	}
+	for ds.scan.Next() {
+		values, err := readValues(ds.scan, ds.fieldNames)
+		if err != nil {
+			ds.err = err
+			return false
+		}
+
+		if ds.previousValues == nil || !equalValues(ds.previousValues, values) {
+			ds.previousValues = values
+			return true
+		}
+	}
+	return false
+}
+
+func (ds *DistinctScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return ds.scan.GetInt(fieldName)
+}
+
+func (ds *DistinctScan) GetString(fieldName types.FieldName) (string, error) {
+	return ds.scan.GetString(fieldName)
+}
+
+func (ds *DistinctScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return ds.scan.GetValue(fieldName)
+}
+
+func (ds *DistinctScan) HasField(fieldName types.FieldName) bool {
+	return ds.scan.HasField(fieldName)
+}
+
+func (ds *DistinctScan) Close() {
+	ds.scan.Close()
+}
+
+func (ds *DistinctScan) GetFields() []types.FieldName {
+	return ds.scan.GetFields()
+}
+
+func (ds *DistinctScan) Err() error {
+	if ds.err != nil {
+		return ds.err
+	}
+	return ds.scan.Err()
+}
+
+// 既に出力したレコードをメモリ上に記録して、重複したレコードを取り除く.
+// ソートが不要な代わりに、レコード数に比例してメモリを使うので、小さな結果に対してだけ使う.
+type HashDistinctScan struct {
+	scan       Scan
+	fieldNames []types.FieldName
+	seen       map[string]struct{}
+	err        error
+}
+
+func NewHashDistinctScan(scan Scan, fieldNames []types.FieldName) *HashDistinctScan {
+	return &HashDistinctScan{scan: scan, fieldNames: fieldNames, seen: make(map[string]struct{})}
+}
+
+func (hs *HashDistinctScan) BeforeFirst() {
+	hs.scan.BeforeFirst()
+	hs.seen = make(map[string]struct{})
+}
+
+func (hs *HashDistinctScan) Next() bool {
+	if hs.err != nil {
+		return false
+	}
+	for hs.scan.Next() {
+		values, err := readValues(hs.scan, hs.fieldNames)
+		if err != nil {
+			hs.err = err
+			return false
+		}
+
+		key := valuesKey(values)
+		if _, exists := hs.seen[key]; !exists {
+			hs.seen[key] = struct{}{}
+			return true
+		}
+	}
+	return false
+}
+
+func (hs *HashDistinctScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return hs.scan.GetInt(fieldName)
+}
+
+func (hs *HashDistinctScan) GetString(fieldName types.FieldName) (string, error) {
+	return hs.scan.GetString(fieldName)
+}
+
+func (hs *HashDistinctScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return hs.scan.GetValue(fieldName)
+}
+
+func (hs *HashDistinctScan) HasField(fieldName types.FieldName) bool {
+	return hs.scan.HasField(fieldName)
+}
+
+func (hs *HashDistinctScan) Close() {
+	hs.scan.Close()
+}
+
+func (hs *HashDistinctScan) GetFields() []types.FieldName {
+	return hs.scan.GetFields()
+}
+
+func (hs *HashDistinctScan) Err() error {
+	if hs.err != nil {
+		return hs.err
+	}
+	return hs.scan.Err()
+}
+
+// レコードの値の組を、map のキーとして使える文字列に変換する.
+// 文字列の中に区切り文字が含まれても衝突しないように、型と長さを前置する.
+func valuesKey(values []Constant) string {
+	var builder strings.Builder
+	for _, value := range values {
+		raw := value.ToString()
+		fmt.Fprintf(&builder, "%T:%d:%s;", value, len(raw), raw)
+	}
+	return builder.String()
+}
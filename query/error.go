@@ -69,3 +69,196 @@ type UnknownFieldInProjectScanError struct {
 func (e *UnknownFieldInProjectScanError) Error() string {
 	return fmt.Sprintf("ProjectScan に不明なフィールドが指定されました。field_name=%s, project_scan=%+v", e.fieldName, e.projectScan)
 }
+
+type UnknownAggregationFunctionError struct {
+	functionName string
+}
+
+func (e *UnknownAggregationFunctionError) Error() string {
+	return fmt.Sprintf("不明な集約関数が指定されました。function_name=%s", e.functionName)
+}
+
+type InvalidAggregationFieldError struct {
+	aggregationFieldName types.FieldName
+	fieldName            types.FieldName
+}
+
+func (e *InvalidAggregationFieldError) Error() string {
+	return fmt.Sprintf("集約関数に指定できないフィールドです。aggregation=%s, field_name=%s", e.aggregationFieldName, e.fieldName)
+}
+
+type UnknownFieldInGroupByScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInGroupByScanError) Error() string {
+	return fmt.Sprintf("GroupByScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type SubqueryNotPlannedError struct {
+	subquery string
+}
+
+func (e *SubqueryNotPlannedError) Error() string {
+	return fmt.Sprintf("plan が作られていないサブクエリを評価しようとしました。subquery=%s", e.subquery)
+}
+
+type SubqueryFieldCountError struct {
+	subquery   string
+	fieldCount int
+}
+
+func (e *SubqueryFieldCountError) Error() string {
+	return fmt.Sprintf("サブクエリはフィールドを1つだけ返す必要があります。subquery=%s, field_count=%d", e.subquery, e.fieldCount)
+}
+
+type ScalarSubqueryMultipleRowsError struct {
+	subquery string
+}
+
+func (e *ScalarSubqueryMultipleRowsError) Error() string {
+	return fmt.Sprintf("スカラサブクエリが複数のレコードを返しました。subquery=%s", e.subquery)
+}
+
+type UnboundOuterFieldError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnboundOuterFieldError) Error() string {
+	return fmt.Sprintf("サブクエリの外側で、外側のクエリのフィールドを参照しようとしました。field_name=%s", e.fieldName)
+}
+
+type UnknownFieldInUnionScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInUnionScanError) Error() string {
+	return fmt.Sprintf("UnionScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type UnknownFieldInRenameScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInRenameScanError) Error() string {
+	return fmt.Sprintf("RenameScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type RecursionDepthExceededError struct {
+	maxDepth int
+}
+
+func (e *RecursionDepthExceededError) Error() string {
+	return fmt.Sprintf("再帰の回数が上限を超えました。max_recursion_depth=%d", e.maxDepth)
+}
+
+type UnknownFieldInRecursiveScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInRecursiveScanError) Error() string {
+	return fmt.Sprintf("RecursiveScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type UnknownWindowFunctionError struct {
+	functionName string
+}
+
+func (e *UnknownWindowFunctionError) Error() string {
+	return fmt.Sprintf("不明な window 関数が指定されました。function_name=%s", e.functionName)
+}
+
+type InvalidWindowFunctionFieldError struct {
+	windowFieldName types.FieldName
+	fieldName       types.FieldName
+}
+
+func (e *InvalidWindowFunctionFieldError) Error() string {
+	return fmt.Sprintf("window 関数に指定できないフィールドです。window_function=%s, field_name=%s", e.windowFieldName, e.fieldName)
+}
+
+type WindowFunctionDefaultTypeError struct {
+	windowFieldName types.FieldName
+	defaultValue    string
+}
+
+func (e *WindowFunctionDefaultTypeError) Error() string {
+	return fmt.Sprintf("window 関数のデフォルト値の型が、フィールドの型と一致しません。window_function=%s, default=%s", e.windowFieldName, e.defaultValue)
+}
+
+type NegativeWindowOffsetError struct {
+	windowFieldName types.FieldName
+	offset          types.Int
+}
+
+func (e *NegativeWindowOffsetError) Error() string {
+	return fmt.Sprintf("window 関数の offset に負の値は指定できません。window_function=%s, offset=%d", e.windowFieldName, e.offset)
+}
+
+type NoCurrentRecordInWindowScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *NoCurrentRecordInWindowScanError) Error() string {
+	return fmt.Sprintf("WindowScan が読んでいるレコードがありません。Next を呼ぶ前に値を読もうとしました。field_name=%s", e.fieldName)
+}
+
+type UnknownFieldInWindowScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInWindowScanError) Error() string {
+	return fmt.Sprintf("WindowScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type UnknownFieldInRowScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInRowScanError) Error() string {
+	return fmt.Sprintf("RowScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type UnknownFieldInValuesScanError struct {
+	fieldName types.FieldName
+}
+
+func (e *UnknownFieldInValuesScanError) Error() string {
+	return fmt.Sprintf("ValuesScan に不明なフィールドが指定されました。field_name=%s", e.fieldName)
+}
+
+type FieldValueTypeMismatchError struct {
+	fieldName types.FieldName
+	value     Constant
+}
+
+func (e *FieldValueTypeMismatchError) Error() string {
+	return fmt.Sprintf("フィールドの型と異なる型の値は書き込めません。field_name=%s, value=%s", e.fieldName, e.value.ToString())
+}
+
+type ArithmeticTypeError struct {
+	expression string
+	lhs        Constant
+	rhs        Constant
+}
+
+func (e *ArithmeticTypeError) Error() string {
+	return fmt.Sprintf("四則演算は整数の値にしか使えません。expression=%s, lhs=%s, rhs=%s", e.expression, e.lhs.ToString(), e.rhs.ToString())
+}
+
+type DivisionByZeroError struct {
+	expression string
+}
+
+func (e *DivisionByZeroError) Error() string {
+	return fmt.Sprintf("0 で割ることはできません。expression=%s", e.expression)
+}
+
+type ArithmeticOverflowError struct {
+	expression string
+	result     int64
+}
+
+func (e *ArithmeticOverflowError) Error() string {
+	return fmt.Sprintf("四則演算の結果が INT の範囲を超えました。expression=%s, result=%d", e.expression, e.result)
+}
@@ -28,3 +28,35 @@ func (e FieldNameExpression) ToString() string {
 func (e FieldNameExpression) GetFieldName() types.FieldName {
 	return e.fieldName
 }
+
+// 相関サブクエリの中から、外側のクエリのフィールドを参照する Expression.
+// 外側のクエリの current record は、サブクエリを評価する際に SubqueryExpression が保持している.
+type OuterFieldExpression struct {
+	fieldName types.FieldName
+	subquery  *SubqueryExpression
+}
+
+// subquery は、外側のクエリの WHERE 句に含まれていて、この Expression を内側に持つサブクエリ.
+func NewOuterFieldExpression(fieldName types.FieldName, subquery *SubqueryExpression) OuterFieldExpression {
+	return OuterFieldExpression{fieldName: fieldName, subquery: subquery}
+}
+
+func (e OuterFieldExpression) Evaluate(scan Scan) (Constant, error) {
+	if e.subquery.outerScan == nil {
+		return nil, &UnboundOuterFieldError{e.fieldName}
+	}
+	return e.subquery.outerScan.GetValue(e.fieldName)
+}
+
+// サブクエリの中では、外側のクエリのフィールドは定数と同じように扱える.
+func (e OuterFieldExpression) AppliesTo(schema *record.Schema) bool {
+	return true
+}
+
+func (e OuterFieldExpression) ToString() string {
+	return string(e.fieldName)
+}
+
+func (e OuterFieldExpression) GetFieldName() types.FieldName {
+	return e.fieldName
+}
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*FailedScan)(nil)
+
+// Open の途中でエラーが発生した plan が返す scan. レコードは返さず、Err でそのエラーを返す.
+// SortPlan のように、Open の中で元の scan を最後まで読む plan で使う.
+type FailedScan struct {
+	fieldNames []types.FieldName
+	err        error
+}
+
+func NewFailedScan(fieldNames []types.FieldName, err error) *FailedScan {
+	return &FailedScan{fieldNames: fieldNames, err: err}
+}
+
+func (fs *FailedScan) BeforeFirst() {}
+
+func (fs *FailedScan) Next() bool {
+	return false
+}
+
+func (fs *FailedScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return 0, fs.err
+}
+
+func (fs *FailedScan) GetString(fieldName types.FieldName) (string, error) {
+	return "", fs.err
+}
+
+func (fs *FailedScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return nil, fs.err
+}
+
+func (fs *FailedScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(fs.fieldNames, fieldName)
+}
+
+func (fs *FailedScan) Close() {}
+
+func (fs *FailedScan) GetFields() []types.FieldName {
+	return fs.fieldNames
+}
+
+func (fs *FailedScan) Err() error {
+	return fs.err
+}
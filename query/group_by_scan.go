@@ -0,0 +1,182 @@
+package query
+
+import (
+	"simple-db-go/types"
+)
+
+var _ Scan = (*GroupByScan)(nil)
+
+// グループ化するフィールドでソート済みの scan を受け取り、グループごとに1レコードを出力する.
+// 各レコードは、グループ化したフィールドの値と、集約関数の結果を持つ.
+type GroupByScan struct {
+	scan                 Scan
+	groupFields          []types.FieldName
+	aggregationFunctions []AggregationFunction
+	groupValues          []Constant
+	hasMoreGroups        bool
+	// GROUP BY 無しで集約する場合は、レコードが1件も無くても結果を1行返す必要がある.
+	isFirstGroup bool
+	err          error
+}
+
+func NewGroupByScan(scan Scan, groupFields []types.FieldName, aggregationFunctions []AggregationFunction) *GroupByScan {
+	groupByScan := &GroupByScan{
+		scan:                 scan,
+		groupFields:          groupFields,
+		aggregationFunctions: aggregationFunctions,
+	}
+	groupByScan.BeforeFirst()
+	return groupByScan
+}
+
+func (gs *GroupByScan) BeforeFirst() {
+	gs.scan.BeforeFirst()
+	gs.hasMoreGroups = gs.scan.Next()
+	gs.isFirstGroup = true
+}
+
+// 次のグループに移動し、そのグループの集約結果を計算する.
+func (gs *GroupByScan) Next() bool {
+	// 元の scan でエラーが発生した場合は、途中までの集約結果は返さない.
+	if gs.Err() != nil {
+		return false
+	}
+
+	isFirstGroup := gs.isFirstGroup
+	gs.isFirstGroup = false
+
+	if !gs.hasMoreGroups {
+		if isFirstGroup && len(gs.groupFields) == 0 {
+			for _, fn := range gs.aggregationFunctions {
+				fn.Reset()
+			}
+			return true
+		}
+		return false
+	}
+
+	groupValues, err := readValues(gs.scan, gs.groupFields)
+	if err != nil {
+		gs.err = err
+		return false
+	}
+	gs.groupValues = groupValues
+
+	for _, fn := range gs.aggregationFunctions {
+		fn.Reset()
+	}
+
+	for {
+		for _, fn := range gs.aggregationFunctions {
+			if err := fn.Process(gs.scan); err != nil {
+				gs.err = err
+				return false
+			}
+		}
+
+		gs.hasMoreGroups = gs.scan.Next()
+		if !gs.hasMoreGroups {
+			if gs.scan.Err() != nil {
+				return false
+			}
+			break
+		}
+
+		nextValues, err := readValues(gs.scan, gs.groupFields)
+		if err != nil {
+			gs.err = err
+			return false
+		}
+		if !equalValues(gs.groupValues, nextValues) {
+			break
+		}
+	}
+
+	return true
+}
+
+func (gs *GroupByScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := gs.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (gs *GroupByScan) GetString(fieldName types.FieldName) (string, error) {
+	value, err := gs.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+func (gs *GroupByScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	for i, groupField := range gs.groupFields {
+		if groupField == fieldName {
+			return gs.groupValues[i], nil
+		}
+	}
+
+	for _, fn := range gs.aggregationFunctions {
+		if fn.GetFieldName() == fieldName {
+			return fn.GetValue(), nil
+		}
+	}
+
+	return nil, &UnknownFieldInGroupByScanError{fieldName}
+}
+
+func (gs *GroupByScan) HasField(fieldName types.FieldName) bool {
+	for _, f := range gs.GetFields() {
+		if f == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+func (gs *GroupByScan) Close() {
+	gs.scan.Close()
+}
+
+func (gs *GroupByScan) Err() error {
+	if gs.err != nil {
+		return gs.err
+	}
+	return gs.scan.Err()
+}
+
+func (gs *GroupByScan) GetFields() []types.FieldName {
+	fields := make([]types.FieldName, 0, len(gs.groupFields)+len(gs.aggregationFunctions))
+	fields = append(fields, gs.groupFields...)
+	for _, fn := range gs.aggregationFunctions {
+		fields = append(fields, fn.GetFieldName())
+	}
+	return fields
+}
+
+// current record の、指定されたフィールドの値を順に読み取る.
+func readValues(scan Scan, fieldNames []types.FieldName) ([]Constant, error) {
+	values := make([]Constant, 0, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		value, err := scan.GetValue(fieldName)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func equalValues(values1 []Constant, values2 []Constant) bool {
+	if len(values1) != len(values2) {
+		return false
+	}
+	for i := range values1 {
+		if values1[i].CompareTo(values2[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
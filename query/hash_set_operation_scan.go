@@ -0,0 +1,121 @@
+package query
+
+import (
+	"errors"
+	"simple-db-go/types"
+)
+
+var _ Scan = (*HashSetOperationScan)(nil)
+
+// INTERSECT, EXCEPT に相当する.
+// scan2 のレコードを全て読んで値の組ごとの件数をメモリ上に記録し、scan1 のレコードのうち条件を満たすものだけを出力する.
+// ALL の場合は重複を残し、INTERSECT ALL は min(m, n) 件、EXCEPT ALL は max(m - n, 0) 件を出力する.
+// 2つの scan のフィールドは位置で対応させ、出力するフィールド名は scan1 のものを使う.
+type HashSetOperationScan struct {
+	operator    SetOperator
+	all         bool
+	scan1       Scan
+	scan2       Scan
+	fieldNames1 []types.FieldName
+	fieldNames2 []types.FieldName
+	// scan2 に含まれる値の組ごとの、まだ対応させていないレコード数.
+	counts map[string]types.Int
+	// ALL でない場合に、既に出力した値の組を記録する.
+	emitted map[string]struct{}
+	err     error
+}
+
+func NewHashSetOperationScan(operator SetOperator, all bool, scan1 Scan, fieldNames1 []types.FieldName, scan2 Scan, fieldNames2 []types.FieldName) *HashSetOperationScan {
+	hashSetOperationScan := &HashSetOperationScan{
+		operator:    operator,
+		all:         all,
+		scan1:       scan1,
+		scan2:       scan2,
+		fieldNames1: fieldNames1,
+		fieldNames2: fieldNames2,
+	}
+	hashSetOperationScan.BeforeFirst()
+	return hashSetOperationScan
+}
+
+func (hs *HashSetOperationScan) BeforeFirst() {
+	hs.scan1.BeforeFirst()
+
+	hs.counts = make(map[string]types.Int)
+	hs.emitted = make(map[string]struct{})
+	hs.scan2.BeforeFirst()
+	for hs.scan2.Next() {
+		values, err := readValues(hs.scan2, hs.fieldNames2)
+		if err != nil {
+			hs.err = err
+			return
+		}
+		hs.counts[valuesKey(values)]++
+	}
+}
+
+func (hs *HashSetOperationScan) Next() bool {
+	// scan2 を読む途中でエラーが発生した場合は、件数が正しくないので何も出力しない.
+	if hs.Err() != nil {
+		return false
+	}
+	for hs.scan1.Next() {
+		values, err := readValues(hs.scan1, hs.fieldNames1)
+		if err != nil {
+			hs.err = err
+			return false
+		}
+
+		key := valuesKey(values)
+		if _, exists := hs.emitted[key]; exists {
+			continue
+		}
+
+		matched := hs.counts[key] > 0
+		if matched && hs.all {
+			hs.counts[key]--
+		}
+
+		if (hs.operator == INTERSECT) != matched {
+			continue
+		}
+
+		if !hs.all {
+			hs.emitted[key] = struct{}{}
+		}
+		return true
+	}
+	return false
+}
+
+func (hs *HashSetOperationScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return hs.scan1.GetInt(fieldName)
+}
+
+func (hs *HashSetOperationScan) GetString(fieldName types.FieldName) (string, error) {
+	return hs.scan1.GetString(fieldName)
+}
+
+func (hs *HashSetOperationScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return hs.scan1.GetValue(fieldName)
+}
+
+func (hs *HashSetOperationScan) HasField(fieldName types.FieldName) bool {
+	return hs.scan1.HasField(fieldName)
+}
+
+func (hs *HashSetOperationScan) Close() {
+	hs.scan1.Close()
+	hs.scan2.Close()
+}
+
+func (hs *HashSetOperationScan) GetFields() []types.FieldName {
+	return hs.scan1.GetFields()
+}
+
+func (hs *HashSetOperationScan) Err() error {
+	if hs.err != nil {
+		return hs.err
+	}
+	return errors.Join(hs.scan1.Err(), hs.scan2.Err())
+}
@@ -20,5 +20,9 @@ type Constant interface {
 	// string or types.Int を返すことを意図している（雑な実装ではある）
 	GetRawValue() any
 
+	// other より小さければ負の値、等しければ 0、大きければ正の値を返す.
+	// ソートや重複排除のために使う. 型が異なる場合は整数を文字列より小さいものとして扱う.
+	CompareTo(other Constant) int
+
 	ToString() string
 }
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"simple-db-go/types"
+)
+
+var _ Scan = (*LimitScan)(nil)
+
+// LIMIT, OFFSET を処理する Scan.
+// 必要なレコード数を返し終えたら、それ以上内部の scan の Next() を呼ばずに Close し、pin しているバッファーを早めに解放する.
+type LimitScan struct {
+	scan   Scan
+	limit  types.Int
+	offset types.Int
+	// 内部の scan から読み出したレコード数. OFFSET で読み飛ばした分も含む.
+	position types.Int
+	// 内部の scan を既に Close したかどうか.
+	released bool
+}
+
+func NewLimitScan(scan Scan, limit types.Int, offset types.Int) *LimitScan {
+	return &LimitScan{scan: scan, limit: limit, offset: offset}
+}
+
+func (ls *LimitScan) BeforeFirst() {
+	ls.scan.BeforeFirst()
+	ls.position = 0
+	ls.released = false
+}
+
+func (ls *LimitScan) Next() bool {
+	if ls.released {
+		return false
+	}
+
+	// OFFSET の分だけ読み飛ばす.
+	for ls.position < ls.offset {
+		if !ls.scan.Next() {
+			ls.release()
+			return false
+		}
+		ls.position++
+	}
+
+	if ls.position >= ls.offset+ls.limit || !ls.scan.Next() {
+		ls.release()
+		return false
+	}
+
+	ls.position++
+	return true
+}
+
+func (ls *LimitScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return ls.scan.GetInt(fieldName)
+}
+
+func (ls *LimitScan) GetString(fieldName types.FieldName) (string, error) {
+	return ls.scan.GetString(fieldName)
+}
+
+func (ls *LimitScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return ls.scan.GetValue(fieldName)
+}
+
+func (ls *LimitScan) HasField(fieldName types.FieldName) bool {
+	return ls.scan.HasField(fieldName)
+}
+
+func (ls *LimitScan) Close() {
+	ls.release()
+}
+
+func (ls *LimitScan) GetFields() []types.FieldName {
+	return ls.scan.GetFields()
+}
+
+// 内部の scan を Close した後も、読み進める途中で発生したエラーは返す.
+func (ls *LimitScan) Err() error {
+	return ls.scan.Err()
+}
+
+// 内部の scan を Close して、pin しているバッファーを解放する.
+// NOTE: ProductScan の内側のように、この後で BeforeFirst が呼ばれると、Close した内部の scan の BeforeFirst を呼ぶ.
+// TableScan は BeforeFirst で先頭のブロックを pin し直すので、どの Scan も Close した後に BeforeFirst で読み直せるものとしている.
+func (ls *LimitScan) release() {
+	if !ls.released {
+		ls.scan.Close()
+		ls.released = true
+	}
+}
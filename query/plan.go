@@ -16,3 +16,27 @@ type Plan interface {
 	// 各Planが出力するテーブルの schema を返す.
 	GetSchema() *record.Schema
 }
+
+// 列の値の分布. Term の絞り込み率の推定に使う.
+// どの割合も、NULL のレコードを含むテーブルの全てのレコードに対する割合を返す.
+type ColumnDistribution interface {
+	// value と等しい値のレコードの割合.
+	EqualFraction(value Constant) float64
+	// value より小さい(orEqual の場合は value 以下の)値のレコードの割合. NULL のレコードは含まない.
+	LessThanFraction(value Constant, orEqual bool) float64
+	// NULL のレコードの割合.
+	NullFraction() float64
+}
+
+// 列の値の分布を返せる Plan. 統計情報を持つ TablePlan と、それを包む一部の Plan が実装する.
+type ColumnDistributionPlan interface {
+	GetColumnDistribution(fieldName types.FieldName) (ColumnDistribution, bool)
+}
+
+// plan が列の値の分布を返せない場合は false を返す.
+func ColumnDistributionOf(plan Plan, fieldName types.FieldName) (ColumnDistribution, bool) {
+	if plan, ok := plan.(ColumnDistributionPlan); ok {
+		return plan.GetColumnDistribution(fieldName)
+	}
+	return nil, false
+}
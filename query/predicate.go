@@ -27,17 +27,37 @@ func (p *Predicate) ConjoinWith(other Predicate) {
 	p.terms = append(p.terms, other.terms...)
 }
 
-func (p *Predicate) IsSatisfied(scan Scan) (bool, error) {
+// scan の current record に対して、各 Term の論理積を3値論理で評価する.
+// FALSE になる Term が見つかった時点で評価をやめる.
+func (p *Predicate) Evaluate(scan Scan) (TruthValue, error) {
+	result := TRUTH_TRUE
 	for _, term := range p.terms {
-		isSatisfied, err := term.IsSatisfied(scan)
+		value, err := term.Evaluate(scan)
 		if err != nil {
-			return false, err
+			return TRUTH_FALSE, err
+		}
+		result = result.And(value)
+		if result == TRUTH_FALSE {
+			break
 		}
-		if !isSatisfied {
-			return false, nil
+	}
+	return result, nil
+}
+
+// WHERE 句では、評価結果が TRUE のレコードだけが条件を満たす. UNKNOWN は条件を満たさない.
+func (p *Predicate) IsSatisfied(scan Scan) (bool, error) {
+	result, err := p.Evaluate(scan)
+	return result == TRUTH_TRUE, err
+}
+
+// すべての Term が schema のフィールドだけで評価できるかどうか.
+func (p *Predicate) AppliesTo(schema *record.Schema) bool {
+	for _, term := range p.terms {
+		if !term.AppliesTo(schema) {
+			return false
 		}
 	}
-	return true, nil
+	return true
 }
 
 func (p *Predicate) SelectSubPred(schema *record.Schema) (*Predicate, error) {
@@ -102,6 +122,35 @@ func (p *Predicate) ToString() string {
 	return strings.Join(termStrings, " AND ")
 }
 
+// 相関サブクエリの WHERE 句で、外側のクエリのフィールドを参照している Expression を置き換えるために使う.
+// サブクエリの中の WHERE 句は、そのサブクエリを plan する時に別途置き換える.
+func (p *Predicate) ReplaceFieldNames(replace func(FieldNameExpression) Expression) {
+	for _, term := range p.terms {
+		term.replaceFieldNames(replace)
+	}
+}
+
+// WHERE 句に直接含まれるサブクエリを返す. planner はこれを使ってサブクエリの plan を作る.
+func (p *Predicate) Subqueries() []*SubqueryExpression {
+	var subqueries []*SubqueryExpression
+	for _, term := range p.terms {
+		if subquery, ok := term.rhs.(*SubqueryExpression); ok {
+			subqueries = append(subqueries, subquery)
+		}
+	}
+	return subqueries
+}
+
+// plan されたサブクエリが、それぞれの Term で使える形になっているか確認する.
+func (p *Predicate) CheckSubqueries() error {
+	for _, term := range p.terms {
+		if err := term.checkSubquery(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *Predicate) GetReductionFactor(plan Plan) types.Int {
 	result := types.Int(1)
 
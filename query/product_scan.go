@@ -1,6 +1,7 @@
 package query
 
 import (
+	"errors"
 	"simple-db-go/types"
 )
 
@@ -67,3 +68,7 @@ func (ps *ProductScan) Close() {
 func (ps *ProductScan) GetFields() []types.FieldName {
 	return append(ps.scan1.GetFields(), ps.scan2.GetFields()...)
 }
+
+func (ps *ProductScan) Err() error {
+	return errors.Join(ps.scan1.Err(), ps.scan2.Err())
+}
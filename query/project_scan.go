@@ -63,3 +63,7 @@ func (ps *ProjectScan) Close() {
 func (ps *ProjectScan) GetFields() []types.FieldName {
 	return ps.fieldNameList
 }
+
+func (ps *ProjectScan) Err() error {
+	return ps.scan.Err()
+}
@@ -0,0 +1,67 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArithmeticExpression(t *testing.T) {
+	fieldNames := []types.FieldName{"a", "b", "name", "age"}
+	values := []query.Constant{query.NewIntConstant(7), query.NewIntConstant(2), query.NewStrConstant("hoge"), query.NewNullConstant()}
+	rowScan := query.NewRowScan(fieldNames, values)
+	rowScan.Next()
+
+	a := query.NewFieldNameExpression("a")
+	b := query.NewFieldNameExpression("b")
+
+	t.Run("レコードの値で四則演算ができること.", func(t *testing.T) {
+		tests := []struct {
+			expression query.Expression
+			expected   types.Int
+		}{
+			{query.NewArithmeticExpression(query.ADD, a, query.NewIntConstant(1)), 8},
+			{query.NewArithmeticExpression(query.SUBTRACT, a, b), 5},
+			{query.NewArithmeticExpression(query.MULTIPLY, a, b), 14},
+			{query.NewArithmeticExpression(query.DIVIDE, a, b), 3},
+			{query.NewArithmeticExpression(query.DIVIDE, query.NewIntConstant(-7), b), -3},
+		}
+
+		for i, test := range tests {
+			result, err := test.expression.Evaluate(rowScan)
+			if assert.NoErrorf(t, err, "[i=%d]", i) {
+				assert.Equalf(t, query.NewIntConstant(test.expected), result, "[i=%d] expression=%s", i, test.expression.ToString())
+			}
+		}
+	})
+
+	t.Run("どちらかが NULL の場合は NULL になること.", func(t *testing.T) {
+		result, err := query.NewArithmeticExpression(query.ADD, query.NewFieldNameExpression("age"), a).Evaluate(rowScan)
+		if assert.NoError(t, err) {
+			assert.True(t, query.IsNull(result))
+		}
+	})
+
+	t.Run("整数でない値、0 での割り算、INT の範囲を超える結果はエラーになること.", func(t *testing.T) {
+		_, err := query.NewArithmeticExpression(query.ADD, query.NewFieldNameExpression("name"), a).Evaluate(rowScan)
+		assert.IsType(t, &query.ArithmeticTypeError{}, err)
+
+		_, err = query.NewArithmeticExpression(query.DIVIDE, a, query.NewIntConstant(0)).Evaluate(rowScan)
+		assert.IsType(t, &query.DivisionByZeroError{}, err)
+
+		_, err = query.NewArithmeticExpression(query.MULTIPLY, query.NewIntConstant(1<<30), query.NewIntConstant(4)).Evaluate(rowScan)
+		assert.IsType(t, &query.ArithmeticOverflowError{}, err)
+	})
+
+	t.Run("優先順位が必要な場合だけ括弧をつけて文字列にすること.", func(t *testing.T) {
+		sum := query.NewArithmeticExpression(query.ADD, a, b)
+		product := query.NewArithmeticExpression(query.MULTIPLY, a, b)
+
+		assert.Equal(t, "a + b * 2", query.NewArithmeticExpression(query.ADD, a, query.NewArithmeticExpression(query.MULTIPLY, b, query.NewIntConstant(2))).ToString())
+		assert.Equal(t, "(a + b) * 2", query.NewArithmeticExpression(query.MULTIPLY, sum, query.NewIntConstant(2)).ToString())
+		assert.Equal(t, "a * b - 1", query.NewArithmeticExpression(query.SUBTRACT, product, query.NewIntConstant(1)).ToString())
+		assert.Equal(t, "1 - (a + b)", query.NewArithmeticExpression(query.SUBTRACT, query.NewIntConstant(1), sum).ToString())
+	})
+}
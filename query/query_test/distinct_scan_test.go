@@ -0,0 +1,60 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinctScans(t *testing.T) {
+	transaction := newTransactionForTest(t, distinctScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("name", 10)
+
+	// (id, name) の組が重複するレコードを、ソート済みの順で入れておく.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	rows := []struct {
+		id   types.Int
+		name string
+	}{{1, "a"}, {1, "a"}, {1, "b"}, {2, "a"}, {2, "a"}, {2, "a"}, {3, "c"}}
+	for _, row := range rows {
+		tableScan.Insert()
+		tableScan.SetInt("id", row.id)
+		tableScan.SetString("name", row.name)
+	}
+	tableScan.Close()
+
+	fieldNames := []types.FieldName{"id", "name"}
+	scans := map[string]query.Scan{
+		"DistinctScan":     query.NewDistinctScan(tempTable.Open(), fieldNames),
+		"HashDistinctScan": query.NewHashDistinctScan(tempTable.Open(), fieldNames),
+	}
+
+	for name, scan := range scans {
+		t.Run(name+" で重複したレコードが取り除かれること.", func(t *testing.T) {
+			defer scan.Close()
+
+			for range 2 {
+				results := []string{}
+				for scan.Next() {
+					idValue, idError := scan.GetInt("id")
+					nameValue, nameError := scan.GetString("name")
+					if assert.NoError(t, idError) && assert.NoError(t, nameError) {
+						results = append(results, idValue.ToString()+nameValue)
+					}
+				}
+				assert.Equal(t, []string{"1a", "1b", "2a", "3c"}, results, "重複が取り除かれていること.")
+
+				// BeforeFirst の後でも同じ結果になること.
+				scan.BeforeFirst()
+			}
+		})
+	}
+}
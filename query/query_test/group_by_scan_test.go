@@ -0,0 +1,124 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByScanAggregatesEachGroup(t *testing.T) {
+	transaction := newTransactionForTest(t, groupByScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("dept")
+	schema.AddIntField("salary")
+
+	// GroupByScan はグループ化するフィールドでソート済みであることを前提とするので、dept 順に入れる.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	rows := [][2]types.Int{{1, 100}, {1, 200}, {1, 200}, {2, 50}, {3, 10}, {3, 30}}
+	for _, row := range rows {
+		tableScan.Insert()
+		tableScan.SetInt("dept", row[0])
+		tableScan.SetInt("salary", row[1])
+	}
+	tableScan.Close()
+
+	t.Run("グループごとに集約関数の結果を取得できること.", func(t *testing.T) {
+		aggregationFunctions := []query.AggregationFunction{
+			query.NewCountFunction("salary", false),
+			query.NewCountFunction("salary", true),
+			query.NewSumFunction("salary", false),
+			query.NewMaxFunction("salary"),
+			query.NewMinFunction("salary"),
+		}
+		groupByScan := query.NewGroupByScan(tempTable.Open(), []types.FieldName{"dept"}, aggregationFunctions)
+		defer groupByScan.Close()
+
+		expected := [][6]types.Int{
+			{1, 3, 2, 500, 200, 100},
+			{2, 1, 1, 50, 50, 50},
+			{3, 2, 2, 40, 30, 10},
+		}
+		fields := []types.FieldName{"dept", "count(salary)", "count(distinct salary)", "sum(salary)", "max(salary)", "min(salary)"}
+		assert.Equal(t, fields, groupByScan.GetFields(), "グループ化したフィールドと集約関数の結果のフィールドを持つこと.")
+
+		i := 0
+		for ; groupByScan.Next(); i++ {
+			for j, field := range fields {
+				value, err := groupByScan.GetInt(field)
+				if assert.NoErrorf(t, err, "field=%s", field) {
+					assert.Equalf(t, expected[i][j], value, "[i=%d] %s が期待した値であること.", i, field)
+				}
+			}
+		}
+		assert.Equal(t, 3, i, "グループの数だけレコードが出力されること.")
+	})
+
+	t.Run("GROUP BY が無い場合、レコードが無くても1行出力されること.", func(t *testing.T) {
+		emptyTable := query.NewTempTable(transaction, schema)
+		groupByScan := query.NewGroupByScan(emptyTable.Open(), []types.FieldName{}, []query.AggregationFunction{query.NewCountFunction(query.ALL_FIELDS, false)})
+		defer groupByScan.Close()
+
+		if assert.True(t, groupByScan.Next(), "1行出力されること.") {
+			value, err := groupByScan.GetInt("count(*)")
+			if assert.NoError(t, err) {
+				assert.Equal(t, types.Int(0), value, "件数は 0 であること.")
+			}
+		}
+		assert.False(t, groupByScan.Next(), "2行目は無いこと.")
+	})
+}
+
+func TestGroupByScanIgnoresNull(t *testing.T) {
+	transaction := newTransactionForTest(t, groupByScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("dept")
+	schema.AddIntField("salary")
+
+	// dept=1 は NULL と値が混在し、dept=2 は NULL だけのグループ.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	for _, row := range []query.Constant{query.NewIntConstant(100), query.NewNullConstant(), query.NewIntConstant(300)} {
+		tableScan.Insert()
+		tableScan.SetInt("dept", 1)
+		tableScan.SetValue("salary", row)
+	}
+	tableScan.Insert()
+	tableScan.SetInt("dept", 2)
+	tableScan.SetValue("salary", query.NewNullConstant())
+	tableScan.Close()
+
+	aggregationFunctions := []query.AggregationFunction{
+		query.NewCountFunction(query.ALL_FIELDS, false),
+		query.NewCountFunction("salary", false),
+		query.NewSumFunction("salary", false),
+		query.NewMaxFunction("salary"),
+		query.NewMinFunction("salary"),
+	}
+	groupByScan := query.NewGroupByScan(tempTable.Open(), []types.FieldName{"dept"}, aggregationFunctions)
+	defer groupByScan.Close()
+
+	fields := []types.FieldName{"count(*)", "count(salary)", "sum(salary)", "max(salary)", "min(salary)"}
+	expected := [][]query.Constant{
+		{query.NewIntConstant(3), query.NewIntConstant(2), query.NewIntConstant(400), query.NewIntConstant(300), query.NewIntConstant(100)},
+		{query.NewIntConstant(1), query.NewIntConstant(0), query.NewNullConstant(), query.NewNullConstant(), query.NewNullConstant()},
+	}
+
+	i := 0
+	for ; groupByScan.Next(); i++ {
+		for j, field := range fields {
+			value, err := groupByScan.GetValue(field)
+			if assert.NoErrorf(t, err, "field=%s", field) {
+				assert.Equalf(t, expected[i][j], value, "[i=%d] %s が期待した値であること. NULL は COUNT(*) 以外では無視される.", i, field)
+			}
+		}
+	}
+	assert.Equal(t, 2, i, "グループの数だけレコードが出力されること.")
+}
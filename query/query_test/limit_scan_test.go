@@ -0,0 +1,97 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitScan(t *testing.T) {
+	transaction := newTransactionForTest(t, limitScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+
+	// 複数ブロックにまたがるように、ある程度の件数を入れておく.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	for i := types.Int(0); i < 200; i++ {
+		tableScan.Insert()
+		tableScan.SetInt("id", i)
+	}
+	tableScan.Close()
+
+	tests := []struct {
+		limit    types.Int
+		offset   types.Int
+		expected []types.Int
+	}{
+		{3, 0, []types.Int{0, 1, 2}},
+		{3, 10, []types.Int{10, 11, 12}},
+		{5, 198, []types.Int{198, 199}},
+		{0, 0, []types.Int{}},
+		{10, 300, []types.Int{}},
+	}
+
+	for i, test := range tests {
+		limitScan := query.NewLimitScan(tempTable.Open(), test.limit, test.offset)
+
+		ids := []types.Int{}
+		for limitScan.Next() {
+			id, err := limitScan.GetInt("id")
+			if assert.NoErrorf(t, err, "[i=%d]", i) {
+				ids = append(ids, id)
+			}
+		}
+		assert.Equalf(t, test.expected, ids, "[i=%d] LIMIT, OFFSET の範囲のレコードだけ取得できること.", i)
+		assert.Falsef(t, limitScan.Next(), "[i=%d] 読み終えた後は Next() が false を返し続けること.", i)
+
+		limitScan.Close()
+	}
+
+	t.Run("LIMIT に達したら内部の scan の pin が解放されること.", func(t *testing.T) {
+		availableBefore := transaction.AvailableBuffers()
+
+		limitScan := query.NewLimitScan(tempTable.Open(), 2, 0)
+		defer limitScan.Close()
+		assert.Equal(t, availableBefore-1, transaction.AvailableBuffers(), "Open した時点でブロックが pin されていること.")
+
+		for limitScan.Next() {
+		}
+		assert.Equal(t, availableBefore, transaction.AvailableBuffers(), "LIMIT に達した時点で pin が解放されていること.")
+
+		limitScan.BeforeFirst()
+		if assert.True(t, limitScan.Next(), "BeforeFirst で先頭から読み直せること.") {
+			id, err := limitScan.GetInt("id")
+			if assert.NoError(t, err) {
+				assert.Equal(t, types.Int(0), id)
+			}
+		}
+	})
+
+	t.Run("積の内側にある場合も、外側のレコードごとに先頭から読み直せること.", func(t *testing.T) {
+		outerScan := query.NewValuesScan([]types.FieldName{"outer_id"}, [][]query.Constant{
+			{query.NewIntConstant(1)},
+			{query.NewIntConstant(2)},
+			{query.NewIntConstant(3)},
+		})
+		// FROM 句の `(SELECT id FROM t LIMIT 2 OFFSET 1)` と同じように、LIMIT の内側にも scan を重ねておく.
+		innerScan := query.NewLimitScan(query.NewProjectScan(tempTable.Open(), []types.FieldName{"id"}), 2, 1)
+		productScan := query.NewProductScan(outerScan, innerScan)
+		defer productScan.Close()
+
+		rows := [][]types.Int{}
+		for productScan.Next() {
+			outerID, err := productScan.GetInt("outer_id")
+			assert.NoError(t, err)
+			id, err := productScan.GetInt("id")
+			assert.NoError(t, err)
+			rows = append(rows, []types.Int{outerID, id})
+		}
+		assert.Equal(t, [][]types.Int{{1, 1}, {1, 2}, {2, 1}, {2, 2}, {3, 1}, {3, 2}}, rows, "内部の scan を Close した後の BeforeFirst でも読み直せること.")
+	})
+}
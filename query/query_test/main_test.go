@@ -11,10 +11,18 @@ import (
 )
 
 const (
-	tableScanTestName   = "test_table_scan"
-	selectScanTestName  = "test_select_scan"
-	projectScanTestName = "test_project_scan"
-	productScanTestName = "test_product_scan"
+	tableScanTestName        = "test_table_scan"
+	selectScanTestName       = "test_select_scan"
+	projectScanTestName      = "test_project_scan"
+	productScanTestName      = "test_product_scan"
+	sortScanTestName         = "test_sort_scan"
+	groupByScanTestName      = "test_group_by_scan"
+	distinctScanTestName     = "test_distinct_scan"
+	limitScanTestName        = "test_limit_scan"
+	subqueryTestName         = "test_subquery"
+	setOperationScanTestName = "test_set_operation_scan"
+	recursiveScanTestName    = "test_recursive_scan"
+	windowScanTestName       = "test_window_scan"
 )
 
 func TestMain(m *testing.M) {
@@ -22,6 +30,14 @@ func TestMain(m *testing.M) {
 	util.Cleanup(selectScanTestName)
 	util.Cleanup(projectScanTestName)
 	util.Cleanup(productScanTestName)
+	util.Cleanup(sortScanTestName)
+	util.Cleanup(groupByScanTestName)
+	util.Cleanup(distinctScanTestName)
+	util.Cleanup(limitScanTestName)
+	util.Cleanup(subqueryTestName)
+	util.Cleanup(setOperationScanTestName)
+	util.Cleanup(recursiveScanTestName)
+	util.Cleanup(windowScanTestName)
 
 	code := m.Run()
 
@@ -29,6 +45,14 @@ func TestMain(m *testing.M) {
 	util.Cleanup(selectScanTestName)
 	util.Cleanup(projectScanTestName)
 	util.Cleanup(productScanTestName)
+	util.Cleanup(sortScanTestName)
+	util.Cleanup(groupByScanTestName)
+	util.Cleanup(distinctScanTestName)
+	util.Cleanup(limitScanTestName)
+	util.Cleanup(subqueryTestName)
+	util.Cleanup(setOperationScanTestName)
+	util.Cleanup(recursiveScanTestName)
+	util.Cleanup(windowScanTestName)
 	os.Exit(code)
 }
 
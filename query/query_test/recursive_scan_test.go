@@ -0,0 +1,138 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scan を作る関数と schema だけを持つ plan.
+type planForTest struct {
+	open   func() query.Scan
+	schema *record.Schema
+}
+
+func (p *planForTest) Open() query.Scan                                      { return p.open() }
+func (p *planForTest) GetBlocksAccessed() types.Int                          { return 1 }
+func (p *planForTest) GetRecordsOutput() types.Int                           { return 1 }
+func (p *planForTest) GetDistinctValues(fieldName types.FieldName) types.Int { return 1 }
+func (p *planForTest) GetSchema() *record.Schema                             { return p.schema }
+
+func TestRecursiveScan(t *testing.T) {
+	transaction := newTransactionForTest(t, recursiveScanTestName)
+	defer transaction.Rollback()
+
+	// 1 -> 2 -> 3 -> 1 と循環していて、3 -> 4 にも辺があるグラフ.
+	edgesSchema := record.NewSchema()
+	edgesSchema.AddIntField("src")
+	edgesSchema.AddIntField("dst")
+	edges := query.NewTempTable(transaction, edgesSchema)
+	scan := edges.Open()
+	for _, edge := range [][2]types.Int{{1, 2}, {2, 3}, {3, 1}, {3, 4}} {
+		scan.Insert()
+		scan.SetInt("src", edge[0])
+		scan.SetInt("dst", edge[1])
+	}
+	scan.Close()
+
+	dstSchema := record.NewSchema()
+	dstSchema.AddIntField("dst")
+
+	// `WITH RECURSIVE reach (node) AS (
+	//   SELECT dst FROM edges WHERE src = 1
+	//   UNION SELECT dst FROM edges, reach WHERE src = node
+	// )` を想定.
+	schema := record.NewSchema()
+	schema.AddIntField("node")
+	workingTable := query.NewWorkingTable(transaction, schema)
+
+	anchorPlan := &planForTest{
+		open: func() query.Scan {
+			predicate := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("src"), query.NewIntConstant(1)))
+			return query.NewProjectScan(query.NewSelectScan(edges.Open(), predicate), []types.FieldName{"dst"})
+		},
+		schema: dstSchema,
+	}
+	recursivePlan := &planForTest{
+		open: func() query.Scan {
+			predicate := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("src"), query.NewFieldNameExpression("node")))
+			productScan := query.NewProductScan(edges.Open(), workingTable.Open())
+			return query.NewProjectScan(query.NewSelectScan(productScan, predicate), []types.FieldName{"dst"})
+		},
+		schema: dstSchema,
+	}
+
+	readNodes := func(t *testing.T, scan query.Scan) []types.Int {
+		nodes := []types.Int{}
+		for scan.Next() {
+			node, err := scan.GetInt("node")
+			if assert.NoError(t, err) {
+				nodes = append(nodes, node)
+			}
+		}
+		return nodes
+	}
+
+	t.Run("UNION の場合、新しいレコードが無くなるまで繰り返すこと.", func(t *testing.T) {
+		recursiveScan := query.NewRecursiveScan(transaction, schema, anchorPlan, []query.Plan{recursivePlan}, workingTable, true)
+		defer recursiveScan.Close()
+
+		assert.Equal(t, []types.Int{2, 3, 1, 4}, readNodes(t, recursiveScan), "循環していても、既に出力したレコードで止まること.")
+
+		recursiveScan.BeforeFirst()
+		assert.Equal(t, []types.Int{2, 3, 1, 4}, readNodes(t, recursiveScan), "BeforeFirst の後は最初から評価し直すこと.")
+	})
+
+	t.Run("UNION ALL の場合、重複を取り除かずに繰り返すこと.", func(t *testing.T) {
+		recursiveScan := query.NewRecursiveScan(transaction, schema, anchorPlan, []query.Plan{recursivePlan}, workingTable, false)
+		limitScan := query.NewLimitScan(recursiveScan, 7, 0)
+		defer limitScan.Close()
+
+		assert.Equal(t, []types.Int{2, 3, 1, 4, 2, 3, 1}, readNodes(t, limitScan))
+	})
+
+	t.Run("繰り返しの回数が上限を超えた場合は、Err でエラーを返すこと.", func(t *testing.T) {
+		recursiveScan := query.NewRecursiveScan(transaction, schema, anchorPlan, []query.Plan{recursivePlan}, workingTable, false)
+		defer recursiveScan.Close()
+
+		nodes := readNodes(t, recursiveScan)
+		assert.Equal(t, []types.Int{2, 3, 1, 4}, nodes[:4], "上限までの繰り返しで追加されたレコードは出力すること.")
+		assert.IsType(t, &query.RecursionDepthExceededError{}, recursiveScan.Err())
+	})
+}
+
+func TestRenameScan(t *testing.T) {
+	transaction := newTransactionForTest(t, recursiveScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("name", 10)
+	tempTable := query.NewTempTable(transaction, schema)
+	scan := tempTable.Open()
+	scan.Insert()
+	scan.SetInt("id", 1)
+	scan.SetString("name", "hoge")
+	scan.Close()
+
+	renameScan := query.NewRenameScan(tempTable.Open(), []types.FieldName{"user_id", "user_name"}, []types.FieldName{"id", "name"})
+	defer renameScan.Close()
+
+	if assert.True(t, renameScan.Next()) {
+		id, err := renameScan.GetInt("user_id")
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.Int(1), id)
+		}
+		name, err := renameScan.GetString("user_name")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "hoge", name)
+		}
+
+		_, err = renameScan.GetInt("id")
+		assert.Error(t, err, "元のフィールド名では読めないこと.")
+	}
+	assert.Equal(t, []types.FieldName{"user_id", "user_name"}, renameScan.GetFields())
+}
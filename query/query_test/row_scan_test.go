@@ -0,0 +1,57 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowScan(t *testing.T) {
+	fieldNames := []types.FieldName{"id", "name", "age"}
+	values := []query.Constant{query.NewIntConstant(1), query.NewStrConstant("hoge"), query.NewNullConstant()}
+	rowScan := query.NewRowScan(fieldNames, values)
+
+	t.Run("1レコードだけ出力されること.", func(t *testing.T) {
+		assert.True(t, rowScan.Next())
+		assert.False(t, rowScan.Next())
+
+		rowScan.BeforeFirst()
+		assert.True(t, rowScan.Next(), "BeforeFirst で先頭に戻れること.")
+	})
+
+	t.Run("フィールドの値を読めること.", func(t *testing.T) {
+		id, err := rowScan.GetInt("id")
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.Int(1), id)
+		}
+
+		name, err := rowScan.GetString("name")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "hoge", name)
+		}
+
+		age, err := rowScan.GetValue("age")
+		if assert.NoError(t, err) {
+			assert.True(t, query.IsNull(age))
+		}
+
+		_, err = rowScan.GetValue("unknown")
+		assert.Error(t, err, "存在しないフィールドはエラーになること.")
+	})
+
+	t.Run("Predicate を評価できること.", func(t *testing.T) {
+		satisfied := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("name"), query.NewStrConstant("hoge")))
+		result, err := satisfied.Evaluate(rowScan)
+		if assert.NoError(t, err) {
+			assert.Equal(t, query.TRUTH_TRUE, result)
+		}
+
+		unknown := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("age"), query.NewIntConstant(20)))
+		result, err = unknown.Evaluate(rowScan)
+		if assert.NoError(t, err) {
+			assert.Equal(t, query.TRUTH_UNKNOWN, result, "NULL との比較は UNKNOWN になること.")
+		}
+	})
+}
@@ -105,8 +105,7 @@ func TestSelectScanScanInterfaceMethods(t *testing.T) {
 		assert.False(t, selectScan.Next(), "条件にマッチするレコードが１件もない場合、Next() が直ちに false を返すべし.")
 	})
 
-	// TODO: これはきちんとハンドリングしたい。ただ、Next() の変更はちょっと面倒なので後回し。
-	t.Run("Predicate にて存在しない列名を含む条件を定義するとpanicする（仕様について要検討）", func(t *testing.T) {
+	t.Run("Predicate にて存在しない列名を含む条件を定義すると、Next() が false を返して Err() でエラーを返す", func(t *testing.T) {
 		// `hoge = 999` を想定。hoge という列名は存在しない。
 		testTerm := query.NewTerm(query.NewFieldNameExpression("hoge"), query.NewIntConstant(999))
 		testPredicate := query.NewPredicateWith(testTerm)
@@ -115,6 +114,133 @@ func TestSelectScanScanInterfaceMethods(t *testing.T) {
 		selectScan := query.NewSelectScan(tableScan, testPredicate)
 		defer selectScan.Close()
 
-		assert.Panics(t, func() { selectScan.Next() }, "存在しない列名を含む条件を定義するとpanicするべし.エラーハンドリングの改善を検討する.")
+		assert.NoError(t, selectScan.Err(), "読み始める前はエラーが無いこと.")
+		assert.False(t, selectScan.Next(), "存在しない列名を含む条件を定義すると、Next() が false を返すべし.")
+		assert.Error(t, selectScan.Err(), "条件の評価で発生したエラーを Err() で返すべし.")
 	})
 }
+
+func TestSelectScanWithNull(t *testing.T) {
+	transaction := newTransactionForTest(t, selectScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("name", 10)
+
+	// id が偶数のレコードだけ name を NULL にする.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	for i := types.Int(0); i < 10; i++ {
+		tableScan.Insert()
+		tableScan.SetInt("id", i)
+		if i%2 == 0 {
+			tableScan.SetValue("name", query.NewNullConstant())
+		} else {
+			tableScan.SetString("name", "hoge")
+		}
+	}
+	tableScan.Close()
+
+	selectIds := func(predicate *query.Predicate) []types.Int {
+		selectScan := query.NewSelectScan(tempTable.Open(), predicate)
+		defer selectScan.Close()
+
+		ids := []types.Int{}
+		for selectScan.Next() {
+			id, err := selectScan.GetInt("id")
+			assert.NoError(t, err)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	t.Run("IS NULL で NULL のレコードだけを取得できること.", func(t *testing.T) {
+		predicate := query.NewPredicateWith(query.NewIsNullTerm(query.NewFieldNameExpression("name")))
+		assert.Equal(t, []types.Int{0, 2, 4, 6, 8}, selectIds(predicate))
+	})
+
+	t.Run("IS NOT NULL で NULL ではないレコードだけを取得できること.", func(t *testing.T) {
+		predicate := query.NewPredicateWith(query.NewIsNotNullTerm(query.NewFieldNameExpression("name")))
+		assert.Equal(t, []types.Int{1, 3, 5, 7, 9}, selectIds(predicate))
+	})
+
+	t.Run("NULL との比較は UNKNOWN になり、どのレコードも取得できないこと.", func(t *testing.T) {
+		predicate := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("name"), query.NewNullConstant()))
+		assert.Equal(t, []types.Int{}, selectIds(predicate))
+	})
+
+	t.Run("NULL のフィールドは GetValue で NULL を返し、GetString ではゼロ値を返すこと.", func(t *testing.T) {
+		tableScan := tempTable.Open()
+		defer tableScan.Close()
+
+		if assert.True(t, tableScan.Next()) {
+			value, err := tableScan.GetValue("name")
+			if assert.NoError(t, err) {
+				assert.True(t, query.IsNull(value))
+			}
+			name, err := tableScan.GetString("name")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "", name)
+			}
+		}
+	})
+}
+
+func TestSelectScanWithRangeComparison(t *testing.T) {
+	transaction := newTransactionForTest(t, selectScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("name", 10)
+
+	// id が 0 のレコードだけ name を NULL にする.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	for i := types.Int(0); i < 5; i++ {
+		tableScan.Insert()
+		tableScan.SetInt("id", i)
+		if i == 0 {
+			tableScan.SetValue("name", query.NewNullConstant())
+		} else {
+			tableScan.SetString("name", string(rune('a'+i)))
+		}
+	}
+	tableScan.Close()
+
+	selectIds := func(term *query.Term) []types.Int {
+		selectScan := query.NewSelectScan(tempTable.Open(), query.NewPredicateWith(term))
+		defer selectScan.Close()
+
+		ids := []types.Int{}
+		for selectScan.Next() {
+			id, err := selectScan.GetInt("id")
+			assert.NoError(t, err)
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		term     *query.Term
+		expected []types.Int
+	}{
+		{"id < 2", query.NewComparisonTerm(query.LESS_THAN, query.NewFieldNameExpression("id"), query.NewIntConstant(2)), []types.Int{0, 1}},
+		{"id <= 2", query.NewComparisonTerm(query.LESS_EQUAL, query.NewFieldNameExpression("id"), query.NewIntConstant(2)), []types.Int{0, 1, 2}},
+		{"id > 2", query.NewComparisonTerm(query.GREATER_THAN, query.NewFieldNameExpression("id"), query.NewIntConstant(2)), []types.Int{3, 4}},
+		{"id >= 2", query.NewComparisonTerm(query.GREATER_EQUAL, query.NewFieldNameExpression("id"), query.NewIntConstant(2)), []types.Int{2, 3, 4}},
+		{"2 > id", query.NewComparisonTerm(query.GREATER_THAN, query.NewIntConstant(2), query.NewFieldNameExpression("id")), []types.Int{0, 1}},
+		// NULL との比較は UNKNOWN になるので、name が NULL の id 0 は含まれない.
+		{"name < 'd'", query.NewComparisonTerm(query.LESS_THAN, query.NewFieldNameExpression("name"), query.NewStrConstant("d")), []types.Int{1, 2}},
+		{"id < NULL", query.NewComparisonTerm(query.LESS_THAN, query.NewFieldNameExpression("id"), query.NewNullConstant()), []types.Int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, selectIds(test.term))
+			assert.Equal(t, test.name, test.term.ToString(), "ToString で元の条件が復元できること.")
+		})
+	}
+}
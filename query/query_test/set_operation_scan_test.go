@@ -0,0 +1,83 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// フィールド名の異なる2つの一時テーブルを用意する.
+// lhs(id) = 1, 2, 2, 3 と rhs(user_id) = 2, 3, 3, 4.
+func newSetOperationTablesForTest(t *testing.T) (*query.TempTable, *query.TempTable) {
+	transaction := newTransactionForTest(t, setOperationScanTestName)
+	t.Cleanup(func() { transaction.Rollback() })
+
+	newTable := func(fieldName types.FieldName, values []types.Int) *query.TempTable {
+		schema := record.NewSchema()
+		schema.AddIntField(fieldName)
+		tempTable := query.NewTempTable(transaction, schema)
+		tableScan := tempTable.Open()
+		for _, value := range values {
+			tableScan.Insert()
+			tableScan.SetInt(fieldName, value)
+		}
+		tableScan.Close()
+		return tempTable
+	}
+
+	return newTable("id", []types.Int{1, 2, 2, 3}), newTable("user_id", []types.Int{2, 3, 3, 4})
+}
+
+func readIDs(t *testing.T, scan query.Scan) []types.Int {
+	ids := []types.Int{}
+	for scan.Next() {
+		id, err := scan.GetInt("id")
+		if assert.NoError(t, err) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestUnionScan(t *testing.T) {
+	lhs, rhs := newSetOperationTablesForTest(t)
+
+	scan := query.NewUnionScan(lhs.Open(), []types.FieldName{"id"}, rhs.Open(), []types.FieldName{"user_id"})
+	defer scan.Close()
+
+	assert.Equal(t, []types.Int{1, 2, 2, 3, 2, 3, 3, 4}, readIDs(t, scan), "lhs に続けて rhs のレコードを、lhs のフィールド名で読めること.")
+	assert.Equal(t, []types.FieldName{"id"}, scan.GetFields())
+	assert.False(t, scan.HasField("user_id"))
+
+	scan.BeforeFirst()
+	assert.Equal(t, []types.Int{1, 2, 2, 3, 2, 3, 3, 4}, readIDs(t, scan), "BeforeFirst の後でも同じ結果になること.")
+}
+
+func TestHashSetOperationScan(t *testing.T) {
+	lhs, rhs := newSetOperationTablesForTest(t)
+
+	tests := []struct {
+		operator query.SetOperator
+		all      bool
+		expected []types.Int
+	}{
+		{query.INTERSECT, false, []types.Int{2, 3}},
+		{query.INTERSECT, true, []types.Int{2, 3}},
+		{query.EXCEPT, false, []types.Int{1}},
+		{query.EXCEPT, true, []types.Int{1, 2}},
+	}
+
+	for _, test := range tests {
+		scan := query.NewHashSetOperationScan(test.operator, test.all, lhs.Open(), []types.FieldName{"id"}, rhs.Open(), []types.FieldName{"user_id"})
+
+		assert.Equalf(t, test.expected, readIDs(t, scan), "operator=%s, all=%t", test.operator, test.all)
+
+		scan.BeforeFirst()
+		assert.Equalf(t, test.expected, readIDs(t, scan), "BeforeFirst の後でも同じ結果になること. operator=%s, all=%t", test.operator, test.all)
+
+		scan.Close()
+	}
+}
@@ -0,0 +1,61 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortScanMergesTwoRuns(t *testing.T) {
+	transaction := newTransactionForTest(t, sortScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("name", 10)
+
+	// ソート済みの run を2つ用意する. 1つ目は偶数、2つ目は奇数.
+	run1 := query.NewTempTable(transaction, schema)
+	run2 := query.NewTempTable(transaction, schema)
+	assert.NotEqual(t, run1.GetTableName(), run2.GetTableName(), "一時テーブルの名前は重複しないこと.")
+
+	for i, run := range []*query.TempTable{run1, run2} {
+		scan := run.Open()
+		for id := types.Int(i); id < 20; id += 2 {
+			scan.Insert()
+			scan.SetInt("id", id)
+			scan.SetString("name", "name"+id.ToString())
+		}
+		scan.Close()
+	}
+
+	sortScan := query.NewSortScan([]*query.TempTable{run1, run2}, query.NewRecordComparator([]types.FieldName{"id"}))
+	defer sortScan.Close()
+
+	t.Run("2つの run をマージして、昇順に読み出せること.", func(t *testing.T) {
+		count := types.Int(0)
+		for sortScan.Next() {
+			idValue, idError := sortScan.GetInt("id")
+			nameValue, nameError := sortScan.GetString("name")
+			if assert.NoError(t, idError) && assert.NoError(t, nameError) {
+				assert.Equalf(t, count, idValue, "id が昇順であること. count=%d", count)
+				assert.Equalf(t, "name"+count.ToString(), nameValue, "name が id と対応していること. count=%d", count)
+			}
+			count++
+		}
+		assert.Equal(t, types.Int(20), count, "全てのレコードを読み出せること.")
+	})
+
+	t.Run("BeforeFirst で先頭から読み直せること.", func(t *testing.T) {
+		sortScan.BeforeFirst()
+		if assert.True(t, sortScan.Next()) {
+			idValue, err := sortScan.GetInt("id")
+			if assert.NoError(t, err) {
+				assert.Equal(t, types.Int(0), idValue, "先頭のレコードに戻っていること.")
+			}
+		}
+	})
+}
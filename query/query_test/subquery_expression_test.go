@@ -0,0 +1,144 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// サブクエリの plan の代わりに使う. 一時テーブルを predicate で絞り込み、Open された回数を記録する.
+type subqueryPlanForTest struct {
+	table     *query.TempTable
+	predicate *query.Predicate
+	opened    int
+}
+
+func (p *subqueryPlanForTest) Open() query.Scan {
+	p.opened++
+	return query.NewSelectScan(p.table.Open(), p.predicate)
+}
+
+func (p *subqueryPlanForTest) GetBlocksAccessed() types.Int { return 1 }
+func (p *subqueryPlanForTest) GetRecordsOutput() types.Int  { return 1 }
+func (p *subqueryPlanForTest) GetDistinctValues(fieldName types.FieldName) types.Int {
+	return 1
+}
+func (p *subqueryPlanForTest) GetSchema() *record.Schema { return p.table.GetLayout().GetSchema() }
+
+type subqueryDefinitionForTest string
+
+func (d subqueryDefinitionForTest) ToString() string { return string(d) }
+
+func TestSubqueryExpression(t *testing.T) {
+	transaction := newTransactionForTest(t, subqueryTestName)
+	defer transaction.Rollback()
+
+	// users(id) と orders(user_id) を用意する. 注文があるのは id = 1, 3, 5 のユーザー.
+	usersSchema := record.NewSchema()
+	usersSchema.AddIntField("id")
+	users := query.NewTempTable(transaction, usersSchema)
+	scan := users.Open()
+	for i := types.Int(0); i < 10; i++ {
+		scan.Insert()
+		scan.SetInt("id", i)
+	}
+	scan.Close()
+
+	ordersSchema := record.NewSchema()
+	ordersSchema.AddIntField("user_id")
+	orders := query.NewTempTable(transaction, ordersSchema)
+	scan = orders.Open()
+	for _, userID := range []types.Int{1, 3, 3, 5} {
+		scan.Insert()
+		scan.SetInt("user_id", userID)
+	}
+	scan.Close()
+
+	selectIDs := func(t *testing.T, predicate *query.Predicate) []types.Int {
+		selectScan := query.NewSelectScan(users.Open(), predicate)
+		defer selectScan.Close()
+
+		ids := []types.Int{}
+		for selectScan.Next() {
+			id, err := selectScan.GetInt("id")
+			if assert.NoError(t, err) {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	t.Run("相関の無い IN サブクエリは、一度だけ評価されること.", func(t *testing.T) {
+		plan := &subqueryPlanForTest{table: orders}
+		subquery := query.NewSubqueryExpression(subqueryDefinitionForTest("SELECT user_id FROM orders;"))
+		subquery.SetPlan(plan)
+
+		predicate := query.NewPredicateWith(query.NewInTerm(query.NewFieldNameExpression("id"), subquery))
+		assert.Equal(t, []types.Int{1, 3, 5}, selectIDs(t, predicate))
+		assert.Equal(t, 1, plan.opened, "サブクエリの結果が使い回されること.")
+		assert.Equal(t, "id IN (SELECT user_id FROM orders)", predicate.ToString())
+	})
+
+	t.Run("相関サブクエリは、外側のレコードごとに評価されること.", func(t *testing.T) {
+		// `EXISTS (SELECT user_id FROM orders WHERE user_id = id)` を想定.
+		subquery := query.NewSubqueryExpression(subqueryDefinitionForTest("SELECT user_id FROM orders WHERE user_id = id;"))
+		plan := &subqueryPlanForTest{
+			table: orders,
+			predicate: query.NewPredicateWith(query.NewTerm(
+				query.NewFieldNameExpression("user_id"),
+				query.NewOuterFieldExpression("id", subquery),
+			)),
+		}
+		subquery.SetPlan(plan)
+		subquery.MarkCorrelated()
+
+		predicate := query.NewPredicateWith(query.NewExistsTerm(subquery))
+		assert.Equal(t, []types.Int{1, 3, 5}, selectIDs(t, predicate))
+		assert.Equal(t, 10, plan.opened, "外側のレコードの数だけ評価されること.")
+	})
+
+	t.Run("スカラサブクエリは、ちょうど1件のレコードを返す場合だけ値を返すこと.", func(t *testing.T) {
+		// `id = (SELECT user_id FROM orders WHERE user_id = ?)` を想定.
+		newScalarSubquery := func(userID types.Int) *query.SubqueryExpression {
+			subquery := query.NewSubqueryExpression(subqueryDefinitionForTest("SELECT user_id FROM orders;"))
+			subquery.SetPlan(&subqueryPlanForTest{
+				table: orders,
+				predicate: query.NewPredicateWith(query.NewTerm(
+					query.NewFieldNameExpression("user_id"),
+					query.NewIntConstant(userID),
+				)),
+			})
+			return subquery
+		}
+
+		predicate := query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("id"), newScalarSubquery(5)))
+		assert.Equal(t, []types.Int{5}, selectIDs(t, predicate))
+
+		predicate = query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("id"), newScalarSubquery(7)))
+		assert.Equal(t, []types.Int{}, selectIDs(t, predicate), "レコードが無い場合は、条件を満たさないこと.")
+
+		usersScan := users.Open()
+		defer usersScan.Close()
+		_, err := newScalarSubquery(3).Evaluate(usersScan)
+		assert.IsType(t, &query.ScalarSubqueryMultipleRowsError{}, err, "複数のレコードを返す場合はエラーになること.")
+
+		predicate = query.NewPredicateWith(query.NewTerm(query.NewFieldNameExpression("id"), newScalarSubquery(3)))
+		selectScan := query.NewSelectScan(users.Open(), predicate)
+		defer selectScan.Close()
+		assert.False(t, selectScan.Next(), "WHERE 句の評価でエラーになった場合は、それ以上読まないこと.")
+		assert.IsType(t, &query.ScalarSubqueryMultipleRowsError{}, selectScan.Err(), "WHERE 句の評価のエラーは Err で返すこと.")
+		selectScan.BeforeFirst()
+		assert.False(t, selectScan.Next(), "エラーの後は BeforeFirst しても読めないこと.")
+	})
+
+	t.Run("plan が作られていないサブクエリは、エラーになること.", func(t *testing.T) {
+		subquery := query.NewSubqueryExpression(subqueryDefinitionForTest("SELECT user_id FROM orders;"))
+		usersScan := users.Open()
+		defer usersScan.Close()
+		_, err := subquery.Exists(usersScan)
+		assert.IsType(t, &query.SubqueryNotPlannedError{}, err)
+	})
+}
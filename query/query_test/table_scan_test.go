@@ -209,8 +209,8 @@ func TestTableScanInsert(t *testing.T) {
 		assert.Equal(t, record.NewRecordID(types.BlockNumber(0), record.SlotNumber(1)), tableScan.GetCurrentRecordID(), "current record id は recordID1 であるべし.")
 	})
 
-	t.Run("スロットは19個存在するため、Insert 呼び出しの3回目〜19回目の呼び出しまでは、ブロック番号は変わらず、スロット番号だけがインクリメントされる.", func(t *testing.T) {
-		for i := types.Int(2); i < 19; i++ {
+	t.Run("スロットは17個存在するため、Insert 呼び出しの3回目〜17回目の呼び出しまでは、ブロック番号は変わらず、スロット番号だけがインクリメントされる.", func(t *testing.T) {
+		for i := types.Int(2); i < 17; i++ {
 			tableScan.Insert()
 			assert.Equal(t, record.NewRecordID(types.BlockNumber(0), record.SlotNumber(i)), tableScan.GetCurrentRecordID(), "current record id は recordID1 であるべし.")
 		}
@@ -222,8 +222,8 @@ func TestTableScanInsert(t *testing.T) {
 		assert.Equal(t, types.Int(2), transaction.Size(fileName), "既存ブロックへの移動なので、ファイルのブロックサイズは変わっていない.")
 	})
 
-	t.Run("もう一度18回 Insert を実行しても、ブロック番号は変わらない.", func(t *testing.T) {
-		for i := record.SlotNumber(1); i < 19; i++ {
+	t.Run("もう一度16回 Insert を実行しても、ブロック番号は変わらない.", func(t *testing.T) {
+		for i := record.SlotNumber(1); i < 17; i++ {
 			tableScan.Insert()
 			assert.Equal(t, record.NewRecordID(types.BlockNumber(1), i), tableScan.GetCurrentRecordID(), "current record id は recordID1 であるべし.")
 		}
@@ -236,6 +236,35 @@ func TestTableScanInsert(t *testing.T) {
 	})
 }
 
+func TestTableScanSetValue(t *testing.T) {
+	schema := buildTestTableSchema()
+	layout := record.NewLayout(schema)
+
+	transaction := newTransactionForTest(t, tableScanTestName)
+	tableScan := query.NewTableScan(transaction, "test_table_scan_set_value", layout)
+	defer tableScan.Close()
+
+	tableScan.Insert()
+
+	t.Run("フィールドの型の値と NULL は書き込める.", func(t *testing.T) {
+		assert.NoError(t, tableScan.SetValue("id", query.NewIntConstant(1)))
+		assert.NoError(t, tableScan.SetValue("name", query.NewStrConstant("hoge")))
+		assert.NoError(t, tableScan.SetValue("age", query.NewNullConstant()))
+	})
+
+	t.Run("フィールドの型と異なる型の値は、panic せずにエラーになる.", func(t *testing.T) {
+		assert.Error(t, tableScan.SetValue("id", query.NewStrConstant("1")))
+		assert.Error(t, tableScan.SetValue("name", query.NewIntConstant(1)))
+
+		id, _ := tableScan.GetInt("id")
+		assert.Equal(t, types.Int(1), id, "元の値が残っている.")
+	})
+
+	t.Run("フィールドの長さを超える文字列はエラーになる.", func(t *testing.T) {
+		assert.Error(t, tableScan.SetValue("name", query.NewStrConstant("0123456789a")))
+	})
+}
+
 func TestTableScanDelete(t *testing.T) {
 	schema := buildTestTableSchema()
 	layout := record.NewLayout(schema)
@@ -260,6 +289,30 @@ func TestTableScanDelete(t *testing.T) {
 	})
 }
 
+func TestTableScanRecordsDelta(t *testing.T) {
+	schema := buildTestTableSchema()
+	layout := record.NewLayout(schema)
+
+	transaction := newTransactionForTest(t, tableScanTestName)
+	tableScan := query.NewTableScan(transaction, "test_table_scan_records_delta", layout)
+	defer tableScan.Close()
+
+	t.Run("空のファイルを開いた時に追加したブロックも数える.", func(t *testing.T) {
+		assert.Equal(t, types.Int(0), tableScan.GetRecordsDelta())
+		assert.Equal(t, types.Int(1), tableScan.GetAppendedBlocks())
+	})
+
+	t.Run("挿入したレコード数から、削除したレコード数を引いた数になる.", func(t *testing.T) {
+		// 1ブロックに17スロットあるので、20レコード挿入するとブロックが1つ追加される.
+		for i := 0; i < 20; i++ {
+			tableScan.Insert()
+		}
+		tableScan.Delete()
+		assert.Equal(t, types.Int(19), tableScan.GetRecordsDelta())
+		assert.Equal(t, types.Int(2), tableScan.GetAppendedBlocks(), "レコードを削除してもブロック数は減らない.")
+	})
+}
+
 func TestTableScanNext(t *testing.T) {
 	schema := buildTestTableSchema()
 	layout := record.NewLayout(schema)
@@ -0,0 +1,56 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesScan(t *testing.T) {
+	fieldNames := []types.FieldName{"id", "name"}
+	rows := [][]query.Constant{
+		{query.NewIntConstant(1), query.NewStrConstant("hoge")},
+		{query.NewIntConstant(2), query.NewNullConstant()},
+	}
+	valuesScan := query.NewValuesScan(fieldNames, rows)
+
+	t.Run("全てのレコードが順に出力されること.", func(t *testing.T) {
+		assert.True(t, valuesScan.Next())
+		id, err := valuesScan.GetInt("id")
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.Int(1), id)
+		}
+		name, err := valuesScan.GetString("name")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "hoge", name)
+		}
+
+		assert.True(t, valuesScan.Next())
+		value, err := valuesScan.GetValue("name")
+		if assert.NoError(t, err) {
+			assert.True(t, query.IsNull(value))
+		}
+
+		assert.False(t, valuesScan.Next())
+		assert.False(t, valuesScan.Next(), "最後まで読んだ後も false を返し続けること.")
+	})
+
+	t.Run("BeforeFirst で先頭に戻れること.", func(t *testing.T) {
+		valuesScan.BeforeFirst()
+		assert.True(t, valuesScan.Next())
+		id, _ := valuesScan.GetInt("id")
+		assert.Equal(t, types.Int(1), id)
+	})
+
+	t.Run("存在しないフィールドはエラーになること.", func(t *testing.T) {
+		_, err := valuesScan.GetValue("unknown")
+		assert.Error(t, err)
+		assert.False(t, valuesScan.HasField("unknown"))
+	})
+
+	t.Run("レコードが無い場合は何も出力されないこと.", func(t *testing.T) {
+		assert.False(t, query.NewValuesScan(fieldNames, nil).Next())
+	})
+}
@@ -0,0 +1,113 @@
+package query_test
+
+import (
+	"simple-db-go/query"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowScan(t *testing.T) {
+	transaction := newTransactionForTest(t, windowScanTestName)
+	defer transaction.Rollback()
+
+	schema := record.NewSchema()
+	schema.AddIntField("id")
+	schema.AddStringField("dept", 10)
+	schema.AddIntField("salary")
+
+	// WindowScan は PARTITION BY, ORDER BY の順にソート済みの入力を受け取るので、その順に入れておく.
+	tempTable := query.NewTempTable(transaction, schema)
+	tableScan := tempTable.Open()
+	records := []struct {
+		id     types.Int
+		dept   string
+		salary types.Int
+	}{
+		{1, "a", 300},
+		{2, "a", 300},
+		{3, "a", 200},
+		{4, "a", 100},
+		{5, "b", 70},
+		{6, "b", 50},
+	}
+	for _, r := range records {
+		tableScan.Insert()
+		tableScan.SetInt("id", r.id)
+		tableScan.SetString("dept", r.dept)
+		tableScan.SetInt("salary", r.salary)
+	}
+	tableScan.Close()
+
+	window := query.NewWindow([]types.FieldName{"dept"}, []types.FieldName{"salary"}, []bool{true})
+	functions := []query.WindowFunction{
+		query.NewRowNumberFunction(window),
+		query.NewRankFunction(window),
+		query.NewDenseRankFunction(window),
+		query.NewAggregateWindowFunction(query.NewSumFunction("salary", false), window),
+		query.NewLagFunction("id", 1, nil, window),
+		query.NewLeadFunction("id", 1, query.NewIntConstant(-1), window),
+	}
+
+	windowScan := query.NewWindowScan(tempTable.Open(), window, functions)
+	defer windowScan.Close()
+
+	// row_number, rank, dense_rank, 累積の sum, lag, lead の順.
+	expected := [][]types.Int{
+		{1, 1, 1, 600, 0, 2},
+		{2, 1, 1, 600, 1, 3},
+		{3, 3, 2, 800, 2, 4},
+		{4, 4, 3, 900, 3, -1},
+		{1, 1, 1, 70, 0, 6},
+		{2, 2, 2, 120, 5, -1},
+	}
+
+	for range 2 {
+		actual := [][]types.Int{}
+		for windowScan.Next() {
+			values := []types.Int{}
+			for _, fn := range functions {
+				value, err := windowScan.GetInt(fn.GetFieldName())
+				if assert.NoError(t, err) {
+					values = append(values, value)
+				}
+			}
+			actual = append(actual, values)
+		}
+		assert.Equal(t, expected, actual, "パーティションごとに window 関数が計算されること.")
+
+		windowScan.BeforeFirst()
+	}
+
+	t.Run("入力のフィールドもそのまま読めること.", func(t *testing.T) {
+		windowScan.BeforeFirst()
+		if assert.True(t, windowScan.Next()) {
+			dept, err := windowScan.GetString("dept")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "a", dept)
+			}
+		}
+		assert.True(t, windowScan.HasField("salary"))
+		assert.True(t, windowScan.HasField("rank() over (partition by dept order by salary desc)"))
+
+		_, err := windowScan.GetValue("unknown")
+		assert.Error(t, err, "存在しないフィールドはエラーになること.")
+	})
+
+	t.Run("Next を呼ぶ前に値を読んだ場合は、エラーになること.", func(t *testing.T) {
+		windowScan.BeforeFirst()
+		_, err := windowScan.GetInt("id")
+		assert.IsType(t, &query.NoCurrentRecordInWindowScanError{}, err)
+		_, err = windowScan.GetInt(functions[0].GetFieldName())
+		assert.IsType(t, &query.NoCurrentRecordInWindowScanError{}, err)
+	})
+
+	t.Run("LAG, LEAD の offset が負の場合は、エラーになること.", func(t *testing.T) {
+		_, _, err := query.NewLagFunction("id", -1, nil, window).GetFieldType(schema)
+		assert.IsType(t, &query.NegativeWindowOffsetError{}, err)
+		_, _, err = query.NewLeadFunction("id", -1, nil, window).GetFieldType(schema)
+		assert.IsType(t, &query.NegativeWindowOffsetError{}, err)
+	})
+}
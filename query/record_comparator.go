@@ -0,0 +1,46 @@
+package query
+
+import "simple-db-go/types"
+
+// 指定されたフィールドの順に、2つの Scan の current record を比較する.
+// SortScan でのマージや、重複排除に使う.
+type RecordComparator struct {
+	fieldNames []types.FieldName
+	// fieldNames と位置で対応し、true のフィールドは降順で比較する. nil の場合はすべて昇順.
+	descending []bool
+}
+
+func NewRecordComparator(fieldNames []types.FieldName) *RecordComparator {
+	return &RecordComparator{fieldNames: fieldNames}
+}
+
+func NewRecordComparatorWithOrder(fieldNames []types.FieldName, descending []bool) *RecordComparator {
+	return &RecordComparator{fieldNames: fieldNames, descending: descending}
+}
+
+// scan1 の方が小さければ負の値、等しければ 0、大きければ正の値を返す.
+func (rc *RecordComparator) Compare(scan1 Scan, scan2 Scan) (int, error) {
+	for i, fieldName := range rc.fieldNames {
+		value1, err := scan1.GetValue(fieldName)
+		if err != nil {
+			return 0, err
+		}
+
+		value2, err := scan2.GetValue(fieldName)
+		if err != nil {
+			return 0, err
+		}
+
+		if result := value1.CompareTo(value2); result != 0 {
+			if rc.descending != nil && rc.descending[i] {
+				return -result, nil
+			}
+			return result, nil
+		}
+	}
+	return 0, nil
+}
+
+func (rc *RecordComparator) GetFieldNames() []types.FieldName {
+	return rc.fieldNames
+}
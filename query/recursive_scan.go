@@ -0,0 +1,222 @@
+package query
+
+import (
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+)
+
+// WITH RECURSIVE の繰り返し回数の上限. 循環したデータで UNION ALL を使うと終わらないので、ここで打ち切る.
+const MAX_RECURSION_DEPTH = 1000
+
+var _ Scan = (*RecursiveScan)(nil)
+
+// WITH RECURSIVE の再帰部分から参照される、直前の繰り返しで追加されたレコードを保持する一時テーブル.
+// 再帰部分の plan は一度だけ作り、Open するたびにその時点の一時テーブルを読む.
+type WorkingTable struct {
+	transaction *transaction.Transaction
+	schema      *record.Schema
+	table       *TempTable
+}
+
+func NewWorkingTable(transaction *transaction.Transaction, schema *record.Schema) *WorkingTable {
+	return &WorkingTable{transaction: transaction, schema: schema}
+}
+
+func (wt *WorkingTable) Open() Scan {
+	if wt.table == nil {
+		wt.table = NewTempTable(wt.transaction, wt.schema)
+	}
+	return wt.table.Open()
+}
+
+func (wt *WorkingTable) GetSchema() *record.Schema {
+	return wt.schema
+}
+
+// WITH RECURSIVE の結果を読む scan.
+// 最初に anchor(UNION の左側)のレコードを読み、次にその結果を WorkingTable として再帰部分を評価する.
+// 再帰部分で新しく追加されたレコードを次の WorkingTable として、新しいレコードが無くなるまで繰り返す.
+// 各レコードは読んだ時点で出力するので、結果全体を一時テーブルに保持することはしない.
+//
+// distinct の場合(UNION)は、既に出力したレコードをメモリ上に記録して取り除く.
+// 出力するフィールド名は schema のもので、各 plan のフィールドとは位置で対応させる.
+type RecursiveScan struct {
+	transaction    *transaction.Transaction
+	schema         *record.Schema
+	anchorPlan     Plan
+	recursivePlans []Plan
+	workingTable   *WorkingTable
+	distinct       bool
+
+	// 今の繰り返しで読んでいる plan と、その scan.
+	plans     []Plan
+	planIndex int
+	current   Scan
+	// current から読むフィールド. schema のフィールドと位置で対応する.
+	currentFields []types.FieldName
+	values        []Constant
+	// 今の繰り返しで追加されたレコード. 次の繰り返しの WorkingTable になる.
+	nextTable *TempTable
+	nextScan  UpdateScan
+	added     types.Int
+	depth     int
+	seen      map[string]struct{}
+	err       error
+}
+
+func NewRecursiveScan(transaction *transaction.Transaction, schema *record.Schema, anchorPlan Plan, recursivePlans []Plan, workingTable *WorkingTable, distinct bool) *RecursiveScan {
+	recursiveScan := &RecursiveScan{
+		transaction:    transaction,
+		schema:         schema,
+		anchorPlan:     anchorPlan,
+		recursivePlans: recursivePlans,
+		workingTable:   workingTable,
+		distinct:       distinct,
+	}
+	recursiveScan.BeforeFirst()
+	return recursiveScan
+}
+
+func (rs *RecursiveScan) BeforeFirst() {
+	rs.closeCurrent()
+	rs.plans = []Plan{rs.anchorPlan}
+	rs.planIndex = 0
+	rs.values = nil
+	rs.depth = 0
+	rs.seen = make(map[string]struct{})
+	rs.startIteration()
+}
+
+func (rs *RecursiveScan) Next() bool {
+	if rs.err != nil {
+		return false
+	}
+
+	for {
+		if rs.current == nil && !rs.openNextPlan() {
+			return false
+		}
+
+		if !rs.current.Next() {
+			rs.err = rs.current.Err()
+			rs.current.Close()
+			rs.current = nil
+			if rs.err != nil {
+				return false
+			}
+			continue
+		}
+
+		values, err := readValues(rs.current, rs.currentFields)
+		if err != nil {
+			rs.err = err
+			return false
+		}
+
+		if rs.distinct {
+			key := valuesKey(values)
+			if _, exists := rs.seen[key]; exists {
+				continue
+			}
+			rs.seen[key] = struct{}{}
+		}
+
+		rs.nextScan.Insert()
+		for i, fieldName := range rs.schema.Fields() {
+			if err := rs.nextScan.SetValue(fieldName, values[i]); err != nil {
+				rs.err = err
+				return false
+			}
+		}
+		rs.added++
+
+		rs.values = values
+		return true
+	}
+}
+
+func (rs *RecursiveScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := rs.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (rs *RecursiveScan) GetString(fieldName types.FieldName) (string, error) {
+	value, err := rs.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+func (rs *RecursiveScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	for i, f := range rs.schema.Fields() {
+		if f == fieldName && rs.values != nil {
+			return rs.values[i], nil
+		}
+	}
+	return nil, &UnknownFieldInRecursiveScanError{fieldName}
+}
+
+func (rs *RecursiveScan) HasField(fieldName types.FieldName) bool {
+	return rs.schema.HasField(fieldName)
+}
+
+func (rs *RecursiveScan) Close() {
+	rs.closeCurrent()
+}
+
+func (rs *RecursiveScan) GetFields() []types.FieldName {
+	return rs.schema.Fields()
+}
+
+// 各繰り返しの plan の scan で発生したエラーも、Close する前にここに移しておく.
+func (rs *RecursiveScan) Err() error {
+	return rs.err
+}
+
+// 今の繰り返しで読む plan を順に開く. 全て読み終えたら、追加されたレコードを WorkingTable にして次の繰り返しに進む.
+// 繰り返しの回数が上限を超えた場合は、err を設定して false を返す.
+func (rs *RecursiveScan) openNextPlan() bool {
+	if rs.planIndex >= len(rs.plans) {
+		if rs.added == 0 {
+			return false
+		}
+
+		rs.depth++
+		if rs.depth > MAX_RECURSION_DEPTH {
+			rs.err = &RecursionDepthExceededError{MAX_RECURSION_DEPTH}
+			return false
+		}
+
+		rs.nextScan.Close()
+		rs.workingTable.table = rs.nextTable
+		rs.plans = rs.recursivePlans
+		rs.planIndex = 0
+		rs.startIteration()
+	}
+
+	rs.current = rs.plans[rs.planIndex].Open()
+	rs.currentFields = rs.plans[rs.planIndex].GetSchema().Fields()
+	rs.planIndex++
+	return true
+}
+
+func (rs *RecursiveScan) startIteration() {
+	rs.nextTable = NewTempTable(rs.transaction, rs.schema)
+	rs.nextScan = rs.nextTable.Open()
+	rs.added = 0
+}
+
+func (rs *RecursiveScan) closeCurrent() {
+	if rs.current != nil {
+		rs.current.Close()
+		rs.current = nil
+	}
+	if rs.nextScan != nil {
+		rs.nextScan.Close()
+	}
+}
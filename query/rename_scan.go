@@ -0,0 +1,76 @@
+package query
+
+import (
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*RenameScan)(nil)
+
+// `WITH t (a, b) AS (SELECT x, y FROM ...)` のように、フィールドに別名を付けて読むための scan.
+// fieldNames と sourceFieldNames は位置で対応する.
+type RenameScan struct {
+	scan             Scan
+	fieldNames       []types.FieldName
+	sourceFieldNames []types.FieldName
+}
+
+func NewRenameScan(scan Scan, fieldNames []types.FieldName, sourceFieldNames []types.FieldName) *RenameScan {
+	return &RenameScan{scan: scan, fieldNames: fieldNames, sourceFieldNames: sourceFieldNames}
+}
+
+func (rs *RenameScan) BeforeFirst() {
+	rs.scan.BeforeFirst()
+}
+
+func (rs *RenameScan) Next() bool {
+	return rs.scan.Next()
+}
+
+func (rs *RenameScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	sourceFieldName, err := rs.sourceFieldName(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return rs.scan.GetInt(sourceFieldName)
+}
+
+func (rs *RenameScan) GetString(fieldName types.FieldName) (string, error) {
+	sourceFieldName, err := rs.sourceFieldName(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return rs.scan.GetString(sourceFieldName)
+}
+
+func (rs *RenameScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	sourceFieldName, err := rs.sourceFieldName(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	return rs.scan.GetValue(sourceFieldName)
+}
+
+func (rs *RenameScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(rs.fieldNames, fieldName)
+}
+
+func (rs *RenameScan) Close() {
+	rs.scan.Close()
+}
+
+func (rs *RenameScan) GetFields() []types.FieldName {
+	return rs.fieldNames
+}
+
+func (rs *RenameScan) Err() error {
+	return rs.scan.Err()
+}
+
+func (rs *RenameScan) sourceFieldName(fieldName types.FieldName) (types.FieldName, error) {
+	index := slices.Index(rs.fieldNames, fieldName)
+	if index < 0 {
+		return "", &UnknownFieldInRenameScanError{fieldName}
+	}
+	return rs.sourceFieldNames[index], nil
+}
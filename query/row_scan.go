@@ -0,0 +1,71 @@
+package query
+
+import (
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*RowScan)(nil)
+
+// メモリ上の1レコードだけを出力する scan.
+// INSERT, UPDATE で書き込む前のレコードに対して、CHECK 制約などの Predicate を評価するために使う.
+type RowScan struct {
+	fieldNames []types.FieldName
+	values     []Constant
+	// Next が呼ばれて、レコードを指しているかどうか.
+	isCurrent bool
+}
+
+// values は fieldNames と位置で対応する.
+func NewRowScan(fieldNames []types.FieldName, values []Constant) *RowScan {
+	return &RowScan{fieldNames: fieldNames, values: values}
+}
+
+func (rs *RowScan) BeforeFirst() {
+	rs.isCurrent = false
+}
+
+func (rs *RowScan) Next() bool {
+	hasNext := !rs.isCurrent
+	rs.isCurrent = true
+	return hasNext
+}
+
+func (rs *RowScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := rs.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (rs *RowScan) GetString(fieldName types.FieldName) (string, error) {
+	value, err := rs.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+// Next を呼ばずに、レコードを指しているものとして読むこともできる.
+func (rs *RowScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	index := slices.Index(rs.fieldNames, fieldName)
+	if index < 0 {
+		return nil, &UnknownFieldInRowScanError{fieldName}
+	}
+	return rs.values[index], nil
+}
+
+func (rs *RowScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(rs.fieldNames, fieldName)
+}
+
+func (rs *RowScan) Close() {}
+
+func (rs *RowScan) GetFields() []types.FieldName {
+	return rs.fieldNames
+}
+
+func (rs *RowScan) Err() error {
+	return nil
+}
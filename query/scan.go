@@ -10,17 +10,26 @@ type Scan interface {
 	Next() bool
 
 	// 存在しないフィールドを指定されたらエラーを返す.
+	// フィールドが NULL の場合は 0 を返すので、NULL かどうかを区別するには GetValue を使う.
 	GetInt(fieldName types.FieldName) (types.Int, error)
 
 	// 存在しないフィールドを指定されたらエラーを返す.
+	// フィールドが NULL の場合は空文字列を返すので、NULL かどうかを区別するには GetValue を使う.
 	GetString(fieldName types.FieldName) (string, error)
 
 	// 存在しないフィールドを指定されたらエラーを返す.
+	// フィールドが NULL の場合は NullConstant を返す.
 	GetValue(fieldName types.FieldName) (Constant, error)
 
 	HasField(fieldName types.FieldName) bool
 
+	// pin しているバッファーを解放する. Close した後でも、BeforeFirst を呼べば先頭から読み直せる.
 	Close()
 
 	GetFields() []types.FieldName
+
+	// Next が false を返した時に、最後まで読み終えたのではなく、途中でエラーが発生したかどうかを返す.
+	// WHERE 句の評価のように、レコードを読み進める途中で発生するエラーを返すために使う.
+	// エラーが発生した後は、BeforeFirst を呼んでも Next は常に false を返す.
+	Err() error
 }
@@ -12,6 +12,8 @@ var _ UpdateScan = (*SelectScan)(nil)
 type SelectScan struct {
 	scan      Scan
 	predicate *Predicate
+	// predicate の評価で発生したエラー. スカラサブクエリが複数のレコードを返した場合などに発生する.
+	err error
 }
 
 func NewSelectScan(scan Scan, predicate *Predicate) *SelectScan {
@@ -28,6 +30,9 @@ func (s *SelectScan) BeforeFirst() {
 
 // NOTE: 与えられたpredicateが満たされるまでscanを進める.
 func (s *SelectScan) Next() bool {
+	if s.err != nil {
+		return false
+	}
 	for s.scan.Next() {
 		// これは WHERE 句が無い SELECT に相当する. 無条件で true を返す.
 		if s.predicate == nil {
@@ -36,10 +41,8 @@ func (s *SelectScan) Next() bool {
 
 		isSatisfied, err := s.predicate.IsSatisfied(s.scan)
 		if err != nil {
-			// このエラーがあるなら、Next() も (bool, error) を返すように変更するべきかもしれない.
-			// 一旦保留にする。無効な列名を指定した時のエラーをきちんとハンドリングするならエラーを返すべき.
-			// TODO: Scan.Next() の仕様変更
-			panic(fmt.Sprintf("[SelectScan] predicate.IsSatisfied でエラーが発生しました。 predicate=%+v, scan=%+v, error=%+v", s.predicate, s.scan, err))
+			s.err = err
+			return false
 		}
 		if isSatisfied {
 			return true
@@ -72,6 +75,13 @@ func (s *SelectScan) GetFields() []types.FieldName {
 	return s.scan.GetFields()
 }
 
+func (s *SelectScan) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.scan.Err()
+}
+
 // ----------------------------------------
 // Methods of UpdateScan Interface
 // ----------------------------------------
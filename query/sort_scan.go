@@ -0,0 +1,114 @@
+package query
+
+import (
+	"simple-db-go/types"
+)
+
+var _ Scan = (*SortScan)(nil)
+
+// SortPlan によって作られた、ソート済みの run(最大2つ) をマージしながら読み出す Scan.
+// run が 2 つの場合は、最後のマージをここで行うことになる.
+type SortScan struct {
+	scan1       UpdateScan
+	scan2       UpdateScan
+	currentScan UpdateScan
+	comparator  *RecordComparator
+	hasMore1    bool
+	hasMore2    bool
+	err         error
+}
+
+func NewSortScan(runs []*TempTable, comparator *RecordComparator) *SortScan {
+	sortScan := &SortScan{comparator: comparator}
+
+	sortScan.scan1 = runs[0].Open()
+	sortScan.hasMore1 = sortScan.scan1.Next()
+
+	if len(runs) > 1 {
+		sortScan.scan2 = runs[1].Open()
+		sortScan.hasMore2 = sortScan.scan2.Next()
+	}
+
+	return sortScan
+}
+
+func (ss *SortScan) BeforeFirst() {
+	ss.currentScan = nil
+
+	ss.scan1.BeforeFirst()
+	ss.hasMore1 = ss.scan1.Next()
+
+	if ss.scan2 != nil {
+		ss.scan2.BeforeFirst()
+		ss.hasMore2 = ss.scan2.Next()
+	}
+}
+
+// 2つの run のうち、current record が小さい方を current scan として選ぶ.
+func (ss *SortScan) Next() bool {
+	if ss.err != nil {
+		return false
+	}
+	if ss.currentScan != nil {
+		if ss.currentScan == ss.scan1 {
+			ss.hasMore1 = ss.scan1.Next()
+		} else if ss.currentScan == ss.scan2 {
+			ss.hasMore2 = ss.scan2.Next()
+		}
+	}
+
+	if !ss.hasMore1 && !ss.hasMore2 {
+		return false
+	}
+
+	if ss.hasMore1 && ss.hasMore2 {
+		result, err := ss.comparator.Compare(ss.scan1, ss.scan2)
+		if err != nil {
+			ss.err = err
+			return false
+		}
+		if result < 0 {
+			ss.currentScan = ss.scan1
+		} else {
+			ss.currentScan = ss.scan2
+		}
+	} else if ss.hasMore1 {
+		ss.currentScan = ss.scan1
+	} else {
+		ss.currentScan = ss.scan2
+	}
+
+	return true
+}
+
+func (ss *SortScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	return ss.currentScan.GetInt(fieldName)
+}
+
+func (ss *SortScan) GetString(fieldName types.FieldName) (string, error) {
+	return ss.currentScan.GetString(fieldName)
+}
+
+func (ss *SortScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	return ss.currentScan.GetValue(fieldName)
+}
+
+func (ss *SortScan) HasField(fieldName types.FieldName) bool {
+	return ss.scan1.HasField(fieldName)
+}
+
+func (ss *SortScan) Close() {
+	ss.scan1.Close()
+	if ss.scan2 != nil {
+		ss.scan2.Close()
+	}
+}
+
+func (ss *SortScan) GetFields() []types.FieldName {
+	return ss.scan1.GetFields()
+}
+
+// run は一時テーブルなので、エラーになるのはレコードの比較だけ.
+func (ss *SortScan) Err() error {
+	return ss.err
+}
@@ -0,0 +1,209 @@
+package query
+
+import (
+	"simple-db-go/record"
+	"strings"
+)
+
+var _ Expression = (*SubqueryExpression)(nil)
+
+// サブクエリの SELECT 文. query パッケージからは parsing/data を参照できないので、interface として持つ.
+type SubqueryDefinition interface {
+	ToString() string
+}
+
+// WHERE 句に含まれるサブクエリ `(SELECT ...)` を表す Expression.
+// パースした時点では plan が作れないので、planner が後から SetPlan で設定する.
+//
+// 外側のクエリのフィールドを参照しない(相関の無い)サブクエリは、最初に評価した時に結果を保持して使い回す.
+// 相関サブクエリは、外側のクエリのレコードごとに評価し直す.
+type SubqueryExpression struct {
+	definition SubqueryDefinition
+	plan       Plan
+	correlated bool
+
+	// 評価中の外側のクエリの scan. 相関サブクエリの中の OuterFieldExpression から参照される.
+	outerScan Scan
+
+	// 相関の無いサブクエリの評価結果.
+	materialized bool
+	values       []Constant
+	valueSet     map[Constant]struct{}
+	exists       *bool
+}
+
+func NewSubqueryExpression(definition SubqueryDefinition) *SubqueryExpression {
+	return &SubqueryExpression{definition: definition}
+}
+
+func (e *SubqueryExpression) GetDefinition() SubqueryDefinition {
+	return e.definition
+}
+
+func (e *SubqueryExpression) GetPlan() Plan {
+	return e.plan
+}
+
+func (e *SubqueryExpression) SetPlan(plan Plan) {
+	e.plan = plan
+}
+
+func (e *SubqueryExpression) IsCorrelated() bool {
+	return e.correlated
+}
+
+// サブクエリの中(さらに内側のサブクエリを含む)で、外側のクエリのフィールドが参照されている場合に planner が呼び出す.
+func (e *SubqueryExpression) MarkCorrelated() {
+	e.correlated = true
+}
+
+// スカラサブクエリとして評価する. サブクエリは最大で1件のレコードを返す必要がある.
+// レコードが無い場合は NULL になる.
+func (e *SubqueryExpression) Evaluate(scan Scan) (Constant, error) {
+	var values []Constant
+	if e.correlated {
+		// 2件目が見つかった時点でエラーにできるので、それ以上は読まない.
+		err := e.run(scan, func(value Constant) bool {
+			values = append(values, value)
+			return len(values) < 2
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := e.materialize(scan); err != nil {
+			return nil, err
+		}
+		values = e.values
+	}
+
+	switch len(values) {
+	case 0:
+		return NewNullConstant(), nil
+	case 1:
+		return values[0], nil
+	default:
+		return nil, &ScalarSubqueryMultipleRowsError{e.ToString()}
+	}
+}
+
+// `value IN (SELECT ...)` として3値論理で評価する.
+// 一致する値が無い場合でも、value かサブクエリの結果に NULL があれば UNKNOWN になる.
+// ただし、サブクエリの結果が0件の場合は value が NULL でも FALSE になる.
+func (e *SubqueryExpression) Contains(scan Scan, value Constant) (TruthValue, error) {
+	if !e.correlated {
+		if err := e.materialize(scan); err != nil {
+			return TRUTH_FALSE, err
+		}
+		if len(e.values) == 0 {
+			return TRUTH_FALSE, nil
+		}
+		if IsNull(value) {
+			return TRUTH_UNKNOWN, nil
+		}
+		if _, found := e.valueSet[value]; found {
+			return TRUTH_TRUE, nil
+		}
+		if _, hasNull := e.valueSet[NewNullConstant()]; hasNull {
+			return TRUTH_UNKNOWN, nil
+		}
+		return TRUTH_FALSE, nil
+	}
+
+	result := TRUTH_FALSE
+	err := e.run(scan, func(v Constant) bool {
+		switch {
+		case IsNull(value) || IsNull(v):
+			result = TRUTH_UNKNOWN
+		case v == value:
+			result = TRUTH_TRUE
+		}
+		return result != TRUTH_TRUE
+	})
+	return result, err
+}
+
+// `EXISTS (SELECT ...)` として評価する. 1件目のレコードが見つかった時点で読むのをやめる.
+func (e *SubqueryExpression) Exists(scan Scan) (bool, error) {
+	if !e.correlated && e.exists != nil {
+		return *e.exists, nil
+	}
+
+	if e.plan == nil {
+		return false, &SubqueryNotPlannedError{e.ToString()}
+	}
+
+	e.outerScan = scan
+	subqueryScan := e.plan.Open()
+	defer subqueryScan.Close()
+	exists := subqueryScan.Next()
+	if err := subqueryScan.Err(); err != nil {
+		return false, err
+	}
+
+	if !e.correlated {
+		e.exists = &exists
+	}
+	return exists, nil
+}
+
+// 外側のクエリのフィールドは参照しないので、どの schema に対しても適用できる.
+// 相関サブクエリの場合も、外側のフィールドは OuterFieldExpression に置き換えられている.
+func (e *SubqueryExpression) AppliesTo(schema *record.Schema) bool {
+	return true
+}
+
+func (e *SubqueryExpression) ToString() string {
+	return "(" + strings.TrimSuffix(e.definition.ToString(), ";") + ")"
+}
+
+// 相関の無いサブクエリを一度だけ実行し、結果を保持する.
+func (e *SubqueryExpression) materialize(scan Scan) error {
+	if e.materialized {
+		return nil
+	}
+
+	values := make([]Constant, 0)
+	valueSet := make(map[Constant]struct{})
+	err := e.run(scan, func(value Constant) bool {
+		values = append(values, value)
+		valueSet[value] = struct{}{}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	e.values = values
+	e.valueSet = valueSet
+	e.materialized = true
+	return nil
+}
+
+// サブクエリを実行し、各レコードの値を順に fn に渡す. fn が false を返したらそこで読むのをやめる.
+// scan は外側のクエリの scan で、相関サブクエリの中から参照できるように保持しておく.
+func (e *SubqueryExpression) run(scan Scan, fn func(value Constant) bool) error {
+	if e.plan == nil {
+		return &SubqueryNotPlannedError{e.ToString()}
+	}
+
+	fields := e.plan.GetSchema().Fields()
+	if len(fields) != 1 {
+		return &SubqueryFieldCountError{e.ToString(), len(fields)}
+	}
+
+	e.outerScan = scan
+	subqueryScan := e.plan.Open()
+	defer subqueryScan.Close()
+
+	for subqueryScan.Next() {
+		value, err := subqueryScan.GetValue(fields[0])
+		if err != nil {
+			return err
+		}
+		if !fn(value) {
+			return nil
+		}
+	}
+	return subqueryScan.Err()
+}
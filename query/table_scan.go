@@ -19,13 +19,23 @@ type TableScan struct {
 	recordPage        *record.RecordPage
 	fileName          string
 	currentSlotNumber record.SlotNumber
+
+	// この TableScan で挿入・削除したレコード数と、ファイルに追加したブロック数. 統計情報の更新に使う.
+	insertedRecords types.Int
+	deletedRecords  types.Int
+	appendedBlocks  types.Int
+}
+
+// テーブルのレコードを保存するファイル名.
+func TableFileName(tableName types.TableName) string {
+	return string(tableName) + ".table"
 }
 
 func NewTableScan(transaction *transaction.Transaction, tableName types.TableName, layout *record.Layout) *TableScan {
 	tableScan := &TableScan{
 		transaction:       transaction,
 		layout:            layout,
-		fileName:          string(tableName) + ".table",
+		fileName:          TableFileName(tableName),
 		currentSlotNumber: record.NULL_SLOT_NUMBER,
 	}
 
@@ -39,10 +49,13 @@ func NewTableScan(transaction *transaction.Transaction, tableName types.TableNam
 }
 
 // RecordPage に読み込んでいたブロックIDを解放（Unpin）する.
+// 複数回呼ばれても二重に Unpin しないように、RecordPage も手放す.
+// Close した後でも、BeforeFirst を呼べば先頭から読み直せる.
 func (ts *TableScan) Close() {
 	if ts.recordPage != nil {
 		blockID := ts.recordPage.GetBlockID()
 		ts.transaction.Unpin(blockID)
+		ts.recordPage = nil
 	}
 }
 
@@ -96,13 +109,23 @@ func (ts *TableScan) Insert() {
 		}
 		ts.currentSlotNumber = ts.recordPage.FindEmptySlotAfter(ts.currentSlotNumber)
 	}
+	ts.insertedRecords++
 }
 
+// NULL のフィールドには以前の値が残っていることがあるので、NULL かどうかを先に確認する.
 func (ts *TableScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	isNull, err := ts.recordPage.IsNull(ts.currentSlotNumber, fieldName)
+	if err != nil || isNull {
+		return 0, err
+	}
 	return ts.recordPage.GetInt(ts.currentSlotNumber, fieldName)
 }
 
 func (ts *TableScan) GetString(fieldName types.FieldName) (string, error) {
+	isNull, err := ts.recordPage.IsNull(ts.currentSlotNumber, fieldName)
+	if err != nil || isNull {
+		return "", err
+	}
 	return ts.recordPage.GetString(ts.currentSlotNumber, fieldName)
 }
 
@@ -113,6 +136,14 @@ func (ts *TableScan) GetValue(fieldName types.FieldName) (Constant, error) {
 		return nil, err
 	}
 
+	isNull, err := ts.recordPage.IsNull(ts.currentSlotNumber, fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if isNull {
+		return NewNullConstant(), nil
+	}
+
 	if fieldType == constants.INTEGER {
 		value, err := ts.GetInt(fieldName)
 		if err != nil {
@@ -145,19 +176,27 @@ func (ts *TableScan) SetValue(fieldName types.FieldName, value Constant) error {
 		return err
 	}
 
+	if IsNull(value) {
+		return ts.SetNull(fieldName)
+	}
+
 	if fieldType == constants.INTEGER {
-		err := ts.SetInt(fieldName, value.GetValue().(types.Int))
-		if err != nil {
-			return err
-		}
-	} else {
-		err := ts.SetString(fieldName, value.GetValue().(string))
-		if err != nil {
-			return err
+		intValue, ok := value.GetValue().(types.Int)
+		if !ok {
+			return &FieldValueTypeMismatchError{fieldName, value}
 		}
+		return ts.SetInt(fieldName, intValue)
 	}
 
-	return nil
+	stringValue, ok := value.GetValue().(string)
+	if !ok {
+		return &FieldValueTypeMismatchError{fieldName, value}
+	}
+	return ts.SetString(fieldName, stringValue)
+}
+
+func (ts *TableScan) SetNull(fieldName types.FieldName) error {
+	return ts.recordPage.SetNull(ts.currentSlotNumber, fieldName)
 }
 
 // 現在の RecordID を返す.
@@ -170,13 +209,41 @@ func (ts *TableScan) GetCurrentRecordID() record.RecordID {
 func (ts *TableScan) Delete() {
 	if ts.currentSlotNumber != record.NULL_SLOT_NUMBER {
 		ts.recordPage.Delete(ts.currentSlotNumber)
+		ts.deletedRecords++
 	}
 }
 
+// テーブルの全てのブロックを空にして、全てのレコードを削除する. ブロックの数は変わらない.
+// 削除はログに残るので、ロールバックすると元のレコードに戻る.
+// 空にしたブロックはどの layout でも空のスロットとして読めるので、ALTER TABLE で新しい layout のレコードを書き直す前に使う.
+func (ts *TableScan) Clear() {
+	size := ts.transaction.Size(ts.fileName)
+	for blockNumber := types.BlockNumber(0); blockNumber < types.BlockNumber(size); blockNumber++ {
+		ts.moveToBlock(blockNumber)
+		ts.recordPage.Clear()
+	}
+	ts.moveToBlock(0)
+}
+
+// この TableScan で挿入したレコード数から、削除したレコード数を引いた数を返す.
+// NOTE: Clear で削除したレコードは数えない.
+func (ts *TableScan) GetRecordsDelta() types.Int {
+	return ts.insertedRecords - ts.deletedRecords
+}
+
+// この TableScan でファイルに追加したブロック数を返す. 空のファイルを開いた時に追加したブロックも含む.
+func (ts *TableScan) GetAppendedBlocks() types.Int {
+	return ts.appendedBlocks
+}
+
 func (ts *TableScan) GetFields() []types.FieldName {
 	return ts.layout.GetSchema().Fields()
 }
 
+func (ts *TableScan) Err() error {
+	return nil
+}
+
 // 指定したブロック番号に移動する.
 // current_slot_number は`-1`にリセットする. そのブロックの最初のレコードの直前、ということになる.
 func (ts *TableScan) moveToBlock(blockNumber types.BlockNumber) {
@@ -201,6 +268,7 @@ func (ts *TableScan) moveToNextBlock() {
 func (ts *TableScan) moveToNewBlock() {
 	ts.Close()
 	blockID := ts.transaction.Append(ts.fileName)
+	ts.appendedBlocks++
 
 	// おそらく書籍では pin が漏れていると思われる.
 	// TableScan のクライアントからはもう Block, Buffer などは完全に隠蔽したいのでここで実施する.
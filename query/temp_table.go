@@ -0,0 +1,52 @@
+package query
+
+import (
+	"fmt"
+	"simple-db-go/record"
+	"simple-db-go/transaction"
+	"simple-db-go/types"
+	"sync"
+)
+
+var (
+	tempTableCounter types.Int
+	tempTableMu      sync.Mutex
+)
+
+// ソートや materialize の途中結果を保持するための一時テーブル.
+// カタログには登録しないので、Layout は自身で保持する.
+// ファイル名は "temp" から始まるので、FileManager の起動時に削除される.
+type TempTable struct {
+	transaction *transaction.Transaction
+	tableName   types.TableName
+	layout      *record.Layout
+}
+
+func NewTempTable(transaction *transaction.Transaction, schema *record.Schema) *TempTable {
+	return &TempTable{
+		transaction: transaction,
+		tableName:   nextTempTableName(),
+		layout:      record.NewLayout(schema),
+	}
+}
+
+// 一時テーブルは TableScan で読み書きする.
+func (tt *TempTable) Open() UpdateScan {
+	return NewTableScan(tt.transaction, tt.tableName, tt.layout)
+}
+
+func (tt *TempTable) GetTableName() types.TableName {
+	return tt.tableName
+}
+
+func (tt *TempTable) GetLayout() *record.Layout {
+	return tt.layout
+}
+
+func nextTempTableName() types.TableName {
+	tempTableMu.Lock()
+	defer tempTableMu.Unlock()
+
+	tempTableCounter++
+	return types.TableName(fmt.Sprintf("temp%d", tempTableCounter))
+}
@@ -2,51 +2,166 @@ package query
 
 import (
 	"fmt"
+	"math"
 	"simple-db-go/constants"
 	"simple-db-go/record"
 	"simple-db-go/types"
 )
 
-// SimpleDB での Term は、2つの Expression の等価比較(`=`)のみをサポートする.
+type termOperator int
+
+const (
+	// `lhs = rhs`
+	termEqual termOperator = iota
+	// `lhs IN (SELECT ...)`
+	termIn
+	// `EXISTS (SELECT ...)`. lhs は使わない.
+	termExists
+	// `lhs IS NULL`. rhs は使わない.
+	termIsNull
+	// `lhs IS NOT NULL`. rhs は使わない.
+	termIsNotNull
+	// `lhs < rhs`
+	termLessThan
+	// `lhs <= rhs`
+	termLessEqual
+	// `lhs > rhs`
+	termGreaterThan
+	// `lhs >= rhs`
+	termGreaterEqual
+)
+
+// 比較演算子. SQL での表記をそのまま値にしている.
+type ComparisonOperator string
+
+const (
+	EQUAL         ComparisonOperator = "="
+	LESS_THAN     ComparisonOperator = "<"
+	LESS_EQUAL    ComparisonOperator = "<="
+	GREATER_THAN  ComparisonOperator = ">"
+	GREATER_EQUAL ComparisonOperator = ">="
+)
+
+var comparisonTermOperators = map[ComparisonOperator]termOperator{
+	EQUAL:         termEqual,
+	LESS_THAN:     termLessThan,
+	LESS_EQUAL:    termLessEqual,
+	GREATER_THAN:  termGreaterThan,
+	GREATER_EQUAL: termGreaterEqual,
+}
+
+// 範囲の比較で、範囲の推定に統計情報が使えない場合の絞り込み率.
+// 書籍などでよく使われる、1/3 のレコードが条件を満たすという推定を使う.
+const defaultRangeReductionFactor types.Int = 3
+
+// SimpleDB での Term は、2つの Expression の比較(`=`, `<`, `<=`, `>`, `>=`)を基本とする.
+// サブクエリを使った `IN`, `EXISTS` と、`IS [NOT] NULL` もサポートする.
 type Term struct {
-	lhs Expression
-	rhs Expression
+	operator termOperator
+	lhs      Expression
+	rhs      Expression
 }
 
 func NewTerm(lhs Expression, rhs Expression) *Term {
-	return &Term{lhs: lhs, rhs: rhs}
+	return &Term{operator: termEqual, lhs: lhs, rhs: rhs}
 }
 
-func (t *Term) IsSatisfied(scan Scan) (bool, error) {
+func NewComparisonTerm(operator ComparisonOperator, lhs Expression, rhs Expression) *Term {
+	return &Term{operator: comparisonTermOperators[operator], lhs: lhs, rhs: rhs}
+}
+
+func NewInTerm(lhs Expression, subquery *SubqueryExpression) *Term {
+	return &Term{operator: termIn, lhs: lhs, rhs: subquery}
+}
+
+func NewExistsTerm(subquery *SubqueryExpression) *Term {
+	return &Term{operator: termExists, rhs: subquery}
+}
+
+func NewIsNullTerm(lhs Expression) *Term {
+	return &Term{operator: termIsNull, lhs: lhs}
+}
+
+func NewIsNotNullTerm(lhs Expression) *Term {
+	return &Term{operator: termIsNotNull, lhs: lhs}
+}
+
+// scan の current record に対して、3値論理で評価する.
+func (t *Term) Evaluate(scan Scan) (TruthValue, error) {
+	if t.operator == termExists {
+		exists, err := t.rhs.(*SubqueryExpression).Exists(scan)
+		return truthValueOf(exists), err
+	}
+
 	lhsValue, err := t.lhs.Evaluate(scan)
 	if err != nil {
-		return false, err
+		return TRUTH_FALSE, err
+	}
+
+	switch t.operator {
+	case termIsNull:
+		return truthValueOf(IsNull(lhsValue)), nil
+	case termIsNotNull:
+		return truthValueOf(!IsNull(lhsValue)), nil
+	case termIn:
+		return t.rhs.(*SubqueryExpression).Contains(scan, lhsValue)
 	}
 
 	rhsValue, err := t.rhs.Evaluate(scan)
 	if err != nil {
-		return false, err
+		return TRUTH_FALSE, err
+	}
+
+	if IsNull(lhsValue) || IsNull(rhsValue) {
+		return TRUTH_UNKNOWN, nil
 	}
 
-	return lhsValue == rhsValue, nil
+	switch t.operator {
+	case termLessThan:
+		return truthValueOf(lhsValue.CompareTo(rhsValue) < 0), nil
+	case termLessEqual:
+		return truthValueOf(lhsValue.CompareTo(rhsValue) <= 0), nil
+	case termGreaterThan:
+		return truthValueOf(lhsValue.CompareTo(rhsValue) > 0), nil
+	case termGreaterEqual:
+		return truthValueOf(lhsValue.CompareTo(rhsValue) >= 0), nil
+	default:
+		return truthValueOf(lhsValue == rhsValue), nil
+	}
+}
+
+// WHERE 句では、評価結果が TRUE のレコードだけが条件を満たす.
+func (t *Term) IsSatisfied(scan Scan) (bool, error) {
+	result, err := t.Evaluate(scan)
+	return result == TRUTH_TRUE, err
 }
 
 func (t *Term) AppliesTo(schema *record.Schema) bool {
-	return t.lhs.AppliesTo(schema) && t.rhs.AppliesTo(schema)
+	switch t.operator {
+	case termExists:
+		return t.rhs.AppliesTo(schema)
+	case termIsNull, termIsNotNull:
+		return t.lhs.AppliesTo(schema)
+	default:
+		return t.lhs.AppliesTo(schema) && t.rhs.AppliesTo(schema)
+	}
 }
 
 // Term が `someFiled = 'hoge'`のような、フィールドを定数値で比較する形式になっているか判断する.
 // planning でコストを計算する際に、ある列の異なる値の数を推定する際に用いる.
+// `someField = NULL` はどのレコードにも一致しないので、定数値との比較として扱わない.
 func (t *Term) EquatesWithConstant(fieldName types.FieldName) (Constant, error) {
-	if lhs, ok := t.lhs.(FieldNameExpression); ok && lhs.fieldName == fieldName {
-		if rhs, ok := t.rhs.(Constant); ok {
-			return rhs, nil
+	if t.operator == termEqual {
+		if lhs, ok := t.lhs.(FieldNameExpression); ok && lhs.fieldName == fieldName {
+			if rhs, ok := t.rhs.(Constant); ok && !IsNull(rhs) {
+				return rhs, nil
+			}
 		}
-	}
 
-	if rhs, ok := t.rhs.(FieldNameExpression); ok && rhs.fieldName == fieldName {
-		if lhs, ok := t.lhs.(Constant); ok {
-			return lhs, nil
+		if rhs, ok := t.rhs.(FieldNameExpression); ok && rhs.fieldName == fieldName {
+			if lhs, ok := t.lhs.(Constant); ok && !IsNull(lhs) {
+				return lhs, nil
+			}
 		}
 	}
 
@@ -57,6 +172,10 @@ func (t *Term) EquatesWithConstant(fieldName types.FieldName) (Constant, error)
 // いつインデックスを使うべきかを判断するために使う.
 // 詳細は Chapter15 で.
 func (t *Term) EquatesWithFieldName(fieldName types.FieldName) (types.FieldName, error) {
+	if t.operator != termEqual {
+		return "", &TermCannnotEquatesWithFieldNameError{t.lhs, t.rhs}
+	}
+
 	if lhs, ok := t.lhs.(FieldNameExpression); ok && lhs.fieldName == fieldName {
 		if rhs, ok := t.rhs.(FieldNameExpression); ok {
 			return rhs.fieldName, nil
@@ -73,10 +192,89 @@ func (t *Term) EquatesWithFieldName(fieldName types.FieldName) (types.FieldName,
 }
 
 func (t *Term) ToString() string {
-	return t.lhs.ToString() + " = " + t.rhs.ToString()
+	switch t.operator {
+	case termIn:
+		return t.lhs.ToString() + " IN " + t.rhs.ToString()
+	case termExists:
+		return "EXISTS " + t.rhs.ToString()
+	case termIsNull:
+		return t.lhs.ToString() + " IS NULL"
+	case termIsNotNull:
+		return t.lhs.ToString() + " IS NOT NULL"
+	default:
+		return t.lhs.ToString() + " " + string(t.comparisonOperator()) + " " + t.rhs.ToString()
+	}
 }
 
+func (t *Term) comparisonOperator() ComparisonOperator {
+	for operator, termOperator := range comparisonTermOperators {
+		if termOperator == t.operator {
+			return operator
+		}
+	}
+	panic(fmt.Sprintf("Unexpected term operator: %d", t.operator))
+}
+
+// 相関サブクエリの中で、外側のクエリのフィールドを参照している Expression を置き換えるために使う.
+func (t *Term) replaceFieldNames(replace func(FieldNameExpression) Expression) {
+	if lhs, ok := t.lhs.(FieldNameExpression); ok {
+		t.lhs = replace(lhs)
+	}
+	if rhs, ok := t.rhs.(FieldNameExpression); ok {
+		t.rhs = replace(rhs)
+	}
+}
+
+// EXISTS 以外で使うサブクエリは、比較する値としてフィールドを1つだけ返す必要がある.
+func (t *Term) checkSubquery() error {
+	subquery, ok := t.rhs.(*SubqueryExpression)
+	if !ok || t.operator == termExists {
+		return nil
+	}
+
+	fieldCount := len(subquery.GetPlan().GetSchema().Fields())
+	if fieldCount != 1 {
+		return &SubqueryFieldCountError{subquery.ToString(), fieldCount}
+	}
+	return nil
+}
+
+// 列の統計情報から値の分布が分かる場合は、それを使って条件を満たすレコードの割合を推定する.
+// 分からない場合は、列の異なる値の数から推定する.
 func (t *Term) GetReductionFactor(plan Plan) types.Int {
+	switch t.operator {
+	case termExists:
+		// NOTE: サブクエリの結果次第なので推定できない. フィルタリングしないものとして扱う.
+		return 1
+	case termIsNull, termIsNotNull:
+		lhs, ok := t.lhs.(FieldNameExpression)
+		if !ok {
+			return 1
+		}
+		if distribution, ok := ColumnDistributionOf(plan, lhs.GetFieldName()); ok {
+			if t.operator == termIsNotNull {
+				return reductionFactorOf(1 - distribution.NullFraction())
+			}
+			return reductionFactorOf(distribution.NullFraction())
+		}
+		if t.operator == termIsNotNull {
+			// NOTE: NULL の数が分からないので、フィルタリングしないものとして扱う.
+			return 1
+		}
+		// NOTE: NULL を値の1つとみなして、`= 定数` と同じように推定する.
+		return plan.GetDistinctValues(lhs.GetFieldName())
+	case termIn:
+		// NOTE: サブクエリが返す値の数だけ、lhs の値が一致すると推定する.
+		lhs, ok := t.lhs.(FieldNameExpression)
+		subquery := t.rhs.(*SubqueryExpression)
+		if !ok || subquery.GetPlan() == nil {
+			return 1
+		}
+		return max(1, plan.GetDistinctValues(lhs.GetFieldName())/max(1, subquery.GetPlan().GetRecordsOutput()))
+	case termLessThan, termLessEqual, termGreaterThan, termGreaterEqual:
+		return t.getRangeReductionFactor(plan)
+	}
+
 	switch lhs := t.lhs.(type) {
 	case FieldNameExpression:
 		{
@@ -90,6 +288,11 @@ func (t *Term) GetReductionFactor(plan Plan) types.Int {
 				}
 			case Constant:
 				{
+					return getEqualReductionFactor(plan, lhs.GetFieldName(), rhs)
+				}
+			case OuterFieldExpression, *SubqueryExpression:
+				{
+					// NOTE: 外側のクエリのフィールドやスカラサブクエリも、評価する時点では1つの値なので定数と同様に扱う.
 					return plan.GetDistinctValues(lhs.GetFieldName())
 				}
 			default:
@@ -101,11 +304,11 @@ func (t *Term) GetReductionFactor(plan Plan) types.Int {
 			switch rhs := t.rhs.(type) {
 			case FieldNameExpression:
 				{
-					return plan.GetDistinctValues(rhs.GetFieldName())
+					return getEqualReductionFactor(plan, rhs.GetFieldName(), lhs)
 				}
 			case Constant:
 				{
-					if lhs == rhs {
+					if lhs == rhs && !IsNull(lhs) {
 						// NOTE: 定数として一致した場合、この term は何もレコードをフィルタリングしない.
 						return 1
 					} else {
@@ -117,7 +320,79 @@ func (t *Term) GetReductionFactor(plan Plan) types.Int {
 				panic(fmt.Sprintf("Unexpected type: %T", rhs))
 			}
 		}
+	case OuterFieldExpression:
+		{
+			if rhs, ok := t.rhs.(FieldNameExpression); ok {
+				return plan.GetDistinctValues(rhs.GetFieldName())
+			}
+			return 1
+		}
 	default:
 		panic(fmt.Sprintf("Unexpected type: %T", lhs))
 	}
 }
+
+// `フィールド = 定数` の絞り込み率. 値の分布が分かれば、定数ごとの偏りも考慮できる.
+func getEqualReductionFactor(plan Plan, fieldName types.FieldName, value Constant) types.Int {
+	if distribution, ok := ColumnDistributionOf(plan, fieldName); ok && !IsNull(value) {
+		return reductionFactorOf(distribution.EqualFraction(value))
+	}
+	return plan.GetDistinctValues(fieldName)
+}
+
+// `フィールド < 定数` のような、フィールドと定数の範囲の比較だけ値の分布から推定する.
+// それ以外の範囲の比較は、defaultRangeReductionFactor を使う.
+func (t *Term) getRangeReductionFactor(plan Plan) types.Int {
+	fieldName, value, operator, ok := t.comparesFieldWithConstant()
+	if !ok {
+		return defaultRangeReductionFactor
+	}
+	distribution, ok := ColumnDistributionOf(plan, fieldName)
+	if !ok {
+		return defaultRangeReductionFactor
+	}
+
+	switch operator {
+	case termLessThan:
+		return reductionFactorOf(distribution.LessThanFraction(value, false))
+	case termLessEqual:
+		return reductionFactorOf(distribution.LessThanFraction(value, true))
+	case termGreaterThan:
+		return reductionFactorOf(1 - distribution.NullFraction() - distribution.LessThanFraction(value, true))
+	default:
+		return reductionFactorOf(1 - distribution.NullFraction() - distribution.LessThanFraction(value, false))
+	}
+}
+
+// `定数 < フィールド` は、`フィールド > 定数` に向きを揃えて返す.
+// NULL との比較はどのレコードにも一致しないので、定数との比較として扱わない.
+func (t *Term) comparesFieldWithConstant() (types.FieldName, Constant, termOperator, bool) {
+	if lhs, ok := t.lhs.(FieldNameExpression); ok {
+		if rhs, ok := t.rhs.(Constant); ok && !IsNull(rhs) {
+			return lhs.GetFieldName(), rhs, t.operator, true
+		}
+	}
+
+	if rhs, ok := t.rhs.(FieldNameExpression); ok {
+		if lhs, ok := t.lhs.(Constant); ok && !IsNull(lhs) {
+			reversed := map[termOperator]termOperator{
+				termLessThan:     termGreaterThan,
+				termLessEqual:    termGreaterEqual,
+				termGreaterThan:  termLessThan,
+				termGreaterEqual: termLessEqual,
+			}
+			return rhs.GetFieldName(), lhs, reversed[t.operator], true
+		}
+	}
+
+	return "", nil, t.operator, false
+}
+
+// 条件を満たすレコードの割合から絞り込み率を求める.
+// 割合が 0 の場合でも、types.Int に収まる値にする.
+func reductionFactorOf(fraction float64) types.Int {
+	if fraction <= 1/float64(constants.MAX_INT_VALUE) {
+		return constants.MAX_INT_VALUE
+	}
+	return max(1, types.Int(math.Round(1/fraction)))
+}
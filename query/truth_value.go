@@ -0,0 +1,24 @@
+package query
+
+// NULL を含む比較の結果を表すための、3値論理の真偽値.
+// NULL との比較は TRUE でも FALSE でもなく UNKNOWN になる.
+type TruthValue int
+
+// 論理積がそれぞれの最小値になるように並べておく.
+const (
+	TRUTH_FALSE TruthValue = iota
+	TRUTH_UNKNOWN
+	TRUTH_TRUE
+)
+
+func truthValueOf(b bool) TruthValue {
+	if b {
+		return TRUTH_TRUE
+	}
+	return TRUTH_FALSE
+}
+
+// 論理積(`AND`). FALSE があれば FALSE、そうでなく UNKNOWN があれば UNKNOWN になる.
+func (v TruthValue) And(other TruthValue) TruthValue {
+	return min(v, other)
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"errors"
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*UnionScan)(nil)
+
+// UNION, INTERSECT, EXCEPT の種類.
+type SetOperator string
+
+const (
+	UNION     SetOperator = "UNION"
+	INTERSECT SetOperator = "INTERSECT"
+	EXCEPT    SetOperator = "EXCEPT"
+)
+
+// UNION ALL に相当する. scan1 のレコードを全て読んだ後、続けて scan2 のレコードを読む.
+// 2つの scan はフィールド名が異なっていても良く、位置で対応させる. 出力するフィールド名は scan1 のものを使う.
+type UnionScan struct {
+	scan1       Scan
+	scan2       Scan
+	fieldNames1 []types.FieldName
+	fieldNames2 []types.FieldName
+	isOnSecond  bool
+}
+
+func NewUnionScan(scan1 Scan, fieldNames1 []types.FieldName, scan2 Scan, fieldNames2 []types.FieldName) *UnionScan {
+	return &UnionScan{scan1: scan1, scan2: scan2, fieldNames1: fieldNames1, fieldNames2: fieldNames2}
+}
+
+func (us *UnionScan) BeforeFirst() {
+	us.scan1.BeforeFirst()
+	us.scan2.BeforeFirst()
+	us.isOnSecond = false
+}
+
+func (us *UnionScan) Next() bool {
+	if !us.isOnSecond {
+		if us.scan1.Next() {
+			return true
+		}
+		us.isOnSecond = true
+	}
+	return us.scan2.Next()
+}
+
+func (us *UnionScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	scan, fieldName, err := us.current(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return scan.GetInt(fieldName)
+}
+
+func (us *UnionScan) GetString(fieldName types.FieldName) (string, error) {
+	scan, fieldName, err := us.current(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return scan.GetString(fieldName)
+}
+
+func (us *UnionScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	scan, fieldName, err := us.current(fieldName)
+	if err != nil {
+		return nil, err
+	}
+	return scan.GetValue(fieldName)
+}
+
+func (us *UnionScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(us.fieldNames1, fieldName)
+}
+
+func (us *UnionScan) Close() {
+	us.scan1.Close()
+	us.scan2.Close()
+}
+
+func (us *UnionScan) GetFields() []types.FieldName {
+	return us.fieldNames1
+}
+
+func (us *UnionScan) Err() error {
+	return errors.Join(us.scan1.Err(), us.scan2.Err())
+}
+
+// 今読んでいる方の scan と、その scan でのフィールド名を返す.
+func (us *UnionScan) current(fieldName types.FieldName) (Scan, types.FieldName, error) {
+	index := slices.Index(us.fieldNames1, fieldName)
+	if index < 0 {
+		return nil, "", &UnknownFieldInUnionScanError{fieldName}
+	}
+
+	if us.isOnSecond {
+		return us.scan2, us.fieldNames2[index], nil
+	}
+	return us.scan1, fieldName, nil
+}
@@ -13,6 +13,7 @@ type UpdateScan interface {
 	// 存在しないフィールドを指定されたらエラーを返す.
 	SetString(fieldName types.FieldName, value string) error
 	// 存在しないフィールドを指定されたらエラーを返す.
+	// NullConstant を指定するとフィールドを NULL にする.
 	SetValue(fieldName types.FieldName, value Constant) error
 
 	Insert()
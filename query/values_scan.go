@@ -0,0 +1,71 @@
+package query
+
+import (
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*ValuesScan)(nil)
+
+// メモリ上の複数のレコードを、順に出力する scan.
+// カタログから組み立てた information_schema のシステムビューのように、テーブルに保存していないレコードを読むために使う.
+type ValuesScan struct {
+	fieldNames []types.FieldName
+	rows       [][]Constant
+	// 現在のレコードの位置. Next を呼ぶ前は -1 になる.
+	current int
+}
+
+// rows の各レコードの値は fieldNames と位置で対応する.
+func NewValuesScan(fieldNames []types.FieldName, rows [][]Constant) *ValuesScan {
+	return &ValuesScan{fieldNames: fieldNames, rows: rows, current: -1}
+}
+
+func (vs *ValuesScan) BeforeFirst() {
+	vs.current = -1
+}
+
+func (vs *ValuesScan) Next() bool {
+	if vs.current < len(vs.rows) {
+		vs.current++
+	}
+	return vs.current < len(vs.rows)
+}
+
+func (vs *ValuesScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := vs.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (vs *ValuesScan) GetString(fieldName types.FieldName) (string, error) {
+	value, err := vs.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+func (vs *ValuesScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	index := slices.Index(vs.fieldNames, fieldName)
+	if index < 0 {
+		return nil, &UnknownFieldInValuesScanError{fieldName}
+	}
+	return vs.rows[vs.current][index], nil
+}
+
+func (vs *ValuesScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(vs.fieldNames, fieldName)
+}
+
+func (vs *ValuesScan) Close() {}
+
+func (vs *ValuesScan) GetFields() []types.FieldName {
+	return vs.fieldNames
+}
+
+func (vs *ValuesScan) Err() error {
+	return nil
+}
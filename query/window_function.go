@@ -0,0 +1,352 @@
+package query
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/record"
+	"simple-db-go/types"
+	"strings"
+)
+
+// `OVER (PARTITION BY a ORDER BY b DESC)` で指定される、window 関数の計算対象の範囲と順序.
+type Window struct {
+	partitionFields []types.FieldName
+	orderFields     []types.FieldName
+	// orderFields と位置で対応し、true のフィールドは降順で並べる.
+	descending []bool
+}
+
+func NewWindow(partitionFields []types.FieldName, orderFields []types.FieldName, descending []bool) *Window {
+	return &Window{partitionFields: partitionFields, orderFields: orderFields, descending: descending}
+}
+
+func (w *Window) GetPartitionFields() []types.FieldName {
+	return w.partitionFields
+}
+
+func (w *Window) GetOrderFields() []types.FieldName {
+	return w.orderFields
+}
+
+// 入力をパーティションごとに、パーティション内は ORDER BY の順に並べるためのソート順.
+// PARTITION BY のフィールドは昇順で並べる.
+func (w *Window) GetSortOrder() ([]types.FieldName, []bool) {
+	sortFields := make([]types.FieldName, 0, len(w.partitionFields)+len(w.orderFields))
+	sortFields = append(sortFields, w.partitionFields...)
+	sortFields = append(sortFields, w.orderFields...)
+
+	descending := make([]bool, len(w.partitionFields), len(sortFields))
+	descending = append(descending, w.descending...)
+	return sortFields, descending
+}
+
+// `(partition by a order by b desc)` のように、関数のフィールド名に使う表記を返す.
+func (w *Window) ToString() string {
+	clauses := make([]string, 0, 2)
+	if len(w.partitionFields) > 0 {
+		partitionFields := make([]string, 0, len(w.partitionFields))
+		for _, fieldName := range w.partitionFields {
+			partitionFields = append(partitionFields, string(fieldName))
+		}
+		clauses = append(clauses, "partition by "+strings.Join(partitionFields, ", "))
+	}
+
+	if len(w.orderFields) > 0 {
+		orderFields := make([]string, 0, len(w.orderFields))
+		for i, fieldName := range w.orderFields {
+			if w.descending[i] {
+				orderFields = append(orderFields, string(fieldName)+" desc")
+			} else {
+				orderFields = append(orderFields, string(fieldName))
+			}
+		}
+		clauses = append(clauses, "order by "+strings.Join(orderFields, ", "))
+	}
+
+	return "(" + strings.Join(clauses, " ") + ")"
+}
+
+// WindowScan で、パーティションの各レコードに対して値を計算する関数.
+type WindowFunction interface {
+	// 計算結果を保持するフィールド名. `rank() over (order by a desc)` のように SQL での表記をそのまま使う.
+	GetFieldName() types.FieldName
+
+	// 計算結果のフィールドの型と長さを、入力の schema から決める.
+	GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error)
+
+	GetWindow() *Window
+
+	// partition の各レコードに対する計算結果を、partition 内の順に返す.
+	compute(partition *windowPartition) ([]Constant, error)
+}
+
+// ROW_NUMBER, RANK, DENSE_RANK のように、引数をとらない順位付けの関数を作る.
+func NewRankingWindowFunction(functionName string, window *Window) (WindowFunction, error) {
+	switch functionName {
+	case "row_number":
+		return NewRowNumberFunction(window), nil
+	case "rank":
+		return NewRankFunction(window), nil
+	case "dense_rank":
+		return NewDenseRankFunction(window), nil
+	default:
+		return nil, &UnknownWindowFunctionError{functionName}
+	}
+}
+
+func windowFunctionFieldName(functionName string, arguments string, window *Window) types.FieldName {
+	return types.FieldName(fmt.Sprintf("%s(%s) over %s", functionName, arguments, window.ToString()))
+}
+
+var _ WindowFunction = (*RowNumberFunction)(nil)
+
+// パーティション内で 1 から順に番号を振る. ORDER BY の値が等しいレコードにも異なる番号を振る.
+type RowNumberFunction struct {
+	window *Window
+}
+
+func NewRowNumberFunction(window *Window) *RowNumberFunction {
+	return &RowNumberFunction{window: window}
+}
+
+func (f *RowNumberFunction) GetFieldName() types.FieldName {
+	return windowFunctionFieldName("row_number", "", f.window)
+}
+
+func (f *RowNumberFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return constants.INTEGER, record.INTEGER_FIELD_LENGTH, nil
+}
+
+func (f *RowNumberFunction) GetWindow() *Window {
+	return f.window
+}
+
+func (f *RowNumberFunction) compute(partition *windowPartition) ([]Constant, error) {
+	results := make([]Constant, partition.size())
+	for i := range results {
+		results[i] = NewIntConstant(types.Int(i + 1))
+	}
+	return results, nil
+}
+
+var _ WindowFunction = (*RankFunction)(nil)
+
+// ORDER BY の値が等しいレコードには同じ順位を振り、その次の順位はレコード数だけ飛ばす(1, 1, 3, ...).
+type RankFunction struct {
+	window *Window
+}
+
+func NewRankFunction(window *Window) *RankFunction {
+	return &RankFunction{window: window}
+}
+
+func (f *RankFunction) GetFieldName() types.FieldName {
+	return windowFunctionFieldName("rank", "", f.window)
+}
+
+func (f *RankFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return constants.INTEGER, record.INTEGER_FIELD_LENGTH, nil
+}
+
+func (f *RankFunction) GetWindow() *Window {
+	return f.window
+}
+
+func (f *RankFunction) compute(partition *windowPartition) ([]Constant, error) {
+	results := make([]Constant, partition.size())
+	for i := range results {
+		results[i] = NewIntConstant(types.Int(partition.peerStarts[i] + 1))
+	}
+	return results, nil
+}
+
+var _ WindowFunction = (*DenseRankFunction)(nil)
+
+// ORDER BY の値が等しいレコードには同じ順位を振り、順位を飛ばさない(1, 1, 2, ...).
+type DenseRankFunction struct {
+	window *Window
+}
+
+func NewDenseRankFunction(window *Window) *DenseRankFunction {
+	return &DenseRankFunction{window: window}
+}
+
+func (f *DenseRankFunction) GetFieldName() types.FieldName {
+	return windowFunctionFieldName("dense_rank", "", f.window)
+}
+
+func (f *DenseRankFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return constants.INTEGER, record.INTEGER_FIELD_LENGTH, nil
+}
+
+func (f *DenseRankFunction) GetWindow() *Window {
+	return f.window
+}
+
+func (f *DenseRankFunction) compute(partition *windowPartition) ([]Constant, error) {
+	results := make([]Constant, partition.size())
+	rank := types.Int(0)
+	for i := range results {
+		if partition.peerStarts[i] == i {
+			rank++
+		}
+		results[i] = NewIntConstant(rank)
+	}
+	return results, nil
+}
+
+// LAG で省略された場合の、何レコード離れた値を読むか.
+const DEFAULT_WINDOW_OFFSET types.Int = 1
+
+var _ WindowFunction = (*OffsetFunction)(nil)
+
+// LAG と LEAD. パーティション内で offset だけ前(LAG)または後ろ(LEAD)のレコードの値を返す.
+type OffsetFunction struct {
+	functionName string
+	fieldName    types.FieldName
+	offset       types.Int
+	// 該当するレコードが無い場合の値. 省略された場合は NULL.
+	defaultValue Constant
+	window       *Window
+}
+
+func NewOffsetWindowFunction(functionName string, fieldName types.FieldName, offset types.Int, defaultValue Constant, window *Window) (WindowFunction, error) {
+	switch functionName {
+	case "lag", "lead":
+		return &OffsetFunction{
+			functionName: functionName,
+			fieldName:    fieldName,
+			offset:       offset,
+			defaultValue: defaultValue,
+			window:       window,
+		}, nil
+	default:
+		return nil, &UnknownWindowFunctionError{functionName}
+	}
+}
+
+func NewLagFunction(fieldName types.FieldName, offset types.Int, defaultValue Constant, window *Window) *OffsetFunction {
+	fn, _ := NewOffsetWindowFunction("lag", fieldName, offset, defaultValue, window)
+	return fn.(*OffsetFunction)
+}
+
+func NewLeadFunction(fieldName types.FieldName, offset types.Int, defaultValue Constant, window *Window) *OffsetFunction {
+	fn, _ := NewOffsetWindowFunction("lead", fieldName, offset, defaultValue, window)
+	return fn.(*OffsetFunction)
+}
+
+// 省略できる引数は、省略された形で表記する. 同じ関数は常に同じフィールド名になるようにしておく.
+func (f *OffsetFunction) GetFieldName() types.FieldName {
+	arguments := string(f.fieldName)
+	if f.defaultValue != nil {
+		arguments += fmt.Sprintf(", %s, %s", f.offset.ToString(), f.defaultValue.ToString())
+	} else if f.offset != DEFAULT_WINDOW_OFFSET {
+		arguments += fmt.Sprintf(", %s", f.offset.ToString())
+	}
+	return windowFunctionFieldName(f.functionName, arguments, f.window)
+}
+
+// 結果は参照するフィールドと同じ型になる. デフォルト値もその型でなければならない.
+// offset が負の場合は、LAG と LEAD の意味が逆になってしまうのでエラーにする.
+func (f *OffsetFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	if f.offset < 0 {
+		return 0, 0, &NegativeWindowOffsetError{f.GetFieldName(), f.offset}
+	}
+
+	fieldType, err := schema.FieldType(f.fieldName)
+	if err != nil {
+		return 0, 0, &InvalidWindowFunctionFieldError{f.GetFieldName(), f.fieldName}
+	}
+
+	fieldLength, err := schema.Length(f.fieldName)
+	if err != nil {
+		return 0, 0, &InvalidWindowFunctionFieldError{f.GetFieldName(), f.fieldName}
+	}
+
+	if f.defaultValue != nil && !IsNull(f.defaultValue) {
+		_, isInt := f.defaultValue.(IntConstant)
+		if isInt != (fieldType == constants.INTEGER) {
+			return 0, 0, &WindowFunctionDefaultTypeError{f.GetFieldName(), f.defaultValue.ToString()}
+		}
+	}
+
+	return fieldType, fieldLength, nil
+}
+
+func (f *OffsetFunction) GetWindow() *Window {
+	return f.window
+}
+
+func (f *OffsetFunction) compute(partition *windowPartition) ([]Constant, error) {
+	offset := int(f.offset)
+	if f.functionName == "lag" {
+		offset = -offset
+	}
+
+	results := make([]Constant, partition.size())
+	for i := range results {
+		target := i + offset
+		if 0 <= target && target < partition.size() {
+			value, err := partition.getValue(target, f.fieldName)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = value
+			continue
+		}
+
+		if f.defaultValue != nil {
+			results[i] = f.defaultValue
+		} else {
+			results[i] = NewNullConstant()
+		}
+	}
+	return results, nil
+}
+
+var _ WindowFunction = (*AggregateWindowFunction)(nil)
+
+// `SUM(a) OVER (...)` のように、集約関数を window 関数として使う.
+// ORDER BY がある場合は、パーティションの先頭から、ORDER BY の値が等しいレコードの末尾までを集計する(累積和など).
+// ORDER BY が無い場合は、パーティション全体を集計する.
+type AggregateWindowFunction struct {
+	function AggregationFunction
+	window   *Window
+}
+
+func NewAggregateWindowFunction(function AggregationFunction, window *Window) *AggregateWindowFunction {
+	return &AggregateWindowFunction{function: function, window: window}
+}
+
+func (f *AggregateWindowFunction) GetFieldName() types.FieldName {
+	return types.FieldName(fmt.Sprintf("%s over %s", f.function.GetFieldName(), f.window.ToString()))
+}
+
+func (f *AggregateWindowFunction) GetFieldType(schema *record.Schema) (types.FieldType, types.FieldLength, error) {
+	return f.function.GetFieldType(schema)
+}
+
+func (f *AggregateWindowFunction) GetWindow() *Window {
+	return f.window
+}
+
+func (f *AggregateWindowFunction) compute(partition *windowPartition) ([]Constant, error) {
+	results := make([]Constant, partition.size())
+	f.function.Reset()
+	for peerStart := 0; peerStart < partition.size(); {
+		peerEnd := partition.peerEnds[peerStart]
+		for i := peerStart; i < peerEnd; i++ {
+			partition.moveTo(i)
+			if err := f.function.Process(partition); err != nil {
+				return nil, err
+			}
+		}
+
+		value := f.function.GetValue()
+		for i := peerStart; i < peerEnd; i++ {
+			results[i] = value
+		}
+		peerStart = peerEnd
+	}
+	return results, nil
+}
@@ -0,0 +1,273 @@
+package query
+
+import (
+	"simple-db-go/types"
+	"slices"
+)
+
+var _ Scan = (*WindowScan)(nil)
+
+// PARTITION BY と ORDER BY のフィールドでソート済みの scan を受け取り、window 関数の結果を加えたレコードを出力する.
+// パーティションを1つずつメモリに読み込み、そのパーティションの全レコードに対して window 関数を計算する.
+// functions はすべて window と同じ window を使うものとする.
+type WindowScan struct {
+	scan      Scan
+	window    *Window
+	functions []WindowFunction
+	partition *windowPartition
+	// results[i][j] は、functions[i] の partition の j 番目のレコードに対する結果.
+	results [][]Constant
+	// scan が次のパーティションの先頭のレコードを指しているかどうか.
+	hasMorePartitions bool
+	err               error
+}
+
+func NewWindowScan(scan Scan, window *Window, functions []WindowFunction) *WindowScan {
+	windowScan := &WindowScan{
+		scan:      scan,
+		window:    window,
+		functions: functions,
+	}
+	windowScan.BeforeFirst()
+	return windowScan
+}
+
+func (ws *WindowScan) BeforeFirst() {
+	ws.scan.BeforeFirst()
+	ws.hasMorePartitions = ws.scan.Next()
+	ws.partition = nil
+}
+
+// パーティション内の次のレコードに移動する. パーティションを読み終えたら、次のパーティションを読み込む.
+func (ws *WindowScan) Next() bool {
+	if ws.err != nil {
+		return false
+	}
+
+	if ws.partition != nil && ws.partition.Next() {
+		return true
+	}
+
+	if !ws.hasMorePartitions {
+		return false
+	}
+
+	if err := ws.loadPartition(); err != nil {
+		ws.err = err
+		return false
+	}
+	return ws.partition.Next()
+}
+
+func (ws *WindowScan) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := ws.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (ws *WindowScan) GetString(fieldName types.FieldName) (string, error) {
+	value, err := ws.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+func (ws *WindowScan) GetValue(fieldName types.FieldName) (Constant, error) {
+	if ws.partition == nil || ws.partition.position < 0 || ws.partition.position >= ws.partition.size() {
+		return nil, &NoCurrentRecordInWindowScanError{fieldName}
+	}
+
+	for i, fn := range ws.functions {
+		if fn.GetFieldName() == fieldName {
+			return ws.results[i][ws.partition.position], nil
+		}
+	}
+
+	if !ws.partition.HasField(fieldName) {
+		return nil, &UnknownFieldInWindowScanError{fieldName}
+	}
+	return ws.partition.GetValue(fieldName)
+}
+
+func (ws *WindowScan) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(ws.GetFields(), fieldName)
+}
+
+func (ws *WindowScan) Close() {
+	ws.scan.Close()
+}
+
+func (ws *WindowScan) Err() error {
+	if ws.err != nil {
+		return ws.err
+	}
+	return ws.scan.Err()
+}
+
+func (ws *WindowScan) GetFields() []types.FieldName {
+	fields := slices.Clone(ws.scan.GetFields())
+	for _, fn := range ws.functions {
+		fields = append(fields, fn.GetFieldName())
+	}
+	return fields
+}
+
+// scan の current record から始まるパーティションを読み込み、window 関数を計算する.
+// 読み終えた時、scan は次のパーティションの先頭のレコードを指している.
+func (ws *WindowScan) loadPartition() error {
+	partition := newWindowPartition(ws.scan.GetFields())
+	partitionValues, err := readValues(ws.scan, ws.window.partitionFields)
+	if err != nil {
+		return err
+	}
+
+	var previousOrderValues []Constant
+	for {
+		row, err := readValues(ws.scan, partition.fieldNames)
+		if err != nil {
+			return err
+		}
+
+		orderValues, err := readValues(ws.scan, ws.window.orderFields)
+		if err != nil {
+			return err
+		}
+		isPeer := previousOrderValues != nil && equalValues(previousOrderValues, orderValues)
+		partition.add(row, isPeer)
+		previousOrderValues = orderValues
+
+		ws.hasMorePartitions = ws.scan.Next()
+		if !ws.hasMorePartitions {
+			// エラーで読み終えた場合は、途中までのパーティションで window 関数を計算しない.
+			if err := ws.scan.Err(); err != nil {
+				return err
+			}
+			break
+		}
+
+		nextValues, err := readValues(ws.scan, ws.window.partitionFields)
+		if err != nil {
+			return err
+		}
+		if !equalValues(partitionValues, nextValues) {
+			break
+		}
+	}
+
+	partition.computePeerEnds()
+
+	ws.results = make([][]Constant, 0, len(ws.functions))
+	for _, fn := range ws.functions {
+		results, err := fn.compute(partition)
+		if err != nil {
+			return err
+		}
+		ws.results = append(ws.results, results)
+	}
+
+	partition.BeforeFirst()
+	ws.partition = partition
+	return nil
+}
+
+var _ Scan = (*windowPartition)(nil)
+
+// メモリに読み込んだ1つのパーティション. 集約関数に渡せるように、Scan として読むこともできる.
+type windowPartition struct {
+	fieldNames []types.FieldName
+	rows       [][]Constant
+	// 各レコードが属する peer (ORDER BY のフィールドの値が等しいレコードの集まり) の先頭の位置と、末尾の次の位置.
+	peerStarts []int
+	peerEnds   []int
+	position   int
+}
+
+func newWindowPartition(fieldNames []types.FieldName) *windowPartition {
+	return &windowPartition{fieldNames: fieldNames, position: -1}
+}
+
+// レコードを末尾に追加する. isPeer が true の場合は、直前のレコードと同じ peer に含める.
+func (p *windowPartition) add(row []Constant, isPeer bool) {
+	index := len(p.rows)
+	p.rows = append(p.rows, row)
+
+	peerStart := index
+	if isPeer {
+		peerStart = p.peerStarts[index-1]
+	}
+	p.peerStarts = append(p.peerStarts, peerStart)
+}
+
+// 全レコードを追加し終えてから、各レコードが属する peer の末尾の次の位置を求める.
+func (p *windowPartition) computePeerEnds() {
+	p.peerEnds = make([]int, len(p.rows))
+	for i := len(p.rows) - 1; i >= 0; i-- {
+		if i == len(p.rows)-1 || p.peerStarts[i+1] != p.peerStarts[i] {
+			p.peerEnds[i] = i + 1
+		} else {
+			p.peerEnds[i] = p.peerEnds[i+1]
+		}
+	}
+}
+
+func (p *windowPartition) size() int {
+	return len(p.rows)
+}
+
+func (p *windowPartition) moveTo(position int) {
+	p.position = position
+}
+
+func (p *windowPartition) getValue(position int, fieldName types.FieldName) (Constant, error) {
+	index := slices.Index(p.fieldNames, fieldName)
+	if index < 0 {
+		return nil, &UnknownFieldInWindowScanError{fieldName}
+	}
+	return p.rows[position][index], nil
+}
+
+func (p *windowPartition) BeforeFirst() {
+	p.position = -1
+}
+
+func (p *windowPartition) Next() bool {
+	p.position++
+	return p.position < len(p.rows)
+}
+
+func (p *windowPartition) GetInt(fieldName types.FieldName) (types.Int, error) {
+	value, err := p.GetValue(fieldName)
+	if err != nil {
+		return 0, err
+	}
+	return intValueOf(value), nil
+}
+
+func (p *windowPartition) GetString(fieldName types.FieldName) (string, error) {
+	value, err := p.GetValue(fieldName)
+	if err != nil {
+		return "", err
+	}
+	return stringValueOf(value), nil
+}
+
+func (p *windowPartition) GetValue(fieldName types.FieldName) (Constant, error) {
+	return p.getValue(p.position, fieldName)
+}
+
+func (p *windowPartition) HasField(fieldName types.FieldName) bool {
+	return slices.Contains(p.fieldNames, fieldName)
+}
+
+func (p *windowPartition) Close() {}
+
+func (p *windowPartition) GetFields() []types.FieldName {
+	return p.fieldNames
+}
+
+func (p *windowPartition) Err() error {
+	return nil
+}
@@ -13,3 +13,21 @@ type UnknownFieldError struct {
 func (e *UnknownFieldError) Error() string {
 	return fmt.Sprintf("Layoutに存在しないフィールドが指定されました。schema=%+v, fieldName=%s", e.schema, e.fieldName)
 }
+
+type NullNotSupportedError struct {
+	fieldName types.FieldName
+}
+
+func (e *NullNotSupportedError) Error() string {
+	return fmt.Sprintf("null bitmap の無い形式のテーブルには NULL を書き込めません。fieldName=%s", e.fieldName)
+}
+
+type StringTooLongError struct {
+	fieldName types.FieldName
+	length    types.FieldLength
+	value     string
+}
+
+func (e *StringTooLongError) Error() string {
+	return fmt.Sprintf("フィールドの長さを超える文字列は書き込めません。fieldName=%s, length=%d, value=%s", e.fieldName, e.length, e.value)
+}
@@ -1,10 +1,13 @@
 package record
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"simple-db-go/constants"
 	"simple-db-go/file"
 	"simple-db-go/types"
+	"slices"
 )
 
 // DB record の物理的な構造を表現する構造体.
@@ -16,15 +19,26 @@ import (
 // - Fixed length
 //
 // また、先頭バイトには empty/inuse フラグがあるとする.
+// スロットの末尾には、各フィールドが NULL かどうかを 1 ビットずつ記録する null bitmap を置く.
+// null bitmap は 4 バイトの整数を単位とし、フィールドの数に応じて必要な個数だけ並べる.
+// ただし、null bitmap を導入する前の形式(RECORD_FORMAT_WITHOUT_NULL_BITMAP)で作られたテーブルには null bitmap が無い.
 type Layout struct {
 	schema   *Schema
 	offsets  map[types.FieldName]types.FieldOffsetInSlot
 	slotSize types.SlotSize
+	format   types.RecordFormat
+	// フィールドごとの、null bitmap の中でのビットの位置.
+	nullBits map[types.FieldName]types.Int
 }
 
 // テーブルが新規作成された際のコンストラクタ.
-// 単に与えられた Schema を元に Layout を計算する.
+// 単に与えられた Schema を元に、現在の形式で Layout を計算する.
 func NewLayout(schema *Schema) *Layout {
+	return NewLayoutOf(schema, constants.CURRENT_RECORD_FORMAT)
+}
+
+// 指定した形式で Layout を計算する. 古い形式で作られたテーブルのファイルを読むために使う.
+func NewLayoutOf(schema *Schema, format types.RecordFormat) *Layout {
 	offsets := make(map[types.FieldName]types.FieldOffsetInSlot)
 
 	// 各スロットの先頭４バイトのフラグを考慮する.
@@ -40,20 +54,28 @@ func NewLayout(schema *Schema) *Layout {
 		flagPos += length
 	}
 
+	if hasNullBitmap(format) {
+		flagPos += nullBitmapSize(schema)
+	}
+
 	return &Layout{
 		schema:   schema,
 		offsets:  offsets,
 		slotSize: types.SlotSize(flagPos),
+		format:   format,
+		nullBits: calcNullBits(offsets),
 	}
 }
 
 // 既存テーブルに対してのコンストラクタ.
 // 既に計算された値をベースに Layout を計算する.
-func NewLayoutWith(schema *Schema, offsets map[types.FieldName]types.FieldOffsetInSlot, slotSize types.SlotSize) *Layout {
+func NewLayoutWith(schema *Schema, offsets map[types.FieldName]types.FieldOffsetInSlot, slotSize types.SlotSize, format types.RecordFormat) *Layout {
 	return &Layout{
 		schema:   schema,
 		offsets:  offsets,
 		slotSize: slotSize,
+		format:   format,
+		nullBits: calcNullBits(offsets),
 	}
 }
 
@@ -73,6 +95,56 @@ func (l *Layout) GetSlotSize() types.SlotSize {
 	return l.slotSize
 }
 
+func (l *Layout) GetFormat() types.RecordFormat {
+	return l.format
+}
+
+// NULL を記録できるかどうか. null bitmap が無い形式のテーブルでは、どのフィールドも NULL にならない.
+func (l *Layout) HasNullBitmap() bool {
+	return hasNullBitmap(l.format)
+}
+
+// フィールドが NULL かどうかを記録している、null bitmap の整数のスロット内でのオフセットと、その中のビットを返す.
+func (l *Layout) GetNullFlag(fieldName types.FieldName) (types.FieldOffsetInSlot, types.Int, error) {
+	bit, exists := l.nullBits[fieldName]
+	if !exists {
+		return 0, 0, &UnknownFieldError{l.schema, fieldName}
+	}
+	if !l.HasNullBitmap() {
+		return 0, 0, &NullNotSupportedError{fieldName}
+	}
+
+	bitmapOffset := types.Int(l.slotSize) - nullBitmapSize(l.schema)
+	offset := bitmapOffset + (bit/NULL_BITS_PER_INT)*constants.Int32ByteSize
+	return types.FieldOffsetInSlot(offset), 1 << (bit % NULL_BITS_PER_INT), nil
+}
+
+// null bitmap の整数1つで記録できるフィールドの数. 符号ビットは使わない.
+const NULL_BITS_PER_INT types.Int = 31
+
+func hasNullBitmap(format types.RecordFormat) bool {
+	return format >= constants.RECORD_FORMAT_WITH_NULL_BITMAP
+}
+
+func nullBitmapSize(schema *Schema) types.Int {
+	numFields := types.Int(len(schema.Fields()))
+	return (numFields + NULL_BITS_PER_INT - 1) / NULL_BITS_PER_INT * constants.Int32ByteSize
+}
+
+// ビットの位置はフィールドのオフセットの順に割り当てる.
+// カタログから Layout を復元する場合でも、テーブル作成時と同じ位置になるようにするため.
+func calcNullBits(offsets map[types.FieldName]types.FieldOffsetInSlot) map[types.FieldName]types.Int {
+	fieldNames := slices.SortedFunc(maps.Keys(offsets), func(a, b types.FieldName) int {
+		return cmp.Compare(offsets[a], offsets[b])
+	})
+
+	nullBits := make(map[types.FieldName]types.Int, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		nullBits[fieldName] = types.Int(i)
+	}
+	return nullBits
+}
+
 func getLengthInBytes(schema *Schema, fieldName types.FieldName) (types.Int, error) {
 	fieldType, err := schema.FieldType(fieldName)
 	if err != nil {
@@ -1,6 +1,7 @@
 package record
 
 import (
+	"simple-db-go/constants"
 	"simple-db-go/types"
 	"testing"
 
@@ -20,9 +21,22 @@ func TestLayoutCalculateSlotOffsets(t *testing.T) {
 		idFieldSize := types.Int(4)
 		nameFieldSize := types.Int(4 + 10)
 		ageFieldSize := types.Int(4)
-		expectedSlotSize := types.SlotSize(flagSize + idFieldSize + nameFieldSize + ageFieldSize)
+		nullBitmapSize := types.Int(4)
+		expectedSlotSize := types.SlotSize(flagSize + idFieldSize + nameFieldSize + ageFieldSize + nullBitmapSize)
 
 		assert.Equal(t, expectedSlotSize, layout.GetSlotSize(), "スロットサイズが期待した値であること.")
+		assert.Equal(t, constants.CURRENT_RECORD_FORMAT, layout.GetFormat(), "現在の形式で計算されること.")
+	})
+
+	t.Run("null bitmap の無い形式では、スロットサイズに null bitmap を含まないこと.", func(t *testing.T) {
+		legacyLayout := NewLayoutOf(schema, constants.RECORD_FORMAT_WITHOUT_NULL_BITMAP)
+		assert.Equal(t, types.SlotSize(4+4+(4+10)+4), legacyLayout.GetSlotSize())
+		assert.False(t, legacyLayout.HasNullBitmap())
+
+		ageOffset, err := legacyLayout.GetOffset("age")
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.FieldOffsetInSlot(22), ageOffset, "フィールドのオフセットは形式によって変わらないこと.")
+		}
 	})
 
 	t.Run("各フィールドのオフセットが期待した値になっていること.", func(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"simple-db-go/file"
 	"simple-db-go/transaction"
 	"simple-db-go/types"
+	"simple-db-go/util"
 )
 
 // スロットの集まりを表現する構造体.
@@ -43,26 +44,82 @@ func (rp *RecordPage) GetString(slotNumber SlotNumber, fieldName types.FieldName
 	return rp.transaction.GetString(rp.blockID, types.Int(fieldOffset)), nil
 }
 
+// 値をセットしたフィールドは NULL ではなくなる.
 func (rp *RecordPage) SetInt(slotNumber SlotNumber, fieldName types.FieldName, value types.Int) error {
 	fieldOffset, err := rp.getFieldOffsetInPage(slotNumber, fieldName)
 	if err != nil {
 		return err
 	}
 	rp.transaction.SetInt(rp.blockID, types.Int(fieldOffset), value, true)
-	return nil
+	return rp.setNullFlag(slotNumber, fieldName, false)
 }
 
+// 値をセットしたフィールドは NULL ではなくなる.
+// フィールドの長さを超える文字列は、次のフィールドを上書きしてしまうので書き込まない.
 func (rp *RecordPage) SetString(slotNumber SlotNumber, fieldName types.FieldName, value string) error {
 	fieldOffset, err := rp.getFieldOffsetInPage(slotNumber, fieldName)
 	if err != nil {
 		return err
 	}
+
+	length, err := rp.layout.GetSchema().Length(fieldName)
+	if err != nil {
+		return err
+	}
+	if file.MaxLength(util.Len(value)) > file.MaxLength(types.Int(length)) {
+		return &StringTooLongError{fieldName, length, value}
+	}
 	rp.transaction.SetString(rp.blockID, types.Int(fieldOffset), value, true)
+	return rp.setNullFlag(slotNumber, fieldName, false)
+}
+
+// null bitmap の無い形式のテーブルでは、常に NULL でないとする.
+func (rp *RecordPage) IsNull(slotNumber SlotNumber, fieldName types.FieldName) (bool, error) {
+	if !rp.layout.HasNullBitmap() {
+		_, err := rp.layout.GetOffset(fieldName)
+		return false, err
+	}
+
+	flagOffset, bit, err := rp.getNullFlagOffsetInPage(slotNumber, fieldName)
+	if err != nil {
+		return false, err
+	}
+	return rp.transaction.GetInt(rp.blockID, types.Int(flagOffset))&bit != 0, nil
+}
+
+// フィールドを NULL にする. フィールドに書かれていた値はそのまま残るが、読まれることはない.
+func (rp *RecordPage) SetNull(slotNumber SlotNumber, fieldName types.FieldName) error {
+	return rp.setNullFlag(slotNumber, fieldName, true)
+}
+
+// null bitmap の該当するビットを更新する. 値が変わらない場合は書き込まない(ログも書かない).
+// null bitmap の無い形式のテーブルでは、NULL にする場合だけエラーにする.
+func (rp *RecordPage) setNullFlag(slotNumber SlotNumber, fieldName types.FieldName, isNull bool) error {
+	if !rp.layout.HasNullBitmap() && !isNull {
+		_, err := rp.layout.GetOffset(fieldName)
+		return err
+	}
+
+	flagOffset, bit, err := rp.getNullFlagOffsetInPage(slotNumber, fieldName)
+	if err != nil {
+		return err
+	}
+
+	flags := rp.transaction.GetInt(rp.blockID, types.Int(flagOffset))
+	newFlags := flags &^ bit
+	if isNull {
+		newFlags = flags | bit
+	}
+
+	if newFlags != flags {
+		rp.transaction.SetInt(rp.blockID, types.Int(flagOffset), newFlags, true)
+	}
 	return nil
 }
 
 // このレコードページ内の全てのスロットを初期化する.
-// 整数は0、文字列は空文字列に初期化する.
+// 整数は0、文字列は空文字列に初期化し、null bitmap はすべて NULL でない状態にする.
+// INSERT で値を指定しなかったフィールドは、planner が明示的に NULL にする.
 func (rp *RecordPage) Format() {
 	for slotNumber := SlotNumber(0); rp.isValidSlot(slotNumber); slotNumber++ {
 		// フラグの初期値を EMPTY に設定する.
@@ -93,10 +150,29 @@ func (rp *RecordPage) Format() {
 			if fieldType == constants.VARCHAR {
 				rp.transaction.SetString(rp.blockID, types.Int(fieldOffset), "", false)
 			}
+
+			if !rp.layout.HasNullBitmap() {
+				continue
+			}
+			flagOffset, _, err := rp.getNullFlagOffsetInPage(slotNumber, fieldName)
+			if err != nil {
+				panic(fmt.Sprintf("RecordPage.Format で予期せぬエラーが発生しました. record_page=%+v, err=%+v", rp, err))
+			}
+			rp.transaction.SetInt(rp.blockID, types.Int(flagOffset), types.Int(0), false)
 		}
 	}
 }
 
+// 既にレコードが書き込まれているブロックを、全てのスロットが空の状態に戻す.
+// Format と違ってログを残すので、ロールバックやリカバリで元のレコードに戻せる.
+// 元のレコードがどの layout で書かれていても元に戻せるように、文字列としては書き込まず、ブロック全体を整数ごとに 0 で埋める.
+// 0 で埋めたスロットは、Format した時と同じく EMPTY で、整数は 0、文字列は空文字列、NULL でない状態になる.
+func (rp *RecordPage) Clear() {
+	for offset := types.Int(0); offset+constants.Int32ByteSize <= rp.transaction.BlockSize(); offset += constants.Int32ByteSize {
+		rp.transaction.SetInt(rp.blockID, offset, 0, true)
+	}
+}
+
 // スロットのフラグに EMPTY をセットする.
 func (rp *RecordPage) Delete(slotNumber SlotNumber) {
 	rp.setSlotFlag(slotNumber, SLOT_EMPTY)
@@ -150,3 +226,12 @@ func (rp *RecordPage) getFieldOffsetInPage(slotNumber SlotNumber, fieldName type
 	}
 	return calcFieldOffsetInPage(slotOffset, fieldOffsetInSlot), nil
 }
+
+func (rp *RecordPage) getNullFlagOffsetInPage(slotNumber SlotNumber, fieldName types.FieldName) (FieldOffsetInPage, types.Int, error) {
+	slotOffset := rp.getSlotOffset(slotNumber)
+	flagOffsetInSlot, bit, err := rp.layout.GetNullFlag(fieldName)
+	if err != nil {
+		return 0, 0, err
+	}
+	return calcFieldOffsetInPage(slotOffset, flagOffsetInSlot), bit, nil
+}
@@ -1,6 +1,7 @@
 package record
 
 import (
+	"simple-db-go/constants"
 	"simple-db-go/types"
 	"testing"
 
@@ -23,7 +24,7 @@ func TestRecordPageFormat(t *testing.T) {
 
 	t.Run("全てのスロットが初期値で初期化されている.", func(t *testing.T) {
 		blockSize := types.Int(512)
-		slotSize := types.Int(4 + 4 + (4 + 10) + 4) // 26
+		slotSize := types.Int(4 + 4 + (4 + 10) + 4 + 4) // 30 (末尾の 4 バイトは null bitmap)
 
 		slotNumber := SlotNumber(0)
 		for ; types.Int(slotNumber+1)*slotSize < blockSize; slotNumber++ {
@@ -49,10 +50,10 @@ func TestRecordPageFormat(t *testing.T) {
 			}
 		}
 
-		// 512 / 26 = 19.692... = 19個スロットがあるはず.
+		// 512 / 30 = 17.066... = 17個スロットがあるはず.
 		// slot_number は 0 から始まる.
-		expectedSlotNumber := SlotNumber(18)
-		assert.Equal(t, expectedSlotNumber, slotNumber-1, "slot number should be 18.") // ループ終了時には1つ余分にインクリメントされているため、-1 する.
+		expectedSlotNumber := SlotNumber(16)
+		assert.Equal(t, expectedSlotNumber, slotNumber-1, "slot number should be 16.") // ループ終了時には1つ余分にインクリメントされているため、-1 する.
 	})
 }
 
@@ -71,7 +72,7 @@ func TestRecordPageFindUsedSlotAfter(t *testing.T) {
 	recordPage.Format()
 
 	t.Run("全て空きスロットの場合、常にスロット番号が NULL を返す.", func(t *testing.T) {
-		for slotNumber := SlotNumber(-1); slotNumber <= 18; slotNumber++ {
+		for slotNumber := SlotNumber(-1); slotNumber <= 16; slotNumber++ {
 			result := recordPage.FindUsedSlotAfter(slotNumber)
 			assert.Equalf(t, NULL_SLOT_NUMBER, result, "どのスロットも空いているため、スロット番号は NULL_SLOT_NUMBER である.(slot_number=%d)\n", slotNumber)
 		}
@@ -109,10 +110,10 @@ func TestRecordPageFindEmptySlotAfter(t *testing.T) {
 	recordPage.Format()
 
 	t.Run("全て空きスロットの場合、常に次のスロット番号が返る. ただし、最後のスロットから探索した場合はNULLになる.", func(t *testing.T) {
-		for slotNumber := SlotNumber(-1); slotNumber <= 18; slotNumber++ {
+		for slotNumber := SlotNumber(-1); slotNumber <= 16; slotNumber++ {
 			result := recordPage.FindEmptySlotAfter(slotNumber)
 
-			if slotNumber < 18 {
+			if slotNumber < 16 {
 				assert.Equalf(t, slotNumber+1, result, "どのスロットも空いているため、次のスロット番号は %d である.(slot_number=%d)\n", slotNumber+1, slotNumber)
 			} else {
 				assert.Equalf(t, NULL_SLOT_NUMBER, result, "最後のスロットから探索した場合、次のスロット番号は NULL_SLOT_NUMBER である.(slot_number=%d)\n", slotNumber)
@@ -159,3 +160,140 @@ func TestRecordPageDelete(t *testing.T) {
 		assert.Equal(t, NULL_SLOT_NUMBER, usedSlotNumber, "スロット番号4は使用されていない.")
 	})
 }
+
+func TestRecordPageSetNull(t *testing.T) {
+	transaction := newTransactionForTest(t, recordPageTestName)
+
+	fileName := "test_record_page_set_null.table"
+	blockID := transaction.Append(fileName)
+	transaction.Pin(blockID)
+	defer transaction.Unpin(blockID)
+
+	schema := buildTestTableSchema()
+	layout := NewLayout(schema)
+
+	recordPage := NewRecordPage(transaction, blockID, layout)
+	recordPage.Format()
+
+	t.Run("初期化直後はどのフィールドも NULL ではない.", func(t *testing.T) {
+		for _, fieldName := range schema.Fields() {
+			isNull, err := recordPage.IsNull(SlotNumber(0), fieldName)
+			if assert.NoError(t, err) {
+				assert.Falsef(t, isNull, "field_name=%s", fieldName)
+			}
+		}
+	})
+
+	t.Run("SetNull したフィールドだけが NULL になり、値をセットすると NULL ではなくなる.", func(t *testing.T) {
+		assert.NoError(t, recordPage.SetNull(SlotNumber(1), "name"))
+
+		isNull, _ := recordPage.IsNull(SlotNumber(1), "name")
+		assert.True(t, isNull, "name は NULL になっている.")
+		isNull, _ = recordPage.IsNull(SlotNumber(1), "age")
+		assert.False(t, isNull, "同じスロットの他のフィールドは NULL ではない.")
+		isNull, _ = recordPage.IsNull(SlotNumber(2), "name")
+		assert.False(t, isNull, "他のスロットの同じフィールドは NULL ではない.")
+
+		assert.NoError(t, recordPage.SetString(SlotNumber(1), "name", "hoge"))
+		isNull, _ = recordPage.IsNull(SlotNumber(1), "name")
+		assert.False(t, isNull, "値をセットすると NULL ではなくなる.")
+	})
+
+	t.Run("存在しないフィールド名を指定した場合、エラーが返ること.", func(t *testing.T) {
+		_, err := recordPage.IsNull(SlotNumber(0), "unknown")
+		assert.Error(t, err)
+		assert.Error(t, recordPage.SetNull(SlotNumber(0), "unknown"))
+	})
+
+	t.Run("null bitmap の無い形式では、NULL にならず、NULL を書き込むとエラーになる.", func(t *testing.T) {
+		legacyBlockID := transaction.Append(fileName)
+		transaction.Pin(legacyBlockID)
+		defer transaction.Unpin(legacyBlockID)
+
+		legacyRecordPage := NewRecordPage(transaction, legacyBlockID, NewLayoutOf(schema, constants.RECORD_FORMAT_WITHOUT_NULL_BITMAP))
+		legacyRecordPage.Format()
+
+		assert.NoError(t, legacyRecordPage.SetString(SlotNumber(0), "name", "hoge"))
+		isNull, err := legacyRecordPage.IsNull(SlotNumber(0), "name")
+		if assert.NoError(t, err) {
+			assert.False(t, isNull)
+		}
+		assert.IsType(t, &NullNotSupportedError{}, legacyRecordPage.SetNull(SlotNumber(0), "name"))
+	})
+}
+
+func TestRecordPageSetStringTooLong(t *testing.T) {
+	transaction := newTransactionForTest(t, recordPageTestName)
+
+	fileName := "test_record_page_set_string_too_long.table"
+	blockID := transaction.Append(fileName)
+	transaction.Pin(blockID)
+	defer transaction.Unpin(blockID)
+
+	schema := buildTestTableSchema()
+	layout := NewLayout(schema)
+
+	recordPage := NewRecordPage(transaction, blockID, layout)
+	recordPage.Format()
+
+	assert.NoError(t, recordPage.SetInt(SlotNumber(0), "age", 20))
+
+	t.Run("フィールドの長さちょうどの文字列は書き込める.", func(t *testing.T) {
+		assert.NoError(t, recordPage.SetString(SlotNumber(0), "name", "0123456789"))
+		value, _ := recordPage.GetString(SlotNumber(0), "name")
+		assert.Equal(t, "0123456789", value)
+	})
+
+	t.Run("フィールドの長さを超える文字列はエラーになり、次のフィールドを上書きしない.", func(t *testing.T) {
+		assert.Error(t, recordPage.SetString(SlotNumber(0), "name", "0123456789a"))
+
+		value, _ := recordPage.GetString(SlotNumber(0), "name")
+		assert.Equal(t, "0123456789", value, "元の値が残っている.")
+		age, _ := recordPage.GetInt(SlotNumber(0), "age")
+		assert.Equal(t, types.Int(20), age, "次のフィールドの値が変わっていない.")
+	})
+}
+
+func TestRecordPageClear(t *testing.T) {
+	transaction := newTransactionForTest(t, recordPageTestName)
+
+	fileName := "test_record_page_clear.table"
+	blockID := transaction.Append(fileName)
+	transaction.Pin(blockID)
+
+	layout := NewLayout(buildTestTableSchema())
+
+	recordPage := NewRecordPage(transaction, blockID, layout)
+	recordPage.Format()
+	slotNumber := recordPage.FindEmptySlotAfter(NULL_SLOT_NUMBER)
+	recordPage.SetInt(slotNumber, "id", 1)
+	recordPage.SetString(slotNumber, "name", "alice")
+	transaction.Unpin(blockID)
+	transaction.Commit()
+
+	t.Run("Clear したブロックには、どの layout で読んでも使用中のスロットが無い.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, recordPageTestName)
+		transaction.Pin(blockID)
+		defer transaction.Rollback()
+		defer transaction.Unpin(blockID)
+
+		NewRecordPage(transaction, blockID, layout).Clear()
+
+		otherSchema := NewSchema()
+		otherSchema.AddStringField("note", 3)
+		for _, l := range []*Layout{layout, NewLayout(otherSchema)} {
+			assert.Equal(t, NULL_SLOT_NUMBER, NewRecordPage(transaction, blockID, l).FindUsedSlotAfter(NULL_SLOT_NUMBER), "使用中のスロットは無い.")
+		}
+	})
+
+	t.Run("Clear はロールバックすると元に戻る.", func(t *testing.T) {
+		transaction := newTransactionForTest(t, recordPageTestName)
+		transaction.Pin(blockID)
+		defer transaction.Unpin(blockID)
+
+		recordPage := NewRecordPage(transaction, blockID, layout)
+		assert.Equal(t, slotNumber, recordPage.FindUsedSlotAfter(NULL_SLOT_NUMBER), "ロールバックしたので、元のスロットが使用中に戻っている.")
+		name, _ := recordPage.GetString(slotNumber, "name")
+		assert.Equal(t, "alice", name, "元の値に戻っている.")
+	})
+}
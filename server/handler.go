@@ -51,11 +51,19 @@ func (h *SimpleDBSQLHandler) HandleQuery(sql string) (*mysql.Result, error) {
 	if err1 == nil {
 		return result, nil
 	}
+	// SELECT 文として実行できた上でのエラーなので、そのままクライアントに返す.
+	if myError, ok := toMySQLError(err1); ok {
+		return nil, myError
+	}
 
 	result, err2 := doUpdate(h, sql)
 	if err2 == nil {
 		return result, nil
 	}
+	// 更新系の SQL として実行できた上でのエラーなので、そのままクライアントに返す.
+	if myError, ok := toMySQLError(err2); ok {
+		return nil, myError
+	}
 
 	result, err3 := doTransactionCommand(h, sql)
 	if err3 == nil {
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"errors"
+	"simple-db-go/metadata"
 	"simple-db-go/parsing"
 	"simple-db-go/parsing/data"
+	"simple-db-go/planning"
 	"simple-db-go/query"
 
 	"github.com/go-mysql-org/go-mysql/mysql"
@@ -36,9 +39,113 @@ func doUpdate(handler *SimpleDBSQLHandler, sql string) (*mysql.Result, error) {
 	result := mysql.NewResult(nil)
 	result.Status = mysql.SERVER_STATUS_IN_TRANS // AUTOCOMMIT=off がデフォルトの想定とする.
 	result.AffectedRows = uint64(affectedRows)
+	result.InsertId = uint64(handler.planner.GetLastInsertID())
 	return result, nil
 }
 
+// go-mysql に定義されていない MySQL 8.0 のエラーコード.
+const (
+	ER_CTE_MAX_RECURSION_DEPTH                = 3636
+	ER_CHECK_CONSTRAINT_VIOLATED              = 3819
+	ER_CHECK_CONSTRAINT_REFERS_UNKNOWN_COLUMN = 3820
+)
+
+// 制約違反やクエリの実行中のエラーのように、MySQL に対応するエラーコードがあるエラーは、そのエラーコードの MyError に変換する.
+func toMySQLError(err error) (*mysql.MyError, bool) {
+	var duplicateKeyError planning.DuplicateKeyError
+	var notNullError planning.NotNullConstraintViolationError
+	var checkConstraintViolationError planning.CheckConstraintViolationError
+	var defaultValueTypeError planning.DefaultValueTypeError
+	var invalidCheckConstraintError planning.InvalidCheckConstraintError
+	var noReferencedRowError planning.NoReferencedRowError
+	var rowIsReferencedError planning.RowIsReferencedError
+	var tableIsReferencedError planning.TableIsReferencedError
+	var cannotTruncateReferencedTableError planning.CannotTruncateReferencedTableError
+	var tableAlreadyExistsError metadata.TableAlreadyExistsError
+	var viewAlreadyExistsError metadata.ViewAlreadyExistsError
+	var indexAlreadyExistsError metadata.IndexAlreadyExistsError
+	var indexFieldNotFoundError metadata.IndexFieldNotFoundError
+	var nameTooLongError metadata.NameTooLongError
+	var duplicateFieldError planning.DuplicateFieldError
+	var cannotDropAllFieldsError planning.CannotDropAllFieldsError
+	var fieldInForeignKeyError planning.FieldInForeignKeyError
+	var fieldNotFoundError planning.FieldNotFoundInTableError
+	var duplicateInsertFieldError planning.DuplicateInsertFieldError
+	var duplicateAssignmentError planning.DuplicateAssignmentError
+	var valueCountError planning.InsertValueCountError
+	var valueTypeError planning.FieldValueTypeError
+	var valueTooLongError planning.FieldValueTooLongError
+	var scalarSubqueryError *query.ScalarSubqueryMultipleRowsError
+	var duplicateQueryableError planning.DuplicateQueryableError
+	var recursionDepthExceededError *query.RecursionDepthExceededError
+	var negativeWindowOffsetError *query.NegativeWindowOffsetError
+	var arithmeticTypeError *query.ArithmeticTypeError
+	var divisionByZeroError *query.DivisionByZeroError
+	var arithmeticOverflowError *query.ArithmeticOverflowError
+
+	switch {
+	case errors.As(err, &duplicateKeyError):
+		return mysql.NewError(mysql.ER_DUP_ENTRY, err.Error()), true
+	case errors.As(err, &notNullError):
+		return mysql.NewError(mysql.ER_BAD_NULL_ERROR, err.Error()), true
+	case errors.As(err, &checkConstraintViolationError):
+		return mysql.NewError(ER_CHECK_CONSTRAINT_VIOLATED, err.Error()), true
+	case errors.As(err, &defaultValueTypeError):
+		return mysql.NewError(mysql.ER_INVALID_DEFAULT, err.Error()), true
+	case errors.As(err, &invalidCheckConstraintError):
+		// サブクエリを含む CHECK 制約も、テーブルのフィールド以外を参照するものとして同じエラーコードにする.
+		return mysql.NewError(ER_CHECK_CONSTRAINT_REFERS_UNKNOWN_COLUMN, err.Error()), true
+	case errors.As(err, &noReferencedRowError):
+		return mysql.NewError(mysql.ER_NO_REFERENCED_ROW_2, err.Error()), true
+	case errors.As(err, &rowIsReferencedError):
+		return mysql.NewError(mysql.ER_ROW_IS_REFERENCED_2, err.Error()), true
+	case errors.As(err, &tableIsReferencedError):
+		return mysql.NewError(mysql.ER_ROW_IS_REFERENCED, err.Error()), true
+	case errors.As(err, &cannotTruncateReferencedTableError):
+		return mysql.NewError(mysql.ER_TRUNCATE_ILLEGAL_FK, err.Error()), true
+	case errors.As(err, &tableAlreadyExistsError), errors.As(err, &viewAlreadyExistsError):
+		return mysql.NewError(mysql.ER_TABLE_EXISTS_ERROR, err.Error()), true
+	case errors.As(err, &indexAlreadyExistsError):
+		return mysql.NewError(mysql.ER_DUP_KEYNAME, err.Error()), true
+	case errors.As(err, &indexFieldNotFoundError):
+		return mysql.NewError(mysql.ER_KEY_COLUMN_DOES_NOT_EXITS, err.Error()), true
+	case errors.As(err, &nameTooLongError):
+		return mysql.NewError(mysql.ER_TOO_LONG_IDENT, err.Error()), true
+	case errors.As(err, &duplicateFieldError):
+		return mysql.NewError(mysql.ER_DUP_FIELDNAME, err.Error()), true
+	case errors.As(err, &cannotDropAllFieldsError):
+		return mysql.NewError(mysql.ER_CANT_REMOVE_ALL_FIELDS, err.Error()), true
+	case errors.As(err, &fieldInForeignKeyError):
+		return mysql.NewError(mysql.ER_FK_COLUMN_CANNOT_DROP, err.Error()), true
+	case errors.As(err, &fieldNotFoundError):
+		return mysql.NewError(mysql.ER_BAD_FIELD_ERROR, err.Error()), true
+	case errors.As(err, &duplicateInsertFieldError), errors.As(err, &duplicateAssignmentError):
+		return mysql.NewError(mysql.ER_FIELD_SPECIFIED_TWICE, err.Error()), true
+	case errors.As(err, &valueCountError):
+		return mysql.NewError(mysql.ER_WRONG_VALUE_COUNT_ON_ROW, err.Error()), true
+	case errors.As(err, &valueTypeError):
+		return mysql.NewError(mysql.ER_TRUNCATED_WRONG_VALUE_FOR_FIELD, err.Error()), true
+	case errors.As(err, &valueTooLongError):
+		return mysql.NewError(mysql.ER_DATA_TOO_LONG, err.Error()), true
+	case errors.As(err, &scalarSubqueryError):
+		return mysql.NewError(mysql.ER_SUBQUERY_NO_1_ROW, err.Error()), true
+	case errors.As(err, &duplicateQueryableError):
+		return mysql.NewError(mysql.ER_NONUNIQ_TABLE, err.Error()), true
+	case errors.As(err, &recursionDepthExceededError):
+		return mysql.NewError(ER_CTE_MAX_RECURSION_DEPTH, err.Error()), true
+	case errors.As(err, &negativeWindowOffsetError):
+		return mysql.NewError(mysql.ER_WRONG_ARGUMENTS, err.Error()), true
+	case errors.As(err, &arithmeticTypeError):
+		return mysql.NewError(mysql.ER_TRUNCATED_WRONG_VALUE, err.Error()), true
+	case errors.As(err, &divisionByZeroError):
+		return mysql.NewError(mysql.ER_DIVISION_BY_ZERO, err.Error()), true
+	case errors.As(err, &arithmeticOverflowError):
+		return mysql.NewError(mysql.ER_DATA_OUT_OF_RANGE, err.Error()), true
+	default:
+		return nil, false
+	}
+}
+
 func doTransactionCommand(handler *SimpleDBSQLHandler, sql string) (*mysql.Result, error) {
 	parser := parsing.NewParser() // ここで parser 呼ぶのは違う気がするけど...
 
@@ -98,10 +205,14 @@ func buildResultsetValues(scan query.Scan) ([][]any, error) {
 			if err != nil {
 				return nil, err
 			}
+			// NULL の raw value は nil で、MySQL の NULL として送られる.
 			row = append(row, value.GetRawValue())
 		}
 		values = append(values, row)
 	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
 
 	return values, nil
 }
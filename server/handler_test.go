@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// クライアントに返したエラーの MySQL のエラーコード. MyError でなければ 0 を返す.
+func errorCode(err error) uint16 {
+	myError, ok := err.(*mysql.MyError)
+	if !ok {
+		return 0
+	}
+	return myError.Code
+}
+
+func TestHandleQueryCheckConstraintError(t *testing.T) {
+	handler := newHandlerForTest(t, checkConstraintErrorTestName)
+	defer handler.transaction.Rollback()
+
+	mustHandleQuery(t, handler, "CREATE TABLE users (id INT, age INT CHECK (age >= 0))")
+	mustHandleQuery(t, handler, "INSERT INTO users (id, age) VALUES (1, 20)")
+
+	t.Run("CHECK 制約に違反する INSERT, UPDATE は ER_CHECK_CONSTRAINT_VIOLATED になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("INSERT INTO users (id, age) VALUES (2, -1)")
+		assert.Equal(t, uint16(ER_CHECK_CONSTRAINT_VIOLATED), errorCode(err), err)
+
+		_, err = handler.HandleQuery("UPDATE users SET age = -1 WHERE id = 1")
+		assert.Equal(t, uint16(ER_CHECK_CONSTRAINT_VIOLATED), errorCode(err), err)
+	})
+
+	t.Run("フィールドの型と異なる DEFAULT は ER_INVALID_DEFAULT になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("CREATE TABLE invalid_defaults (id INT DEFAULT 'one')")
+		assert.Equal(t, uint16(mysql.ER_INVALID_DEFAULT), errorCode(err), err)
+	})
+
+	t.Run("テーブルに無いフィールドを参照する CHECK 制約は ER_CHECK_CONSTRAINT_REFERS_UNKNOWN_COLUMN になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("CREATE TABLE invalid_checks (id INT CHECK (code > 0))")
+		assert.Equal(t, uint16(ER_CHECK_CONSTRAINT_REFERS_UNKNOWN_COLUMN), errorCode(err), err)
+	})
+}
+
+func TestHandleQueryFieldValueError(t *testing.T) {
+	handler := newHandlerForTest(t, fieldValueErrorTestName)
+	defer handler.transaction.Rollback()
+
+	mustHandleQuery(t, handler, "CREATE TABLE users (id INT, name VARCHAR(5))")
+
+	t.Run("値の数がフィールドの数と異なる INSERT は ER_WRONG_VALUE_COUNT_ON_ROW になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("INSERT INTO users (id, name) VALUES (1)")
+		assert.Equal(t, uint16(mysql.ER_WRONG_VALUE_COUNT_ON_ROW), errorCode(err), err)
+	})
+
+	t.Run("フィールドの型と異なる値は ER_TRUNCATED_WRONG_VALUE_FOR_FIELD になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("INSERT INTO users (id, name) VALUES ('one', 'alice')")
+		assert.Equal(t, uint16(mysql.ER_TRUNCATED_WRONG_VALUE_FOR_FIELD), errorCode(err), err)
+	})
+
+	t.Run("VARCHAR のフィールドの長さを超える文字列は ER_DATA_TOO_LONG になること.", func(t *testing.T) {
+		_, err := handler.HandleQuery("INSERT INTO users (id, name) VALUES (1, 'charlie')")
+		assert.Equal(t, uint16(mysql.ER_DATA_TOO_LONG), errorCode(err), err)
+	})
+}
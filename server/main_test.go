@@ -0,0 +1,51 @@
+package server
+
+import (
+	"os"
+	"simple-db-go/config"
+	"simple-db-go/file"
+	"simple-db-go/metadata"
+	"simple-db-go/planning"
+	"simple-db-go/transaction"
+	"simple-db-go/util"
+	"testing"
+)
+
+const checkConstraintErrorTestName = "check_constraint_error_test"
+const fieldValueErrorTestName = "field_value_error_test"
+
+func TestMain(m *testing.M) {
+	testNames := []string{
+		checkConstraintErrorTestName,
+		fieldValueErrorTestName,
+	}
+
+	for _, name := range testNames {
+		util.Cleanup(name)
+	}
+
+	code := m.Run()
+
+	for _, name := range testNames {
+		util.Cleanup(name)
+	}
+	os.Exit(code)
+}
+
+// テスト用のデータベースを作り、そのデータベースに SQL を実行するハンドラーを返す.
+func newHandlerForTest(t *testing.T, testName string) *SimpleDBSQLHandler {
+	config := config.NewDBConfigForTest(t, testName, 512, 30)
+	transaction := transaction.NewTransactionForTest(testName, config)
+	fileManager := file.GetManagerForTest(testName)
+	metadataManager := metadata.StartManagerForTest(testName, config, fileManager.IsNew(), transaction)
+	planner := planning.NewPlanner(planning.NewBasicQueryPlanner(metadataManager), planning.NewBasicUpdatePlanner(metadataManager))
+	return &SimpleDBSQLHandler{dbConfig: config, transaction: transaction, planner: planner}
+}
+
+// テストの準備のための SQL を実行する. 失敗した場合はテストを中断する.
+func mustHandleQuery(t *testing.T, handler *SimpleDBSQLHandler, sql string) {
+	t.Helper()
+	if _, err := handler.HandleQuery(sql); err != nil {
+		t.Fatalf("SQL の実行に失敗しました. sql=%s, err=%v", sql, err)
+	}
+}
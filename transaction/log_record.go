@@ -16,6 +16,8 @@ const (
 	ROLLBACK
 	SETINT
 	SETSTRING
+	REMOVEFILE
+	TRUNCATEFILE
 )
 
 const DummyTransactionNumber types.TransactionNumber = -1
@@ -42,6 +44,10 @@ func CreateLogRecord(b []byte) LogRecord {
 		return NewSetIntRecord(page)
 	case SETSTRING:
 		return NewSetStringRecord(page)
+	case REMOVEFILE:
+		return NewRemoveFileRecord(page)
+	case TRUNCATEFILE:
+		return NewTruncateFileRecord(page)
 	default:
 		panic(fmt.Sprintf("Unknown record operator. got=%d\n", page.GetInt(0)))
 	}
@@ -46,13 +46,30 @@ func (rm *RecoveryManager) Rollback() {
 	rm.logManager.Flush(lsn) // ROLLBACK レコードを書き込んでいる
 }
 
+// コミット済みのトランザクションが削除するはずだったファイルは、Undo した変更を書き込んだ後に削除する.
+// CHECKPOINT レコードを書き込むと次のリカバリでは読まれないので、その前に削除する.
 func (rm *RecoveryManager) Recover() {
 	rm.doRecover()
 	rm.bufferManager.FlushAll(rm.transactionNumber)
+	rm.transaction.removeFiles()
 	lsn := WriteCheckpointRecord(rm.logManager)
 	rm.logManager.Flush(lsn)
 }
 
+// コミットした時にファイルを削除することをログに記録する.
+// COMMIT レコードと一緒にディスクに書き込まれるので、ファイルを削除する前にクラッシュしてもリカバリで削除し直せる.
+func (rm *RecoveryManager) RemoveFile(filename string) log.LSN {
+	return WriteRemoveFileRecord(rm.logManager, rm.transactionNumber, filename)
+}
+
+// ファイルを空にすることをログに記録する.
+// ファイルはログに記録した直後に退避するので、WAL のためにこの時点でログをディスクに書き込む.
+func (rm *RecoveryManager) TruncateFile(filename string, backupFilename string) log.LSN {
+	lsn := WriteTruncateFileRecord(rm.logManager, rm.transactionNumber, filename, backupFilename)
+	rm.logManager.Flush(lsn)
+	return lsn
+}
+
 // 古い値を buffer から読み出し、更新するためのログレコードをログに記録する
 // undoするための情報になるっぽい。
 func (rm *RecoveryManager) SetInt(buffer *buffer.Buffer, offset types.Int, newVal types.Int) log.LSN {
@@ -95,11 +112,15 @@ func (rm *RecoveryManager) doRollback() {
 // 対象にしている TransactionNumber に該当するレコードであれば何かしら処理をする.
 // CHECKPOINT レコード: 処理を止める. それ以前のログはすでに処理されているとみなす.
 // COMMIT, ROLLBACK レコード: そのトランザクションが終了したことを示すので、そのトランザクションに関する処理は行わない. 処理済みのトランザクション番号として記録しておく.
+// コミット済みのトランザクションの REMOVEFILE レコード: ファイルを削除する前にクラッシュした可能性があるので、削除するファイルとして登録する.
+// ただし、それより後のレコードで変更されたファイルは、削除した後に同じ名前で作り直されたものなので削除しない.
 // それ以外のレコード: まだ COMMIT または ROLLBACK されていないトランザクションのレコードなので、undo を実行する.
 //
 // 注意：doRollback とは違い、こちらはコミットされていない全てのトランザクションが対象.
 func (rm *RecoveryManager) doRecover() {
 	finishedTransactionNumbers := make(map[types.TransactionNumber]bool)
+	committedTransactionNumbers := make(map[types.TransactionNumber]bool)
+	modifiedFiles := make(map[string]bool)
 	rawLogRecordChan := rm.logManager.StreamLogs()
 
 	for rawLogRecord := range rawLogRecordChan {
@@ -109,8 +130,20 @@ func (rm *RecoveryManager) doRecover() {
 			return
 		}
 
+		switch logRecord := logRecord.(type) {
+		case *SetIntRecord:
+			modifiedFiles[logRecord.blockID.Filename] = true
+		case *SetStringRecord:
+			modifiedFiles[logRecord.blockID.Filename] = true
+		case *RemoveFileRecord:
+			if committedTransactionNumbers[logRecord.transactionNumber] && !modifiedFiles[logRecord.filename] {
+				rm.transaction.filesToRemove = append(rm.transaction.filesToRemove, logRecord.filename)
+			}
+		}
+
 		if logRecord.GetOperation() == COMMIT || logRecord.GetOperation() == ROLLBACK {
 			finishedTransactionNumbers[logRecord.GetTransactionNumber()] = true
+			committedTransactionNumbers[logRecord.GetTransactionNumber()] = logRecord.GetOperation() == COMMIT
 		} else if _, exists := finishedTransactionNumbers[logRecord.GetTransactionNumber()]; !exists {
 			// 注意：他のトランザクションの変更も Undo する可能性がある.
 			//      つまり、logRecord に記録されているトランザクション番号と、引数に渡している rm.transaction は別の番号である可能性もあることに注意.
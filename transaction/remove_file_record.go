@@ -0,0 +1,73 @@
+package transaction
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/file"
+	"simple-db-go/log"
+	"simple-db-go/types"
+	"simple-db-go/util"
+)
+
+type RemoveFileRecord struct {
+	transactionNumber types.TransactionNumber
+	// コミットした時に削除するファイル名
+	filename string
+}
+
+/*
+# REMOVEFILE レコードの構造
+
+```example
+<REMOVEFILE 0, users.tbl>
+```
+
+* 1つ目：REMOVEFILE
+* 2つ目：トランザクション番号
+* 3つ目：コミットした時に削除するファイル名
+
+ファイルはコミットした後に削除するので、削除する前にクラッシュした場合は、リカバリでコミット済みのトランザクションのファイルを削除し直す.
+*/
+func NewRemoveFileRecord(page *file.Page) *RemoveFileRecord {
+	tpos := constants.Int32ByteSize
+	txNum := types.TransactionNumber(page.GetInt(tpos))
+
+	fpos := tpos + constants.Int32ByteSize
+	filename := page.GetString(fpos)
+
+	return &RemoveFileRecord{
+		transactionNumber: txNum,
+		filename:          filename,
+	}
+}
+
+func (rfr *RemoveFileRecord) GetOperation() RecordOperator {
+	return REMOVEFILE
+}
+
+func (rfr *RemoveFileRecord) GetTransactionNumber() types.TransactionNumber {
+	return rfr.transactionNumber
+}
+
+// ファイルはコミットするまで削除しないので、元に戻すものはない.
+func (rfr *RemoveFileRecord) Undo(t *Transaction) {
+	// することがないので何もしない
+}
+
+func (rfr *RemoveFileRecord) ToString() string {
+	return fmt.Sprintf("<REMOVEFILE %d %s>", rfr.transactionNumber, rfr.filename)
+}
+
+func WriteRemoveFileRecord(logManager *log.LogManager, transactionNumber types.TransactionNumber, filename string) log.LSN {
+	tpos := constants.Int32ByteSize
+	fpos := tpos + constants.Int32ByteSize
+	recordLength := fpos + file.MaxLength(util.Len(filename))
+
+	rawLogRecord := make([]byte, recordLength)
+	page := file.NewPageFrom(rawLogRecord)
+	page.SetInt(0, types.Int(REMOVEFILE))
+	page.SetInt(tpos, types.Int(transactionNumber))
+	page.SetString(fpos, filename)
+
+	return logManager.Append(page.Data)
+}
@@ -6,6 +6,7 @@ import (
 	"simple-db-go/file"
 	"simple-db-go/log"
 	"simple-db-go/types"
+	"slices"
 )
 
 const END_OF_FILE types.Int = -1
@@ -17,6 +18,13 @@ type Transaction struct {
 	fileManager        *file.FileManager
 	transactionNumber  types.TransactionNumber
 	bufferList         *BufferList
+	// コミットした時に削除するファイル. DROP TABLE したテーブルのファイルなど.
+	filesToRemove []string
+	// コミットした時に、末尾の空のブロックを切り詰めるファイル. TRUNCATE TABLE したテーブルのファイルなど.
+	filesToTruncate []string
+	// コミットした時、ロールバックした時に呼ぶ関数. メモリ上に保持しているメタデータの破棄などに使う.
+	commitCallbacks   []func()
+	rollbackCallbacks []func()
 }
 
 func NewTransaction(
@@ -45,18 +53,47 @@ func NewTransaction(
 
 func (t *Transaction) Commit() {
 	t.recoveryManager.Commit()
-	t.concurrencyManager.Release()
 	t.bufferList.UnpinAll()
+	// NOTE: ファイルの EOF のロックを解放する前に切り詰め、削除する.
+	// truncateFiles は削除するファイルを filesToRemove で判定して飛ばすので、removeFiles より先に呼ぶ.
+	t.truncateFiles()
+	t.removeFiles()
+	// NOTE: 他のトランザクションがコミット前の状態を読まないように、ロックを解放する前に呼ぶ.
+	t.runCallbacks(t.commitCallbacks)
+	t.concurrencyManager.Release()
 	fmt.Printf("transaction %d committed.\n", t.transactionNumber)
 }
 
+// ロールバックした場合は、ファイルは削除しない.
 func (t *Transaction) Rollback() {
 	t.recoveryManager.Rollback()
-	t.concurrencyManager.Release()
 	t.bufferList.UnpinAll()
+	t.filesToRemove = nil
+	t.filesToTruncate = nil
+	t.runCallbacks(t.rollbackCallbacks)
+	t.concurrencyManager.Release()
 	fmt.Printf("transaction %d rolled back.\n", t.transactionNumber)
 }
 
+// コミットした時に呼ぶ関数を登録する. ロックを解放する前に、登録した順に呼ぶ.
+func (t *Transaction) OnCommit(callback func()) {
+	t.commitCallbacks = append(t.commitCallbacks, callback)
+}
+
+// ロールバックした時に呼ぶ関数を登録する. ロックを解放する前に、登録した順に呼ぶ.
+func (t *Transaction) OnRollback(callback func()) {
+	t.rollbackCallbacks = append(t.rollbackCallbacks, callback)
+}
+
+// コミットとロールバックのどちらか一方の関数しか呼ばないように、両方とも破棄してから呼ぶ.
+func (t *Transaction) runCallbacks(callbacks []func()) {
+	t.commitCallbacks = nil
+	t.rollbackCallbacks = nil
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
 // NOTE: 他のトランザクションも含めて、完了していないトランザクションの変更を全て Undo する.
 func (t *Transaction) Recover() {
 	t.bufferManager.FlushAll(t.transactionNumber)
@@ -126,6 +163,97 @@ func (t *Transaction) Size(filename string) types.Int {
 	return t.fileManager.GetBlockLength(filename)
 }
 
+// コミットした時にファイルを削除するように登録する.
+// ファイルの中身はロールバックで元に戻せる必要があるので、この時点では削除しない.
+// 削除することはログに記録するので、コミットした後、削除する前にクラッシュしてもリカバリで削除される.
+// 注意：End Of File marker に対してのロックを獲得して、他のトランザクションがファイルを読み書きしないようにする.
+func (t *Transaction) RemoveFileOnCommit(filename string) {
+	dummyBlockID := file.NewBlockID(filename, types.BlockNumber(END_OF_FILE))
+	t.concurrencyManager.XLock(dummyBlockID)
+	if !t.IsRemovedOnCommit(filename) {
+		t.recoveryManager.RemoveFile(filename)
+		t.filesToRemove = append(t.filesToRemove, filename)
+	}
+}
+
+// コミットした時に削除するように登録されたファイルかどうか.
+func (t *Transaction) IsRemovedOnCommit(filename string) bool {
+	return slices.Contains(t.filesToRemove, filename)
+}
+
+func (t *Transaction) removeFiles() {
+	for _, filename := range t.filesToRemove {
+		t.bufferManager.DiscardFile(filename)
+		t.fileManager.Remove(filename)
+	}
+	t.filesToRemove = nil
+}
+
+// ファイルを空にする. ブロックごとの変更ではなく、ファイルを空にしたことを1つのログレコードに記録する.
+// 元のファイルは退避用のファイルに名前を変えて残しておき、ロールバックやリカバリの Undo で元の名前に戻す.
+// 退避用のファイルはコミットした時に削除する.
+// 注意：End Of File marker と全てのブロックに対してのロックを獲得して、他のトランザクションがファイルを読み書きしないようにする.
+func (t *Transaction) TruncateFile(filename string) {
+	dummyBlockID := file.NewBlockID(filename, types.BlockNumber(END_OF_FILE))
+	t.concurrencyManager.XLock(dummyBlockID)
+	blockCount := t.fileManager.GetBlockLength(filename)
+	for blockNumber := types.BlockNumber(0); blockNumber < types.BlockNumber(blockCount); blockNumber++ {
+		t.concurrencyManager.XLock(file.NewBlockID(filename, blockNumber))
+	}
+
+	// 同じトランザクションで何度も空にした場合に備えて、使われていない名前を探す.
+	var backupFilename string
+	for i := 0; ; i++ {
+		backupFilename = fmt.Sprintf("%s.truncated.%d", filename, i)
+		if !t.fileManager.Exists(backupFilename) {
+			break
+		}
+	}
+
+	t.recoveryManager.TruncateFile(filename, backupFilename)
+	// 退避するファイルに、このトランザクションでの変更も含める. 変更はログに残っているので、Undo で元に戻せる.
+	t.bufferManager.FlushAll(t.transactionNumber)
+	t.bufferManager.DiscardFile(filename)
+	t.fileManager.Rename(filename, backupFilename)
+	t.RemoveFileOnCommit(backupFilename)
+}
+
+// コミットした時に、ファイルの末尾にある 0 で埋められたブロックを切り詰めるように登録する.
+// ブロックを空にする変更はログに残して行い、切り詰めはコミットの後で行う.
+// そのため、途中でクラッシュしても、Undo で元の内容に戻るか、空のブロックが残るだけになる.
+// 注意：End Of File marker に対してのロックを獲得して、他のトランザクションがブロックを追加しないようにする.
+func (t *Transaction) TruncateFileOnCommit(filename string) {
+	dummyBlockID := file.NewBlockID(filename, types.BlockNumber(END_OF_FILE))
+	t.concurrencyManager.XLock(dummyBlockID)
+	if !slices.Contains(t.filesToTruncate, filename) {
+		t.filesToTruncate = append(t.filesToTruncate, filename)
+	}
+}
+
+// コミット済みの内容はディスクに書き込まれているので、ディスクのブロックを末尾から読んで空かどうか判定する.
+// 切り詰めたブロックを保持しているバッファーは破棄する.
+func (t *Transaction) truncateFiles() {
+	for _, filename := range t.filesToTruncate {
+		if t.IsRemovedOnCommit(filename) {
+			continue
+		}
+
+		blockCount := t.fileManager.GetBlockLength(filename)
+		page := file.NewPage(t.fileManager.BlockSize())
+		for blockCount > 0 {
+			t.fileManager.Read(file.NewBlockID(filename, types.BlockNumber(blockCount-1)), page)
+			if slices.ContainsFunc(page.Data, func(b byte) bool { return b != 0 }) {
+				break
+			}
+			blockCount--
+		}
+
+		t.bufferManager.DiscardFile(filename)
+		t.fileManager.Truncate(filename, blockCount)
+	}
+	t.filesToTruncate = nil
+}
+
 // 注意：End Of File marker に対してのロックを獲得して排他制御をする.
 func (t *Transaction) Append(filename string) file.BlockID {
 	dummyBlockID := file.NewBlockID(filename, types.BlockNumber(END_OF_FILE))
@@ -321,3 +321,239 @@ func TestTransactionAppend(t *testing.T) {
 		assert.NotPanics(t, func() { transaction3.Size(fileName) }, "他トランザクションからのファイルサイズの取得が可能になる.")
 	})
 }
+
+func TestTransactionRemoveFileOnCommit(t *testing.T) {
+	fileManager := file.GetManagerForTest(transactionTestName)
+
+	// テスト用の削除対象ファイルを準備しておく.
+	prepareFile := func(fileName string) file.BlockID {
+		testBlockID := file.NewBlockID(fileName, 0)
+		testPage := file.NewPage(blockSize)
+		testPage.SetString(0, "test")
+		fileManager.Write(testBlockID, testPage)
+		return testBlockID
+	}
+
+	t.Run("コミットするまではファイルは削除されず、コミットすると削除される.", func(t *testing.T) {
+		fileName := "test_transaction_remove_file_commit.data"
+		testBlockID := prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.Pin(testBlockID)
+		transaction.SetString(testBlockID, 0, "modified", true)
+		transaction.RemoveFileOnCommit(fileName)
+
+		assert.True(t, transaction.IsRemovedOnCommit(fileName))
+		assert.FileExists(t, path.Join(transactionTestName, fileName), "コミット前はファイルが残っている.")
+
+		transaction.Commit()
+		assert.NoFileExists(t, path.Join(transactionTestName, fileName), "コミットするとファイルが削除される.")
+	})
+
+	t.Run("ロールバックするとファイルは削除されない.", func(t *testing.T) {
+		fileName := "test_transaction_remove_file_rollback.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.RemoveFileOnCommit(fileName)
+		transaction.Rollback()
+
+		assert.False(t, transaction.IsRemovedOnCommit(fileName))
+		assert.FileExists(t, path.Join(transactionTestName, fileName), "ロールバックしたのでファイルが残っている.")
+	})
+
+	t.Run("コミットした後、ファイルを削除する前にクラッシュしても、リカバリで削除される.", func(t *testing.T) {
+		fileName := "test_transaction_remove_file_recover.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.RemoveFileOnCommit(fileName)
+		// COMMIT レコードだけ書き込んで、ファイルを削除する前にクラッシュしたものとする.
+		transaction.recoveryManager.Commit()
+		rebootDatabaseForTransactionTest(t)
+		assert.FileExists(t, path.Join(transactionTestName, fileName), "クラッシュしたのでファイルが残っている.")
+
+		rebootTransaction := startNewTransactionForTest(t, transactionTestName)
+		rebootTransaction.Recover()
+		assert.NoFileExists(t, path.Join(transactionTestName, fileName), "リカバリでファイルが削除される.")
+	})
+
+	t.Run("削除した後に同じ名前で作り直したファイルは、リカバリで削除されない.", func(t *testing.T) {
+		fileName := "test_transaction_remove_file_recreate.data"
+		testBlockID := file.NewBlockID(fileName, 0)
+		file.GetManagerForTest(transactionTestName).Write(testBlockID, file.NewPage(blockSize))
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.RemoveFileOnCommit(fileName)
+		transaction.Commit()
+
+		transaction = startNewTransactionForTest(t, transactionTestName)
+		transaction.Append(fileName)
+		transaction.Pin(testBlockID)
+		transaction.SetInt(testBlockID, 0, 1, true)
+		transaction.Commit()
+
+		rebootDatabaseForTransactionTest(t)
+		rebootTransaction := startNewTransactionForTest(t, transactionTestName)
+		rebootTransaction.Recover()
+		assert.FileExists(t, path.Join(transactionTestName, fileName), "作り直したファイルは残っている.")
+	})
+}
+
+func TestTransactionTruncateFileOnCommit(t *testing.T) {
+	fileManager := file.GetManagerForTest(transactionTestName)
+
+	// ブロック 0 と 1 に値が入った、3 ブロックのファイルを準備しておく.
+	prepareFile := func(fileName string) {
+		testPage := file.NewPage(blockSize)
+		testPage.SetInt(0, 1)
+		fileManager.Write(file.NewBlockID(fileName, 0), testPage)
+		fileManager.Write(file.NewBlockID(fileName, 1), testPage)
+		fileManager.Write(file.NewBlockID(fileName, 2), file.NewPage(blockSize))
+	}
+
+	t.Run("コミットすると、末尾の空のブロックが切り詰められる.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_file_commit.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		testBlockID := file.NewBlockID(fileName, 1)
+		transaction.Pin(testBlockID)
+		transaction.SetInt(testBlockID, 0, 0, true)
+		transaction.TruncateFileOnCommit(fileName)
+		assert.Equal(t, types.Int(3), fileManager.GetBlockLength(fileName), "コミット前はブロック数は変わらない.")
+
+		transaction.Commit()
+		assert.Equal(t, types.Int(1), fileManager.GetBlockLength(fileName), "空になったブロック 1 と 2 が切り詰められる.")
+	})
+
+	t.Run("ロールバックすると切り詰めない.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_file_rollback.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		testBlockID := file.NewBlockID(fileName, 1)
+		transaction.Pin(testBlockID)
+		transaction.SetInt(testBlockID, 0, 0, true)
+		transaction.TruncateFileOnCommit(fileName)
+		transaction.Rollback()
+
+		assert.Equal(t, types.Int(3), fileManager.GetBlockLength(fileName))
+		page := file.NewPage(blockSize)
+		fileManager.Read(testBlockID, page)
+		assert.Equal(t, types.Int(1), page.GetInt(0), "ブロック 1 の値は元に戻る.")
+	})
+
+	t.Run("同じファイルを削除するようにも登録した場合は、切り詰めずに削除する.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_file_remove.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.TruncateFileOnCommit(fileName)
+		transaction.RemoveFileOnCommit(fileName)
+		transaction.Commit()
+
+		assert.NoFileExists(t, path.Join(transactionTestName, fileName), "削除したファイルが作り直されない.")
+	})
+}
+
+func TestTransactionTruncateFile(t *testing.T) {
+	// ブロック 0 と 1 に値が入ったファイルを準備しておく.
+	prepareFile := func(fileName string) {
+		fileManager := file.GetManagerForTest(transactionTestName)
+		testPage := file.NewPage(blockSize)
+		testPage.SetInt(0, 1)
+		fileManager.Write(file.NewBlockID(fileName, 0), testPage)
+		fileManager.Write(file.NewBlockID(fileName, 1), testPage)
+	}
+
+	t.Run("コミットすると、ファイルが空になり、退避したファイルは削除される.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_commit.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.TruncateFile(fileName)
+		assert.Equal(t, types.Int(0), transaction.Size(fileName), "トランザクション内ではすぐに空になる.")
+
+		transaction.Commit()
+		fileManager := file.GetManagerForTest(transactionTestName)
+		assert.Equal(t, types.Int(0), fileManager.GetBlockLength(fileName))
+		assert.NoFileExists(t, path.Join(transactionTestName, fileName+".truncated.0"), "退避したファイルは削除される.")
+	})
+
+	t.Run("ロールバックすると、空にした後の変更も含めて元の内容に戻る.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_rollback.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		testBlockID := file.NewBlockID(fileName, 0)
+		transaction.Pin(testBlockID)
+		transaction.SetInt(testBlockID, 0, 2, true)
+		transaction.Unpin(testBlockID)
+		transaction.TruncateFile(fileName)
+		transaction.Append(fileName)
+		transaction.Pin(testBlockID)
+		transaction.SetInt(testBlockID, 0, 3, true)
+		transaction.Rollback()
+
+		fileManager := file.GetManagerForTest(transactionTestName)
+		assert.Equal(t, types.Int(2), fileManager.GetBlockLength(fileName))
+		page := file.NewPage(blockSize)
+		fileManager.Read(testBlockID, page)
+		assert.Equal(t, types.Int(1), page.GetInt(0), "ブロック 0 の値は元に戻る.")
+		assert.NoFileExists(t, path.Join(transactionTestName, fileName+".truncated.0"), "退避したファイルは元の名前に戻る.")
+	})
+
+	t.Run("コミットする前にクラッシュすると、リカバリで元の内容に戻る.", func(t *testing.T) {
+		fileName := "test_transaction_truncate_recover.data"
+		prepareFile(fileName)
+
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.TruncateFile(fileName)
+		rebootDatabaseForTransactionTest(t)
+
+		rebootTransaction := startNewTransactionForTest(t, transactionTestName)
+		rebootTransaction.Recover()
+		fileManager := file.GetManagerForTest(transactionTestName)
+		assert.Equal(t, types.Int(2), fileManager.GetBlockLength(fileName))
+		page := file.NewPage(blockSize)
+		fileManager.Read(file.NewBlockID(fileName, 1), page)
+		assert.Equal(t, types.Int(1), page.GetInt(0), "ブロック 1 の値は元に戻る.")
+	})
+}
+
+func TestTransactionCallbacks(t *testing.T) {
+	t.Run("コミットすると、OnCommit で登録した関数だけが登録した順に呼ばれる.", func(t *testing.T) {
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		calls := []string{}
+		transaction.OnCommit(func() { calls = append(calls, "commit1") })
+		transaction.OnRollback(func() { calls = append(calls, "rollback") })
+		transaction.OnCommit(func() { calls = append(calls, "commit2") })
+
+		transaction.Commit()
+		assert.Equal(t, []string{"commit1", "commit2"}, calls)
+	})
+
+	t.Run("ロールバックすると、OnRollback で登録した関数だけが呼ばれる.", func(t *testing.T) {
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		calls := []string{}
+		transaction.OnCommit(func() { calls = append(calls, "commit") })
+		transaction.OnRollback(func() { calls = append(calls, "rollback") })
+
+		transaction.Rollback()
+		assert.Equal(t, []string{"rollback"}, calls)
+	})
+
+	t.Run("コールバックはロックを解放する前に呼ばれる.", func(t *testing.T) {
+		fileName := "test_transaction_callbacks.data"
+		transaction := startNewTransactionForTest(t, transactionTestName)
+		transaction.Append(fileName)
+
+		transaction2 := startNewTransactionForTest(t, transactionTestName)
+		transaction.OnCommit(func() {
+			assert.Panics(t, func() { transaction2.Size(fileName) }, "コールバックの中では、まだ XLock が獲得されている.")
+		})
+		transaction.Commit()
+		assert.NotPanics(t, func() { transaction2.Size(fileName) }, "コミット後は XLock が解放される.")
+	})
+}
@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"fmt"
+	"simple-db-go/constants"
+	"simple-db-go/file"
+	"simple-db-go/log"
+	"simple-db-go/types"
+	"simple-db-go/util"
+)
+
+type TruncateFileRecord struct {
+	transactionNumber types.TransactionNumber
+	// 空にしたファイル名
+	filename string
+	// 空にする前のファイルを退避したファイル名
+	backupFilename string
+}
+
+/*
+# TRUNCATEFILE レコードの構造
+
+```example
+<TRUNCATEFILE 0, users.tbl, users.tbl.truncated.0>
+```
+
+* 1つ目：TRUNCATEFILE
+* 2つ目：トランザクション番号
+* 3つ目：空にしたファイル名
+* 4つ目：空にする前のファイルを退避したファイル名
+*/
+func NewTruncateFileRecord(page *file.Page) *TruncateFileRecord {
+	tpos := constants.Int32ByteSize
+	txNum := types.TransactionNumber(page.GetInt(tpos))
+
+	fpos := tpos + constants.Int32ByteSize
+	filename := page.GetString(fpos)
+
+	bpos := fpos + file.MaxLength(util.Len(filename))
+	backupFilename := page.GetString(bpos)
+
+	return &TruncateFileRecord{
+		transactionNumber: txNum,
+		filename:          filename,
+		backupFilename:    backupFilename,
+	}
+}
+
+func (tfr *TruncateFileRecord) GetOperation() RecordOperator {
+	return TRUNCATEFILE
+}
+
+func (tfr *TruncateFileRecord) GetTransactionNumber() types.TransactionNumber {
+	return tfr.transactionNumber
+}
+
+// 退避したファイルを元の名前に戻す. 空にした後のファイルのブロックを保持しているバッファーは破棄する.
+// ファイルを退避する前にクラッシュした場合は、退避したファイルが無く、元のファイルもそのままなので何もしない.
+func (tfr *TruncateFileRecord) Undo(t *Transaction) {
+	if !t.fileManager.Exists(tfr.backupFilename) {
+		return
+	}
+	t.bufferManager.DiscardFile(tfr.filename)
+	t.fileManager.Rename(tfr.backupFilename, tfr.filename)
+}
+
+func (tfr *TruncateFileRecord) ToString() string {
+	return fmt.Sprintf("<TRUNCATEFILE %d %s %s>", tfr.transactionNumber, tfr.filename, tfr.backupFilename)
+}
+
+func WriteTruncateFileRecord(logManager *log.LogManager, transactionNumber types.TransactionNumber, filename string, backupFilename string) log.LSN {
+	tpos := constants.Int32ByteSize
+	fpos := tpos + constants.Int32ByteSize
+	bpos := fpos + file.MaxLength(util.Len(filename))
+	recordLength := bpos + file.MaxLength(util.Len(backupFilename))
+
+	rawLogRecord := make([]byte, recordLength)
+	page := file.NewPageFrom(rawLogRecord)
+	page.SetInt(0, types.Int(TRUNCATEFILE))
+	page.SetInt(tpos, types.Int(transactionNumber))
+	page.SetString(fpos, filename)
+	page.SetString(bpos, backupFilename)
+
+	return logManager.Append(page.Data)
+}
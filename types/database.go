@@ -18,6 +18,9 @@ type FieldType Int
 // 整数フィールドの場合は 0 とし、この値は使わない（固定長のため）.
 type FieldLength Int
 
+// DBレコードの物理的な形式のバージョン. テーブルごとに table_catalog に記録する.
+type RecordFormat Int
+
 // 各スロット内における、フィールドのオフセット
 // 前にあるフィールドの長さの合計＋フラグの長さ(4bytes)
 type FieldOffsetInSlot Int
@@ -33,5 +36,14 @@ func (v ViewDef) ToSQL() SQL { return SQL(v) }
 // DB インデックス名
 type IndexName string
 
+// テーブルの制約の種類
+type ConstraintType Int
+
+// 外部キーで参照されているレコードを削除した時の動作
+type ReferentialAction Int
+
+// DB 制約定義. `NOT NULL`, `DEFAULT 0`, `CHECK (age = 20)` のように SQL で表したもの.
+type ConstraintDef string
+
 // SQL 文
 type SQL string